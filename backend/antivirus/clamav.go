@@ -0,0 +1,189 @@
+// Package antivirus scans uploaded files for malware through a ClamAV daemon
+// (clamd) before they are stored in the library.
+package antivirus
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"strings"
+	"time"
+
+	"mangahub/backend/config"
+
+	"go.uber.org/zap"
+)
+
+// chunkSize is how much of a file is streamed to clamd at a time
+const chunkSize = 64 << 10
+
+// logger discards output until SetLogger installs the configured logger
+var logger = zap.NewNop()
+
+// SetLogger sets the logger used by this package
+func SetLogger(l *zap.Logger) {
+	logger = l
+}
+
+var (
+	network string
+	address string
+	timeout time.Duration
+)
+
+// InfectedError reports that clamd found malware in a file
+type InfectedError struct {
+	Signature string
+}
+
+func (e InfectedError) Error() string {
+	return fmt.Sprintf("infected with %s", e.Signature)
+}
+
+// IsInfected checks if an error is an InfectedError
+func IsInfected(err error) bool {
+	_, ok := err.(InfectedError)
+	return ok
+}
+
+// Init checks the ClamAV settings. Without an address, scanning stays
+// disabled. An unreachable daemon is only logged, since it may start later;
+// uploads are refused until it answers.
+func Init(cfg config.ClamAVConfig) error {
+	if cfg.Address == "" {
+		logger.Info("No ClamAV daemon configured; uploads are not scanned")
+		return nil
+	}
+	if cfg.TimeoutSeconds <= 0 {
+		return fmt.Errorf("clamav timeout must be positive, got %d", cfg.TimeoutSeconds)
+	}
+
+	network, address = "tcp", cfg.Address
+	if path, ok := strings.CutPrefix(cfg.Address, "unix:"); ok {
+		network, address = "unix", path
+	} else if strings.HasPrefix(cfg.Address, "/") {
+		network = "unix"
+	}
+	timeout = time.Duration(cfg.TimeoutSeconds) * time.Second
+
+	if reply, err := command(context.Background(), "PING", nil); err != nil || reply != "PONG" {
+		logger.Warn("ClamAV daemon is not answering", zap.String("address", cfg.Address), zap.String("reply", reply), zap.Error(err))
+	} else {
+		logger.Info("ClamAV scanning enabled", zap.String("address", cfg.Address))
+	}
+	return nil
+}
+
+// Enabled reports whether a ClamAV daemon is configured
+func Enabled() bool {
+	return address != ""
+}
+
+// Scan streams data to clamd. It returns an InfectedError when malware is
+// found, and nil when scanning is disabled.
+func Scan(ctx context.Context, data io.Reader) error {
+	if !Enabled() {
+		return nil
+	}
+	reply, err := command(ctx, "INSTREAM", data)
+	if err != nil {
+		logger.Error("ClamAV scan failed", zap.Error(err))
+		return fmt.Errorf("virus scan failed: %w", err)
+	}
+
+	result := strings.TrimPrefix(reply, "stream: ")
+	switch {
+	case result == "OK":
+		return nil
+	case strings.HasSuffix(result, " FOUND"):
+		signature := strings.TrimSuffix(result, " FOUND")
+		logger.Warn("ClamAV found malware", zap.String("signature", signature))
+		return InfectedError{Signature: signature}
+	}
+	logger.Error("ClamAV scan failed", zap.String("reply", reply))
+	return fmt.Errorf("virus scan failed: %s", result)
+}
+
+// ScanFile scans the file at path, see Scan
+func ScanFile(ctx context.Context, path string) error {
+	if !Enabled() {
+		return nil
+	}
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	return Scan(ctx, file)
+}
+
+// ScanBytes scans data held in memory, see Scan
+func ScanBytes(ctx context.Context, data []byte) error {
+	return Scan(ctx, bytes.NewReader(data))
+}
+
+// command sends a clamd command, streaming data after it for INSTREAM, and
+// returns the reply
+func command(ctx context.Context, name string, data io.Reader) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, network, address)
+	if err != nil {
+		return "", err
+	}
+	defer conn.Close()
+	deadline, _ := ctx.Deadline()
+	conn.SetDeadline(deadline)
+
+	// The "z" prefix makes clamd use NUL-terminated messages
+	if _, err := conn.Write([]byte("z" + name + "\x00")); err != nil {
+		return "", err
+	}
+	if data != nil {
+		if err := stream(conn, data); err != nil {
+			// clamd hangs up early on files over its StreamMaxLength and
+			// says why
+			if reply, readErr := readReply(conn); readErr == nil && reply != "" {
+				return reply, nil
+			}
+			return "", err
+		}
+	}
+	return readReply(conn)
+}
+
+// stream sends data in length-prefixed chunks, ending with an empty chunk
+func stream(conn net.Conn, data io.Reader) error {
+	buf := make([]byte, 4+chunkSize)
+	for {
+		n, err := data.Read(buf[4:])
+		if n > 0 {
+			binary.BigEndian.PutUint32(buf[:4], uint32(n))
+			if _, werr := conn.Write(buf[:4+n]); werr != nil {
+				return werr
+			}
+		}
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+	}
+	_, err := conn.Write([]byte{0, 0, 0, 0})
+	return err
+}
+
+func readReply(conn net.Conn) (string, error) {
+	reply, err := io.ReadAll(conn)
+	if err != nil && len(reply) == 0 {
+		return "", err
+	}
+	return strings.TrimSpace(strings.TrimRight(string(reply), "\x00")), nil
+}
@@ -0,0 +1,180 @@
+// Package bench implements "mangahub bench", a load generator that simulates
+// concurrent readers browsing and reading a library and reports latencies
+// per endpoint. By default it serves a synthetic library of its own, so runs
+// are comparable between versions; -url points it at a running server.
+package bench
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"mangahub/backend/routes"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// warmingHeader flags listings served before the library index is built
+const warmingHeader = "X-Library-Warming"
+
+// options are the command line settings of a run
+type options struct {
+	url      string
+	token    string
+	readers  int
+	duration time.Duration
+	think    time.Duration
+	library  Library
+	keep     bool
+}
+
+// Run runs the bench command with its arguments and returns the exit code
+func Run(args []string) int {
+	flags := flag.NewFlagSet("mangahub bench", flag.ContinueOnError)
+	var opts options
+	flags.StringVar(&opts.url, "url", "", "base URL of a running server to load; empty serves a synthetic library in-process")
+	flags.StringVar(&opts.token, "token", "", "API token sent as a bearer token, for servers that require sign-in")
+	flags.IntVar(&opts.readers, "readers", 10, "number of concurrent readers")
+	flags.DurationVar(&opts.duration, "duration", 30*time.Second, "how long readers keep reading")
+	flags.DurationVar(&opts.think, "think", 0, "pause between a reader's requests")
+	flags.IntVar(&opts.library.Series, "series", 100, "series in the synthetic library")
+	flags.IntVar(&opts.library.Chapters, "chapters", 5, "chapters per synthetic series")
+	flags.IntVar(&opts.library.Pages, "pages", 10, "pages per synthetic chapter")
+	flags.BoolVar(&opts.keep, "keep", false, "keep the synthetic library's directory instead of removing it")
+	if err := flags.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return 0
+		}
+		return 2
+	}
+	if opts.readers < 1 || opts.duration <= 0 {
+		fmt.Fprintln(os.Stderr, "readers and duration must be positive")
+		return 2
+	}
+
+	if err := run(opts); err != nil {
+		fmt.Fprintln(os.Stderr, "bench:", err)
+		return 1
+	}
+	return 0
+}
+
+func run(opts options) error {
+	client := &http.Client{Timeout: time.Minute}
+	baseURL := strings.TrimSuffix(opts.url, "/")
+
+	if baseURL == "" {
+		dir, err := os.MkdirTemp("", "mangahub-bench-")
+		if err != nil {
+			return err
+		}
+		if opts.keep {
+			fmt.Printf("Synthetic library kept in %s\n", dir)
+		} else {
+			defer os.RemoveAll(dir)
+		}
+
+		start := time.Now()
+		pages, err := opts.library.Generate(filepath.Join(dir, "manga"))
+		if err != nil {
+			return fmt.Errorf("generating the synthetic library: %w", err)
+		}
+		fmt.Printf("Generated %d series, %d pages in %s\n", opts.library.Series, pages, time.Since(start).Round(time.Millisecond))
+
+		start = time.Now()
+		server, listener, err := serve(dir)
+		if err != nil {
+			return err
+		}
+		// Let requests cut off by the end of the run finish before the
+		// directory is removed
+		defer server.Shutdown(context.Background())
+		baseURL = "http://" + listener.Addr().String()
+		if err := waitForIndex(client, baseURL, opts.token); err != nil {
+			return err
+		}
+		fmt.Printf("Library indexed in %s\n", time.Since(start).Round(time.Millisecond))
+	}
+
+	fmt.Printf("Running %d readers against %s for %s\n\n", opts.readers, baseURL, opts.duration)
+	results := newResults()
+	start := time.Now()
+	ctx, cancel := context.WithTimeout(context.Background(), opts.duration)
+	defer cancel()
+
+	var wg sync.WaitGroup
+	for i := 0; i < opts.readers; i++ {
+		r := &reader{
+			client:  client,
+			baseURL: baseURL,
+			token:   opts.token,
+			think:   opts.think,
+			results: results,
+			seed:    int64(i + 1),
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			r.run(ctx)
+		}()
+	}
+	wg.Wait()
+
+	results.print(os.Stdout, time.Since(start))
+	return nil
+}
+
+// serve starts the server in-process on a free local port with the library,
+// index, cache and user data under dir
+func serve(dir string) (*http.Server, net.Listener, error) {
+	gin.SetMode(gin.ReleaseMode)
+	routes.InitRoutes(filepath.Join(dir, "manga"), filepath.Join(dir, "library-index.json.gz"),
+		filepath.Join(dir, "cache"), filepath.Join(dir, "data"))
+	router := gin.New()
+	router.HandleMethodNotAllowed = true
+	router.Use(routes.HeadRequests)
+	routes.SetupRoutes(router)
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return nil, nil, err
+	}
+	server := &http.Server{Handler: routes.Methods(router)}
+	go server.Serve(listener)
+	return server, listener, nil
+}
+
+// waitForIndex waits until the server's library index is built, so readers
+// see the whole library
+func waitForIndex(client *http.Client, baseURL, token string) error {
+	for {
+		req, err := http.NewRequest(http.MethodGet, baseURL+"/api/manga?fields=id", nil)
+		if err != nil {
+			return err
+		}
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		resp, err := client.Do(req)
+		if err != nil {
+			return err
+		}
+		io.Copy(io.Discard, resp.Body)
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			return fmt.Errorf("listing the library: %s", resp.Status)
+		}
+		if resp.Header.Get(warmingHeader) == "" {
+			return nil
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+}
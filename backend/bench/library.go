@@ -0,0 +1,84 @@
+package bench
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"math/rand"
+	"os"
+	"path/filepath"
+)
+
+// Page images are about the size of a phone-resolution scan, so the resize
+// paths do realistic work
+const (
+	pageWidth  = 720
+	pageHeight = 1080
+)
+
+// Library describes the synthetic library to generate
+type Library struct {
+	Series   int
+	Chapters int // Per series
+	Pages    int // Per chapter
+}
+
+// Generate writes the library into dir as folders without metadata files,
+// the way a fresh import looks, and returns how many pages it wrote. Every
+// page is a hard link to one image where the filesystem allows it.
+func (l Library) Generate(dir string) (int, error) {
+	page, err := pageImage()
+	if err != nil {
+		return 0, err
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return 0, err
+	}
+	source := filepath.Join(dir, ".page.png")
+	if err := os.WriteFile(source, page, 0644); err != nil {
+		return 0, err
+	}
+	defer os.Remove(source)
+
+	written := 0
+	for s := 1; s <= l.Series; s++ {
+		for c := 1; c <= l.Chapters; c++ {
+			chapterDir := filepath.Join(dir, fmt.Sprintf("bench-series-%04d", s), fmt.Sprintf("chapter-%d", c))
+			if err := os.MkdirAll(chapterDir, 0755); err != nil {
+				return written, err
+			}
+			for p := 1; p <= l.Pages; p++ {
+				path := filepath.Join(chapterDir, fmt.Sprintf("%03d.png", p))
+				if err := os.Link(source, path); err != nil {
+					if err := os.WriteFile(path, page, 0644); err != nil {
+						return written, err
+					}
+				}
+				written++
+			}
+		}
+	}
+	return written, nil
+}
+
+// pageImage renders a noisy grayscale page, which compresses about as badly
+// as screentone does
+func pageImage() ([]byte, error) {
+	rng := rand.New(rand.NewSource(1))
+	img := image.NewGray(image.Rect(0, 0, pageWidth, pageHeight))
+	for i := range img.Pix {
+		img.Pix[i] = uint8(rng.Intn(256))
+	}
+	for y := pageHeight / 4; y < pageHeight*3/4; y++ {
+		for x := pageWidth / 4; x < pageWidth*3/4; x++ {
+			img.SetGray(x, y, color.Gray{Y: 255})
+		}
+	}
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
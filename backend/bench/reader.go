@@ -0,0 +1,180 @@
+package bench
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/rand"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"text/tabwriter"
+	"time"
+)
+
+// Endpoints are reported by route rather than by URL
+const (
+	endpointList     = "GET /api/manga"
+	endpointSearch   = "GET /api/search"
+	endpointSeries   = "GET /api/manga/:id"
+	endpointChapters = "GET /api/manga/:id/chapters"
+	endpointChapter  = "GET /api/manga/:id/chapter/:n"
+	endpointPage     = "GET /api/manga/:id/chapter/:n/page/:p/image"
+)
+
+// pageScales are the page sizes readers ask for, like phones and desktops
+var pageScales = []string{"", "0.5"}
+
+// reader browses to a random series, opens a random chapter and reads its
+// pages in order, over and over
+type reader struct {
+	client  *http.Client
+	baseURL string
+	token   string
+	think   time.Duration
+	results *results
+	seed    int64
+}
+
+func (r *reader) run(ctx context.Context) {
+	rng := rand.New(rand.NewSource(r.seed))
+	for ctx.Err() == nil {
+		var series []struct {
+			ID    string `json:"id"`
+			Title string `json:"title"`
+		}
+		if !r.get(ctx, endpointList, "/api/manga", &series) || len(series) == 0 {
+			continue
+		}
+		manga := series[rng.Intn(len(series))]
+		if words := strings.Fields(manga.Title); len(words) > 0 {
+			r.get(ctx, endpointSearch, "/api/search?q="+url.QueryEscape(words[rng.Intn(len(words))]), nil)
+		}
+		seriesPath := "/api/manga/" + url.PathEscape(manga.ID)
+		r.get(ctx, endpointSeries, seriesPath, nil)
+
+		var chapters []struct {
+			Number float64 `json:"number"`
+		}
+		if !r.get(ctx, endpointChapters, seriesPath+"/chapters", &chapters) || len(chapters) == 0 {
+			continue
+		}
+		chapterPath := fmt.Sprintf("%s/chapter/%g", seriesPath, chapters[rng.Intn(len(chapters))].Number)
+		var chapter struct {
+			Pages []struct {
+				Number int `json:"number"`
+			} `json:"pages"`
+		}
+		if !r.get(ctx, endpointChapter, chapterPath, &chapter) {
+			continue
+		}
+
+		scale := pageScales[rng.Intn(len(pageScales))]
+		for _, page := range chapter.Pages {
+			path := fmt.Sprintf("%s/page/%d/image", chapterPath, page.Number)
+			if scale != "" {
+				path += "?scale=" + scale
+			}
+			if !r.get(ctx, endpointPage, path, nil) {
+				break
+			}
+		}
+	}
+}
+
+// get requests path, timing it under endpoint, and decodes a JSON response
+// into v unless v is nil. It reports whether the request succeeded.
+func (r *reader) get(ctx context.Context, endpoint, path string, v interface{}) bool {
+	if r.think > 0 {
+		select {
+		case <-ctx.Done():
+			return false
+		case <-time.After(r.think):
+		}
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, r.baseURL+path, nil)
+	if err != nil {
+		return false
+	}
+	if r.token != "" {
+		req.Header.Set("Authorization", "Bearer "+r.token)
+	}
+
+	start := time.Now()
+	resp, err := r.client.Do(req)
+	if err != nil {
+		// Requests cut off by the end of the run aren't failures
+		if ctx.Err() == nil {
+			r.results.add(endpoint, time.Since(start), false)
+		}
+		return false
+	}
+	defer resp.Body.Close()
+	if v != nil && resp.StatusCode == http.StatusOK {
+		err = json.NewDecoder(resp.Body).Decode(v)
+	} else {
+		_, err = io.Copy(io.Discard, resp.Body)
+	}
+	if err != nil && ctx.Err() != nil {
+		return false
+	}
+	ok := err == nil && resp.StatusCode == http.StatusOK
+	r.results.add(endpoint, time.Since(start), ok)
+	return ok
+}
+
+// results collects the latencies of every request by endpoint
+type results struct {
+	mu        sync.Mutex
+	latencies map[string][]time.Duration
+	errors    map[string]int
+}
+
+func newResults() *results {
+	return &results{
+		latencies: make(map[string][]time.Duration),
+		errors:    make(map[string]int),
+	}
+}
+
+func (r *results) add(endpoint string, latency time.Duration, ok bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.latencies[endpoint] = append(r.latencies[endpoint], latency)
+	if !ok {
+		r.errors[endpoint]++
+	}
+}
+
+// print writes a table of request rates and latency percentiles
+func (r *results) print(w io.Writer, elapsed time.Duration) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(tw, "endpoint\trequests\terrors\treq/s\tp50\tp90\tp99\tmax\t")
+	endpoints := make([]string, 0, len(r.latencies))
+	for endpoint := range r.latencies {
+		endpoints = append(endpoints, endpoint)
+	}
+	sort.Strings(endpoints)
+	for _, endpoint := range endpoints {
+		latencies := r.latencies[endpoint]
+		sort.Slice(latencies, func(i, j int) bool { return latencies[i] < latencies[j] })
+		fmt.Fprintf(tw, "%s\t%d\t%d\t%.1f\t%s\t%s\t%s\t%s\t\n",
+			endpoint, len(latencies), r.errors[endpoint],
+			float64(len(latencies))/elapsed.Seconds(),
+			percentile(latencies, 50), percentile(latencies, 90), percentile(latencies, 99),
+			latencies[len(latencies)-1].Round(time.Microsecond))
+	}
+	tw.Flush()
+}
+
+// percentile returns the p-th percentile of sorted latencies
+func percentile(sorted []time.Duration, p int) time.Duration {
+	i := (len(sorted)*p+99)/100 - 1
+	return sorted[max(i, 0)].Round(time.Microsecond)
+}
@@ -0,0 +1,161 @@
+// Package capture keeps the most recent requests and responses to a chosen
+// set of routes in memory while an admin has debug capture switched on, so
+// API bugs clients report can be reproduced from what was actually sent.
+// Nothing is written to disk and capture switches itself off again.
+package capture
+
+import (
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// DefaultCapacity is how many exchanges the ring buffer holds
+const DefaultCapacity = 200
+
+// DefaultMaxBodyBytes caps each captured request and response body
+const DefaultMaxBodyBytes = 64 << 10
+
+// logger discards output until SetLogger installs the configured logger
+var logger = zap.NewNop()
+
+// SetLogger sets the logger used by this package
+func SetLogger(l *zap.Logger) {
+	logger = l
+}
+
+// Filter selects the requests to capture
+type Filter struct {
+	Paths   []string `json:"paths"`   // Path prefixes, e.g. "/api/manga"; empty captures every path
+	Methods []string `json:"methods"` // Empty captures every method
+}
+
+// Exchange is one captured request and its response
+type Exchange struct {
+	Time            time.Time           `json:"time"`
+	Method          string              `json:"method"`
+	Path            string              `json:"path"`
+	Query           string              `json:"query,omitempty"`
+	User            string              `json:"user,omitempty"`
+	RequestHeaders  map[string][]string `json:"requestHeaders"`
+	RequestBody     string              `json:"requestBody,omitempty"`
+	Status          int                 `json:"status"`
+	ResponseHeaders map[string][]string `json:"responseHeaders"`
+	ResponseBody    string              `json:"responseBody,omitempty"`
+	Truncated       bool                `json:"truncated,omitempty"` // A body was cut at the size cap
+	LatencyMS       float64             `json:"latencyMs"`
+}
+
+// Recorder holds the capture settings and the ring buffer
+type Recorder struct {
+	mu           sync.RWMutex
+	filter       Filter
+	until        time.Time // Capture is on before this
+	maxBodyBytes int
+	exchanges    []Exchange
+	next         int // Slot the next exchange goes into once the buffer is full
+	capacity     int
+}
+
+// NewRecorder creates a switched-off recorder holding up to capacity
+// exchanges
+func NewRecorder(capacity int) *Recorder {
+	if capacity <= 0 {
+		capacity = DefaultCapacity
+	}
+	return &Recorder{capacity: capacity, maxBodyBytes: DefaultMaxBodyBytes}
+}
+
+// Start captures requests matching filter until the given time, with bodies
+// capped at maxBodyBytes (0 keeps the default). Earlier exchanges are kept.
+func (r *Recorder) Start(filter Filter, until time.Time, maxBodyBytes int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for i, method := range filter.Methods {
+		filter.Methods[i] = strings.ToUpper(strings.TrimSpace(method))
+	}
+	r.filter = filter
+	r.until = until
+	r.maxBodyBytes = DefaultMaxBodyBytes
+	if maxBodyBytes > 0 {
+		r.maxBodyBytes = maxBodyBytes
+	}
+	logger.Info("Debug capture started", zap.Strings("paths", filter.Paths), zap.Strings("methods", filter.Methods), zap.Time("until", until))
+}
+
+// Stop switches capture off, keeping what was captured
+func (r *Recorder) Stop() {
+	r.mu.Lock()
+	r.until = time.Time{}
+	r.mu.Unlock()
+	logger.Info("Debug capture stopped")
+}
+
+// Status returns the filter, when capture ends (zero when it is off) and the
+// body size cap
+func (r *Recorder) Status(now time.Time) (Filter, time.Time, int) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if !now.Before(r.until) {
+		return r.filter, time.Time{}, r.maxBodyBytes
+	}
+	return r.filter, r.until, r.maxBodyBytes
+}
+
+// Wants reports whether a request should be captured now, and the body size
+// cap to capture it with
+func (r *Recorder) Wants(method, path string, now time.Time) (bool, int) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if !now.Before(r.until) {
+		return false, 0
+	}
+	if len(r.filter.Methods) > 0 && !slices.Contains(r.filter.Methods, method) {
+		return false, 0
+	}
+	if len(r.filter.Paths) == 0 {
+		return true, r.maxBodyBytes
+	}
+	for _, prefix := range r.filter.Paths {
+		if strings.HasPrefix(path, prefix) {
+			return true, r.maxBodyBytes
+		}
+	}
+	return false, 0
+}
+
+// Add stores an exchange, dropping the oldest once the buffer is full
+func (r *Recorder) Add(e Exchange) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if len(r.exchanges) < r.capacity {
+		r.exchanges = append(r.exchanges, e)
+		return
+	}
+	r.exchanges[r.next] = e
+	r.next = (r.next + 1) % r.capacity
+}
+
+// Exchanges returns the captured exchanges, oldest first
+func (r *Recorder) Exchanges() []Exchange {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	list := make([]Exchange, 0, len(r.exchanges))
+	list = append(list, r.exchanges[r.next:]...)
+	return append(list, r.exchanges[:r.next]...)
+}
+
+// Clear drops every captured exchange
+func (r *Recorder) Clear() {
+	r.mu.Lock()
+	r.exchanges, r.next = nil, 0
+	r.mu.Unlock()
+}
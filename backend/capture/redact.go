@@ -0,0 +1,179 @@
+package capture
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// redacted replaces secrets in captured headers and bodies
+const redacted = "[redacted]"
+
+// secretHeaders carry credentials and are never captured
+var secretHeaders = map[string]bool{
+	"Authorization": true,
+	"Cookie":        true,
+	"Set-Cookie":    true,
+	"X-Csrf-Token":  true,
+	"X-Api-Key":     true,
+	"X-Share-Token": true,
+}
+
+// secretFields are substrings of JSON keys, query parameters and route
+// parameters whose values are never captured
+var secretFields = []string{"password", "secret", "token", "apikey", "api_key"}
+
+// secretParams are query and route parameters carrying credentials whose
+// names don't give them away
+var secretParams = map[string]bool{"share": true, "code": true}
+
+var (
+	// secretJSONField matches a secret string field of a JSON body, even one
+	// cut off at the size cap
+	secretJSONField = regexp.MustCompile(`(?i)("[^"]*(?:password|secret|token|apikey|api_key)[^"]*"\s*:\s*)"(?:[^"\\]|\\.)*"?`)
+
+	// secretQueryParam matches a secret parameter of a query string or form
+	// body, or one embedded in a URL inside a body
+	secretQueryParam = regexp.MustCompile(`(?i)((?:^|[?&])(?:share|code|[^=&?#\s"]*(?:password|secret|token|apikey|api_key)[^=&?#\s"]*)=)[^&#\s"]*`)
+)
+
+// Headers copies headers with credentials redacted
+func Headers(header http.Header) map[string][]string {
+	copied := make(map[string][]string, len(header))
+	for name, values := range header {
+		if secretHeaders[http.CanonicalHeaderKey(name)] {
+			copied[name] = []string{redacted}
+			continue
+		}
+		copied[name] = append([]string(nil), values...)
+	}
+	return copied
+}
+
+// Query copies a raw query string with the values of secret parameters
+// redacted
+func Query(rawQuery string) string {
+	if rawQuery == "" {
+		return ""
+	}
+	pairs := strings.Split(rawQuery, "&")
+	for i, pair := range pairs {
+		key, _, hasValue := strings.Cut(pair, "=")
+		if name, err := url.QueryUnescape(key); hasValue && (err != nil || isSecretParam(name)) {
+			pairs[i] = key + "=" + redacted
+		}
+	}
+	return strings.Join(pairs, "&")
+}
+
+// Path copies a request path with the values of secret route parameters
+// redacted. route is the matched route pattern, e.g. "/kobo/:token/v1/...";
+// paths that don't follow it are returned as-is.
+func Path(path, route string) string {
+	routeSegments := strings.Split(route, "/")
+	segments := strings.Split(path, "/")
+	if len(routeSegments) != len(segments) {
+		return path
+	}
+	for i, segment := range routeSegments {
+		if name, ok := strings.CutPrefix(segment, ":"); ok && isSecretParam(name) {
+			segments[i] = redacted
+		}
+	}
+	return strings.Join(segments, "/")
+}
+
+// Body turns the captured start of a body of the given full size into text.
+// Secret fields of JSON bodies, including truncated ones, and secret
+// parameters of form bodies and embedded URLs are redacted. Binary bodies
+// are only described.
+func Body(body []byte, size int, contentType string) string {
+	if len(body) == 0 {
+		return ""
+	}
+	truncated := size > len(body)
+	if isBinary(contentType) || (!truncated && !utf8.Valid(body)) {
+		return "[" + strconv.Itoa(size) + " bytes of " + contentType + "]"
+	}
+	if strings.Contains(contentType, "json") && !truncated {
+		var value any
+		if err := json.Unmarshal(body, &value); err == nil {
+			var buf bytes.Buffer
+			encoder := json.NewEncoder(&buf)
+			encoder.SetEscapeHTML(false)
+			if encoder.Encode(redactValue(value)) == nil {
+				return strings.TrimSuffix(buf.String(), "\n")
+			}
+		}
+	}
+	return redactText(strings.ToValidUTF8(string(body), "\uFFFD"))
+}
+
+// redactText redacts the secrets a body that couldn't be decoded as JSON may
+// still contain
+func redactText(text string) string {
+	text = secretJSONField.ReplaceAllString(text, `$1"`+redacted+`"`)
+	return secretQueryParam.ReplaceAllString(text, "${1}"+redacted)
+}
+
+// isBinary reports whether a content type is one not worth showing as text
+func isBinary(contentType string) bool {
+	mediaType, _, _ := strings.Cut(strings.ToLower(contentType), ";")
+	mediaType = strings.TrimSpace(mediaType)
+	switch {
+	case strings.HasPrefix(mediaType, "text/"),
+		strings.Contains(mediaType, "json"),
+		strings.Contains(mediaType, "xml"),
+		mediaType == "application/x-www-form-urlencoded",
+		mediaType == "application/javascript":
+		return false
+	case strings.HasPrefix(mediaType, "image/"),
+		strings.HasPrefix(mediaType, "audio/"),
+		strings.HasPrefix(mediaType, "video/"),
+		strings.HasPrefix(mediaType, "application/"),
+		strings.HasPrefix(mediaType, "multipart/"):
+		return true
+	}
+	return false
+}
+
+// redactValue replaces the values of secret fields anywhere in a decoded
+// JSON value
+func redactValue(value any) any {
+	switch v := value.(type) {
+	case map[string]any:
+		for key, field := range v {
+			if isSecretField(key) {
+				v[key] = redacted
+			} else {
+				v[key] = redactValue(field)
+			}
+		}
+	case []any:
+		for i := range v {
+			v[i] = redactValue(v[i])
+		}
+	case string:
+		return secretQueryParam.ReplaceAllString(v, "${1}"+redacted)
+	}
+	return value
+}
+
+func isSecretParam(name string) bool {
+	return secretParams[strings.ToLower(name)] || isSecretField(name)
+}
+
+func isSecretField(key string) bool {
+	key = strings.ToLower(key)
+	for _, secret := range secretFields {
+		if strings.Contains(key, secret) {
+			return true
+		}
+	}
+	return false
+}
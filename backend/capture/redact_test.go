@@ -0,0 +1,97 @@
+package capture
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestQuery(t *testing.T) {
+	tests := []struct {
+		query string
+		want  string
+	}{
+		{"", ""},
+		{"page=2&q=berserk", "page=2&q=berserk"},
+		{"share=mhs_abc123&page=2", "share=[redacted]&page=2"},
+		{"page=1&api_key=xyz", "page=1&api_key=[redacted]"},
+		{"resetToken=abc", "resetToken=[redacted]"},
+	}
+	for _, tt := range tests {
+		if got := Query(tt.query); got != tt.want {
+			t.Errorf("Query(%q) = %q, want %q", tt.query, got, tt.want)
+		}
+	}
+}
+
+func TestPath(t *testing.T) {
+	tests := []struct {
+		path  string
+		route string
+		want  string
+	}{
+		{"/kobo/abc123/v1/library/sync", "/kobo/:token/v1/library/sync", "/kobo/[redacted]/v1/library/sync"},
+		{"/api/admin/invites/XYZ", "/api/admin/invites/:code", "/api/admin/invites/[redacted]"},
+		{"/api/manga/berserk", "/api/manga/:id", "/api/manga/berserk"},
+		{"/kobo/abc123/unknown", "", "/kobo/abc123/unknown"},
+	}
+	for _, tt := range tests {
+		if got := Path(tt.path, tt.route); got != tt.want {
+			t.Errorf("Path(%q, %q) = %q, want %q", tt.path, tt.route, got, tt.want)
+		}
+	}
+}
+
+func TestBody(t *testing.T) {
+	tests := []struct {
+		name        string
+		body        string
+		size        int
+		contentType string
+		want        string
+	}{
+		{
+			name:        "json",
+			body:        `{"username":"alice","password":"hunter2"}`,
+			contentType: "application/json",
+			want:        `{"password":"[redacted]","username":"alice"}`,
+		},
+		{
+			name:        "truncated json",
+			body:        `{"username":"alice","password":"hunt`,
+			size:        100,
+			contentType: "application/json",
+			want:        `{"username":"alice","password":"[redacted]"`,
+		},
+		{
+			name:        "url with share token",
+			body:        `{"url":"https://example.com/manga/1?share=mhs_abc"}`,
+			contentType: "application/json",
+			want:        `{"url":"https://example.com/manga/1?share=[redacted]"}`,
+		},
+		{
+			name:        "form",
+			body:        "username=alice&password=hunter2",
+			contentType: "application/x-www-form-urlencoded",
+			want:        "username=alice&password=[redacted]",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			size := max(tt.size, len(tt.body))
+			if got := Body([]byte(tt.body), size, tt.contentType); got != tt.want {
+				t.Errorf("Body() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHeaders(t *testing.T) {
+	header := http.Header{"X-Share-Token": {"mhs_abc"}, "Accept": {"application/json"}}
+	got := Headers(header)
+	if got["X-Share-Token"][0] != redacted {
+		t.Errorf("X-Share-Token = %q, want it redacted", got["X-Share-Token"][0])
+	}
+	if got["Accept"][0] != "application/json" {
+		t.Errorf("Accept = %q, want it kept", got["Accept"][0])
+	}
+}
@@ -0,0 +1,200 @@
+package collections
+
+import (
+	"errors"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"mangahub/backend/slug"
+	"mangahub/backend/storage"
+
+	"go.uber.org/zap"
+)
+
+const collectionsFileName = "collections.json"
+
+// ErrNotFound is returned for operations on a collection that doesn't exist
+var ErrNotFound = errors.New("collection not found")
+
+// logger discards output until SetLogger installs the configured logger
+var logger = zap.NewNop()
+
+// SetLogger sets the logger used by this package
+func SetLogger(l *zap.Logger) {
+	logger = l
+}
+
+// Collection is a named, ordered group of series
+type Collection struct {
+	ID        string    `json:"id"`
+	Name      string    `json:"name"`
+	Summary   string    `json:"summary,omitempty"`
+	MangaIDs  []string  `json:"mangaIds"`
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+// Store keeps collections in a JSON file in the data directory
+type Store struct {
+	path string
+
+	mu          sync.RWMutex
+	collections []*Collection
+}
+
+// NewStore loads the collection store from dataDir
+func NewStore(dataDir string) (*Store, error) {
+	s := &Store{path: filepath.Join(dataDir, collectionsFileName)}
+	if err := storage.LoadJSON(s.path, &s.collections); err != nil {
+		return nil, err
+	}
+	logger.Info("Collection store loaded", zap.Int("collectionCount", len(s.collections)))
+	return s, nil
+}
+
+// List returns every collection sorted by name
+func (s *Store) List() []Collection {
+	s.mu.RLock()
+	list := make([]Collection, 0, len(s.collections))
+	for _, c := range s.collections {
+		list = append(list, *c)
+	}
+	s.mu.RUnlock()
+
+	sort.Slice(list, func(i, j int) bool {
+		return strings.ToLower(list[i].Name) < strings.ToLower(list[j].Name)
+	})
+	return list
+}
+
+// Get returns one collection
+func (s *Store) Get(id string) (Collection, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if c := s.findLocked(id); c != nil {
+		return *c, nil
+	}
+	return Collection{}, ErrNotFound
+}
+
+// Create adds a collection, deriving its ID from the name
+func (s *Store) Create(name, summary string, mangaIDs []string) (Collection, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	base := slug.Make(name)
+	if base == "" {
+		base = slug.Fallback("collection", name)
+	}
+	id := base
+	for n := 2; s.findLocked(id) != nil; n++ {
+		id = base + "-" + strconv.Itoa(n)
+	}
+
+	now := time.Now().UTC()
+	c := &Collection{
+		ID:        id,
+		Name:      strings.TrimSpace(name),
+		Summary:   summary,
+		MangaIDs:  dedupe(mangaIDs),
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	s.collections = append(s.collections, c)
+	if err := s.saveLocked(); err != nil {
+		return Collection{}, err
+	}
+	return *c, nil
+}
+
+// Update replaces a collection's name, summary and series
+func (s *Store) Update(id, name, summary string, mangaIDs []string) (Collection, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	c := s.findLocked(id)
+	if c == nil {
+		return Collection{}, ErrNotFound
+	}
+	c.Name = strings.TrimSpace(name)
+	c.Summary = summary
+	c.MangaIDs = dedupe(mangaIDs)
+	c.UpdatedAt = time.Now().UTC()
+	if err := s.saveLocked(); err != nil {
+		return Collection{}, err
+	}
+	return *c, nil
+}
+
+// Merge adds series to the collection with the given name, creating it when
+// no collection has that name yet. Existing series keep their position.
+func (s *Store) Merge(name, summary string, mangaIDs []string) (Collection, error) {
+	s.mu.RLock()
+	var existing *Collection
+	for _, c := range s.collections {
+		if strings.EqualFold(c.Name, strings.TrimSpace(name)) {
+			copied := *c
+			existing = &copied
+			break
+		}
+	}
+	s.mu.RUnlock()
+
+	if existing == nil {
+		return s.Create(name, summary, mangaIDs)
+	}
+	if summary == "" {
+		summary = existing.Summary
+	}
+	return s.Update(existing.ID, existing.Name, summary, append(existing.MangaIDs, mangaIDs...))
+}
+
+// Delete removes a collection, reporting whether it existed
+func (s *Store) Delete(id string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i, c := range s.collections {
+		if c.ID == id {
+			s.collections = append(s.collections[:i], s.collections[i+1:]...)
+			return true, s.saveLocked()
+		}
+	}
+	return false, nil
+}
+
+func (s *Store) findLocked(id string) *Collection {
+	for _, c := range s.collections {
+		if c.ID == id {
+			return c
+		}
+	}
+	return nil
+}
+
+func (s *Store) saveLocked() error {
+	if err := storage.SaveJSON(s.path, s.collections); err != nil {
+		logger.Error("Failed to save collections", zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+// dedupe drops empty and repeated IDs, keeping the first occurrence
+func dedupe(ids []string) []string {
+	seen := make(map[string]bool, len(ids))
+	result := []string{}
+	for _, id := range ids {
+		if id == "" || seen[id] {
+			continue
+		}
+		seen[id] = true
+		result = append(result, id)
+	}
+	return result
+}
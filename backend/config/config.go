@@ -0,0 +1,615 @@
+// Package config loads the server configuration: built-in defaults, then an
+// optional JSON file, then MANGAHUB_* environment variables.
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"runtime"
+	"strconv"
+	"strings"
+
+	"mangahub/backend/storage"
+)
+
+const (
+	// DefaultFile is read when MANGAHUB_CONFIG doesn't name another file
+	DefaultFile = "./config.json"
+
+	// FileEnv names the environment variable selecting the config file
+	FileEnv = "MANGAHUB_CONFIG"
+)
+
+// Config stores application configuration
+type Config struct {
+	Port         string `json:"port"`
+	MangaRootDir string `json:"mangaRootDir"`
+	IndexFile    string `json:"indexFile"`
+	CacheDir     string `json:"cacheDir"`
+	DataDir      string `json:"dataDir"`
+
+	// PublicURL is the externally visible base URL (e.g.
+	// https://manga.example.com) used in the sitemap and share previews;
+	// empty derives it from each request
+	PublicURL string `json:"publicUrl"`
+
+	// SourcesDir holds external source plugins: executables that speak
+	// JSON over stdio
+	SourcesDir string `json:"sourcesDir"`
+
+	// StaticDir serves the frontend from a directory, e.g. a development
+	// build, instead of the build embedded in the binary
+	StaticDir string `json:"staticDir"`
+
+	// InboxDir is watched for new chapters to import automatically; empty
+	// disables the inbox. It is scanned every InboxIntervalSeconds.
+	InboxDir             string `json:"inboxDir"`
+	InboxIntervalSeconds int    `json:"inboxIntervalSeconds"`
+
+	// CountCheckHours is how often recorded page and chapter counts are
+	// checked against the files and corrected; 0 disables the check
+	CountCheckHours int `json:"countCheckHours"`
+
+	// MetadataRefreshHours is how often series linked to MangaDex or AniList
+	// through a "mangadex" or "anilist" custom field are refreshed from
+	// there; 0, the default, only refreshes when an admin asks
+	MetadataRefreshHours int `json:"metadataRefreshHours"`
+
+	// PregenerateWorkers is how many chapters have their resized page images
+	// and thumbnails generated ahead of time at once; 0 disables it
+	PregenerateWorkers int `json:"pregenerateWorkers"`
+
+	// CacheMaxMB caps the size of the generated images in CacheDir; the
+	// least recently used are removed to stay under it. 0, the default, is
+	// unlimited. A cap smaller than the pregenerated images of the library
+	// makes pregeneration evict what it generated before.
+	CacheMaxMB int `json:"cacheMaxMB"`
+
+	// ReadingSecondsPerPage is how long reading a page, or a screen of a
+	// webtoon strip, takes in chapter reading time estimates
+	ReadingSecondsPerPage int `json:"readingSecondsPerPage"`
+
+	// PersistDerivedMetadata saves the metadata derived for series and
+	// chapter folders without a metadata.json as they are scanned
+	PersistDerivedMetadata bool `json:"persistDerivedMetadata"`
+
+	// IgnoredFiles are glob patterns, e.g. "*.txt", of files in chapter
+	// folders that are neither pages nor sidecars; hidden files are always
+	// ignored
+	IgnoredFiles []string `json:"ignoredFiles"`
+
+	// PrefetchPages is how many upcoming page images readers are hinted to
+	// load ahead; 0 disables the hints
+	PrefetchPages int `json:"prefetchPages"`
+
+	// Limits tune how hard the server works the machine, e.g. lower for a
+	// small ARM NAS or higher for a many-core server
+	Limits LimitsConfig `json:"limits"`
+
+	Log       LogConfig       `json:"log"`
+	AccessLog AccessLogConfig `json:"accessLog"`
+	Reporting ReportingConfig `json:"reporting"`
+
+	// DedupPages stores page images by content hash, hard-linking identical
+	// pages so they use disk space once
+	DedupPages bool `json:"dedupPages"`
+
+	// ReencodeUploads decodes and re-encodes uploaded images instead of only
+	// stripping their metadata, neutralizing malformed files
+	ReencodeUploads bool `json:"reencodeUploads"`
+
+	// ImportPolicy downscales or recompresses oversized pages as chapters
+	// are imported into the shared library
+	ImportPolicy ImportPolicyConfig `json:"importPolicy"`
+
+	// GuestAccess sets what visitors who aren't signed in may do: "read"
+	// (the default), "safe" (read, except series marked NSFW), "browse"
+	// (the catalog, but no chapters) or "none"
+	GuestAccess string `json:"guestAccess"`
+
+	// DownloadsPerDay caps how many chapters each user, or each guest
+	// address, may download as offline bundles or EPUBs per UTC day; admins
+	// can raise it per user. 0, the default, is unlimited.
+	DownloadsPerDay int `json:"downloadsPerDay"`
+
+	// ContentSecurityPolicy replaces the default policy sent with every
+	// response, e.g. to allow a CDN; empty keeps the default
+	ContentSecurityPolicy string `json:"contentSecurityPolicy"`
+
+	// CORSOrigins are the origins (e.g. "https://reader.example.com") whose
+	// pages may call the API; "*" allows any origin without credentials
+	CORSOrigins []string `json:"corsOrigins"`
+
+	// TrustedProxies are the reverse proxies, as addresses or CIDR ranges,
+	// whose ClientIPHeaders name the real client, e.g. the address of nginx
+	// or Traefik; "cloudflare" trusts Cloudflare's edge and its
+	// CF-Connecting-IP header. Other peers can't spoof their address.
+	TrustedProxies  []string `json:"trustedProxies"`
+	ClientIPHeaders []string `json:"clientIpHeaders"`
+
+	// AccessRules limit route groups by client address, keyed by group:
+	// "all", "api", "admin", "kobo" or "images". For example
+	// {"admin": {"allow": ["192.168.0.0/16"]}} keeps the admin API on the
+	// LAN while reading stays public.
+	AccessRules map[string]AccessRuleConfig `json:"accessRules"`
+
+	// GeoIPDatabase is a MaxMind GeoLite2 or GeoIP2 Country database
+	// (.mmdb), needed by country access rules
+	GeoIPDatabase string `json:"geoipDatabase"`
+
+	// InviteOnly requires an invite from an admin to register, except for
+	// the first account
+	InviteOnly bool `json:"inviteOnly"`
+
+	// PersonalLibraries gives each user a private upload space next to the
+	// shared catalog
+	PersonalLibraries PersonalLibrariesConfig `json:"personalLibraries"`
+
+	// Naming sets the folder names new series and chapters are stored under
+	Naming NamingConfig `json:"naming"`
+
+	// Hooks run after library events such as a chapter being imported
+	Hooks []HookConfig `json:"hooks"`
+
+	// SMTP sends account emails such as password resets. Without a host,
+	// or without PublicURL to build their links from, those features are
+	// unavailable.
+	SMTP SMTPConfig `json:"smtp"`
+
+	// VerifyEmail mails new users a link to confirm their address
+	VerifyEmail bool `json:"verifyEmail"`
+
+	// ClamAV scans uploaded archives and images through a clamd daemon
+	// before they are stored. Without an address uploads aren't scanned.
+	ClamAV ClamAVConfig `json:"clamav"`
+
+	// Presence counts who is reading each series and chapter over a
+	// WebSocket and shows the counts, for community instances
+	Presence bool `json:"presence"`
+
+	// Kobo lets Kobo e-readers sync chapters as books; devices authenticate
+	// with a personal API token in the URL
+	Kobo bool `json:"kobo"`
+
+	// ExportCredits puts a generated credits page, with the series'
+	// creators, the chapter's scanlators and the export date, in front of
+	// exported chapters
+	ExportCredits ExportCreditsConfig `json:"exportCredits"`
+
+	// Watermark marks downloaded and exported pages with the downloader's
+	// username and the time, to trace leaks: "visible" draws it in a
+	// corner, "hidden" hides it in the pixels, "both" does both. Empty
+	// leaves pages untouched.
+	Watermark string `json:"watermark"`
+
+	// Libraries sets the scan policy of each library root, keyed by the
+	// root's path; MangaRootDir is currently the only root. Policies edited
+	// through the admin API are written back to the config file.
+	Libraries map[string]ScanPolicyConfig `json:"libraries"`
+}
+
+// ScanPolicyConfig sets how a library root is scanned
+type ScanPolicyConfig struct {
+	// IntervalMinutes rescans the root in the background; 0 leaves it to
+	// listings to notice changed folders
+	IntervalMinutes int `json:"intervalMinutes"`
+
+	// Archives turns CBZ files found in series folders into chapters when
+	// the root is scanned
+	Archives bool `json:"archives"`
+
+	// GenerateMetadata overrides PersistDerivedMetadata for this root
+	GenerateMetadata *bool `json:"generateMetadata,omitempty"`
+
+	// ContentRating is given to series found without a metadata.json:
+	// "safe" (the default) or "nsfw"
+	ContentRating string `json:"contentRating,omitempty"`
+}
+
+// ExportCreditsConfig sets up the credits page of exported chapters
+type ExportCreditsConfig struct {
+	Enabled bool `json:"enabled"`
+
+	// Template is a Go text/template file the page is rendered from; its
+	// first line is the page title. Empty uses the built-in template.
+	Template string `json:"template"`
+}
+
+// SMTPConfig is the mail server account emails are sent through. STARTTLS
+// is used when the server offers it.
+type SMTPConfig struct {
+	Host     string `json:"host"`
+	Port     int    `json:"port"`
+	Username string `json:"username"`
+	Password string `json:"password"`
+	From     string `json:"from"` // Sender address, e.g. "MangaHub <noreply@example.com>"
+}
+
+// ClamAVConfig is the clamd daemon uploads are scanned with
+type ClamAVConfig struct {
+	Address        string `json:"address"` // Unix socket path, or host:port for TCP
+	TimeoutSeconds int    `json:"timeoutSeconds"`
+}
+
+// LimitsConfig bounds the server's concurrency and resources
+type LimitsConfig struct {
+	// ImageDecodes is how many images are decoded and resized at once,
+	// which bounds the memory pixels take; 0 is unlimited. Defaults to the
+	// number of CPUs.
+	ImageDecodes int `json:"imageDecodes"`
+
+	// ScanWorkers is how many series folders are loaded at once when the
+	// library is scanned
+	ScanWorkers int `json:"scanWorkers"`
+
+	// OpenFiles sets the process' limit on open file descriptors, up to the
+	// system's hard limit; 0 keeps the limit the server starts with
+	OpenFiles int `json:"openFiles"`
+}
+
+// PersonalLibrariesConfig enables personal libraries and sets the default
+// per-user storage quota, which admins can override per user
+type PersonalLibrariesConfig struct {
+	Enabled bool `json:"enabled"`
+	QuotaMB int  `json:"quotaMB"`
+
+	// ImportPolicy applies to chapters uploaded to personal libraries
+	ImportPolicy ImportPolicyConfig `json:"importPolicy"`
+}
+
+// ImportPolicyConfig caps the size of imported pages; zero values are
+// unlimited. KeepOriginals saves the untouched pages in a hidden
+// ".originals" folder inside the chapter.
+type ImportPolicyConfig struct {
+	MaxWidth      int  `json:"maxWidth"`
+	MaxHeight     int  `json:"maxHeight"`
+	MaxKB         int  `json:"maxKB"` // Per page
+	KeepOriginals bool `json:"keepOriginals"`
+}
+
+// AccessRuleConfig allows or denies client addresses, as single addresses
+// or CIDR ranges, and countries, as ISO 3166-1 codes. Deny wins; when an
+// allow list is set, everything not on it is denied.
+type AccessRuleConfig struct {
+	Allow          []string `json:"allow"`
+	Deny           []string `json:"deny"`
+	AllowCountries []string `json:"allowCountries"`
+	DenyCountries  []string `json:"denyCountries"`
+}
+
+// NamingConfig holds folder name templates, e.g. "{series}" and
+// "{series}[ v{volume:00}] c{chapter:000}"; see package naming for the
+// placeholders. Empty templates keep the defaults: the series ID and
+// "chapter-" followed by the chapter number.
+type NamingConfig struct {
+	SeriesFolder  string `json:"seriesFolder"`
+	ChapterFolder string `json:"chapterFolder"`
+}
+
+// HookConfig is a command or HTTP callback run after matching events. The
+// command's arguments may use the placeholders {event}, {mangaId},
+// {chapterId}, {chapter} and {path}; the same values are passed in
+// MANGAHUB_* environment variables. The callback receives the event as a
+// JSON POST, signed with Secret if set.
+type HookConfig struct {
+	Name    string   `json:"name"`
+	Events  []string `json:"events"` // Event types; empty means chapter.imported
+	Command []string `json:"command"`
+	URL     string   `json:"url"`
+	Secret  string   `json:"secret"`
+
+	// TimeoutSeconds bounds each run; zero uses the default
+	TimeoutSeconds int `json:"timeoutSeconds"`
+}
+
+// ReportingConfig configures the optional Sentry/GlitchTip error reporter
+type ReportingConfig struct {
+	// DSN of the project to report to; empty disables reporting
+	DSN         string  `json:"dsn"`
+	Environment string  `json:"environment"`
+	Release     string  `json:"release"`
+	SampleRate  float64 `json:"sampleRate"` // Fraction of errors sent, 0-1
+}
+
+// LogConfig controls how much is logged, in which format, where logs are
+// written and how log files are rotated
+type LogConfig struct {
+	// Mode is "development" (debug level, readable console output) or
+	// "production" (info level, JSON output, sampling of repeated messages)
+	Mode string `json:"mode"`
+
+	// Level overrides the mode's minimum level: debug, info, warn or error
+	Level string `json:"level"`
+
+	// Format overrides the console output format: "console" or "json".
+	// The log file is always JSON.
+	Format string `json:"format"`
+
+	// Sampling overrides whether repeated messages are sampled
+	Sampling *bool `json:"sampling"`
+
+	// File is the log file path; empty disables file logging
+	File string `json:"file"`
+
+	// Rotation: a file is rotated once it reaches MaxSizeMB; rotated files
+	// are removed after MaxAgeDays or when there are more than MaxBackups
+	MaxSizeMB  int  `json:"maxSizeMB"`
+	MaxBackups int  `json:"maxBackups"`
+	MaxAgeDays int  `json:"maxAgeDays"`
+	Compress   bool `json:"compress"`
+
+	// Console also writes logs to stderr
+	Console bool `json:"console"`
+}
+
+// AccessLogConfig sends a JSON record of every request (method, path,
+// status, bytes, latency and user) to a sink of its own. Output is empty to
+// keep request records in the application log, "file" for a rotating File,
+// or "syslog" for the syslog daemon at SyslogAddress, e.g.
+// "udp://logs.lan:514"; an empty address is the local daemon.
+type AccessLogConfig struct {
+	Output string `json:"output"`
+
+	File       string `json:"file"`
+	MaxSizeMB  int    `json:"maxSizeMB"`
+	MaxBackups int    `json:"maxBackups"`
+	MaxAgeDays int    `json:"maxAgeDays"`
+	Compress   bool   `json:"compress"`
+
+	SyslogAddress string `json:"syslogAddress"`
+	SyslogTag     string `json:"syslogTag"`
+}
+
+// Default returns the configuration used when nothing is overridden
+func Default() Config {
+	return Config{
+		Port:         "8080",
+		MangaRootDir: "../manga",
+		IndexFile:    "./library-index.json.gz",
+		CacheDir:     "./cache",
+		DataDir:      "./data",
+		SourcesDir:   "./sources",
+
+		InboxIntervalSeconds: 30,
+		CountCheckHours:      24,
+		PregenerateWorkers:   1,
+
+		ReadingSecondsPerPage: 20,
+		PrefetchPages:         3,
+
+		Limits: LimitsConfig{
+			ImageDecodes: runtime.NumCPU(),
+			ScanWorkers:  1,
+		},
+
+		TrustedProxies:  []string{"127.0.0.1", "::1"},
+		ClientIPHeaders: []string{"X-Forwarded-For", "X-Real-IP"},
+
+		PersonalLibraries: PersonalLibrariesConfig{QuotaMB: 1024},
+		SMTP:              SMTPConfig{Port: 587},
+		ClamAV:            ClamAVConfig{TimeoutSeconds: 60},
+		Log: LogConfig{
+			Mode:       "development",
+			File:       "./manga-server.log",
+			MaxSizeMB:  100,
+			MaxBackups: 5,
+			MaxAgeDays: 30,
+			Compress:   true,
+			Console:    true,
+		},
+		AccessLog: AccessLogConfig{
+			File:       "./access.log",
+			MaxSizeMB:  100,
+			MaxBackups: 5,
+			MaxAgeDays: 30,
+			Compress:   true,
+			SyslogTag:  "mangahub",
+		},
+		Reporting: ReportingConfig{
+			Environment: "development",
+			SampleRate:  1,
+		},
+	}
+}
+
+// Load builds the configuration from the defaults, the config file (if it
+// exists) and the environment, in that order of precedence
+func Load() (Config, error) {
+	cfg := Default()
+
+	path := Path()
+	explicit := os.Getenv(FileEnv) != ""
+
+	data, err := os.ReadFile(path)
+	switch {
+	case err == nil:
+		if err := json.Unmarshal(data, &cfg); err != nil {
+			return Config{}, fmt.Errorf("failed to parse config file %s: %w", path, err)
+		}
+	case os.IsNotExist(err) && !explicit:
+		// No config file; defaults and environment only
+	default:
+		return Config{}, fmt.Errorf("failed to read config file %s: %w", path, err)
+	}
+
+	if err := applyEnv(&cfg); err != nil {
+		return Config{}, err
+	}
+	return cfg, nil
+}
+
+// Path returns the config file Load reads
+func Path() string {
+	if path := os.Getenv(FileEnv); path != "" {
+		return path
+	}
+	return DefaultFile
+}
+
+// SaveLibraries writes the library scan policies to the config file at path,
+// creating it if needed, and leaves its other settings as they are
+func SaveLibraries(path string, libraries map[string]ScanPolicyConfig) error {
+	settings := map[string]json.RawMessage{}
+	perm := os.FileMode(0644)
+	data, err := os.ReadFile(path)
+	switch {
+	case err == nil:
+		if err := json.Unmarshal(data, &settings); err != nil {
+			return fmt.Errorf("failed to parse config file %s: %w", path, err)
+		}
+		// Keep the file as private as it was; it may hold passwords
+		if info, err := os.Stat(path); err == nil {
+			perm = info.Mode().Perm()
+		}
+	case !os.IsNotExist(err):
+		return fmt.Errorf("failed to read config file %s: %w", path, err)
+	}
+
+	encoded, err := json.Marshal(libraries)
+	if err != nil {
+		return err
+	}
+	settings["libraries"] = encoded
+	data, err = json.MarshalIndent(settings, "", "  ")
+	if err != nil {
+		return err
+	}
+	return storage.WriteFileAtomic(path, append(data, '\n'), perm)
+}
+
+// applyEnv overrides settings from MANGAHUB_* environment variables
+func applyEnv(cfg *Config) error {
+	strings := map[string]*string{
+		"MANGAHUB_PORT":        &cfg.Port,
+		"MANGAHUB_MANGA_ROOT":  &cfg.MangaRootDir,
+		"MANGAHUB_INDEX_FILE":  &cfg.IndexFile,
+		"MANGAHUB_CACHE_DIR":   &cfg.CacheDir,
+		"MANGAHUB_DATA_DIR":    &cfg.DataDir,
+		"MANGAHUB_PUBLIC_URL":  &cfg.PublicURL,
+		"MANGAHUB_SOURCES_DIR": &cfg.SourcesDir,
+		"MANGAHUB_STATIC_DIR":  &cfg.StaticDir,
+		"MANGAHUB_INBOX_DIR":   &cfg.InboxDir,
+		"MANGAHUB_LOG_FILE":    &cfg.Log.File,
+		"MANGAHUB_LOG_MODE":    &cfg.Log.Mode,
+		"MANGAHUB_LOG_LEVEL":   &cfg.Log.Level,
+		"MANGAHUB_LOG_FORMAT":  &cfg.Log.Format,
+
+		"MANGAHUB_ACCESS_LOG":        &cfg.AccessLog.Output,
+		"MANGAHUB_ACCESS_LOG_FILE":   &cfg.AccessLog.File,
+		"MANGAHUB_ACCESS_LOG_SYSLOG": &cfg.AccessLog.SyslogAddress,
+
+		"MANGAHUB_GUEST_ACCESS": &cfg.GuestAccess,
+		"MANGAHUB_CSP":          &cfg.ContentSecurityPolicy,
+		"MANGAHUB_GEOIP_DB":     &cfg.GeoIPDatabase,
+
+		"MANGAHUB_EXPORT_CREDITS_TEMPLATE": &cfg.ExportCredits.Template,
+		"MANGAHUB_WATERMARK":               &cfg.Watermark,
+
+		"MANGAHUB_SERIES_FOLDER":  &cfg.Naming.SeriesFolder,
+		"MANGAHUB_CHAPTER_FOLDER": &cfg.Naming.ChapterFolder,
+
+		"MANGAHUB_SMTP_HOST":     &cfg.SMTP.Host,
+		"MANGAHUB_SMTP_USERNAME": &cfg.SMTP.Username,
+		"MANGAHUB_SMTP_PASSWORD": &cfg.SMTP.Password,
+		"MANGAHUB_SMTP_FROM":     &cfg.SMTP.From,
+
+		"MANGAHUB_CLAMAV_ADDRESS": &cfg.ClamAV.Address,
+
+		"MANGAHUB_SENTRY_DSN":         &cfg.Reporting.DSN,
+		"MANGAHUB_SENTRY_ENVIRONMENT": &cfg.Reporting.Environment,
+	}
+	for name, target := range strings {
+		if value, ok := os.LookupEnv(name); ok {
+			*target = value
+		}
+	}
+
+	lists := map[string]*[]string{
+		"MANGAHUB_CORS_ORIGINS":      &cfg.CORSOrigins,
+		"MANGAHUB_TRUSTED_PROXIES":   &cfg.TrustedProxies,
+		"MANGAHUB_CLIENT_IP_HEADERS": &cfg.ClientIPHeaders,
+	}
+	for name, target := range lists {
+		if value, ok := os.LookupEnv(name); ok {
+			*target = splitList(value)
+		}
+	}
+
+	ints := map[string]*int{
+		"MANGAHUB_INBOX_INTERVAL":   &cfg.InboxIntervalSeconds,
+		"MANGAHUB_USER_QUOTA_MB":    &cfg.PersonalLibraries.QuotaMB,
+		"MANGAHUB_SMTP_PORT":        &cfg.SMTP.Port,
+		"MANGAHUB_CLAMAV_TIMEOUT":   &cfg.ClamAV.TimeoutSeconds,
+		"MANGAHUB_LOG_MAX_SIZE_MB":  &cfg.Log.MaxSizeMB,
+		"MANGAHUB_LOG_MAX_BACKUPS":  &cfg.Log.MaxBackups,
+		"MANGAHUB_LOG_MAX_AGE_DAYS": &cfg.Log.MaxAgeDays,
+
+		"MANGAHUB_IMPORT_MAX_WIDTH":  &cfg.ImportPolicy.MaxWidth,
+		"MANGAHUB_IMPORT_MAX_HEIGHT": &cfg.ImportPolicy.MaxHeight,
+		"MANGAHUB_IMPORT_MAX_KB":     &cfg.ImportPolicy.MaxKB,
+		"MANGAHUB_COUNT_CHECK_HOURS": &cfg.CountCheckHours,
+
+		"MANGAHUB_METADATA_REFRESH_HOURS": &cfg.MetadataRefreshHours,
+		"MANGAHUB_PREGENERATE_WORKERS":    &cfg.PregenerateWorkers,
+		"MANGAHUB_CACHE_MAX_MB":           &cfg.CacheMaxMB,
+
+		"MANGAHUB_READING_SECONDS_PER_PAGE": &cfg.ReadingSecondsPerPage,
+		"MANGAHUB_PREFETCH_PAGES":           &cfg.PrefetchPages,
+		"MANGAHUB_DOWNLOADS_PER_DAY":        &cfg.DownloadsPerDay,
+
+		"MANGAHUB_MAX_IMAGE_DECODES": &cfg.Limits.ImageDecodes,
+		"MANGAHUB_SCAN_WORKERS":      &cfg.Limits.ScanWorkers,
+		"MANGAHUB_MAX_OPEN_FILES":    &cfg.Limits.OpenFiles,
+	}
+	for name, target := range ints {
+		if value, ok := os.LookupEnv(name); ok {
+			n, err := strconv.Atoi(value)
+			if err != nil {
+				return fmt.Errorf("%s must be a number: %w", name, err)
+			}
+			*target = n
+		}
+	}
+
+	bools := map[string]*bool{
+		"MANGAHUB_LOG_COMPRESS": &cfg.Log.Compress,
+		"MANGAHUB_LOG_CONSOLE":  &cfg.Log.Console,
+		"MANGAHUB_DEDUP_PAGES":  &cfg.DedupPages,
+		"MANGAHUB_INVITE_ONLY":  &cfg.InviteOnly,
+		"MANGAHUB_VERIFY_EMAIL": &cfg.VerifyEmail,
+
+		"MANGAHUB_PERSONAL_LIBRARIES": &cfg.PersonalLibraries.Enabled,
+		"MANGAHUB_REENCODE_UPLOADS":   &cfg.ReencodeUploads,
+
+		"MANGAHUB_IMPORT_KEEP_ORIGINALS": &cfg.ImportPolicy.KeepOriginals,
+
+		"MANGAHUB_PRESENCE": &cfg.Presence,
+		"MANGAHUB_KOBO":     &cfg.Kobo,
+
+		"MANGAHUB_EXPORT_CREDITS": &cfg.ExportCredits.Enabled,
+
+		"MANGAHUB_PERSIST_DERIVED_METADATA": &cfg.PersistDerivedMetadata,
+	}
+	for name, target := range bools {
+		if value, ok := os.LookupEnv(name); ok {
+			b, err := strconv.ParseBool(value)
+			if err != nil {
+				return fmt.Errorf("%s must be true or false: %w", name, err)
+			}
+			*target = b
+		}
+	}
+	return nil
+}
+
+// splitList splits a comma-separated environment value, dropping empty items
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
@@ -0,0 +1,222 @@
+// Package dedup stores page images by content hash so that identical pages,
+// e.g. shared between a volume rip and the chapter rips of the same volume,
+// take up disk space once.
+//
+// Every page is hard-linked to an object named after its SHA-256 in a hidden
+// folder at the library root. The library layout is unchanged: a page is
+// still an ordinary file in its chapter folder, only its data is shared.
+// Pages must therefore be replaced, never edited in place. File systems
+// without hard links leave pages untouched.
+package dedup
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"mangahub/backend/storage"
+
+	"go.uber.org/zap"
+)
+
+// ObjectsDir is the folder, inside the library root, holding the objects
+const ObjectsDir = ".objects"
+
+// logger discards output until SetLogger installs the configured logger
+var logger = zap.NewNop()
+
+// SetLogger sets the logger used by this package
+func SetLogger(l *zap.Logger) {
+	logger = l
+}
+
+// Stats summarises a deduplication run
+type Stats struct {
+	Files      int   `json:"files"`      // Pages examined
+	Linked     int   `json:"linked"`     // Pages replaced by a link to an existing copy
+	BytesSaved int64 `json:"bytesSaved"` // Disk space freed by those links
+	Pruned     int   `json:"pruned"`     // Objects no page referred to any more
+}
+
+// Store is the object folder of a library
+type Store struct {
+	root string
+	dir  string
+}
+
+// NewStore opens the object store of the library at root, creating it if needed
+func NewStore(root string) (*Store, error) {
+	dir := filepath.Join(root, ObjectsDir)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+	return &Store{root: root, dir: dir}, nil
+}
+
+// objectPath is where the object with the given hash is stored
+func (s *Store) objectPath(hash string) string {
+	return filepath.Join(s.dir, hash[:2], hash)
+}
+
+// LinkFile stores path by its hash. If an identical object already exists the
+// file is replaced by a link to it, and the bytes saved are returned.
+func (s *Store) LinkFile(path string) (hash string, saved int64, err error) {
+	hash, err = storage.HashFile(path)
+	if err != nil {
+		return "", 0, err
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return "", 0, err
+	}
+
+	object := s.objectPath(hash)
+	objectInfo, err := os.Stat(object)
+	switch {
+	case err == nil && os.SameFile(info, objectInfo):
+		return hash, 0, nil
+	case err == nil:
+		// Link the existing object into place under a temporary name, then
+		// swap it in, so the page is never missing
+		tmp := filepath.Join(filepath.Dir(path), ".dedup-"+filepath.Base(path))
+		os.Remove(tmp)
+		if err := os.Link(object, tmp); err != nil {
+			return hash, 0, err
+		}
+		if err := os.Rename(tmp, path); err != nil {
+			os.Remove(tmp)
+			return hash, 0, err
+		}
+		return hash, info.Size(), nil
+	case os.IsNotExist(err):
+		if err := os.MkdirAll(filepath.Dir(object), 0755); err != nil {
+			return hash, 0, err
+		}
+		if err := os.Link(path, object); err != nil && !os.IsExist(err) {
+			return hash, 0, err
+		}
+		return hash, 0, nil
+	default:
+		return hash, 0, err
+	}
+}
+
+// LinkDir stores the pages of one chapter folder
+func (s *Store) LinkDir(dir string) (Stats, error) {
+	var stats Stats
+	err := s.linkDir(dir, &stats, nil)
+	return stats, err
+}
+
+func (s *Store) linkDir(dir string, stats *Stats, seen map[string]bool) error {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		if !isPage(entry) {
+			continue
+		}
+		hash, saved, err := s.LinkFile(filepath.Join(dir, entry.Name()))
+		if err != nil {
+			return fmt.Errorf("%s: %w", filepath.Join(dir, entry.Name()), err)
+		}
+		stats.Files++
+		if saved > 0 {
+			stats.Linked++
+			stats.BytesSaved += saved
+		}
+		if seen != nil {
+			seen[hash] = true
+		}
+	}
+	return nil
+}
+
+// Migrate stores every page of the library and then prunes objects no page
+// refers to any more, e.g. those of deleted chapters. report is called after
+// each series.
+func (s *Store) Migrate(ctx context.Context, report func(done, total int)) (Stats, error) {
+	var stats Stats
+	seriesDirs, err := subdirs(s.root)
+	if err != nil {
+		return stats, err
+	}
+
+	seen := make(map[string]bool)
+	for i, seriesDir := range seriesDirs {
+		chapterDirs, err := subdirs(seriesDir)
+		if err != nil {
+			return stats, err
+		}
+		for _, chapterDir := range chapterDirs {
+			if err := ctx.Err(); err != nil {
+				return stats, err
+			}
+			if err := s.linkDir(chapterDir, &stats, seen); err != nil {
+				// Keep going; one unreadable chapter shouldn't stop the run,
+				// but without a complete picture nothing may be pruned
+				logger.Warn("Failed to deduplicate chapter", zap.String("path", chapterDir), zap.Error(err))
+				seen = nil
+			}
+		}
+		if report != nil {
+			report(i+1, len(seriesDirs))
+		}
+	}
+
+	if seen != nil {
+		pruned, err := s.prune(seen)
+		stats.Pruned = pruned
+		if err != nil {
+			return stats, err
+		}
+	}
+	logger.Info("Library deduplicated",
+		zap.Int("files", stats.Files),
+		zap.Int("linked", stats.Linked),
+		zap.Int64("bytesSaved", stats.BytesSaved),
+		zap.Int("pruned", stats.Pruned),
+	)
+	return stats, nil
+}
+
+// prune removes the objects whose hash isn't in keep
+func (s *Store) prune(keep map[string]bool) (int, error) {
+	pruned := 0
+	err := filepath.WalkDir(s.dir, func(path string, d os.DirEntry, err error) error {
+		if err != nil || d.IsDir() || keep[d.Name()] {
+			return err
+		}
+		if err := os.Remove(path); err != nil {
+			return err
+		}
+		pruned++
+		return nil
+	})
+	return pruned, err
+}
+
+// subdirs lists the non-hidden folders directly inside dir
+func subdirs(dir string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	var dirs []string
+	for _, entry := range entries {
+		if entry.IsDir() && !strings.HasPrefix(entry.Name(), ".") {
+			dirs = append(dirs, filepath.Join(dir, entry.Name()))
+		}
+	}
+	return dirs, nil
+}
+
+// isPage reports whether a chapter folder entry is a page image, rather than
+// metadata or a hidden file
+func isPage(entry os.DirEntry) bool {
+	name := entry.Name()
+	return entry.Type().IsRegular() && !strings.HasPrefix(name, ".") && !strings.EqualFold(filepath.Ext(name), ".json")
+}
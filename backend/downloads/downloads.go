@@ -0,0 +1,142 @@
+// Package downloads counts chapter downloads, such as offline bundles and
+// EPUB exports, and enforces daily per-user download limits so a shared
+// server can't be mirrored wholesale by one account.
+package downloads
+
+import (
+	"errors"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"mangahub/backend/storage"
+
+	"go.uber.org/zap"
+)
+
+const fileName = "downloads.json"
+
+// dayLayout keys daily usage by UTC date
+const dayLayout = "2006-01-02"
+
+// ErrLimitReached is returned when a downloader has used up the day's limit
+var ErrLimitReached = errors.New("daily download limit reached")
+
+// logger discards output until SetLogger installs the configured logger
+var logger = zap.NewNop()
+
+// SetLogger sets the logger used by this package
+func SetLogger(l *zap.Logger) {
+	logger = l
+}
+
+// usage is the chapters one downloader took on one day
+type usage struct {
+	Day      string          `json:"day"`
+	Chapters map[string]bool `json:"chapters"`
+}
+
+// file is the on-disk form of the store
+type file struct {
+	Counts map[string]int    `json:"counts"` // chapter key -> downloads
+	Usage  map[string]*usage `json:"usage"`  // downloader -> today's chapters
+}
+
+// Store keeps download counts and today's usage
+type Store struct {
+	path string
+
+	mu   sync.RWMutex
+	data file
+}
+
+// NewStore loads the download counts from dataDir
+func NewStore(dataDir string) (*Store, error) {
+	s := &Store{path: filepath.Join(dataDir, fileName)}
+	if err := storage.LoadJSON(s.path, &s.data); err != nil {
+		return nil, err
+	}
+	if s.data.Counts == nil {
+		s.data.Counts = make(map[string]int)
+	}
+	if s.data.Usage == nil {
+		s.data.Usage = make(map[string]*usage)
+	}
+	logger.Info("Download counts loaded", zap.Int("chapterCount", len(s.data.Counts)))
+	return s, nil
+}
+
+func chapterKey(mangaID, chapterID string) string {
+	return mangaID + "/" + chapterID
+}
+
+// Take records a download of a chapter by downloader, a user ID or guest
+// address. Downloading a chapter again on the same day is free. Once limit
+// chapters were taken that day it fails with ErrLimitReached; a limit of 0 is
+// unlimited.
+func (s *Store) Take(downloader, mangaID, chapterID string, limit int, now time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	day := now.UTC().Format(dayLayout)
+	key := chapterKey(mangaID, chapterID)
+	today := s.data.Usage[downloader]
+	if today == nil || today.Day != day {
+		today = &usage{Day: day, Chapters: make(map[string]bool)}
+	}
+	if !today.Chapters[key] {
+		if limit > 0 && len(today.Chapters) >= limit {
+			logger.Info("Download limit reached", zap.String("downloader", downloader), zap.Int("limit", limit))
+			return ErrLimitReached
+		}
+		today.Chapters[key] = true
+	}
+	s.data.Usage[downloader] = today
+	s.data.Counts[key]++
+
+	// Usage from earlier days no longer limits anyone
+	for id, u := range s.data.Usage {
+		if u.Day != day {
+			delete(s.data.Usage, id)
+		}
+	}
+	if err := storage.SaveJSON(s.path, s.data); err != nil {
+		logger.Error("Failed to save download counts", zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+// Used returns how many chapters downloader has taken today
+func (s *Store) Used(downloader string, now time.Time) int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if today := s.data.Usage[downloader]; today != nil && today.Day == now.UTC().Format(dayLayout) {
+		return len(today.Chapters)
+	}
+	return 0
+}
+
+// Counts returns how often each chapter of a series was downloaded, by
+// chapter ID
+func (s *Store) Counts(mangaID string) map[string]int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	prefix := chapterKey(mangaID, "")
+	counts := make(map[string]int)
+	for key, n := range s.data.Counts {
+		if chapterID, ok := strings.CutPrefix(key, prefix); ok {
+			counts[chapterID] = n
+		}
+	}
+	return counts
+}
+
+// NextReset returns when the daily limits start over after now
+func NextReset(now time.Time) time.Time {
+	year, month, day := now.UTC().Date()
+	return time.Date(year, month, day+1, 0, 0, 0, 0, time.UTC)
+}
@@ -0,0 +1,227 @@
+// Package epub writes image-only, fixed-layout EPUB 3 books, one page image
+// per spine item, for e-readers that can't read loose images.
+package epub
+
+import (
+	"archive/zip"
+	"bytes"
+	"fmt"
+	"html"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Page is one page image of a book
+type Page struct {
+	Path      string // Image file; only its extension is used when Data is set
+	Data      []byte // Image content, read from Path when nil
+	MediaType string // e.g. "image/png"; derived from the extension when empty
+	Width     int
+	Height    int
+}
+
+// Book is what Write puts in an EPUB
+type Book struct {
+	ID          string // Unique identifier, such as a UUID
+	Title       string
+	Series      string
+	SeriesIndex float64
+	Authors     []string
+	Publisher   string
+	Language    string // BCP 47 tag; "en" when empty
+	Modified    time.Time
+	RightToLeft bool // Pages turn right to left, as in most manga
+	Pages       []Page
+}
+
+// mediaTypes maps image extensions to the media types EPUB readers accept
+var mediaTypes = map[string]string{
+	".jpg":  "image/jpeg",
+	".jpeg": "image/jpeg",
+	".png":  "image/png",
+	".gif":  "image/gif",
+	".webp": "image/webp",
+}
+
+// Write writes book as an EPUB to w
+func Write(w io.Writer, book Book) error {
+	if len(book.Pages) == 0 {
+		return fmt.Errorf("book has no pages")
+	}
+	if book.Language == "" {
+		book.Language = "en"
+	}
+	if book.Modified.IsZero() {
+		book.Modified = time.Now()
+	}
+
+	zw := zip.NewWriter(w)
+	// The mimetype must come first and uncompressed so readers can sniff it
+	if err := writeEntry(zw, "mimetype", zip.Store, strings.NewReader("application/epub+zip")); err != nil {
+		return err
+	}
+	if err := writeEntry(zw, "META-INF/container.xml", zip.Deflate, strings.NewReader(containerXML)); err != nil {
+		return err
+	}
+	if err := writeEntry(zw, "OEBPS/content.opf", zip.Deflate, strings.NewReader(packageDocument(book))); err != nil {
+		return err
+	}
+	if err := writeEntry(zw, "OEBPS/nav.xhtml", zip.Deflate, strings.NewReader(navDocument(book))); err != nil {
+		return err
+	}
+	for i, page := range book.Pages {
+		if err := writeEntry(zw, "OEBPS/"+pageDocumentName(i), zip.Deflate, strings.NewReader(pageDocument(book, i, page))); err != nil {
+			return err
+		}
+		if err := writeImage(zw, "OEBPS/"+imageName(i, page), page); err != nil {
+			return err
+		}
+	}
+	return zw.Close()
+}
+
+// writeImage copies a page image into the book. Images are already
+// compressed, so they are stored as they are.
+func writeImage(zw *zip.Writer, name string, page Page) error {
+	if page.Data != nil {
+		return writeEntry(zw, name, zip.Store, bytes.NewReader(page.Data))
+	}
+	file, err := os.Open(page.Path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	return writeEntry(zw, name, zip.Store, file)
+}
+
+func writeEntry(zw *zip.Writer, name string, method uint16, content io.Reader) error {
+	w, err := zw.CreateHeader(&zip.FileHeader{Name: name, Method: method})
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(w, content)
+	return err
+}
+
+func pageDocumentName(i int) string {
+	return fmt.Sprintf("page-%04d.xhtml", i+1)
+}
+
+func imageName(i int, page Page) string {
+	return fmt.Sprintf("images/%04d%s", i+1, strings.ToLower(filepath.Ext(page.Path)))
+}
+
+func mediaType(page Page) string {
+	if page.MediaType != "" {
+		return page.MediaType
+	}
+	if t, ok := mediaTypes[strings.ToLower(filepath.Ext(page.Path))]; ok {
+		return t
+	}
+	return "application/octet-stream"
+}
+
+// viewport is the page size a fixed-layout page is drawn at
+func viewport(page Page) (int, int) {
+	if page.Width > 0 && page.Height > 0 {
+		return page.Width, page.Height
+	}
+	// Unknown dimensions: a common manga page size
+	return 1200, 1800
+}
+
+const containerXML = `<?xml version="1.0" encoding="UTF-8"?>
+<container version="1.0" xmlns="urn:oasis:names:tc:opendocument:xmlns:container">
+  <rootfiles>
+    <rootfile full-path="OEBPS/content.opf" media-type="application/oebps-package+xml"/>
+  </rootfiles>
+</container>
+`
+
+// packageDocument renders content.opf: the metadata, the files and the
+// reading order
+func packageDocument(book Book) string {
+	var b strings.Builder
+	esc := html.EscapeString
+
+	b.WriteString(`<?xml version="1.0" encoding="UTF-8"?>` + "\n")
+	b.WriteString(`<package xmlns="http://www.idpf.org/2007/opf" version="3.0" unique-identifier="book-id" prefix="rendition: http://www.idpf.org/vocab/rendition/#">` + "\n")
+	b.WriteString("  <metadata xmlns:dc=\"http://purl.org/dc/elements/1.1/\">\n")
+	fmt.Fprintf(&b, "    <dc:identifier id=\"book-id\">urn:uuid:%s</dc:identifier>\n", esc(book.ID))
+	fmt.Fprintf(&b, "    <dc:title>%s</dc:title>\n", esc(book.Title))
+	fmt.Fprintf(&b, "    <dc:language>%s</dc:language>\n", esc(book.Language))
+	for _, author := range book.Authors {
+		fmt.Fprintf(&b, "    <dc:creator>%s</dc:creator>\n", esc(author))
+	}
+	if book.Publisher != "" {
+		fmt.Fprintf(&b, "    <dc:publisher>%s</dc:publisher>\n", esc(book.Publisher))
+	}
+	if book.Series != "" {
+		fmt.Fprintf(&b, "    <meta property=\"belongs-to-collection\" id=\"series\">%s</meta>\n", esc(book.Series))
+		b.WriteString("    <meta refines=\"#series\" property=\"collection-type\">series</meta>\n")
+		fmt.Fprintf(&b, "    <meta refines=\"#series\" property=\"group-position\">%s</meta>\n",
+			strconv.FormatFloat(book.SeriesIndex, 'f', -1, 64))
+	}
+	fmt.Fprintf(&b, "    <meta property=\"dcterms:modified\">%s</meta>\n", book.Modified.UTC().Format("2006-01-02T15:04:05Z"))
+	b.WriteString("    <meta property=\"rendition:layout\">pre-paginated</meta>\n")
+	b.WriteString("    <meta property=\"rendition:spread\">none</meta>\n")
+	b.WriteString("    <meta name=\"cover\" content=\"image-1\"/>\n")
+	b.WriteString("  </metadata>\n")
+
+	b.WriteString("  <manifest>\n")
+	b.WriteString("    <item id=\"nav\" href=\"nav.xhtml\" media-type=\"application/xhtml+xml\" properties=\"nav\"/>\n")
+	for i, page := range book.Pages {
+		fmt.Fprintf(&b, "    <item id=\"page-%d\" href=\"%s\" media-type=\"application/xhtml+xml\"/>\n", i+1, pageDocumentName(i))
+		properties := ""
+		if i == 0 {
+			properties = ` properties="cover-image"`
+		}
+		fmt.Fprintf(&b, "    <item id=\"image-%d\" href=\"%s\" media-type=\"%s\"%s/>\n", i+1, imageName(i, page), mediaType(page), properties)
+	}
+	b.WriteString("  </manifest>\n")
+
+	direction := "ltr"
+	if book.RightToLeft {
+		direction = "rtl"
+	}
+	fmt.Fprintf(&b, "  <spine page-progression-direction=\"%s\">\n", direction)
+	for i := range book.Pages {
+		fmt.Fprintf(&b, "    <itemref idref=\"page-%d\"/>\n", i+1)
+	}
+	b.WriteString("  </spine>\n")
+	b.WriteString("</package>\n")
+	return b.String()
+}
+
+// navDocument renders the table of contents, which only points at the
+// first page
+func navDocument(book Book) string {
+	title := html.EscapeString(book.Title)
+	return `<?xml version="1.0" encoding="UTF-8"?>
+<html xmlns="http://www.w3.org/1999/xhtml" xmlns:epub="http://www.idpf.org/2007/ops">
+<head><title>` + title + `</title></head>
+<body>
+  <nav epub:type="toc"><ol><li><a href="` + pageDocumentName(0) + `">` + title + `</a></li></ol></nav>
+</body>
+</html>
+`
+}
+
+// pageDocument renders the fixed-layout page showing one image
+func pageDocument(book Book, i int, page Page) string {
+	width, height := viewport(page)
+	return fmt.Sprintf(`<?xml version="1.0" encoding="UTF-8"?>
+<html xmlns="http://www.w3.org/1999/xhtml">
+<head>
+  <title>%s</title>
+  <meta name="viewport" content="width=%d, height=%d"/>
+  <style>html, body { margin: 0; padding: 0; } img { display: block; width: 100%%; height: 100%%; object-fit: contain; }</style>
+</head>
+<body><img src="%s" alt=""/></body>
+</html>
+`, html.EscapeString(fmt.Sprintf("%s %d", book.Title, i+1)), width, height, imageName(i, page))
+}
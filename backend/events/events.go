@@ -0,0 +1,68 @@
+package events
+
+import (
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// Event types published by the server
+const (
+	ChapterPublished = "chapter.published"
+	ChapterImported  = "chapter.imported"
+)
+
+// logger discards output until SetLogger installs the configured logger
+var logger = zap.NewNop()
+
+// SetLogger sets the logger used by this package
+func SetLogger(l *zap.Logger) {
+	logger = l
+}
+
+// Event is a notification that something happened in the library
+type Event struct {
+	Type string                 `json:"type"`
+	Time time.Time              `json:"time"`
+	Data map[string]interface{} `json:"data,omitempty"`
+}
+
+// Handler receives published events
+type Handler func(Event)
+
+// Bus fans events out to its subscribers. Handlers run synchronously in the
+// publishing goroutine, so they should hand off anything slow.
+type Bus struct {
+	mu       sync.RWMutex
+	handlers map[string][]Handler
+}
+
+// NewBus creates an empty event bus
+func NewBus() *Bus {
+	return &Bus{handlers: make(map[string][]Handler)}
+}
+
+// Subscribe registers handler for events of the given type; "*" receives all events
+func (b *Bus) Subscribe(eventType string, handler Handler) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.handlers[eventType] = append(b.handlers[eventType], handler)
+}
+
+// Publish delivers an event to every matching subscriber
+func (b *Bus) Publish(eventType string, data map[string]interface{}) {
+	event := Event{Type: eventType, Time: time.Now().UTC(), Data: data}
+
+	b.mu.RLock()
+	handlers := append(append([]Handler(nil), b.handlers[eventType]...), b.handlers["*"]...)
+	b.mu.RUnlock()
+
+	logger.Info("Publishing event",
+		zap.String("type", eventType),
+		zap.Int("subscribers", len(handlers)),
+	)
+	for _, handler := range handlers {
+		handler(event)
+	}
+}
@@ -0,0 +1,77 @@
+// Package firstseen remembers when each series and chapter was first seen by
+// a library sync, which tells sync clients what was created since their last
+// sync apart from what was only updated. Folders carry no creation time that
+// survives edits, so this is the closest the server gets to one.
+package firstseen
+
+import (
+	"path/filepath"
+	"sync"
+	"time"
+
+	"mangahub/backend/storage"
+
+	"go.uber.org/zap"
+)
+
+const fileName = "first-seen.json"
+
+// logger discards output until SetLogger installs the configured logger
+var logger = zap.NewNop()
+
+// SetLogger sets the logger used by this package
+func SetLogger(l *zap.Logger) {
+	logger = l
+}
+
+// Store maps keys of library items to when they were first seen
+type Store struct {
+	path string
+
+	mu    sync.Mutex
+	times map[string]time.Time
+}
+
+// NewStore loads the first-seen times from dataDir
+func NewStore(dataDir string) (*Store, error) {
+	s := &Store{
+		path:  filepath.Join(dataDir, fileName),
+		times: make(map[string]time.Time),
+	}
+	if err := storage.LoadJSON(s.path, &s.times); err != nil {
+		return nil, err
+	}
+	logger.Info("First-seen times loaded", zap.Int("itemCount", len(s.times)))
+	return s, nil
+}
+
+// Observe takes the keys of everything in the library, recording now as the
+// first-seen time of new ones and forgetting the ones that are gone, and
+// returns the first-seen time of every key
+func (s *Store) Observe(keys []string, now time.Time) map[string]time.Time {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	observed := make(map[string]time.Time, len(keys))
+	changed := false
+	for _, key := range keys {
+		seen, ok := s.times[key]
+		if !ok {
+			seen = now
+			changed = true
+		}
+		observed[key] = seen
+	}
+	if len(observed) != len(s.times) {
+		changed = true
+	}
+	// Replaced rather than updated, so the returned map is never written to
+	s.times = observed
+
+	if changed {
+		if err := storage.SaveJSON(s.path, s.times); err != nil {
+			logger.Error("Failed to save first-seen times", zap.Error(err))
+		}
+	}
+	return observed
+}
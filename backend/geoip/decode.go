@@ -0,0 +1,205 @@
+package geoip
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+)
+
+// Data types of the MaxMind DB data section
+const (
+	typeExtended = iota
+	typePointer
+	typeString
+	typeDouble
+	typeBytes
+	typeUint16
+	typeUint32
+	typeMap
+	typeInt32
+	typeUint64
+	typeUint128
+	typeArray
+	typeContainer
+	typeEndMarker
+	typeBool
+	typeFloat
+)
+
+// maxDepth bounds how deeply maps and arrays may nest, so a corrupt file
+// can't recurse without end
+const maxDepth = 32
+
+var errTruncated = errors.New("truncated data")
+
+// decoder reads values from a data section. Pointers are offsets into buf.
+type decoder struct {
+	buf   []byte
+	depth int
+}
+
+// decode reads the value at offset and returns it with the offset after it.
+// Maps become map[string]any, arrays []any, integers uint64 or int32 and
+// floating point numbers float64; 128-bit integers are returned as bytes.
+func (d decoder) decode(offset uint) (any, uint, error) {
+	if d.depth > maxDepth {
+		return nil, 0, errors.New("data nested too deeply")
+	}
+	typ, size, offset, err := d.controlByte(offset)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	if typ == typePointer {
+		target, next, err := d.pointer(size, offset)
+		if err != nil {
+			return nil, 0, err
+		}
+		value, _, err := decoder{buf: d.buf, depth: d.depth + 1}.decode(target)
+		return value, next, err
+	}
+
+	switch typ {
+	case typeMap:
+		m := make(map[string]any, size)
+		inner := decoder{buf: d.buf, depth: d.depth + 1}
+		for i := uint(0); i < size; i++ {
+			key, next, err := inner.decode(offset)
+			if err != nil {
+				return nil, 0, err
+			}
+			name, ok := key.(string)
+			if !ok {
+				return nil, 0, errors.New("map key is not a string")
+			}
+			value, next, err := inner.decode(next)
+			if err != nil {
+				return nil, 0, err
+			}
+			m[name], offset = value, next
+		}
+		return m, offset, nil
+	case typeArray:
+		a := make([]any, 0, size)
+		inner := decoder{buf: d.buf, depth: d.depth + 1}
+		for i := uint(0); i < size; i++ {
+			value, next, err := inner.decode(offset)
+			if err != nil {
+				return nil, 0, err
+			}
+			a, offset = append(a, value), next
+		}
+		return a, offset, nil
+	case typeBool:
+		return size != 0, offset, nil
+	}
+
+	end := offset + size
+	if end > uint(len(d.buf)) {
+		return nil, 0, errTruncated
+	}
+	b := d.buf[offset:end]
+	switch typ {
+	case typeString:
+		return string(b), end, nil
+	case typeBytes, typeUint128:
+		return b, end, nil
+	case typeDouble:
+		if size != 8 {
+			return nil, 0, fmt.Errorf("double of %d bytes", size)
+		}
+		return math.Float64frombits(binary.BigEndian.Uint64(b)), end, nil
+	case typeFloat:
+		if size != 4 {
+			return nil, 0, fmt.Errorf("float of %d bytes", size)
+		}
+		return float64(math.Float32frombits(binary.BigEndian.Uint32(b))), end, nil
+	case typeUint16, typeUint32, typeUint64:
+		if size > 8 {
+			return nil, 0, fmt.Errorf("integer of %d bytes", size)
+		}
+		var n uint64
+		for _, c := range b {
+			n = n<<8 | uint64(c)
+		}
+		return n, end, nil
+	case typeInt32:
+		if size > 4 {
+			return nil, 0, fmt.Errorf("int32 of %d bytes", size)
+		}
+		var n uint32
+		for _, c := range b {
+			n = n<<8 | uint32(c)
+		}
+		return int32(n), end, nil
+	}
+	return nil, 0, fmt.Errorf("unsupported data type %d", typ)
+}
+
+// controlByte reads the type and size of the value at offset and returns
+// the offset of its payload
+func (d decoder) controlByte(offset uint) (typ, size, next uint, err error) {
+	if offset >= uint(len(d.buf)) {
+		return 0, 0, 0, errTruncated
+	}
+	ctrl := d.buf[offset]
+	offset++
+	typ = uint(ctrl >> 5)
+	if typ == typePointer {
+		// Pointers keep their own size bits, decoded by pointer
+		return typ, uint(ctrl & 0x1f), offset, nil
+	}
+	if typ == typeExtended {
+		if offset >= uint(len(d.buf)) {
+			return 0, 0, 0, errTruncated
+		}
+		typ = 7 + uint(d.buf[offset])
+		offset++
+	}
+
+	size = uint(ctrl & 0x1f)
+	if size >= 29 {
+		extra := size - 28 // 1 to 3 bytes follow
+		if offset+extra > uint(len(d.buf)) {
+			return 0, 0, 0, errTruncated
+		}
+		var n uint
+		for _, c := range d.buf[offset : offset+extra] {
+			n = n<<8 | uint(c)
+		}
+		switch extra {
+		case 1:
+			size = 29 + n
+		case 2:
+			size = 285 + n
+		default:
+			size = 65821 + n
+		}
+		offset += extra
+	}
+	return typ, size, offset, nil
+}
+
+// pointer decodes a pointer from the size bits of its control byte and the
+// bytes after it, returning the target and the offset after the pointer
+func (d decoder) pointer(bits, offset uint) (target, next uint, err error) {
+	length := (bits>>3)&0x3 + 1
+	if offset+length > uint(len(d.buf)) {
+		return 0, 0, errTruncated
+	}
+	var n uint
+	if length < 4 {
+		n = bits & 0x7
+	}
+	for _, c := range d.buf[offset : offset+length] {
+		n = n<<8 | uint(c)
+	}
+	switch length {
+	case 2:
+		n += 2048
+	case 3:
+		n += 526336
+	}
+	return n, offset + length, nil
+}
@@ -0,0 +1,170 @@
+// Package geoip looks up the country of client addresses in a MaxMind
+// GeoLite2 or GeoIP2 Country database (.mmdb), for country-based access
+// rules. Only what those lookups need of the MaxMind DB format is read.
+package geoip
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"net/netip"
+	"os"
+	"strings"
+
+	"go.uber.org/zap"
+)
+
+// metadataMarker precedes the metadata at the end of a MaxMind DB file
+var metadataMarker = []byte("\xab\xcd\xefMaxMind.com")
+
+// dataSeparatorSize is the gap of zero bytes between the search tree and the
+// data section
+const dataSeparatorSize = 16
+
+// ErrInvalidDatabase is returned for files that aren't MaxMind databases
+var ErrInvalidDatabase = errors.New("not a MaxMind DB file")
+
+// logger discards output until SetLogger installs the configured logger
+var logger = zap.NewNop()
+
+// SetLogger sets the logger used by this package
+func SetLogger(l *zap.Logger) {
+	logger = l
+}
+
+// DB is a MaxMind database held in memory
+type DB struct {
+	tree       []byte
+	data       []byte
+	nodeCount  uint
+	recordSize uint
+	ipVersion  uint
+
+	// ipv4Start is the node IPv4 lookups start at in an IPv6 tree
+	ipv4Start uint
+}
+
+// Open reads a MaxMind database file
+func Open(path string) (*DB, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	marker := bytes.LastIndex(raw, metadataMarker)
+	if marker < 0 {
+		return nil, ErrInvalidDatabase
+	}
+	metadata, _, err := decoder{buf: raw[marker+len(metadataMarker):]}.decode(0)
+	if err != nil {
+		return nil, fmt.Errorf("reading metadata: %w", err)
+	}
+	fields, ok := metadata.(map[string]any)
+	if !ok {
+		return nil, ErrInvalidDatabase
+	}
+
+	db := &DB{
+		nodeCount:  uintField(fields, "node_count"),
+		recordSize: uintField(fields, "record_size"),
+		ipVersion:  uintField(fields, "ip_version"),
+	}
+	switch db.recordSize {
+	case 24, 28, 32:
+	default:
+		return nil, fmt.Errorf("unsupported record size %d", db.recordSize)
+	}
+	treeSize := db.nodeCount * db.recordSize / 4
+	if treeSize+dataSeparatorSize > uint(marker) {
+		return nil, ErrInvalidDatabase
+	}
+	db.tree = raw[:treeSize]
+	db.data = raw[treeSize+dataSeparatorSize : marker]
+
+	if db.ipVersion == 6 {
+		// IPv4 addresses live under ::/96
+		node := uint(0)
+		for i := 0; i < 96 && node < db.nodeCount; i++ {
+			node = db.record(node, 0)
+		}
+		db.ipv4Start = node
+	}
+
+	databaseType, _ := fields["database_type"].(string)
+	logger.Info("GeoIP database loaded", zap.String("path", path), zap.String("type", databaseType))
+	return db, nil
+}
+
+// Country returns the ISO 3166-1 code of the country an address is in, or
+// registered to when that is all the database knows, in upper case. It
+// returns "" for addresses the database doesn't cover, such as LAN ones.
+func (db *DB) Country(addr netip.Addr) string {
+	record, ok := db.lookup(addr.Unmap())
+	if !ok {
+		return ""
+	}
+	fields, _ := record.(map[string]any)
+	for _, key := range []string{"country", "registered_country"} {
+		country, _ := fields[key].(map[string]any)
+		if code, _ := country["iso_code"].(string); code != "" {
+			return strings.ToUpper(code)
+		}
+	}
+	return ""
+}
+
+// lookup walks the search tree down to the record of an address
+func (db *DB) lookup(addr netip.Addr) (any, bool) {
+	if !addr.IsValid() {
+		return nil, false
+	}
+	bits := addr.AsSlice()
+	node := uint(0)
+	if addr.Is4() {
+		if db.ipVersion == 6 {
+			node = db.ipv4Start
+		}
+	} else if db.ipVersion == 4 {
+		return nil, false
+	}
+
+	for i := 0; i < len(bits)*8 && node < db.nodeCount; i++ {
+		bit := (bits[i/8] >> (7 - i%8)) & 1
+		node = db.record(node, uint(bit))
+	}
+	if node <= db.nodeCount {
+		// Either an empty record or a tree deeper than the address
+		return nil, false
+	}
+
+	offset := node - db.nodeCount - dataSeparatorSize
+	value, _, err := decoder{buf: db.data}.decode(offset)
+	if err != nil {
+		logger.Warn("Failed to decode GeoIP record", zap.String("addr", addr.String()), zap.Error(err))
+		return nil, false
+	}
+	return value, true
+}
+
+// record reads the left (0) or right (1) record of a search tree node
+func (db *DB) record(node, side uint) uint {
+	size := db.recordSize / 4 // bytes per node
+	b := db.tree[node*size : (node+1)*size]
+	switch db.recordSize {
+	case 24:
+		b = b[side*3:]
+		return uint(b[0])<<16 | uint(b[1])<<8 | uint(b[2])
+	case 28:
+		if side == 0 {
+			return uint(b[3]&0xf0)<<20 | uint(b[0])<<16 | uint(b[1])<<8 | uint(b[2])
+		}
+		return uint(b[3]&0x0f)<<24 | uint(b[4])<<16 | uint(b[5])<<8 | uint(b[6])
+	default:
+		b = b[side*4:]
+		return uint(b[0])<<24 | uint(b[1])<<16 | uint(b[2])<<8 | uint(b[3])
+	}
+}
+
+func uintField(fields map[string]any, name string) uint {
+	n, _ := fields[name].(uint64)
+	return uint(n)
+}
@@ -0,0 +1,230 @@
+// Package hooks runs admin-configured commands and HTTP callbacks after
+// library events, e.g. to upscale or back up a chapter once it is imported.
+// Hooks run one at a time in the background, in the order events happen.
+package hooks
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"mangahub/backend/config"
+	"mangahub/backend/events"
+
+	"go.uber.org/zap"
+)
+
+const (
+	// DefaultTimeout bounds a hook run when its config sets none
+	DefaultTimeout = 5 * time.Minute
+
+	// queueSize is how many events may wait for hooks before new ones are dropped
+	queueSize = 256
+
+	// SignatureHeader carries the hex HMAC-SHA256 of a callback body, keyed
+	// with the hook's secret
+	SignatureHeader = "X-MangaHub-Signature"
+)
+
+// logger discards output until SetLogger installs the configured logger
+var logger = zap.NewNop()
+
+// SetLogger sets the logger used by this package
+func SetLogger(l *zap.Logger) {
+	logger = l
+}
+
+var (
+	hooks []config.HookConfig
+	queue chan events.Event
+)
+
+// Init validates the configured hooks and starts running them. Without hooks
+// nothing is started.
+func Init(cfg []config.HookConfig) error {
+	for i, hook := range cfg {
+		if (len(hook.Command) == 0) == (hook.URL == "") {
+			return fmt.Errorf("hook %s must set exactly one of command and url", hookName(hook, i))
+		}
+		if hook.URL != "" && !strings.HasPrefix(hook.URL, "http://") && !strings.HasPrefix(hook.URL, "https://") {
+			return fmt.Errorf("hook %s: url must be http(s)", hookName(hook, i))
+		}
+		if len(hook.Events) == 0 {
+			cfg[i].Events = []string{events.ChapterImported}
+		}
+		if cfg[i].Name == "" {
+			cfg[i].Name = hookName(hook, i)
+		}
+	}
+	if len(cfg) == 0 {
+		logger.Info("No hooks configured")
+		return nil
+	}
+
+	hooks = cfg
+	queue = make(chan events.Event, queueSize)
+	go run()
+	logger.Info("Hooks enabled", zap.Int("count", len(hooks)))
+	return nil
+}
+
+// Handle queues an event for the hooks subscribed to it. It never blocks, so
+// it can be subscribed to an event bus directly.
+func Handle(event events.Event) {
+	if queue == nil || !subscribed(event.Type) {
+		return
+	}
+	select {
+	case queue <- event:
+	default:
+		logger.Warn("Hook queue full; dropping event", zap.String("type", event.Type))
+	}
+}
+
+func subscribed(eventType string) bool {
+	for _, hook := range hooks {
+		if matches(hook, eventType) {
+			return true
+		}
+	}
+	return false
+}
+
+func matches(hook config.HookConfig, eventType string) bool {
+	for _, t := range hook.Events {
+		if t == eventType || t == "*" {
+			return true
+		}
+	}
+	return false
+}
+
+func run() {
+	for event := range queue {
+		for _, hook := range hooks {
+			if !matches(hook, event.Type) {
+				continue
+			}
+			start := time.Now()
+			err := runHook(hook, event)
+			fields := []zap.Field{
+				zap.String("hook", hook.Name),
+				zap.String("event", event.Type),
+				zap.Duration("duration", time.Since(start)),
+			}
+			if err != nil {
+				logger.Error("Hook failed", append(fields, zap.Error(err))...)
+				continue
+			}
+			logger.Info("Hook completed", fields...)
+		}
+	}
+}
+
+func runHook(hook config.HookConfig, event events.Event) error {
+	timeout := DefaultTimeout
+	if hook.TimeoutSeconds > 0 {
+		timeout = time.Duration(hook.TimeoutSeconds) * time.Second
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	if hook.URL != "" {
+		return callURL(ctx, hook, event)
+	}
+	return runCommand(ctx, hook, event)
+}
+
+// params are the values hooks can refer to
+func params(event events.Event) map[string]string {
+	values := map[string]string{"event": event.Type}
+	for key, name := range map[string]string{"mangaId": "mangaId", "chapterId": "chapterId", "number": "chapter", "path": "path"} {
+		switch v := event.Data[key].(type) {
+		case string:
+			values[name] = v
+		case float64:
+			values[name] = strconv.FormatFloat(v, 'f', -1, 64)
+		}
+	}
+	if path := values["path"]; path != "" {
+		if abs, err := filepath.Abs(path); err == nil {
+			values["path"] = abs
+		}
+	}
+	return values
+}
+
+func runCommand(ctx context.Context, hook config.HookConfig, event events.Event) error {
+	values := params(event)
+	args := make([]string, len(hook.Command))
+	for i, arg := range hook.Command {
+		for name, value := range values {
+			arg = strings.ReplaceAll(arg, "{"+name+"}", value)
+		}
+		args[i] = arg
+	}
+
+	cmd := exec.CommandContext(ctx, args[0], args[1:]...)
+	cmd.Env = append(os.Environ(),
+		"MANGAHUB_EVENT="+values["event"],
+		"MANGAHUB_MANGA_ID="+values["mangaId"],
+		"MANGAHUB_CHAPTER_ID="+values["chapterId"],
+		"MANGAHUB_CHAPTER_NUMBER="+values["chapter"],
+		"MANGAHUB_CHAPTER_PATH="+values["path"],
+	)
+	output, err := cmd.CombinedOutput()
+	if len(output) > 0 {
+		logger.Info("Hook output", zap.String("hook", hook.Name), zap.String("output", strings.TrimSpace(string(output))))
+	}
+	if err != nil {
+		return fmt.Errorf("running %s: %w", args[0], err)
+	}
+	return nil
+}
+
+func callURL(ctx context.Context, hook config.HookConfig, event events.Event) error {
+	body, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, hook.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if hook.Secret != "" {
+		mac := hmac.New(sha256.New, []byte(hook.Secret))
+		mac.Write(body)
+		req.Header.Set(SignatureHeader, hex.EncodeToString(mac.Sum(nil)))
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("%s returned %s", hook.URL, resp.Status)
+	}
+	return nil
+}
+
+func hookName(hook config.HookConfig, i int) string {
+	if hook.Name != "" {
+		return hook.Name
+	}
+	return "#" + strconv.Itoa(i+1)
+}
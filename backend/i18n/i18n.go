@@ -0,0 +1,85 @@
+// Package i18n translates user-facing API messages into the language a client
+// asks for with Accept-Language. Messages are written in English throughout
+// the code; each bundle in locales maps English messages to their
+// translation.
+package i18n
+
+import (
+	"embed"
+	"encoding/json"
+	"path"
+	"strings"
+
+	"golang.org/x/text/language"
+)
+
+// Default is the language messages are written in
+const Default = "en"
+
+//go:embed locales/*.json
+var locales embed.FS
+
+var (
+	bundles = map[string]map[string]string{}
+	matcher language.Matcher
+)
+
+func init() {
+	tags := []language.Tag{language.English}
+	files, err := locales.ReadDir("locales")
+	if err != nil {
+		panic(err)
+	}
+	for _, file := range files {
+		lang := strings.TrimSuffix(file.Name(), ".json")
+		data, err := locales.ReadFile(path.Join("locales", file.Name()))
+		if err != nil {
+			panic(err)
+		}
+		bundle := map[string]string{}
+		if err := json.Unmarshal(data, &bundle); err != nil {
+			panic("i18n: invalid bundle " + file.Name() + ": " + err.Error())
+		}
+		bundles[lang] = bundle
+		tags = append(tags, language.MustParse(lang))
+	}
+	matcher = language.NewMatcher(tags)
+}
+
+// Languages returns the languages messages are available in
+func Languages() []string {
+	langs := []string{Default}
+	for lang := range bundles {
+		langs = append(langs, lang)
+	}
+	return langs
+}
+
+// Match picks the best supported language for an Accept-Language header
+func Match(acceptLanguage string) string {
+	tag, _ := language.MatchStrings(matcher, acceptLanguage)
+	base, _ := tag.Base()
+	if _, ok := bundles[base.String()]; ok {
+		return base.String()
+	}
+	return Default
+}
+
+// Translate returns message in the given language. A message with details
+// after a colon, e.g. "Failed to save review: disk full", is translated part
+// by part; parts without a translation stay in English.
+func Translate(lang, message string) string {
+	bundle, ok := bundles[lang]
+	if !ok || message == "" {
+		return message
+	}
+	if translated, ok := bundle[message]; ok {
+		return translated
+	}
+	if head, tail, ok := strings.Cut(message, ": "); ok {
+		if translated, ok := bundle[head]; ok {
+			return translated + ": " + Translate(lang, tail)
+		}
+	}
+	return message
+}
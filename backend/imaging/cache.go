@@ -0,0 +1,433 @@
+package imaging
+
+import (
+	"container/list"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// touchAfter is how stale a cached file's modification time may get before a
+// hit refreshes it, so the order files were last used in survives restarts
+const touchAfter = time.Hour
+
+// Options describes how a derived image is produced from its source
+type Options struct {
+	// Scale is the resize factor relative to the original; 0 or 1 keeps the size
+	Scale float64
+
+	// MaxWidth, when set, shrinks the image further so it is at most this wide
+	MaxWidth int
+
+	// Filters are applied after resizing; see ApplyFilters
+	Filters []string
+
+	// CropMargins trims large white/black borders before anything else
+	CropMargins bool
+}
+
+// IsOriginal reports whether the options leave the source image unchanged
+func (o Options) IsOriginal() bool {
+	return (o.Scale == 0 || o.Scale == 1) && o.MaxWidth == 0 && len(normalizeFilters(o.Filters)) == 0 && !o.CropMargins
+}
+
+// key identifies the derived image for a source file version and options
+func (o Options) key(srcPath string, info os.FileInfo) string {
+	desc := fmt.Sprintf("%s|%d|%d|scale=%s|filters=%s|crop=%t",
+		srcPath, info.Size(), info.ModTime().UnixNano(),
+		strconv.FormatFloat(o.Scale, 'f', -1, 64),
+		strings.Join(normalizeFilters(o.Filters), ","),
+		o.CropMargins)
+	// Only appended when set so existing cache entries keep their keys
+	if o.MaxWidth > 0 {
+		desc += fmt.Sprintf("|maxWidth=%d", o.MaxWidth)
+	}
+	sum := sha256.Sum256([]byte(desc))
+	return hex.EncodeToString(sum[:])
+}
+
+// Cache stores derived images (resized variants and the like) on disk so each
+// is only generated once per source file version. With a size limit the
+// least recently used files are removed to stay under it.
+type Cache struct {
+	Dir string
+
+	locksMu sync.Mutex
+	locks   map[string]*keyLock
+
+	mu        sync.Mutex
+	maxBytes  int64                    // 0 is unlimited
+	entries   map[string]*list.Element // By path
+	lru       *list.List               // Of *cacheEntry, most recently used first
+	size      int64
+	hits      int64
+	misses    int64
+	evictions int64
+}
+
+// cacheEntry is a file in the cache
+type cacheEntry struct {
+	path string
+	key  string // Lock key of the entry the file belongs to
+	size int64
+}
+
+// Stats describes the contents and use of a cache since the server started
+type Stats struct {
+	Dir       string `json:"dir"`
+	Files     int    `json:"files"`
+	Bytes     int64  `json:"bytes"`
+	MaxBytes  int64  `json:"maxBytes"` // 0 is unlimited
+	Hits      int64  `json:"hits"`
+	Misses    int64  `json:"misses"`
+	Evictions int64  `json:"evictions"`
+}
+
+// keyLock serializes generation of a single cache entry
+type keyLock struct {
+	mu   sync.Mutex
+	refs int
+}
+
+// NewCache creates a cache rooted at dir, picking up the files already in
+// it, least recently used first by modification time
+func NewCache(dir string) *Cache {
+	ic := &Cache{
+		Dir:     dir,
+		locks:   make(map[string]*keyLock),
+		entries: make(map[string]*list.Element),
+		lru:     list.New(),
+	}
+	ic.index()
+	return ic
+}
+
+// SetMaxBytes caps the total size of the cached files, evicting the least
+// recently used ones right away if they are over it; 0 is unlimited
+func (ic *Cache) SetMaxBytes(n int64) {
+	ic.mu.Lock()
+	ic.maxBytes = max(n, 0)
+	victims := ic.evict()
+	ic.mu.Unlock()
+	ic.removeFiles(victims)
+}
+
+// Stats returns the size and hit counts of the cache
+func (ic *Cache) Stats() Stats {
+	ic.mu.Lock()
+	defer ic.mu.Unlock()
+	return Stats{
+		Dir:       ic.Dir,
+		Files:     ic.lru.Len(),
+		Bytes:     ic.size,
+		MaxBytes:  ic.maxBytes,
+		Hits:      ic.hits,
+		Misses:    ic.misses,
+		Evictions: ic.evictions,
+	}
+}
+
+// Flush removes every cached file except those being generated right now,
+// returning how many files and bytes were removed
+func (ic *Cache) Flush() (files int, bytes int64) {
+	ic.mu.Lock()
+	var victims []*cacheEntry
+	for elem := ic.lru.Back(); elem != nil; {
+		prev := elem.Prev()
+		if entry, ok := ic.remove(elem); ok {
+			victims = append(victims, entry)
+		}
+		elem = prev
+	}
+	ic.mu.Unlock()
+	return ic.removeFiles(victims)
+}
+
+// Get returns the path of the derived image for srcPath, generating it if it
+// isn't cached yet. For options that leave the image unchanged the source path
+// itself is returned.
+func (ic *Cache) Get(srcPath string, opts Options) (string, error) {
+	if opts.IsOriginal() {
+		return srcPath, nil
+	}
+
+	info, err := os.Stat(srcPath)
+	if err != nil {
+		return "", err
+	}
+
+	key := opts.key(srcPath, info)
+	ic.lock(key)
+	defer ic.unlock(key)
+
+	// Derived files are named by key with the output format as extension
+	for _, ext := range []string{".jpeg", ".png"} {
+		if cached := ic.pathFor(key, ext); ic.cached(cached) {
+			return cached, nil
+		}
+	}
+
+	release := AcquireDecode()
+	defer release()
+	img, format, err := Decode(srcPath)
+	if err != nil {
+		return "", err
+	}
+	if opts.CropMargins {
+		img = CropMargins(img)
+	}
+	img = Scale(img, opts.Scale)
+	if width := img.Bounds().Dx(); opts.MaxWidth > 0 && width > opts.MaxWidth {
+		img = Scale(img, float64(opts.MaxWidth)/float64(width))
+	}
+	img = ApplyFilters(img, opts.Filters)
+
+	outPath := ic.pathFor(key, "."+OutputFormat(format))
+	if err := ic.write(outPath, func(f *os.File) error { return Encode(f, img, format) }); err != nil {
+		return "", err
+	}
+	return outPath, nil
+}
+
+// pathFor shards cache entries into subdirectories by key prefix
+func (ic *Cache) pathFor(key, ext string) string {
+	return filepath.Join(ic.Dir, key[:2], key+ext)
+}
+
+// write creates path atomically from the output of fill
+func (ic *Cache) write(path string, fill func(*os.File) error) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".tmp-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if err := fill(tmp); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return err
+	}
+	ic.stored(path)
+	return nil
+}
+
+// cached reports whether path is in the cache, marking it as just used. The
+// file may still be evicted once the entry's lock is released, so callers
+// serving it must be ready to generate it again.
+func (ic *Cache) cached(path string) bool {
+	info, err := os.Stat(path)
+	ic.mu.Lock()
+	if err != nil {
+		if elem, ok := ic.entries[path]; ok {
+			ic.drop(elem)
+		}
+		ic.mu.Unlock()
+		return false
+	}
+	ic.hits++
+	ic.use(path, info.Size())
+	ic.mu.Unlock()
+
+	if now := time.Now(); now.Sub(info.ModTime()) > touchAfter {
+		os.Chtimes(path, now, now)
+	}
+	return true
+}
+
+// stored adds a newly written file to the cache, evicting older files if the
+// cache is now over its limit
+func (ic *Cache) stored(path string) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return
+	}
+	ic.mu.Lock()
+	ic.misses++
+	ic.use(path, info.Size())
+	victims := ic.evict()
+	ic.mu.Unlock()
+	ic.removeFiles(victims)
+}
+
+// use moves path to the front of the LRU list, adding it if it is new.
+// ic.mu must be held.
+func (ic *Cache) use(path string, size int64) {
+	if elem, ok := ic.entries[path]; ok {
+		entry := elem.Value.(*cacheEntry)
+		ic.size += size - entry.size
+		entry.size = size
+		ic.lru.MoveToFront(elem)
+		return
+	}
+	base := filepath.Base(path)
+	entry := &cacheEntry{path: path, key: strings.TrimSuffix(base, filepath.Ext(base)), size: size}
+	ic.entries[path] = ic.lru.PushFront(entry)
+	ic.size += size
+}
+
+// evict drops the least recently used files until the cache is under its
+// limit, skipping files that are being generated, and returns them for
+// removeFiles to delete. ic.mu must be held.
+func (ic *Cache) evict() []*cacheEntry {
+	var victims []*cacheEntry
+	for elem := ic.lru.Back(); elem != nil && ic.maxBytes > 0 && ic.size > ic.maxBytes; {
+		prev := elem.Prev()
+		if entry, ok := ic.remove(elem); ok {
+			victims = append(victims, entry)
+			ic.evictions++
+		}
+		elem = prev
+	}
+	return victims
+}
+
+// remove drops elem unless its entry is locked, returning it so its file can
+// be deleted by removeFiles. ic.mu must be held.
+func (ic *Cache) remove(elem *list.Element) (*cacheEntry, bool) {
+	entry := elem.Value.(*cacheEntry)
+	if ic.locked(entry.key) {
+		return nil, false
+	}
+	ic.drop(elem)
+	return entry, true
+}
+
+// removeFiles deletes the files of dropped entries, returning how many files
+// and bytes were removed. Each file is deleted under its entry's lock, so a
+// concurrent Get can't lose a file it just regenerated; entries that were
+// locked or used again since they were dropped, and files that can't be
+// deleted, are kept. ic.mu must not be held, so cache hits aren't blocked by
+// the deletes.
+func (ic *Cache) removeFiles(victims []*cacheEntry) (files int, bytes int64) {
+	for _, entry := range victims {
+		if !ic.tryLock(entry.key) {
+			ic.keep(entry)
+			continue
+		}
+		ic.mu.Lock()
+		_, reused := ic.entries[entry.path]
+		ic.mu.Unlock()
+		if !reused {
+			if err := os.Remove(entry.path); err == nil || errors.Is(err, fs.ErrNotExist) {
+				files++
+				bytes += entry.size
+			} else {
+				ic.keep(entry)
+			}
+		}
+		ic.unlock(entry.key)
+	}
+	return files, bytes
+}
+
+// keep puts back an entry whose file wasn't deleted, unless it already was
+func (ic *Cache) keep(entry *cacheEntry) {
+	ic.mu.Lock()
+	defer ic.mu.Unlock()
+	if _, ok := ic.entries[entry.path]; !ok {
+		ic.use(entry.path, entry.size)
+	}
+}
+
+// drop forgets elem without touching its file. ic.mu must be held.
+func (ic *Cache) drop(elem *list.Element) {
+	entry := ic.lru.Remove(elem).(*cacheEntry)
+	delete(ic.entries, entry.path)
+	ic.size -= entry.size
+}
+
+// index adds the files already in the cache directory, removing temporary
+// files left behind by interrupted writes
+func (ic *Cache) index() {
+	type found struct {
+		path    string
+		size    int64
+		modTime time.Time
+	}
+	var files []found
+	filepath.WalkDir(ic.Dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return nil
+		}
+		if strings.HasPrefix(d.Name(), ".tmp-") {
+			os.Remove(path)
+			return nil
+		}
+		if info, err := d.Info(); err == nil {
+			files = append(files, found{path, info.Size(), info.ModTime()})
+		}
+		return nil
+	})
+	slices.SortFunc(files, func(a, b found) int { return a.modTime.Compare(b.modTime) })
+
+	ic.mu.Lock()
+	defer ic.mu.Unlock()
+	for _, f := range files {
+		ic.use(f.path, f.size)
+	}
+}
+
+// locked reports whether a goroutine holds or waits for the lock of key
+func (ic *Cache) locked(key string) bool {
+	ic.locksMu.Lock()
+	defer ic.locksMu.Unlock()
+	return ic.locks[key] != nil
+}
+
+// lock blocks until no other goroutine is generating the entry for key
+func (ic *Cache) lock(key string) {
+	ic.locksMu.Lock()
+	lock, ok := ic.locks[key]
+	if !ok {
+		lock = &keyLock{}
+		ic.locks[key] = lock
+	}
+	lock.refs++
+	ic.locksMu.Unlock()
+
+	lock.mu.Lock()
+}
+
+// tryLock takes the lock of key only if no other goroutine holds or waits
+// for it, reporting whether it did
+func (ic *Cache) tryLock(key string) bool {
+	ic.locksMu.Lock()
+	defer ic.locksMu.Unlock()
+	if ic.locks[key] != nil {
+		return false
+	}
+	lock := &keyLock{refs: 1}
+	lock.mu.Lock()
+	ic.locks[key] = lock
+	return true
+}
+
+func (ic *Cache) unlock(key string) {
+	ic.locksMu.Lock()
+	lock := ic.locks[key]
+	lock.refs--
+	if lock.refs == 0 {
+		delete(ic.locks, key)
+	}
+	ic.locksMu.Unlock()
+
+	lock.mu.Unlock()
+}
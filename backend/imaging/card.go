@@ -0,0 +1,213 @@
+package imaging
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"os"
+	"strings"
+	"sync"
+
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/gofont/gobold"
+	"golang.org/x/image/font/gofont/goregular"
+	"golang.org/x/image/font/opentype"
+	"golang.org/x/image/math/fixed"
+)
+
+// Share card dimensions, the size Open Graph and Twitter previews expect
+const (
+	CardWidth  = 1200
+	CardHeight = 630
+)
+
+const (
+	cardPadding    = 48
+	cardTitleSize  = 60
+	cardTextSize   = 32
+	cardTitleLines = 3
+)
+
+var (
+	cardBackground = color.RGBA{R: 0x1d, G: 0x21, B: 0x2b, A: 0xff}
+	cardTitleColor = color.RGBA{R: 0xff, G: 0xff, B: 0xff, A: 0xff}
+	cardTextColor  = color.RGBA{R: 0xb4, G: 0xbb, B: 0xc8, A: 0xff}
+)
+
+// cardFaces are parsed once; Go fonts only cover Latin, Greek and Cyrillic, so
+// other scripts render as missing-glyph boxes
+var cardFaces = sync.OnceValues(func() (map[string]font.Face, error) {
+	faces := make(map[string]font.Face)
+	for name, spec := range map[string]struct {
+		data []byte
+		size float64
+	}{
+		"title": {gobold.TTF, cardTitleSize},
+		"text":  {goregular.TTF, cardTextSize},
+	} {
+		parsed, err := opentype.Parse(spec.data)
+		if err != nil {
+			return nil, err
+		}
+		face, err := opentype.NewFace(parsed, &opentype.FaceOptions{Size: spec.size, DPI: 72, Hinting: font.HintingFull})
+		if err != nil {
+			return nil, err
+		}
+		faces[name] = face
+	}
+	return faces, nil
+})
+
+// cardMu serializes rendering; font faces aren't safe for concurrent use
+var cardMu sync.Mutex
+
+// RenderCard composites a share preview: the cover on the left, the title
+// (wrapped to a few lines) and the subtitle lines on the right. cover may be nil.
+func RenderCard(cover image.Image, title string, subtitles ...string) (image.Image, error) {
+	faces, err := cardFaces()
+	if err != nil {
+		return nil, err
+	}
+	cardMu.Lock()
+	defer cardMu.Unlock()
+
+	card := image.NewRGBA(image.Rect(0, 0, CardWidth, CardHeight))
+	draw.Draw(card, card.Bounds(), image.NewUniform(cardBackground), image.Point{}, draw.Src)
+
+	textLeft := cardPadding
+	if cover != nil {
+		bounds := cover.Bounds()
+		height := CardHeight - 2*cardPadding
+		width := max(bounds.Dx()*height/max(bounds.Dy(), 1), 1)
+		if limit := CardWidth / 2; width > limit {
+			height = max(height*limit/width, 1)
+			width = limit
+		}
+		top := (CardHeight - height) / 2
+		draw.Draw(card, image.Rect(cardPadding, top, cardPadding+width, top+height), Resize(cover, width, height), image.Point{}, draw.Src)
+		textLeft = cardPadding + width + cardPadding
+	}
+	textWidth := CardWidth - textLeft - cardPadding
+
+	titleFace := faces["title"]
+	y := cardPadding + titleFace.Metrics().Ascent.Ceil()
+	for _, line := range wrapText(titleFace, title, textWidth, cardTitleLines) {
+		drawText(card, titleFace, cardTitleColor, textLeft, y, line)
+		y += titleFace.Metrics().Height.Ceil()
+	}
+
+	textFace := faces["text"]
+	y += textFace.Metrics().Height.Ceil() / 2
+	for _, subtitle := range subtitles {
+		if subtitle == "" {
+			continue
+		}
+		for _, line := range wrapText(textFace, subtitle, textWidth, 1) {
+			y += textFace.Metrics().Height.Ceil()
+			drawText(card, textFace, cardTextColor, textLeft, y, line)
+		}
+	}
+	return card, nil
+}
+
+// Card returns the path of the share card for a series, rendering it if it
+// isn't cached yet. coverPath may be empty; the card is regenerated whenever
+// the cover file or any of the text changes.
+func (ic *Cache) Card(coverPath, title string, subtitles ...string) (string, error) {
+	desc := fmt.Sprintf("card|%s|%s", title, strings.Join(subtitles, "|"))
+	var cover image.Image
+	if coverPath != "" {
+		info, err := os.Stat(coverPath)
+		if err != nil {
+			return "", err
+		}
+		desc += fmt.Sprintf("|%s|%d|%d", coverPath, info.Size(), info.ModTime().UnixNano())
+	}
+	sum := sha256.Sum256([]byte(desc))
+	key := hex.EncodeToString(sum[:])
+
+	ic.lock(key)
+	defer ic.unlock(key)
+
+	cached := ic.pathFor(key, ".png")
+	if ic.cached(cached) {
+		return cached, nil
+	}
+
+	release := AcquireDecode()
+	defer release()
+	if coverPath != "" {
+		var err error
+		if cover, _, err = Decode(coverPath); err != nil {
+			return "", err
+		}
+	}
+	card, err := RenderCard(cover, title, subtitles...)
+	if err != nil {
+		return "", err
+	}
+	if err := ic.write(cached, func(f *os.File) error { return png.Encode(f, card) }); err != nil {
+		return "", err
+	}
+	return cached, nil
+}
+
+// wrapText breaks s into lines no wider than width, ending the last allowed
+// line with an ellipsis when the text doesn't fit
+func wrapText(face font.Face, s string, width, maxLines int) []string {
+	limit := fixed.I(width)
+	var lines []string
+	line := ""
+	words := strings.Fields(s)
+	for i, word := range words {
+		candidate := strings.TrimSpace(line + " " + word)
+		if line == "" || font.MeasureString(face, candidate) <= limit {
+			line = candidate
+			continue
+		}
+		if len(lines) == maxLines-1 {
+			// Last allowed line: take the rest and shorten it to fit
+			line = candidate + " " + strings.Join(words[i+1:], " ")
+			break
+		}
+		lines = append(lines, line)
+		line = word
+	}
+	if line != "" {
+		lines = append(lines, line)
+	}
+	for i := range lines {
+		lines[i] = truncateText(face, lines[i], limit)
+	}
+	return lines
+}
+
+// truncateText shortens s with an ellipsis until it fits in limit
+func truncateText(face font.Face, s string, limit fixed.Int26_6) string {
+	if font.MeasureString(face, s) <= limit {
+		return s
+	}
+	runes := []rune(s)
+	for len(runes) > 0 {
+		runes = runes[:len(runes)-1]
+		candidate := strings.TrimSpace(string(runes)) + "…"
+		if font.MeasureString(face, candidate) <= limit {
+			return candidate
+		}
+	}
+	return ""
+}
+
+func drawText(dst draw.Image, face font.Face, c color.Color, x, y int, s string) {
+	d := font.Drawer{
+		Dst:  dst,
+		Src:  image.NewUniform(c),
+		Face: face,
+		Dot:  fixed.P(x, y),
+	}
+	d.DrawString(s)
+}
@@ -0,0 +1,88 @@
+package imaging
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"image"
+	"image/draw"
+	"image/png"
+	"os"
+	"strings"
+)
+
+// Credits page dimensions, a common manga page size
+const (
+	CreditsWidth  = 1200
+	CreditsHeight = 1800
+)
+
+const (
+	creditsPadding    = 96
+	creditsTitleLines = 4
+	creditsLineLines  = 3 // Lines one line of text may wrap to
+)
+
+// RenderCreditsPage draws a page with the title at the top and the text
+// lines below it, each wrapped to the page width; blank lines leave a gap.
+// Text that doesn't fit on the page is cut off.
+func RenderCreditsPage(title string, lines []string) (image.Image, error) {
+	faces, err := cardFaces()
+	if err != nil {
+		return nil, err
+	}
+	cardMu.Lock()
+	defer cardMu.Unlock()
+
+	page := image.NewRGBA(image.Rect(0, 0, CreditsWidth, CreditsHeight))
+	draw.Draw(page, page.Bounds(), image.NewUniform(cardBackground), image.Point{}, draw.Src)
+	width := CreditsWidth - 2*creditsPadding
+	bottom := CreditsHeight - creditsPadding
+
+	titleFace := faces["title"]
+	y := creditsPadding + titleFace.Metrics().Ascent.Ceil()
+	for _, line := range wrapText(titleFace, title, width, creditsTitleLines) {
+		drawText(page, titleFace, cardTitleColor, creditsPadding, y, line)
+		y += titleFace.Metrics().Height.Ceil()
+	}
+
+	textFace := faces["text"]
+	lineHeight := textFace.Metrics().Height.Ceil() * 5 / 4
+	for _, line := range lines {
+		if strings.TrimSpace(line) == "" {
+			y += lineHeight
+			continue
+		}
+		for _, wrapped := range wrapText(textFace, line, width, creditsLineLines) {
+			y += lineHeight
+			if y > bottom {
+				return page, nil
+			}
+			drawText(page, textFace, cardTextColor, creditsPadding, y, wrapped)
+		}
+	}
+	return page, nil
+}
+
+// CreditsPage returns the path of a rendered credits page, rendering it if
+// the same text isn't cached yet
+func (ic *Cache) CreditsPage(title string, lines []string) (string, error) {
+	sum := sha256.Sum256([]byte("credits|" + title + "|" + strings.Join(lines, "\n")))
+	key := hex.EncodeToString(sum[:])
+
+	ic.lock(key)
+	defer ic.unlock(key)
+
+	cached := ic.pathFor(key, ".png")
+	if ic.cached(cached) {
+		return cached, nil
+	}
+
+	page, err := RenderCreditsPage(title, lines)
+	if err != nil {
+		return "", err
+	}
+	if err := ic.write(cached, func(f *os.File) error { return png.Encode(f, page) }); err != nil {
+		return "", err
+	}
+	return cached, nil
+}
@@ -0,0 +1,113 @@
+package imaging
+
+import (
+	"image"
+	"image/color"
+)
+
+const (
+	// cropTolerance is how far (0-255) a pixel may be from pure white/black
+	// and still count as border
+	cropTolerance = 24
+
+	// cropMinBorderRatio is the share of a row/column that must be border
+	// colored for the line to be trimmed, allowing for specks and scan noise
+	cropMinBorderRatio = 0.995
+
+	// cropMinMargin is the smallest margin, relative to the page size, worth
+	// cropping; thinner borders are left alone
+	cropMinMargin = 0.02
+
+	// cropPadding is kept around the content, relative to the page size
+	cropPadding = 0.005
+)
+
+// CropMargins trims large uniform white or black borders from img. Images
+// without such borders are returned unchanged.
+func CropMargins(img image.Image) image.Image {
+	bounds := img.Bounds()
+	if bounds.Dx() < 16 || bounds.Dy() < 16 {
+		return img
+	}
+
+	isBorder := borderPredicate(img)
+	rowIsBorder := func(y int) bool {
+		return lineIsBorder(bounds.Min.X, bounds.Max.X, func(x int) color.Color { return img.At(x, y) }, isBorder)
+	}
+	colIsBorder := func(x int) bool {
+		return lineIsBorder(bounds.Min.Y, bounds.Max.Y, func(y int) color.Color { return img.At(x, y) }, isBorder)
+	}
+
+	top := bounds.Min.Y
+	for top < bounds.Max.Y && rowIsBorder(top) {
+		top++
+	}
+	bottom := bounds.Max.Y
+	for bottom > top && rowIsBorder(bottom-1) {
+		bottom--
+	}
+	left := bounds.Min.X
+	for left < bounds.Max.X && colIsBorder(left) {
+		left++
+	}
+	right := bounds.Max.X
+	for right > left && colIsBorder(right-1) {
+		right--
+	}
+
+	// Completely blank page: nothing sensible to crop to
+	if bottom <= top || right <= left {
+		return img
+	}
+
+	minX := int(float64(bounds.Dx()) * cropMinMargin)
+	minY := int(float64(bounds.Dy()) * cropMinMargin)
+	if top-bounds.Min.Y < minY && bounds.Max.Y-bottom < minY &&
+		left-bounds.Min.X < minX && bounds.Max.X-right < minX {
+		return img
+	}
+
+	padX := int(float64(bounds.Dx()) * cropPadding)
+	padY := int(float64(bounds.Dy()) * cropPadding)
+	rect := image.Rect(
+		max(left-padX, bounds.Min.X), max(top-padY, bounds.Min.Y),
+		min(right+padX, bounds.Max.X), min(bottom+padY, bounds.Max.Y),
+	)
+
+	cropped := image.NewRGBA(image.Rect(0, 0, rect.Dx(), rect.Dy()))
+	for y := rect.Min.Y; y < rect.Max.Y; y++ {
+		for x := rect.Min.X; x < rect.Max.X; x++ {
+			cropped.Set(x-rect.Min.X, y-rect.Min.Y, img.At(x, y))
+		}
+	}
+	return cropped
+}
+
+// borderPredicate decides from the top-left corner whether the page has a white
+// or a black border and returns a matcher for that color
+func borderPredicate(img image.Image) func(color.Color) bool {
+	corner := color.GrayModel.Convert(img.At(img.Bounds().Min.X, img.Bounds().Min.Y)).(color.Gray).Y
+	white := corner >= 128
+	return func(c color.Color) bool {
+		y := color.GrayModel.Convert(c).(color.Gray).Y
+		if white {
+			return y >= 255-cropTolerance
+		}
+		return y <= cropTolerance
+	}
+}
+
+func lineIsBorder(from, to int, at func(int) color.Color, isBorder func(color.Color) bool) bool {
+	length := to - from
+	allowed := int(float64(length) * (1 - cropMinBorderRatio))
+	misses := 0
+	for i := from; i < to; i++ {
+		if !isBorder(at(i)) {
+			misses++
+			if misses > allowed {
+				return false
+			}
+		}
+	}
+	return true
+}
@@ -0,0 +1,84 @@
+package imaging
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/jpeg"
+)
+
+// recompressQualities are the JPEG qualities tried, in order, to bring a page
+// under its byte limit
+var recompressQualities = []int{JPEGQuality, 75, 65, 50}
+
+// Limits caps the size of a page image; zero fields are unlimited
+type Limits struct {
+	MaxWidth  int
+	MaxHeight int
+	MaxBytes  int64
+}
+
+// IsZero reports whether no limit is set
+func (l Limits) IsZero() bool {
+	return l.MaxWidth <= 0 && l.MaxHeight <= 0 && l.MaxBytes <= 0
+}
+
+// exceeds reports whether an image of the given size breaks the limits
+func (l Limits) exceeds(width, height int, size int64) bool {
+	return (l.MaxWidth > 0 && width > l.MaxWidth) ||
+		(l.MaxHeight > 0 && height > l.MaxHeight) ||
+		(l.MaxBytes > 0 && size > l.MaxBytes)
+}
+
+// Fit downscales an image to fit within the limits' dimensions and, when it
+// is still too large, recompresses it as JPEG at decreasing quality. Images
+// already within the limits are returned unchanged with changed false.
+func Fit(data []byte, limits Limits) (fitted []byte, format string, changed bool, err error) {
+	config, sourceFormat, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, "", false, fmt.Errorf("not a valid image: %w", err)
+	}
+	if !limits.exceeds(config.Width, config.Height, int64(len(data))) {
+		return data, sourceFormat, false, nil
+	}
+
+	release := AcquireDecode()
+	defer release()
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, "", false, fmt.Errorf("not a valid image: %w", err)
+	}
+	factor := 1.0
+	if limits.MaxWidth > 0 && config.Width > limits.MaxWidth {
+		factor = float64(limits.MaxWidth) / float64(config.Width)
+	}
+	if limits.MaxHeight > 0 && config.Height > limits.MaxHeight {
+		factor = min(factor, float64(limits.MaxHeight)/float64(config.Height))
+	}
+	img = Scale(img, factor)
+
+	var buf bytes.Buffer
+	if err := Encode(&buf, img, sourceFormat); err != nil {
+		return nil, "", false, err
+	}
+	format = OutputFormat(sourceFormat)
+	if limits.MaxBytes > 0 && int64(buf.Len()) > limits.MaxBytes {
+		for _, quality := range recompressQualities {
+			buf.Reset()
+			if err := jpeg.Encode(&buf, img, &jpeg.Options{Quality: quality}); err != nil {
+				return nil, "", false, err
+			}
+			format = "jpeg"
+			if int64(buf.Len()) <= limits.MaxBytes {
+				break
+			}
+		}
+	}
+
+	// Re-encoding an image that was only over the byte limit can make it
+	// bigger; keep the original then
+	if factor == 1 && buf.Len() >= len(data) {
+		return data, sourceFormat, false, nil
+	}
+	return buf.Bytes(), format, true, nil
+}
@@ -0,0 +1,132 @@
+package imaging
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"sort"
+)
+
+// Filter names accepted in Options.Filters
+const (
+	FilterGrayscale    = "grayscale"
+	FilterAutoContrast = "autocontrast"
+	FilterInvert       = "invert"
+)
+
+// filterOrder is the order filters are applied in, regardless of request order
+var filterOrder = map[string]int{
+	FilterGrayscale:    0,
+	FilterAutoContrast: 1,
+	FilterInvert:       2,
+}
+
+// IsFilter reports whether name is a supported filter
+func IsFilter(name string) bool {
+	_, ok := filterOrder[name]
+	return ok
+}
+
+// normalizeFilters sorts and de-duplicates filters into application order
+func normalizeFilters(filters []string) []string {
+	seen := make(map[string]bool, len(filters))
+	var normalized []string
+	for _, f := range filters {
+		if IsFilter(f) && !seen[f] {
+			seen[f] = true
+			normalized = append(normalized, f)
+		}
+	}
+	sort.Slice(normalized, func(i, j int) bool {
+		return filterOrder[normalized[i]] < filterOrder[normalized[j]]
+	})
+	return normalized
+}
+
+// ApplyFilters runs the named filters over img in their canonical order
+func ApplyFilters(img image.Image, filters []string) image.Image {
+	filters = normalizeFilters(filters)
+	if len(filters) == 0 {
+		return img
+	}
+
+	rgba := toRGBA(img)
+	for _, f := range filters {
+		switch f {
+		case FilterGrayscale:
+			grayscale(rgba)
+		case FilterAutoContrast:
+			autoContrast(rgba)
+		case FilterInvert:
+			invert(rgba)
+		}
+	}
+	return rgba
+}
+
+// toRGBA returns a mutable copy of img
+func toRGBA(img image.Image) *image.RGBA {
+	bounds := img.Bounds()
+	rgba := image.NewRGBA(image.Rect(0, 0, bounds.Dx(), bounds.Dy()))
+	draw.Draw(rgba, rgba.Bounds(), img, bounds.Min, draw.Src)
+	return rgba
+}
+
+func grayscale(img *image.RGBA) {
+	for i := 0; i+3 < len(img.Pix); i += 4 {
+		y := color.GrayModel.Convert(color.RGBA{img.Pix[i], img.Pix[i+1], img.Pix[i+2], 255}).(color.Gray).Y
+		img.Pix[i], img.Pix[i+1], img.Pix[i+2] = y, y, y
+	}
+}
+
+// autoContrast stretches levels so the darkest and brightest 0.5% of pixels
+// map to black and white, which brings back contrast in faded scans
+func autoContrast(img *image.RGBA) {
+	var histogram [256]int
+	total := 0
+	for i := 0; i+3 < len(img.Pix); i += 4 {
+		luma := (299*int(img.Pix[i]) + 587*int(img.Pix[i+1]) + 114*int(img.Pix[i+2])) / 1000
+		histogram[luma]++
+		total++
+	}
+	if total == 0 {
+		return
+	}
+
+	clip := total / 200
+	low, high := 0, 255
+	for count := 0; low < 255; low++ {
+		count += histogram[low]
+		if count > clip {
+			break
+		}
+	}
+	for count := 0; high > 0; high-- {
+		count += histogram[high]
+		if count > clip {
+			break
+		}
+	}
+	if high <= low {
+		return
+	}
+
+	var levels [256]uint8
+	for v := range levels {
+		scaled := (v - low) * 255 / (high - low)
+		levels[v] = uint8(min(max(scaled, 0), 255))
+	}
+	for i := 0; i+3 < len(img.Pix); i += 4 {
+		img.Pix[i] = levels[img.Pix[i]]
+		img.Pix[i+1] = levels[img.Pix[i+1]]
+		img.Pix[i+2] = levels[img.Pix[i+2]]
+	}
+}
+
+func invert(img *image.RGBA) {
+	for i := 0; i+3 < len(img.Pix); i += 4 {
+		img.Pix[i] = 255 - img.Pix[i]
+		img.Pix[i+1] = 255 - img.Pix[i+1]
+		img.Pix[i+2] = 255 - img.Pix[i+2]
+	}
+}
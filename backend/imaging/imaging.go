@@ -0,0 +1,88 @@
+package imaging
+
+import (
+	"image"
+	"image/jpeg"
+	"image/png"
+	"io"
+	"os"
+
+	"golang.org/x/image/draw"
+	_ "golang.org/x/image/webp" // Register WebP format for decoding
+)
+
+// JPEGQuality is the quality used when re-encoding JPEG output
+const JPEGQuality = 85
+
+// decodeSlots bounds how many images are decoded and processed at once; nil
+// means no limit
+var decodeSlots chan struct{}
+
+// SetMaxDecodes limits how many images are decoded and processed at once,
+// bounding the memory decoded pixels take; 0 lifts the limit
+func SetMaxDecodes(n int) {
+	if n > 0 {
+		decodeSlots = make(chan struct{}, n)
+	} else {
+		decodeSlots = nil
+	}
+}
+
+// AcquireDecode waits until another image may be decoded. Call the returned
+// function once done with the decoded image.
+func AcquireDecode() (release func()) {
+	slots := decodeSlots
+	if slots == nil {
+		return func() {}
+	}
+	slots <- struct{}{}
+	return func() { <-slots }
+}
+
+// Decode reads and decodes the image at path, returning it with its format
+// name. Callers hold a slot from AcquireDecode while they use the image.
+func Decode(path string) (image.Image, string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, "", err
+	}
+	defer file.Close()
+
+	return image.Decode(file)
+}
+
+// Encode writes img in the given format. Formats without an encoder (e.g. webp)
+// are written as JPEG; OutputFormat reports the format actually used.
+func Encode(w io.Writer, img image.Image, format string) error {
+	if OutputFormat(format) == "png" {
+		return png.Encode(w, img)
+	}
+	return jpeg.Encode(w, img, &jpeg.Options{Quality: JPEGQuality})
+}
+
+// OutputFormat returns the format Encode will produce for a source format
+func OutputFormat(format string) string {
+	if format == "png" {
+		return "png"
+	}
+	return "jpeg"
+}
+
+// Scale resizes img by factor, keeping at least one pixel in each dimension
+func Scale(img image.Image, factor float64) image.Image {
+	if factor <= 0 || factor == 1 {
+		return img
+	}
+
+	bounds := img.Bounds()
+	width := int(float64(bounds.Dx())*factor + 0.5)
+	height := int(float64(bounds.Dy())*factor + 0.5)
+	return Resize(img, max(width, 1), max(height, 1))
+}
+
+// Resize scales img to exactly width x height
+func Resize(img image.Image, width, height int) image.Image {
+	dst := image.NewRGBA(image.Rect(0, 0, width, height))
+	draw.CatmullRom.Scale(dst, dst.Bounds(), img, img.Bounds(), draw.Over, nil)
+	return dst
+}
@@ -0,0 +1,202 @@
+package imaging
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"image"
+	"strings"
+)
+
+// formatExtensions are the file extensions each decodable format may use
+var formatExtensions = map[string][]string{
+	"jpeg": {".jpg", ".jpeg"},
+	"png":  {".png"},
+	"gif":  {".gif"},
+	"webp": {".webp"},
+}
+
+// Extension returns the file extension images of a format are stored with
+func Extension(format string) string {
+	if exts, ok := formatExtensions[format]; ok {
+		return exts[0]
+	}
+	return ""
+}
+
+// CheckExtension decodes the header of an image and verifies that its actual
+// format matches the file extension it was uploaded with. It returns the format.
+func CheckExtension(data []byte, ext string) (string, error) {
+	_, format, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return "", fmt.Errorf("not a valid image: %w", err)
+	}
+	ext = strings.ToLower(ext)
+	for _, known := range formatExtensions[format] {
+		if known == ext {
+			return format, nil
+		}
+	}
+	return "", fmt.Errorf("file is a %s image but named %s", format, ext)
+}
+
+// Sanitize removes metadata such as EXIF (camera details, GPS positions) and
+// text comments from an image. With reencode the image is decoded and
+// encoded afresh instead, which also drops anything malformed a decoder
+// could trip over; the format may change, see OutputFormat. The result's
+// format is returned with it.
+func Sanitize(data []byte, reencode bool) ([]byte, string, error) {
+	if reencode {
+		release := AcquireDecode()
+		defer release()
+		img, format, err := image.Decode(bytes.NewReader(data))
+		if err != nil {
+			return nil, "", fmt.Errorf("not a valid image: %w", err)
+		}
+		var buf bytes.Buffer
+		if err := Encode(&buf, img, format); err != nil {
+			return nil, "", err
+		}
+		return buf.Bytes(), OutputFormat(format), nil
+	}
+
+	_, format, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, "", fmt.Errorf("not a valid image: %w", err)
+	}
+	var stripped []byte
+	switch format {
+	case "jpeg":
+		stripped, err = stripJPEG(data)
+	case "png":
+		stripped, err = stripPNG(data)
+	case "webp":
+		stripped, err = stripWebP(data)
+	default:
+		// GIF comments are rare and hold no camera data
+		stripped = data
+	}
+	if err != nil {
+		return nil, "", fmt.Errorf("malformed %s: %w", format, err)
+	}
+	return stripped, format, nil
+}
+
+var errTruncated = errors.New("truncated file")
+
+// stripJPEG drops the APP1 (EXIF, XMP), APP13 (IPTC) and comment segments
+// before the image data. The JFIF header, ICC profile and Adobe colour
+// transform are kept since they affect how the image looks.
+func stripJPEG(data []byte) ([]byte, error) {
+	if len(data) < 2 || data[0] != 0xFF || data[1] != 0xD8 {
+		return nil, errors.New("missing start of image")
+	}
+	out := bytes.NewBuffer(make([]byte, 0, len(data)))
+	out.Write(data[:2])
+
+	for i := 2; ; {
+		if i+4 > len(data) || data[i] != 0xFF {
+			return nil, errTruncated
+		}
+		marker := data[i+1]
+		if marker == 0xFF {
+			// Fill byte before a marker
+			i++
+			continue
+		}
+		if marker == 0xDA {
+			// Start of scan: the rest is image data
+			out.Write(data[i:])
+			return out.Bytes(), nil
+		}
+		length := int(binary.BigEndian.Uint16(data[i+2 : i+4]))
+		end := i + 2 + length
+		if length < 2 || end > len(data) {
+			return nil, errTruncated
+		}
+		switch marker {
+		case 0xE1, 0xED, 0xFE:
+		default:
+			out.Write(data[i:end])
+		}
+		i = end
+	}
+}
+
+// pngDroppedChunks hold text, timestamps and EXIF
+var pngDroppedChunks = map[string]bool{"tEXt": true, "zTXt": true, "iTXt": true, "eXIf": true, "tIME": true}
+
+// stripPNG drops the metadata chunks of a PNG
+func stripPNG(data []byte) ([]byte, error) {
+	const signatureLength = 8
+	if len(data) < signatureLength {
+		return nil, errTruncated
+	}
+	out := bytes.NewBuffer(make([]byte, 0, len(data)))
+	out.Write(data[:signatureLength])
+
+	for i := signatureLength; i < len(data); {
+		if i+8 > len(data) {
+			return nil, errTruncated
+		}
+		length := int(binary.BigEndian.Uint32(data[i : i+4]))
+		chunkType := string(data[i+4 : i+8])
+		end := i + 12 + length // length, type, data, CRC
+		if length < 0 || end > len(data) {
+			return nil, errTruncated
+		}
+		if !pngDroppedChunks[chunkType] {
+			out.Write(data[i:end])
+		}
+		i = end
+		if chunkType == "IEND" {
+			break
+		}
+	}
+	return out.Bytes(), nil
+}
+
+// VP8X feature flags announcing metadata chunks
+const (
+	webpFlagEXIF = 0x08
+	webpFlagXMP  = 0x04
+)
+
+// stripWebP drops the EXIF and XMP chunks of a WebP and clears the flags
+// announcing them
+func stripWebP(data []byte) ([]byte, error) {
+	if len(data) < 12 || string(data[:4]) != "RIFF" || string(data[8:12]) != "WEBP" {
+		return nil, errors.New("missing RIFF header")
+	}
+	out := bytes.NewBuffer(make([]byte, 0, len(data)))
+	out.Write(data[:12])
+
+	for i := 12; i < len(data); {
+		if i+8 > len(data) {
+			return nil, errTruncated
+		}
+		chunkType := string(data[i : i+4])
+		size := int(binary.LittleEndian.Uint32(data[i+4 : i+8]))
+		end := i + 8 + size + size%2 // chunks are padded to an even size
+		if end > len(data) {
+			return nil, errTruncated
+		}
+		switch chunkType {
+		case "EXIF", "XMP ":
+		case "VP8X":
+			chunk := append([]byte(nil), data[i:end]...)
+			if len(chunk) > 8 {
+				chunk[8] &^= webpFlagEXIF | webpFlagXMP
+			}
+			out.Write(chunk)
+		default:
+			out.Write(data[i:end])
+		}
+		i = end
+	}
+
+	stripped := out.Bytes()
+	binary.LittleEndian.PutUint32(stripped[4:8], uint32(len(stripped)-8))
+	return stripped, nil
+}
@@ -0,0 +1,120 @@
+package imaging
+
+import (
+	"bytes"
+	"encoding/binary"
+	"image"
+	"image/color"
+	"image/draw"
+	"sync"
+
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/gofont/goregular"
+	"golang.org/x/image/font/opentype"
+)
+
+// markMagic starts every hidden mark, so pages without one aren't misread
+var markMagic = []byte("MHWM")
+
+// markHeaderBytes is the magic and the 16-bit payload length
+const markHeaderBytes = 6
+
+var (
+	watermarkText   = color.NRGBA{R: 0xff, G: 0xff, B: 0xff, A: 0x90}
+	watermarkShadow = color.NRGBA{R: 0x00, G: 0x00, B: 0x00, A: 0x90}
+)
+
+// watermarkFont is parsed once; faces are made per page, sized to it
+var watermarkFont = sync.OnceValues(func() (*opentype.Font, error) {
+	return opentype.Parse(goregular.TTF)
+})
+
+// RGBA returns img as an RGBA image that can be drawn on, copying it
+// unless it already is one
+func RGBA(img image.Image) *image.RGBA {
+	if rgba, ok := img.(*image.RGBA); ok {
+		return rgba
+	}
+	bounds := img.Bounds()
+	rgba := image.NewRGBA(image.Rect(0, 0, bounds.Dx(), bounds.Dy()))
+	draw.Draw(rgba, rgba.Bounds(), img, bounds.Min, draw.Src)
+	return rgba
+}
+
+// Watermark writes text in the bottom right corner of img, translucent so
+// the page stays readable, scaled to the page width
+func Watermark(img *image.RGBA, text string) error {
+	parsed, err := watermarkFont()
+	if err != nil {
+		return err
+	}
+	bounds := img.Bounds()
+	size := max(float64(bounds.Dx())/48, 10)
+	face, err := opentype.NewFace(parsed, &opentype.FaceOptions{Size: size, DPI: 72, Hinting: font.HintingFull})
+	if err != nil {
+		return err
+	}
+	defer face.Close()
+
+	margin := int(size)
+	width := font.MeasureString(face, text).Ceil()
+	x := max(bounds.Max.X-margin-width, bounds.Min.X)
+	y := bounds.Max.Y - margin
+	shadow := max(int(size/12), 1)
+	drawText(img, face, watermarkShadow, x+shadow, y+shadow, text)
+	drawText(img, face, watermarkText, x, y, text)
+	return nil
+}
+
+// EmbedMark hides text in the lowest bit of the blue channel of img's
+// pixels, invisible to the eye. The mark only survives lossless formats, so
+// marked pages are saved as PNG. It reports false when img is too small.
+func EmbedMark(img *image.RGBA, text string) bool {
+	payload := []byte(text)
+	if len(payload) > 0xffff {
+		return false
+	}
+	data := make([]byte, 0, markHeaderBytes+len(payload))
+	data = append(data, markMagic...)
+	data = binary.BigEndian.AppendUint16(data, uint16(len(payload)))
+	data = append(data, payload...)
+
+	bounds := img.Bounds()
+	if bounds.Dx()*bounds.Dy() < len(data)*8 {
+		return false
+	}
+	for i := 0; i < len(data)*8; i++ {
+		bit := data[i/8] >> (7 - i%8) & 1
+		offset := img.PixOffset(bounds.Min.X+i%bounds.Dx(), bounds.Min.Y+i/bounds.Dx()) + 2
+		img.Pix[offset] = img.Pix[offset]&^1 | bit
+	}
+	return true
+}
+
+// ReadMark returns the text EmbedMark hid in img, if any
+func ReadMark(img image.Image) (string, bool) {
+	bounds := img.Bounds()
+	width, capacity := bounds.Dx(), bounds.Dx()*bounds.Dy()/8
+	readBytes := func(from, n int) []byte {
+		out := make([]byte, n)
+		for i := 0; i < n*8; i++ {
+			bit := from*8 + i
+			_, _, b, _ := img.At(bounds.Min.X+bit%width, bounds.Min.Y+bit/width).RGBA()
+			out[i/8] |= byte(b>>8&1) << (7 - i%8)
+		}
+		return out
+	}
+
+	if capacity < markHeaderBytes {
+		return "", false
+	}
+	header := readBytes(0, markHeaderBytes)
+	if !bytes.Equal(header[:len(markMagic)], markMagic) {
+		return "", false
+	}
+	length := int(binary.BigEndian.Uint16(header[len(markMagic):]))
+	if markHeaderBytes+length > capacity {
+		return "", false
+	}
+	return string(readBytes(markHeaderBytes, length)), true
+}
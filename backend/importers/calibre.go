@@ -0,0 +1,191 @@
+package importers
+
+import (
+	"context"
+	"encoding/xml"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"go.uber.org/zap"
+)
+
+// calibreOPFName is the metadata file Calibre keeps in every book folder
+const calibreOPFName = "metadata.opf"
+
+// maxCalibreOPFSize caps the metadata files read
+const maxCalibreOPFSize = 4 << 20
+
+// calibreMinYear filters out the placeholder dates Calibre writes for books
+// without a publication date
+const calibreMinYear = 1000
+
+// Calibre reads a Calibre library folder through the metadata.opf files
+// Calibre keeps next to every book, so the library's database doesn't need to
+// be opened. Books are grouped into series by their Calibre series; a book
+// without one becomes a series of its own.
+type Calibre struct {
+	Path string
+}
+
+type calibreCreator struct {
+	Name string `xml:",chardata"`
+	Role string `xml:"role,attr"`
+}
+
+type calibreOPF struct {
+	Metadata struct {
+		Title       string           `xml:"title"`
+		Creators    []calibreCreator `xml:"creator"`
+		Description string           `xml:"description"`
+		Publisher   string           `xml:"publisher"`
+		Date        string           `xml:"date"`
+		Subjects    []string         `xml:"subject"`
+		Meta        []struct {
+			Name    string `xml:"name,attr"`
+			Content string `xml:"content,attr"`
+		} `xml:"meta"`
+	} `xml:"metadata"`
+}
+
+// meta returns the value of a Calibre <meta name="..."> element
+func (o *calibreOPF) meta(name string) string {
+	for _, m := range o.Metadata.Meta {
+		if m.Name == name {
+			return strings.TrimSpace(m.Content)
+		}
+	}
+	return ""
+}
+
+// calibreBook is one book of a Calibre series
+type calibreBook struct {
+	index float64
+	opf   *calibreOPF
+}
+
+// Fetch reads the metadata of every book in the library
+func (c *Calibre) Fetch(ctx context.Context) (*Library, error) {
+	if _, err := os.Stat(c.Path); err != nil {
+		return nil, err
+	}
+
+	var order []string
+	books := make(map[string][]calibreBook) // series title -> books
+	err := filepath.WalkDir(c.Path, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if path != c.Path && strings.HasPrefix(d.Name(), ".") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if d.Name() != calibreOPFName {
+			return nil
+		}
+
+		opf, err := readCalibreOPF(path)
+		if err != nil {
+			logger.Warn("Skipping unreadable book metadata", zap.String("path", path), zap.Error(err))
+			return nil
+		}
+		title := opf.meta("calibre:series")
+		if title == "" {
+			title = strings.TrimSpace(opf.Metadata.Title)
+		}
+		if title == "" {
+			return nil
+		}
+		index, _ := strconv.ParseFloat(opf.meta("calibre:series_index"), 64)
+		key := strings.ToLower(title)
+		if _, ok := books[key]; !ok {
+			order = append(order, key)
+		}
+		books[key] = append(books[key], calibreBook{index: index, opf: opf})
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	library := &Library{Source: "calibre"}
+	for _, key := range order {
+		library.Series = append(library.Series, calibreSeries(key, books[key]))
+	}
+
+	logger.Info("Read Calibre library",
+		zap.String("path", c.Path),
+		zap.Int("seriesCount", len(library.Series)),
+	)
+	return library, nil
+}
+
+// calibreSeries merges the metadata of a series' books. The summary and
+// publisher come from the earliest book that has them; people and tags are
+// gathered from all of them.
+func calibreSeries(key string, books []calibreBook) Series {
+	sort.SliceStable(books, func(i, j int) bool { return books[i].index < books[j].index })
+
+	first := books[0].opf
+	series := Series{SourceID: key, Title: first.meta("calibre:series")}
+	if series.Title == "" {
+		series.Title = strings.TrimSpace(first.Metadata.Title)
+	}
+	for _, book := range books {
+		metadata := &book.opf.Metadata
+		if series.Summary == "" {
+			series.Summary = plainText(metadata.Description)
+		}
+		if series.Publisher == "" {
+			series.Publisher = strings.TrimSpace(metadata.Publisher)
+		}
+		if year := calibreYear(metadata.Date); year > 0 && (series.Year == 0 || year < series.Year) {
+			series.Year = year
+		}
+		series.Tags = appendUnique(series.Tags, metadata.Subjects...)
+		for _, creator := range metadata.Creators {
+			switch creator.Role {
+			case "", "aut", "wri":
+				series.Authors = appendUnique(series.Authors, creator.Name)
+			case "art", "ill":
+				series.Artists = appendUnique(series.Artists, creator.Name)
+			}
+		}
+	}
+	return series
+}
+
+func readCalibreOPF(path string) (*calibreOPF, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var opf calibreOPF
+	if err := xml.NewDecoder(io.LimitReader(f, maxCalibreOPFSize)).Decode(&opf); err != nil {
+		return nil, err
+	}
+	return &opf, nil
+}
+
+// calibreYear returns the year of an OPF date, or 0 when it is unset
+func calibreYear(date string) int {
+	date = strings.TrimSpace(date)
+	if len(date) < 4 {
+		return 0
+	}
+	if year, err := strconv.Atoi(date[:4]); err == nil && year >= calibreMinYear {
+		return year
+	}
+	return 0
+}
@@ -0,0 +1,67 @@
+package importers
+
+import (
+	"encoding/xml"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ComicInfoFileName is the metadata file ComicRack-style archives carry. The
+// extractors copy it next to the pages so the importer can read it.
+const ComicInfoFileName = "ComicInfo.xml"
+
+// maxComicInfoSize caps the ComicInfo.xml read from an archive
+const maxComicInfoSize = 1 << 20
+
+// ComicInfo holds the chapter fields MangaHub reads from a ComicInfo.xml
+type ComicInfo struct {
+	Title   string `xml:"Title"`
+	Series  string `xml:"Series"`
+	Number  string `xml:"Number"`
+	Volume  int    `xml:"Volume"`
+	Summary string `xml:"Summary"`
+	Format  string `xml:"Format"` // e.g. "One-Shot" or "Special"
+	Web     string `xml:"Web"`    // Space-separated URLs since schema 2.1
+}
+
+// SourceURL returns the first web link of the chapter
+func (ci *ComicInfo) SourceURL() string {
+	fields := strings.Fields(ci.Web)
+	if len(fields) == 0 {
+		return ""
+	}
+	return fields[0]
+}
+
+// ParseComicInfo decodes the contents of a ComicInfo.xml
+func ParseComicInfo(data []byte) (*ComicInfo, error) {
+	var info ComicInfo
+	if err := xml.Unmarshal(data, &info); err != nil {
+		return nil, err
+	}
+	info.Summary = strings.TrimSpace(info.Summary)
+	return &info, nil
+}
+
+// TakeComicInfo reads and removes the ComicInfo.xml an extractor left in dir.
+// It returns nil when there is none.
+func TakeComicInfo(dir string) (*ComicInfo, error) {
+	path := filepath.Join(dir, ComicInfoFileName)
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := os.Remove(path); err != nil {
+		return nil, err
+	}
+	return ParseComicInfo(data)
+}
+
+// isComicInfo reports whether name is a ComicInfo.xml, in any case
+func isComicInfo(name string) bool {
+	return strings.EqualFold(filepath.Base(name), ComicInfoFileName)
+}
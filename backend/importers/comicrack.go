@@ -0,0 +1,159 @@
+package importers
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"go.uber.org/zap"
+)
+
+// comicRackListExt is the extension of ComicRack reading lists
+const comicRackListExt = ".cbl"
+
+// maxComicRackListSize caps the reading list files read
+const maxComicRackListSize = 16 << 20
+
+// ComicRack reads ComicRack reading lists (.cbl files). Path is either one
+// list or a folder of them. Each list becomes a collection of the series its
+// books belong to; the books themselves are matched by series only, since
+// MangaHub collections hold series rather than chapters.
+type ComicRack struct {
+	Path string
+}
+
+type comicRackList struct {
+	Name  string `xml:"Name"`
+	Books []struct {
+		Series string `xml:"Series,attr"`
+		Number string `xml:"Number,attr"`
+		Volume int    `xml:"Volume,attr"`
+		Year   int    `xml:"Year,attr"`
+	} `xml:"Books>Book"`
+}
+
+// Fetch reads every reading list under the path
+func (r *ComicRack) Fetch(ctx context.Context) (*Library, error) {
+	paths, err := comicRackListPaths(r.Path)
+	if err != nil {
+		return nil, err
+	}
+	library := &Library{Source: "comicrack"}
+
+	seriesIndex := make(map[string]int) // source series ID -> index in library.Series
+	for _, path := range paths {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		list, err := readComicRackList(path)
+		if err != nil {
+			return nil, fmt.Errorf("reading %s: %w", filepath.Base(path), err)
+		}
+		if len(list.Books) == 0 {
+			// Smart lists keep matchers instead of books, which can't be
+			// evaluated without ComicRack's own database
+			logger.Warn("Skipping reading list without books", zap.String("path", path))
+			continue
+		}
+
+		name := strings.TrimSpace(list.Name)
+		if name == "" {
+			name = strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+		}
+		collection := Collection{Name: name}
+		inCollection := make(map[string]bool)
+		for _, book := range list.Books {
+			title := strings.TrimSpace(book.Series)
+			if title == "" {
+				continue
+			}
+			// Volume is usually the year a run started, which tells reboots
+			// of a series apart
+			id := strings.ToLower(title)
+			if book.Volume > 0 {
+				id += " (" + strconv.Itoa(book.Volume) + ")"
+			}
+
+			i, known := seriesIndex[id]
+			if !known {
+				i = len(library.Series)
+				seriesIndex[id] = i
+				library.Series = append(library.Series, Series{SourceID: id, Title: title})
+			}
+			library.Series[i].Year = comicRackYear(library.Series[i].Year, book.Volume, book.Year)
+			if !inCollection[id] {
+				inCollection[id] = true
+				collection.SeriesIDs = append(collection.SeriesIDs, id)
+			}
+		}
+		if len(collection.SeriesIDs) > 0 {
+			library.Collections = append(library.Collections, collection)
+		}
+	}
+
+	logger.Info("Read ComicRack reading lists",
+		zap.String("path", r.Path),
+		zap.Int("listCount", len(paths)),
+		zap.Int("seriesCount", len(library.Series)),
+		zap.Int("collectionCount", len(library.Collections)),
+	)
+	return library, nil
+}
+
+// comicRackListPaths returns path when it is a file, or the reading lists
+// directly inside it when it is a folder
+func comicRackListPaths(path string) ([]string, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	if !info.IsDir() {
+		return []string{path}, nil
+	}
+
+	entries, err := os.ReadDir(path)
+	if err != nil {
+		return nil, err
+	}
+	var paths []string
+	for _, entry := range entries {
+		if !entry.IsDir() && strings.EqualFold(filepath.Ext(entry.Name()), comicRackListExt) {
+			paths = append(paths, filepath.Join(path, entry.Name()))
+		}
+	}
+	if len(paths) == 0 {
+		return nil, fmt.Errorf("no %s reading lists in %s", comicRackListExt, path)
+	}
+	return paths, nil
+}
+
+func readComicRackList(path string) (*comicRackList, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var list comicRackList
+	if err := xml.NewDecoder(io.LimitReader(f, maxComicRackListSize)).Decode(&list); err != nil {
+		return nil, err
+	}
+	return &list, nil
+}
+
+// comicRackYear returns the year a series started, from the volume when it
+// looks like a year and otherwise from the earliest book
+func comicRackYear(current, volume, year int) int {
+	if volume >= 1900 {
+		return volume
+	}
+	if year > 0 && (current == 0 || year < current) {
+		return year
+	}
+	return current
+}
@@ -0,0 +1,178 @@
+// Package importers brings outside content into the library: the libraries of
+// other manga servers, read through their REST APIs, the libraries of desktop
+// comic managers, read from their files, and chapter pages downloaded from
+// remote URLs or archives.
+package importers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"html"
+	"io"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// logger discards output until SetLogger installs the configured logger
+var logger = zap.NewNop()
+
+// SetLogger sets the logger used by this package
+func SetLogger(l *zap.Logger) {
+	logger = l
+}
+
+// Series statuses produced by the importers, matching the values MangaHub stores
+const (
+	StatusOngoing   = "ongoing"
+	StatusCompleted = "completed"
+	StatusHiatus    = "hiatus"
+	StatusCancelled = "cancelled"
+)
+
+// Series is one series as read from the source server
+type Series struct {
+	SourceID    string
+	Title       string
+	AltTitles   []string
+	Summary     string
+	Status      string // One of the Status* constants, or empty when unknown
+	Genres      []string
+	Tags        []string
+	Publisher   string
+	Demographic string // Target readership, such as "shounen"; empty when unknown
+	Authors     []string
+	Artists     []string
+	Year        int
+	Path        string // Folder of the series on the source server
+	Progress    []ChapterProgress
+}
+
+// ChapterProgress is how far the importing account has read one chapter
+type ChapterProgress struct {
+	Number    float64
+	Page      int // Pages read, 1-based
+	PageCount int
+	Completed bool
+	ReadAt    time.Time
+}
+
+// Collection is a named group of series, referenced by source series ID
+type Collection struct {
+	Name      string
+	Summary   string
+	SeriesIDs []string
+}
+
+// Library is everything read from a source server
+type Library struct {
+	Source      string
+	Series      []Series
+	Collections []Collection
+}
+
+// Source reads a library from another server
+type Source interface {
+	Fetch(ctx context.Context) (*Library, error)
+}
+
+// defaultTimeout bounds each request to the source server
+const defaultTimeout = 30 * time.Second
+
+// maxResponseSize caps how much of a single API response is read
+const maxResponseSize = 64 << 20
+
+// APIError is returned when the source server answers with a non-2xx status
+type APIError struct {
+	URL    string
+	Status int
+	Body   string
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("%s returned %d: %s", e.URL, e.Status, e.Body)
+}
+
+// IsAPIError checks if an error is an APIError
+func IsAPIError(err error) bool {
+	_, ok := err.(*APIError)
+	return ok
+}
+
+// doJSON performs req and decodes a JSON response into v
+func doJSON(client *http.Client, req *http.Request, v interface{}) error {
+	req.Header.Set("Accept", "application/json")
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	body := io.LimitReader(resp.Body, maxResponseSize)
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		snippet, _ := io.ReadAll(io.LimitReader(body, 512))
+		return &APIError{URL: req.URL.String(), Status: resp.StatusCode, Body: strings.TrimSpace(string(snippet))}
+	}
+	if v == nil {
+		return nil
+	}
+	if err := json.NewDecoder(body).Decode(v); err != nil {
+		return fmt.Errorf("decoding %s: %w", req.URL.String(), err)
+	}
+	return nil
+}
+
+// newRequest builds a request against baseURL, which may or may not end in a slash
+func newRequest(ctx context.Context, method, baseURL, path string, body io.Reader) (*http.Request, error) {
+	return http.NewRequestWithContext(ctx, method, strings.TrimRight(baseURL, "/")+path, body)
+}
+
+// httpClient returns client, or a default client with a request timeout
+func httpClient(client *http.Client) *http.Client {
+	if client != nil {
+		return client
+	}
+	return &http.Client{Timeout: defaultTimeout}
+}
+
+// appendUnique appends the non-empty values not already in list
+func appendUnique(list []string, values ...string) []string {
+	for _, v := range values {
+		v = strings.TrimSpace(v)
+		if v == "" {
+			continue
+		}
+		found := false
+		for _, existing := range list {
+			if strings.EqualFold(existing, v) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			list = append(list, v)
+		}
+	}
+	return list
+}
+
+// markup matches HTML tags, lineBreaks and paragraphEnds the tags that end a
+// line or a paragraph, and blankLines the gaps they leave behind
+var (
+	markup        = regexp.MustCompile(`<[^>]*>`)
+	lineBreaks    = regexp.MustCompile(`(?i)<br\s*/?>`)
+	paragraphEnds = regexp.MustCompile(`(?i)</p>`)
+	blankLines    = regexp.MustCompile(`\n{3,}`)
+)
+
+// plainText turns an HTML description into plain text with paragraph breaks
+func plainText(description string) string {
+	description = lineBreaks.ReplaceAllString(description, "\n")
+	description = paragraphEnds.ReplaceAllString(description, "\n\n")
+	description = html.UnescapeString(markup.ReplaceAllString(description, ""))
+	return strings.TrimSpace(blankLines.ReplaceAllString(description, "\n\n"))
+}
@@ -0,0 +1,292 @@
+package importers
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// kavitaPageSize is the page size used when walking Kavita's paged listings
+const kavitaPageSize = 500
+
+// kavitaPluginName identifies MangaHub when exchanging an API key for a token
+const kavitaPluginName = "MangaHub"
+
+// Kavita reads a Kavita server through its REST API. The API key is that of
+// the account whose read progress is imported.
+type Kavita struct {
+	BaseURL string
+	APIKey  string
+	Client  *http.Client
+
+	token string
+}
+
+type kavitaSeries struct {
+	ID            int    `json:"id"`
+	Name          string `json:"name"`
+	OriginalName  string `json:"originalName"`
+	LocalizedName string `json:"localizedName"`
+	FolderPath    string `json:"folderPath"`
+	PagesRead     int    `json:"pagesRead"`
+}
+
+type kavitaNamed struct {
+	Title string `json:"title"`
+	Name  string `json:"name"`
+}
+
+type kavitaMetadata struct {
+	Summary           string        `json:"summary"`
+	Genres            []kavitaNamed `json:"genres"`
+	Tags              []kavitaNamed `json:"tags"`
+	Writers           []kavitaNamed `json:"writers"`
+	Pencillers        []kavitaNamed `json:"pencillers"`
+	Publishers        []kavitaNamed `json:"publishers"`
+	PublicationStatus int           `json:"publicationStatus"`
+	ReleaseYear       int           `json:"releaseYear"`
+}
+
+type kavitaVolume struct {
+	Chapters []struct {
+		Number                 string     `json:"number"`    // Older servers
+		MinNumber              *float64   `json:"minNumber"` // Newer servers
+		Pages                  int        `json:"pages"`
+		PagesRead              int        `json:"pagesRead"`
+		LastReadingProgressUtc kavitaTime `json:"lastReadingProgressUtc"`
+	} `json:"chapters"`
+}
+
+// kavitaTime accepts timestamps with or without a zone; Kavita writes UTC
+// values both ways depending on version. Unparseable values are left zero.
+type kavitaTime struct {
+	time.Time
+}
+
+func (t *kavitaTime) UnmarshalJSON(data []byte) error {
+	s := strings.Trim(string(data), `"`)
+	for _, layout := range []string{time.RFC3339Nano, "2006-01-02T15:04:05.999999999"} {
+		if parsed, err := time.Parse(layout, s); err == nil {
+			t.Time = parsed.UTC()
+			return nil
+		}
+	}
+	return nil
+}
+
+type kavitaCollection struct {
+	ID      int    `json:"id"`
+	Title   string `json:"title"`
+	Summary string `json:"summary"`
+}
+
+// Kavita publication statuses
+const (
+	kavitaOngoing   = 0
+	kavitaHiatus    = 1
+	kavitaCompleted = 2
+	kavitaCancelled = 3
+	kavitaEnded     = 4
+)
+
+// Fetch reads every series, the account's read progress and all collections
+func (k *Kavita) Fetch(ctx context.Context) (*Library, error) {
+	if err := k.authenticate(ctx); err != nil {
+		return nil, fmt.Errorf("authenticating: %w", err)
+	}
+	library := &Library{Source: "kavita"}
+
+	rawSeries, err := k.listSeries(ctx, "/api/Series/all-v2", http.MethodPost, []byte(`{"statements":[],"combination":1,"limitTo":0}`))
+	if err != nil {
+		return nil, fmt.Errorf("listing series: %w", err)
+	}
+	for _, raw := range rawSeries {
+		series, err := k.series(ctx, raw)
+		if err != nil {
+			return nil, fmt.Errorf("reading %q: %w", raw.Name, err)
+		}
+		library.Series = append(library.Series, series)
+	}
+
+	var rawCollections []kavitaCollection
+	if err := k.get(ctx, "/api/Collection", &rawCollections); err != nil {
+		return nil, fmt.Errorf("listing collections: %w", err)
+	}
+	for _, raw := range rawCollections {
+		members, err := k.listSeries(ctx, "/api/Series/series-by-collection?collectionId="+strconv.Itoa(raw.ID), http.MethodGet, nil)
+		if err != nil {
+			return nil, fmt.Errorf("listing collection %q: %w", raw.Title, err)
+		}
+		collection := Collection{Name: raw.Title, Summary: raw.Summary}
+		for _, member := range members {
+			collection.SeriesIDs = append(collection.SeriesIDs, strconv.Itoa(member.ID))
+		}
+		library.Collections = append(library.Collections, collection)
+	}
+
+	logger.Info("Read Kavita library",
+		zap.String("baseURL", k.BaseURL),
+		zap.Int("seriesCount", len(library.Series)),
+		zap.Int("collectionCount", len(library.Collections)),
+	)
+	return library, nil
+}
+
+// authenticate exchanges the API key for a bearer token
+func (k *Kavita) authenticate(ctx context.Context) error {
+	q := url.Values{}
+	q.Set("apiKey", k.APIKey)
+	q.Set("pluginName", kavitaPluginName)
+	req, err := newRequest(ctx, http.MethodPost, k.BaseURL, "/api/Plugin/authenticate?"+q.Encode(), nil)
+	if err != nil {
+		return err
+	}
+
+	var user struct {
+		Token string `json:"token"`
+	}
+	if err := doJSON(httpClient(k.Client), req, &user); err != nil {
+		return err
+	}
+	if user.Token == "" {
+		return fmt.Errorf("no token returned")
+	}
+	k.token = user.Token
+	return nil
+}
+
+// series reads the metadata and, when anything has been read, the chapter
+// progress of one series
+func (k *Kavita) series(ctx context.Context, raw kavitaSeries) (Series, error) {
+	series := Series{
+		SourceID: strconv.Itoa(raw.ID),
+		Title:    raw.Name,
+		Path:     raw.FolderPath,
+	}
+	for _, alt := range []string{raw.LocalizedName, raw.OriginalName} {
+		if !strings.EqualFold(alt, raw.Name) {
+			series.AltTitles = appendUnique(series.AltTitles, alt)
+		}
+	}
+
+	var meta kavitaMetadata
+	if err := k.get(ctx, "/api/Series/metadata?seriesId="+series.SourceID, &meta); err != nil {
+		return Series{}, err
+	}
+	series.Summary = meta.Summary
+	series.Status = kavitaStatus(meta.PublicationStatus)
+	series.Year = meta.ReleaseYear
+	series.Genres = kavitaNames(meta.Genres)
+	series.Tags = kavitaNames(meta.Tags)
+	series.Authors = kavitaNames(meta.Writers)
+	series.Artists = kavitaNames(meta.Pencillers)
+	if publishers := kavitaNames(meta.Publishers); len(publishers) > 0 {
+		series.Publisher = publishers[0]
+	}
+
+	if raw.PagesRead == 0 {
+		return series, nil
+	}
+	var volumes []kavitaVolume
+	if err := k.get(ctx, "/api/Series/volumes?seriesId="+series.SourceID, &volumes); err != nil {
+		return Series{}, err
+	}
+	for _, volume := range volumes {
+		for _, chapter := range volume.Chapters {
+			if chapter.PagesRead == 0 {
+				continue
+			}
+			number := 0.0
+			if chapter.MinNumber != nil {
+				number = *chapter.MinNumber
+			} else {
+				number, _ = strconv.ParseFloat(chapter.Number, 64)
+			}
+			series.Progress = append(series.Progress, ChapterProgress{
+				Number:    number,
+				Page:      chapter.PagesRead,
+				PageCount: chapter.Pages,
+				Completed: chapter.Pages > 0 && chapter.PagesRead >= chapter.Pages,
+				ReadAt:    chapter.LastReadingProgressUtc.Time,
+			})
+		}
+	}
+	return series, nil
+}
+
+// listSeries walks every page of a paged series listing
+func (k *Kavita) listSeries(ctx context.Context, path, method string, body []byte) ([]kavitaSeries, error) {
+	client := httpClient(k.Client)
+	separator := "?"
+	if strings.Contains(path, "?") {
+		separator = "&"
+	}
+
+	var all []kavitaSeries
+	for page := 1; ; page++ {
+		paged := path + separator + "PageNumber=" + strconv.Itoa(page) + "&PageSize=" + strconv.Itoa(kavitaPageSize)
+		req, err := newRequest(ctx, method, k.BaseURL, paged, bytes.NewReader(body))
+		if err != nil {
+			return nil, err
+		}
+		if body != nil {
+			req.Header.Set("Content-Type", "application/json")
+		}
+		k.authorize(req)
+
+		var result []kavitaSeries
+		if err := doJSON(client, req, &result); err != nil {
+			return nil, err
+		}
+		all = append(all, result...)
+		if len(result) < kavitaPageSize {
+			return all, nil
+		}
+	}
+}
+
+func (k *Kavita) get(ctx context.Context, path string, v interface{}) error {
+	req, err := newRequest(ctx, http.MethodGet, k.BaseURL, path, nil)
+	if err != nil {
+		return err
+	}
+	k.authorize(req)
+	return doJSON(httpClient(k.Client), req, v)
+}
+
+func (k *Kavita) authorize(req *http.Request) {
+	req.Header.Set("Authorization", "Bearer "+k.token)
+}
+
+func kavitaNames(list []kavitaNamed) []string {
+	var names []string
+	for _, item := range list {
+		name := item.Title
+		if name == "" {
+			name = item.Name
+		}
+		names = appendUnique(names, name)
+	}
+	return names
+}
+
+func kavitaStatus(status int) string {
+	switch status {
+	case kavitaOngoing:
+		return StatusOngoing
+	case kavitaHiatus:
+		return StatusHiatus
+	case kavitaCompleted, kavitaEnded:
+		return StatusCompleted
+	case kavitaCancelled:
+		return StatusCancelled
+	}
+	return ""
+}
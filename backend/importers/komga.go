@@ -0,0 +1,211 @@
+package importers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// komgaPageSize is the page size used when walking Komga's paged listings
+const komgaPageSize = 500
+
+// Komga reads a Komga server through its v1 REST API. Read progress is that of
+// the account used to authenticate, which is either an API key or a username
+// and password.
+type Komga struct {
+	BaseURL  string
+	Username string
+	Password string
+	APIKey   string
+	Client   *http.Client
+}
+
+type komgaPage[T any] struct {
+	Content []T  `json:"content"`
+	Last    bool `json:"last"`
+}
+
+type komgaSeries struct {
+	ID                   string `json:"id"`
+	Name                 string `json:"name"`
+	URL                  string `json:"url"`
+	BooksReadCount       int    `json:"booksReadCount"`
+	BooksInProgressCount int    `json:"booksInProgressCount"`
+	Metadata             struct {
+		Status          string   `json:"status"`
+		Title           string   `json:"title"`
+		Summary         string   `json:"summary"`
+		Publisher       string   `json:"publisher"`
+		Genres          []string `json:"genres"`
+		Tags            []string `json:"tags"`
+		AlternateTitles []struct {
+			Title string `json:"title"`
+		} `json:"alternateTitles"`
+	} `json:"metadata"`
+	BooksMetadata struct {
+		Authors []struct {
+			Name string `json:"name"`
+			Role string `json:"role"`
+		} `json:"authors"`
+		Summary     string `json:"summary"`
+		ReleaseDate string `json:"releaseDate"`
+	} `json:"booksMetadata"`
+}
+
+type komgaBook struct {
+	Media struct {
+		PagesCount int `json:"pagesCount"`
+	} `json:"media"`
+	Metadata struct {
+		NumberSort float64 `json:"numberSort"`
+	} `json:"metadata"`
+	ReadProgress *struct {
+		Page      int       `json:"page"`
+		Completed bool      `json:"completed"`
+		ReadDate  time.Time `json:"readDate"`
+	} `json:"readProgress"`
+}
+
+type komgaCollection struct {
+	Name      string   `json:"name"`
+	SeriesIDs []string `json:"seriesIds"`
+}
+
+// Fetch reads every series, the account's read progress and all collections
+func (k *Komga) Fetch(ctx context.Context) (*Library, error) {
+	library := &Library{Source: "komga"}
+
+	rawSeries, err := komgaList[komgaSeries](ctx, k, "/api/v1/series")
+	if err != nil {
+		return nil, fmt.Errorf("listing series: %w", err)
+	}
+	for _, raw := range rawSeries {
+		series := raw.toSeries()
+		if raw.BooksReadCount+raw.BooksInProgressCount > 0 {
+			books, err := komgaList[komgaBook](ctx, k, "/api/v1/series/"+url.PathEscape(raw.ID)+"/books")
+			if err != nil {
+				return nil, fmt.Errorf("listing books of %q: %w", raw.Name, err)
+			}
+			for _, book := range books {
+				if book.ReadProgress == nil {
+					continue
+				}
+				series.Progress = append(series.Progress, ChapterProgress{
+					Number:    book.Metadata.NumberSort,
+					Page:      book.ReadProgress.Page,
+					PageCount: book.Media.PagesCount,
+					Completed: book.ReadProgress.Completed,
+					ReadAt:    book.ReadProgress.ReadDate,
+				})
+			}
+		}
+		library.Series = append(library.Series, series)
+	}
+
+	rawCollections, err := komgaList[komgaCollection](ctx, k, "/api/v1/collections")
+	if err != nil {
+		return nil, fmt.Errorf("listing collections: %w", err)
+	}
+	for _, raw := range rawCollections {
+		library.Collections = append(library.Collections, Collection{Name: raw.Name, SeriesIDs: raw.SeriesIDs})
+	}
+
+	logger.Info("Read Komga library",
+		zap.String("baseURL", k.BaseURL),
+		zap.Int("seriesCount", len(library.Series)),
+		zap.Int("collectionCount", len(library.Collections)),
+	)
+	return library, nil
+}
+
+// komgaList walks every page of a paged Komga listing
+func komgaList[T any](ctx context.Context, k *Komga, path string) ([]T, error) {
+	client := httpClient(k.Client)
+
+	var all []T
+	for page := 0; ; page++ {
+		q := url.Values{}
+		q.Set("page", strconv.Itoa(page))
+		q.Set("size", strconv.Itoa(komgaPageSize))
+
+		req, err := newRequest(ctx, http.MethodGet, k.BaseURL, path+"?"+q.Encode(), nil)
+		if err != nil {
+			return nil, err
+		}
+		k.authorize(req)
+
+		var result komgaPage[T]
+		if err := doJSON(client, req, &result); err != nil {
+			return nil, err
+		}
+		all = append(all, result.Content...)
+		if result.Last || len(result.Content) == 0 {
+			return all, nil
+		}
+	}
+}
+
+func (k *Komga) authorize(req *http.Request) {
+	if k.APIKey != "" {
+		req.Header.Set("X-API-Key", k.APIKey)
+		return
+	}
+	req.SetBasicAuth(k.Username, k.Password)
+}
+
+func (raw komgaSeries) toSeries() Series {
+	series := Series{
+		SourceID:  raw.ID,
+		Title:     raw.Metadata.Title,
+		Summary:   raw.Metadata.Summary,
+		Status:    komgaStatus(raw.Metadata.Status),
+		Genres:    appendUnique(nil, raw.Metadata.Genres...),
+		Tags:      appendUnique(nil, raw.Metadata.Tags...),
+		Publisher: raw.Metadata.Publisher,
+		Path:      raw.URL,
+	}
+	if series.Title == "" {
+		series.Title = raw.Name
+	}
+	if series.Summary == "" {
+		series.Summary = raw.BooksMetadata.Summary
+	}
+	for _, alt := range raw.Metadata.AlternateTitles {
+		series.AltTitles = appendUnique(series.AltTitles, alt.Title)
+	}
+	if raw.Name != series.Title {
+		series.AltTitles = appendUnique(series.AltTitles, raw.Name)
+	}
+	for _, author := range raw.BooksMetadata.Authors {
+		switch strings.ToLower(author.Role) {
+		case "writer":
+			series.Authors = appendUnique(series.Authors, author.Name)
+		case "penciller", "artist":
+			series.Artists = appendUnique(series.Artists, author.Name)
+		}
+	}
+	if len(raw.BooksMetadata.ReleaseDate) >= 4 {
+		series.Year, _ = strconv.Atoi(raw.BooksMetadata.ReleaseDate[:4])
+	}
+	return series
+}
+
+func komgaStatus(status string) string {
+	switch strings.ToUpper(status) {
+	case "ONGOING":
+		return StatusOngoing
+	case "ENDED":
+		return StatusCompleted
+	case "HIATUS":
+		return StatusHiatus
+	case "ABANDONED":
+		return StatusCancelled
+	}
+	return ""
+}
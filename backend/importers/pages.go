@@ -0,0 +1,400 @@
+package importers
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"fmt"
+	"image"
+	_ "image/gif" // Register decoders so pages can be validated
+	_ "image/jpeg"
+	_ "image/png"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+	_ "golang.org/x/image/webp" // Register WebP format for decoding
+)
+
+const (
+	// MaxPageSize caps a single downloaded or extracted page image
+	MaxPageSize = 50 << 20
+
+	// MaxArchiveSize caps a downloaded chapter archive
+	MaxArchiveSize = 2 << 30
+
+	// MaxExtractedSize caps the total size of the pages extracted from an
+	// archive, however well they compressed
+	MaxExtractedSize = 4 << 30
+
+	// MaxArchivePages caps the number of pages extracted from an archive
+	MaxArchivePages = 5000
+
+	// DefaultAttempts is how often a download is tried before giving up
+	DefaultAttempts = 3
+
+	// retryDelay is the wait before the first retry; it doubles each attempt
+	retryDelay = time.Second
+)
+
+// pageExtensions maps decoded image formats to the extension pages are saved with
+var pageExtensions = map[string]string{
+	"jpeg": ".jpg",
+	"png":  ".png",
+	"gif":  ".gif",
+	"webp": ".webp",
+}
+
+// ValidateURL checks that raw is an absolute http(s) URL
+func ValidateURL(raw string) error {
+	u, err := url.Parse(raw)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("%q is not an absolute http(s) URL", raw)
+	}
+	return nil
+}
+
+// RemotePage is a page image to download, with any headers the host requires
+// (e.g. a Referer)
+type RemotePage struct {
+	URL    string
+	Header http.Header
+}
+
+// PagesFromURLs wraps plain URLs as remote pages
+func PagesFromURLs(urls []string) []RemotePage {
+	pages := make([]RemotePage, len(urls))
+	for i, u := range urls {
+		pages[i] = RemotePage{URL: u}
+	}
+	return pages
+}
+
+// Download fetches rawURL into w, reading at most maxBytes. Failures that may
+// be temporary (network errors, 429 and 5xx responses) are retried with
+// exponential backoff; each retry starts writing again from scratch, so w is
+// reset through the supplied reset func.
+func Download(ctx context.Context, client *http.Client, rawURL string, header http.Header, maxBytes int64, attempts int, reset func() error, w io.Writer) error {
+	client = httpClient(client)
+	delay := retryDelay
+
+	var lastErr error
+	for attempt := 1; attempt <= max(attempts, 1); attempt++ {
+		if attempt > 1 {
+			logger.Warn("Retrying download",
+				zap.String("url", rawURL),
+				zap.Int("attempt", attempt),
+				zap.Error(lastErr),
+			)
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(delay):
+			}
+			delay *= 2
+			if reset != nil {
+				if err := reset(); err != nil {
+					return err
+				}
+			}
+		}
+
+		retry, err := downloadOnce(ctx, client, rawURL, header, maxBytes, w)
+		if err == nil {
+			return nil
+		}
+		if !retry || ctx.Err() != nil {
+			return err
+		}
+		lastErr = err
+	}
+	return lastErr
+}
+
+// downloadOnce makes a single attempt, reporting whether a failure is worth retrying
+func downloadOnce(ctx context.Context, client *http.Client, rawURL string, header http.Header, maxBytes int64, w io.Writer) (bool, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+	if err != nil {
+		return false, err
+	}
+	for key, values := range header {
+		req.Header[key] = values
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return true, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		retry := resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500
+		return retry, fmt.Errorf("%s returned %s", rawURL, resp.Status)
+	}
+	if resp.ContentLength > maxBytes {
+		return false, fmt.Errorf("%s is larger than %d bytes", rawURL, maxBytes)
+	}
+
+	n, err := io.Copy(w, io.LimitReader(resp.Body, maxBytes+1))
+	if err != nil {
+		return true, err
+	}
+	if n > maxBytes {
+		return false, fmt.Errorf("%s is larger than %d bytes", rawURL, maxBytes)
+	}
+	return false, nil
+}
+
+// DownloadPages downloads page images in order into dir, naming them 001.jpg,
+// 002.png and so on. report is called after each page. Every download is
+// checked to be a decodable image.
+func DownloadPages(ctx context.Context, client *http.Client, pages []RemotePage, dir string, report func(done, total int)) (int, error) {
+	for i, page := range pages {
+		var buf bytes.Buffer
+		err := Download(ctx, client, page.URL, page.Header, MaxPageSize, DefaultAttempts, func() error { buf.Reset(); return nil }, &buf)
+		if err != nil {
+			return i, fmt.Errorf("page %d: %w", i+1, err)
+		}
+		if err := writePage(dir, i+1, buf.Bytes()); err != nil {
+			return i, fmt.Errorf("page %d (%s): %w", i+1, page.URL, err)
+		}
+		if report != nil {
+			report(i+1, len(pages))
+		}
+	}
+	return len(pages), nil
+}
+
+// DownloadArchive downloads a CBZ (zip) archive to a temporary file and
+// extracts its pages into dir. report is called after each extracted page.
+func DownloadArchive(ctx context.Context, client *http.Client, rawURL, dir string, report func(done, total int)) (int, error) {
+	tmp, err := os.CreateTemp("", "mangahub-*.cbz")
+	if err != nil {
+		return 0, err
+	}
+	defer os.Remove(tmp.Name())
+	defer tmp.Close()
+
+	reset := func() error {
+		if _, err := tmp.Seek(0, io.SeekStart); err != nil {
+			return err
+		}
+		return tmp.Truncate(0)
+	}
+	if err := Download(ctx, client, rawURL, nil, MaxArchiveSize, DefaultAttempts, reset, tmp); err != nil {
+		return 0, err
+	}
+	return ExtractArchive(ctx, tmp.Name(), dir, report)
+}
+
+// ExtractArchive extracts the images of a CBZ (zip) archive into dir in name
+// order, numbering them like DownloadPages. Directories inside the archive
+// are flattened and non-image entries are skipped, except a ComicInfo.xml,
+// which is copied as is. Archives with more than MaxArchivePages pages or
+// whose pages add up to more than MaxExtractedSize are rejected.
+func ExtractArchive(ctx context.Context, archivePath, dir string, report func(done, total int)) (int, error) {
+	archive, err := zip.OpenReader(archivePath)
+	if err != nil {
+		return 0, fmt.Errorf("opening archive: %w", err)
+	}
+	defer archive.Close()
+
+	var entries []*zip.File
+	var comicInfo *zip.File
+	for _, f := range archive.File {
+		name := filepath.Base(f.Name)
+		if f.FileInfo().IsDir() || strings.HasPrefix(name, ".") || strings.HasPrefix(f.Name, "__MACOSX/") {
+			continue
+		}
+		if isPageImage(name) {
+			entries = append(entries, f)
+		} else if isComicInfo(name) && f.UncompressedSize64 <= maxComicInfoSize {
+			comicInfo = f
+		}
+	}
+	if len(entries) == 0 {
+		return 0, fmt.Errorf("archive contains no images")
+	}
+	if len(entries) > MaxArchivePages {
+		return 0, fmt.Errorf("archive contains more than %d images", MaxArchivePages)
+	}
+	var declared uint64
+	for _, f := range entries {
+		declared += f.UncompressedSize64
+	}
+	if declared > MaxExtractedSize {
+		return 0, fmt.Errorf("archive images add up to more than %d bytes", MaxExtractedSize)
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return naturalLess(entries[i].Name, entries[j].Name)
+	})
+
+	var extracted int64
+	for i, f := range entries {
+		if err := ctx.Err(); err != nil {
+			return i, err
+		}
+		if f.UncompressedSize64 > MaxPageSize {
+			return i, fmt.Errorf("%s is larger than %d bytes", f.Name, MaxPageSize)
+		}
+		rc, err := f.Open()
+		if err != nil {
+			return i, err
+		}
+		data, err := io.ReadAll(io.LimitReader(rc, MaxPageSize+1))
+		rc.Close()
+		if err != nil {
+			return i, fmt.Errorf("%s: %w", f.Name, err)
+		}
+		// The sizes in the archive's directory may lie, so check what was read
+		if len(data) > MaxPageSize {
+			return i, fmt.Errorf("%s is larger than %d bytes", f.Name, MaxPageSize)
+		}
+		if extracted += int64(len(data)); extracted > MaxExtractedSize {
+			return i, fmt.Errorf("archive images add up to more than %d bytes", MaxExtractedSize)
+		}
+		if err := writePage(dir, i+1, data); err != nil {
+			return i, fmt.Errorf("%s: %w", f.Name, err)
+		}
+		if report != nil {
+			report(i+1, len(entries))
+		}
+	}
+
+	if comicInfo != nil {
+		rc, err := comicInfo.Open()
+		if err != nil {
+			return len(entries), err
+		}
+		data, err := io.ReadAll(io.LimitReader(rc, maxComicInfoSize))
+		rc.Close()
+		if err != nil {
+			return len(entries), fmt.Errorf("%s: %w", comicInfo.Name, err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, ComicInfoFileName), data, 0644); err != nil {
+			return len(entries), err
+		}
+	}
+	return len(entries), nil
+}
+
+// CopyPages copies the images in srcDir into dir in name order, numbering
+// them like DownloadPages, along with a ComicInfo.xml. Other files and
+// subfolders are ignored.
+func CopyPages(ctx context.Context, srcDir, dir string, report func(done, total int)) (int, error) {
+	entries, err := os.ReadDir(srcDir)
+	if err != nil {
+		return 0, err
+	}
+	var names []string
+	comicInfo := ""
+	for _, entry := range entries {
+		if entry.IsDir() || strings.HasPrefix(entry.Name(), ".") {
+			continue
+		}
+		if isPageImage(entry.Name()) {
+			names = append(names, entry.Name())
+		} else if isComicInfo(entry.Name()) {
+			comicInfo = entry.Name()
+		}
+	}
+	if len(names) == 0 {
+		return 0, fmt.Errorf("folder contains no images")
+	}
+	sort.Slice(names, func(i, j int) bool {
+		return naturalLess(names[i], names[j])
+	})
+
+	for i, name := range names {
+		if err := ctx.Err(); err != nil {
+			return i, err
+		}
+		data, err := os.ReadFile(filepath.Join(srcDir, name))
+		if err != nil {
+			return i, err
+		}
+		if len(data) > MaxPageSize {
+			return i, fmt.Errorf("%s is larger than %d bytes", name, MaxPageSize)
+		}
+		if err := writePage(dir, i+1, data); err != nil {
+			return i, fmt.Errorf("%s: %w", name, err)
+		}
+		if report != nil {
+			report(i+1, len(names))
+		}
+	}
+
+	if comicInfo != "" {
+		data, err := os.ReadFile(filepath.Join(srcDir, comicInfo))
+		if err != nil {
+			return len(names), err
+		}
+		if len(data) <= maxComicInfoSize {
+			if err := os.WriteFile(filepath.Join(dir, ComicInfoFileName), data, 0644); err != nil {
+				return len(names), err
+			}
+		}
+	}
+	return len(names), nil
+}
+
+// writePage validates data as an image and stores it as page number n
+func writePage(dir string, n int, data []byte) error {
+	_, format, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return fmt.Errorf("not a valid image: %w", err)
+	}
+	ext, ok := pageExtensions[format]
+	if !ok {
+		return fmt.Errorf("unsupported image format %q", format)
+	}
+	return os.WriteFile(filepath.Join(dir, fmt.Sprintf("%03d%s", n, ext)), data, 0644)
+}
+
+// isPageImage reports whether name has the extension of a supported image
+func isPageImage(name string) bool {
+	ext := strings.ToLower(filepath.Ext(name))
+	for _, known := range pageExtensions {
+		if ext == known {
+			return true
+		}
+	}
+	return ext == ".jpeg"
+}
+
+// naturalLess orders names so that digit runs compare numerically, e.g.
+// "page2" before "page10"
+func naturalLess(a, b string) bool {
+	for a != "" && b != "" {
+		da, db := leadingDigits(a), leadingDigits(b)
+		if da != "" && db != "" {
+			na, nb := strings.TrimLeft(da, "0"), strings.TrimLeft(db, "0")
+			if len(na) != len(nb) {
+				return len(na) < len(nb)
+			}
+			if na != nb {
+				return na < nb
+			}
+			a, b = a[len(da):], b[len(db):]
+			continue
+		}
+		if a[0] != b[0] {
+			return a[0] < b[0]
+		}
+		a, b = a[1:], b[1:]
+	}
+	return len(a) < len(b)
+}
+
+func leadingDigits(s string) string {
+	i := 0
+	for i < len(s) && s[i] >= '0' && s[i] <= '9' {
+		i++
+	}
+	return s[:i]
+}
@@ -0,0 +1,277 @@
+package importers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+
+	"go.uber.org/zap"
+)
+
+// ProviderMangaDex names MangaDex, whose series IDs are UUIDs
+const ProviderMangaDex = "mangadex"
+
+// MetadataProvider looks up one series on a catalog site by its ID there
+type MetadataProvider interface {
+	Lookup(ctx context.Context, id string) (*Series, error)
+}
+
+// MangaDex reads series metadata from the MangaDex API
+type MangaDex struct {
+	BaseURL string // Defaults to https://api.mangadex.org
+	Client  *http.Client
+}
+
+type mangadexManga struct {
+	Data struct {
+		ID         string `json:"id"`
+		Attributes struct {
+			Title                  map[string]string   `json:"title"`
+			AltTitles              []map[string]string `json:"altTitles"`
+			Description            map[string]string   `json:"description"`
+			Status                 string              `json:"status"`
+			Year                   int                 `json:"year"`
+			PublicationDemographic string              `json:"publicationDemographic"`
+			Tags                   []struct {
+				Attributes struct {
+					Name  map[string]string `json:"name"`
+					Group string            `json:"group"`
+				} `json:"attributes"`
+			} `json:"tags"`
+		} `json:"attributes"`
+		Relationships []struct {
+			Type       string `json:"type"`
+			Attributes struct {
+				Name string `json:"name"`
+			} `json:"attributes"`
+		} `json:"relationships"`
+	} `json:"data"`
+}
+
+// Lookup fetches a series by its MangaDex UUID. English text is preferred,
+// then romanized Japanese, then whatever language comes first.
+func (m *MangaDex) Lookup(ctx context.Context, id string) (*Series, error) {
+	baseURL := m.BaseURL
+	if baseURL == "" {
+		baseURL = "https://api.mangadex.org"
+	}
+	req, err := newRequest(ctx, http.MethodGet, baseURL, "/manga/"+url.PathEscape(id)+"?includes[]=author&includes[]=artist", nil)
+	if err != nil {
+		return nil, err
+	}
+	var manga mangadexManga
+	if err := doJSON(httpClient(m.Client), req, &manga); err != nil {
+		return nil, err
+	}
+
+	attrs := manga.Data.Attributes
+	series := &Series{
+		SourceID:    manga.Data.ID,
+		Title:       localized(attrs.Title),
+		Summary:     strings.TrimSpace(localized(attrs.Description)),
+		Status:      mangadexStatus(attrs.Status),
+		Year:        attrs.Year,
+		Demographic: attrs.PublicationDemographic,
+	}
+	for _, alt := range attrs.AltTitles {
+		for _, title := range alt {
+			series.AltTitles = appendUnique(series.AltTitles, title)
+		}
+	}
+	for _, tag := range attrs.Tags {
+		name := localized(tag.Attributes.Name)
+		if tag.Attributes.Group == "genre" {
+			series.Genres = appendUnique(series.Genres, name)
+		} else {
+			series.Tags = appendUnique(series.Tags, name)
+		}
+	}
+	for _, rel := range manga.Data.Relationships {
+		switch rel.Type {
+		case "author":
+			series.Authors = appendUnique(series.Authors, rel.Attributes.Name)
+		case "artist":
+			series.Artists = appendUnique(series.Artists, rel.Attributes.Name)
+		}
+	}
+	logger.Info("Looked up MangaDex series", zap.String("id", id), zap.String("title", series.Title))
+	return series, nil
+}
+
+// localized picks the English value of a MangaDex localized string, then the
+// romanized Japanese one, then the first by language code
+func localized(values map[string]string) string {
+	for _, lang := range []string{"en", "ja-ro"} {
+		if v := strings.TrimSpace(values[lang]); v != "" {
+			return v
+		}
+	}
+	for _, lang := range slices.Sorted(maps.Keys(values)) {
+		if v := strings.TrimSpace(values[lang]); v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+func mangadexStatus(status string) string {
+	switch status {
+	case "ongoing":
+		return StatusOngoing
+	case "completed":
+		return StatusCompleted
+	case "hiatus":
+		return StatusHiatus
+	case "cancelled":
+		return StatusCancelled
+	}
+	return ""
+}
+
+// AniList reads series metadata from the AniList GraphQL API
+type AniList struct {
+	BaseURL string // Defaults to https://graphql.anilist.co
+	Client  *http.Client
+}
+
+const anilistMediaQuery = `query ($id: Int) {
+  Media(id: $id, type: MANGA) {
+    id
+    title { romaji english native }
+    synonyms
+    description(asHtml: false)
+    status
+    genres
+    tags { name isMediaSpoiler }
+    startDate { year }
+    staff { edges { role node { name { full } } } }
+  }
+}`
+
+type anilistMedia struct {
+	Data struct {
+		Media *struct {
+			ID    int `json:"id"`
+			Title struct {
+				Romaji  string `json:"romaji"`
+				English string `json:"english"`
+				Native  string `json:"native"`
+			} `json:"title"`
+			Synonyms    []string `json:"synonyms"`
+			Description string   `json:"description"`
+			Status      string   `json:"status"`
+			Genres      []string `json:"genres"`
+			Tags        []struct {
+				Name    string `json:"name"`
+				Spoiler bool   `json:"isMediaSpoiler"`
+			} `json:"tags"`
+			StartDate struct {
+				Year int `json:"year"`
+			} `json:"startDate"`
+			Staff struct {
+				Edges []struct {
+					Role string `json:"role"`
+					Node struct {
+						Name struct {
+							Full string `json:"full"`
+						} `json:"name"`
+					} `json:"node"`
+				} `json:"edges"`
+			} `json:"staff"`
+		} `json:"Media"`
+	} `json:"data"`
+	Errors []struct {
+		Message string `json:"message"`
+	} `json:"errors"`
+}
+
+// Lookup fetches a series by its AniList media ID. Spoiler tags are left out.
+func (a *AniList) Lookup(ctx context.Context, id string) (*Series, error) {
+	baseURL := a.BaseURL
+	if baseURL == "" {
+		baseURL = "https://graphql.anilist.co"
+	}
+	mediaID, err := strconv.Atoi(id)
+	if err != nil {
+		return nil, fmt.Errorf("AniList IDs are numbers, not %q", id)
+	}
+	body, err := json.Marshal(map[string]interface{}{
+		"query":     anilistMediaQuery,
+		"variables": map[string]int{"id": mediaID},
+	})
+	if err != nil {
+		return nil, err
+	}
+	req, err := newRequest(ctx, http.MethodPost, baseURL, "/", bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	var response anilistMedia
+	if err := doJSON(httpClient(a.Client), req, &response); err != nil {
+		return nil, err
+	}
+	if len(response.Errors) > 0 {
+		return nil, fmt.Errorf("AniList: %s", response.Errors[0].Message)
+	}
+	media := response.Data.Media
+	if media == nil {
+		return nil, fmt.Errorf("AniList has no manga with ID %d", mediaID)
+	}
+
+	series := &Series{
+		SourceID: strconv.Itoa(media.ID),
+		Status:   anilistStatus(media.Status),
+		Genres:   appendUnique(nil, media.Genres...),
+		Year:     media.StartDate.Year,
+	}
+	for _, title := range append([]string{media.Title.English, media.Title.Romaji, media.Title.Native}, media.Synonyms...) {
+		if series.Title == "" {
+			series.Title = strings.TrimSpace(title)
+			continue
+		}
+		if !strings.EqualFold(strings.TrimSpace(title), series.Title) {
+			series.AltTitles = appendUnique(series.AltTitles, title)
+		}
+	}
+	// AniList leaves HTML in descriptions even when asked for plain text
+	series.Summary = plainText(media.Description)
+	for _, tag := range media.Tags {
+		if !tag.Spoiler {
+			series.Tags = appendUnique(series.Tags, tag.Name)
+		}
+	}
+	for _, edge := range media.Staff.Edges {
+		role := strings.ToLower(edge.Role)
+		name := edge.Node.Name.Full
+		if strings.Contains(role, "story") {
+			series.Authors = appendUnique(series.Authors, name)
+		}
+		if strings.Contains(role, "art") {
+			series.Artists = appendUnique(series.Artists, name)
+		}
+	}
+	logger.Info("Looked up AniList series", zap.String("id", id), zap.String("title", series.Title))
+	return series, nil
+}
+
+func anilistStatus(status string) string {
+	switch status {
+	case "RELEASING":
+		return StatusOngoing
+	case "FINISHED":
+		return StatusCompleted
+	case "HIATUS":
+		return StatusHiatus
+	case "CANCELLED":
+		return StatusCancelled
+	}
+	return ""
+}
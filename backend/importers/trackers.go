@@ -0,0 +1,198 @@
+package importers
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"go.uber.org/zap"
+)
+
+// Reading statuses of tracker list entries, matching the values the progress
+// store keeps
+const (
+	ListReading   = "reading"
+	ListCompleted = "completed"
+	ListOnHold    = "on-hold"
+	ListDropped   = "dropped"
+	ListPlanned   = "planned"
+)
+
+// MaxTrackerExportSize caps a tracker list export, after decompression
+const MaxTrackerExportSize = 32 << 20
+
+// Trackers whose list exports ParseTrackerExport reads
+const (
+	TrackerMAL     = "mal"
+	TrackerAniList = "anilist"
+)
+
+// TrackerEntry is one series of a reading list exported from a tracker
+type TrackerEntry struct {
+	Tracker      string // TrackerMAL or TrackerAniList
+	ID           string // The series' ID on the tracker
+	MALID        string // MyAnimeList ID, which AniList entries carry too
+	Title        string
+	AltTitles    []string
+	Status       string // One of the List* constants, or empty when unknown
+	ChaptersRead float64
+}
+
+// malExport is a MyAnimeList manga list export
+type malExport struct {
+	XMLName xml.Name `xml:"myanimelist"`
+	Manga   []struct {
+		ID           string  `xml:"manga_mangadb_id"`
+		Title        string  `xml:"manga_title"`
+		ChaptersRead float64 `xml:"my_read_chapters"`
+		Status       string  `xml:"my_status"`
+	} `xml:"manga"`
+}
+
+// malStatuses maps MyAnimeList statuses, as words or as the numbers older
+// exports use, to list statuses
+var malStatuses = map[string]string{
+	"reading":      ListReading,
+	"completed":    ListCompleted,
+	"on-hold":      ListOnHold,
+	"dropped":      ListDropped,
+	"plan to read": ListPlanned,
+	"1":            ListReading,
+	"2":            ListCompleted,
+	"3":            ListOnHold,
+	"4":            ListDropped,
+	"6":            ListPlanned,
+}
+
+// anilistCollection is an AniList MediaListCollection, as returned by its
+// GraphQL API for a user's manga list
+type anilistCollection struct {
+	Lists []struct {
+		Entries []struct {
+			Status   string  `json:"status"`
+			Progress float64 `json:"progress"`
+			Media    struct {
+				ID    int `json:"id"`
+				IDMal int `json:"idMal"`
+				Title struct {
+					Romaji  string `json:"romaji"`
+					English string `json:"english"`
+					Native  string `json:"native"`
+				} `json:"title"`
+				Synonyms []string `json:"synonyms"`
+			} `json:"media"`
+		} `json:"entries"`
+	} `json:"lists"`
+}
+
+// anilistStatuses maps AniList list statuses to list statuses
+var anilistStatuses = map[string]string{
+	"CURRENT":   ListReading,
+	"REPEATING": ListReading,
+	"COMPLETED": ListCompleted,
+	"PAUSED":    ListOnHold,
+	"DROPPED":   ListDropped,
+	"PLANNING":  ListPlanned,
+}
+
+// ParseTrackerExport reads a reading list exported from MyAnimeList (XML,
+// optionally gzip-compressed, as MAL's export produces) or AniList (the JSON
+// of a MediaListCollection query, either the whole response or the
+// collection itself)
+func ParseTrackerExport(data []byte) ([]TrackerEntry, error) {
+	if bytes.HasPrefix(data, []byte{0x1f, 0x8b}) {
+		zr, err := gzip.NewReader(bytes.NewReader(data))
+		if err != nil {
+			return nil, fmt.Errorf("reading gzip export: %w", err)
+		}
+		if data, err = io.ReadAll(io.LimitReader(zr, MaxTrackerExportSize+1)); err != nil {
+			return nil, fmt.Errorf("reading gzip export: %w", err)
+		}
+		if len(data) > MaxTrackerExportSize {
+			return nil, fmt.Errorf("export is larger than %d MB", MaxTrackerExportSize>>20)
+		}
+	}
+
+	trimmed := bytes.TrimSpace(data)
+	switch {
+	case bytes.HasPrefix(trimmed, []byte("<")):
+		return parseMALExport(trimmed)
+	case bytes.HasPrefix(trimmed, []byte("{")):
+		return parseAniListExport(trimmed)
+	}
+	return nil, fmt.Errorf("not a MyAnimeList XML or AniList JSON export")
+}
+
+func parseMALExport(data []byte) ([]TrackerEntry, error) {
+	var export malExport
+	if err := xml.Unmarshal(data, &export); err != nil {
+		return nil, fmt.Errorf("parsing MyAnimeList export: %w", err)
+	}
+	entries := make([]TrackerEntry, 0, len(export.Manga))
+	for _, manga := range export.Manga {
+		id := strings.TrimSpace(manga.ID)
+		entries = append(entries, TrackerEntry{
+			Tracker:      TrackerMAL,
+			ID:           id,
+			MALID:        id,
+			Title:        strings.TrimSpace(manga.Title),
+			Status:       malStatuses[strings.ToLower(strings.TrimSpace(manga.Status))],
+			ChaptersRead: manga.ChaptersRead,
+		})
+	}
+	logger.Info("Parsed MyAnimeList export", zap.Int("entryCount", len(entries)))
+	return entries, nil
+}
+
+func parseAniListExport(data []byte) ([]TrackerEntry, error) {
+	var response struct {
+		Data struct {
+			MediaListCollection *anilistCollection `json:"MediaListCollection"`
+		} `json:"data"`
+		MediaListCollection *anilistCollection `json:"MediaListCollection"`
+		anilistCollection
+	}
+	if err := json.Unmarshal(data, &response); err != nil {
+		return nil, fmt.Errorf("parsing AniList export: %w", err)
+	}
+	collection := &response.anilistCollection
+	switch {
+	case response.Data.MediaListCollection != nil:
+		collection = response.Data.MediaListCollection
+	case response.MediaListCollection != nil:
+		collection = response.MediaListCollection
+	}
+
+	var entries []TrackerEntry
+	for _, list := range collection.Lists {
+		for _, e := range list.Entries {
+			media := e.Media
+			entry := TrackerEntry{
+				Tracker:      TrackerAniList,
+				ID:           strconv.Itoa(media.ID),
+				Status:       anilistStatuses[strings.ToUpper(e.Status)],
+				ChaptersRead: e.Progress,
+			}
+			if media.IDMal != 0 {
+				entry.MALID = strconv.Itoa(media.IDMal)
+			}
+			for _, title := range append([]string{media.Title.English, media.Title.Romaji, media.Title.Native}, media.Synonyms...) {
+				switch title = strings.TrimSpace(title); {
+				case title == "":
+				case entry.Title == "":
+					entry.Title = title
+				default:
+					entry.AltTitles = append(entry.AltTitles, title)
+				}
+			}
+			entries = append(entries, entry)
+		}
+	}
+	logger.Info("Parsed AniList export", zap.Int("entryCount", len(entries)))
+	return entries, nil
+}
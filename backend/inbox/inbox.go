@@ -0,0 +1,287 @@
+// Package inbox watches a drop folder for new chapters. CBZ archives and
+// folders of page images named after their series and chapter, e.g.
+// "Berserk - Chapter 12.cbz" or "Berserk v02 c012", are picked up once they
+// stop changing. A folder that isn't itself a chapter is read as a series
+// folder holding chapters, e.g. "Berserk/Chapter 12.cbz".
+package inbox
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+const (
+	// DefaultInterval is how often the inbox is scanned
+	DefaultInterval = 30 * time.Second
+
+	// FailedDir is the folder inside the inbox that items which could not be
+	// imported are moved to, so they aren't retried on every scan
+	FailedDir = ".failed"
+)
+
+// logger discards output until SetLogger installs the configured logger
+var logger = zap.NewNop()
+
+// SetLogger sets the logger used by this package
+func SetLogger(l *zap.Logger) {
+	logger = l
+}
+
+// Item is a chapter waiting in the inbox
+type Item struct {
+	Path    string  // Archive or folder of page images
+	Archive bool    // Path is a CBZ file rather than a folder
+	Series  string  // Series title as named in the inbox
+	Number  float64 // Chapter number
+	Volume  int     // Volume number, 0 when not named
+}
+
+// Name is the item's file or folder name
+func (i Item) Name() string {
+	return filepath.Base(i.Path)
+}
+
+var (
+	// chapterPattern matches "<series> [v|vol. N] (c|ch.|chapter|#) N[.N] [anything]"
+	chapterPattern = regexp.MustCompile(`(?i)^(.*?)[\s._-]*(?:\bv(?:ol(?:ume)?)?\.?\s*(\d+))?[\s._-]*(?:\bc(?:h(?:apter)?)?\.?|#)\s*(\d+(?:\.\d+)?)(?:[\s._)\]-].*)?$`)
+
+	// dashPattern matches "<series> - N[.N]"
+	dashPattern = regexp.MustCompile(`^(.+?)\s+-\s+(\d+(?:\.\d+)?)$`)
+)
+
+// Parse reads the series title, chapter number and volume from a file or
+// folder name. The series title may be empty for names like "Chapter 12".
+func Parse(name string) (series string, number float64, volume int, ok bool) {
+	if strings.EqualFold(filepath.Ext(name), ".cbz") {
+		name = name[:len(name)-len(".cbz")]
+	}
+	name = strings.TrimSpace(strings.ReplaceAll(name, "_", " "))
+
+	var numberText, volumeText string
+	if m := chapterPattern.FindStringSubmatch(name); m != nil {
+		series, volumeText, numberText = m[1], m[2], m[3]
+	} else if m := dashPattern.FindStringSubmatch(name); m != nil {
+		series, numberText = m[1], m[2]
+	} else {
+		return "", 0, 0, false
+	}
+
+	number, err := strconv.ParseFloat(numberText, 64)
+	if err != nil {
+		return "", 0, 0, false
+	}
+	if volumeText != "" {
+		volume, _ = strconv.Atoi(volumeText)
+	}
+	series = strings.Trim(series, " .-")
+	return series, number, volume, true
+}
+
+// Watcher finds inbox items that have stopped changing
+type Watcher struct {
+	dir string
+
+	// sizes remembers each entry's size and modification time from the
+	// previous scan; an item is only ready once they are unchanged
+	sizes map[string]snapshot
+}
+
+type snapshot struct {
+	size    int64
+	modTime time.Time
+}
+
+// NewWatcher creates a watcher for dir, creating the directory if needed
+func NewWatcher(dir string) (*Watcher, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+	return &Watcher{dir: dir, sizes: make(map[string]snapshot)}, nil
+}
+
+// Dir is the watched directory
+func (w *Watcher) Dir() string {
+	return w.dir
+}
+
+// Run scans the inbox every interval until stop is closed, passing the items
+// that are ready to handle. Items are expected to be gone from the inbox
+// once handle returns, either imported or moved aside with Reject.
+func (w *Watcher) Run(interval time.Duration, stop <-chan struct{}, handle func([]Item)) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			if items := w.Scan(); len(items) > 0 {
+				handle(items)
+			}
+		case <-stop:
+			return
+		}
+	}
+}
+
+// Scan returns the items that are unchanged since the previous scan.
+// Entries whose names can't be parsed are moved to FailedDir.
+func (w *Watcher) Scan() []Item {
+	entries, err := os.ReadDir(w.dir)
+	if err != nil {
+		logger.Error("Failed to read inbox", zap.String("dir", w.dir), zap.Error(err))
+		return nil
+	}
+
+	seen := make(map[string]snapshot)
+	var ready []Item
+	for _, entry := range entries {
+		if strings.HasPrefix(entry.Name(), ".") {
+			continue
+		}
+		path := filepath.Join(w.dir, entry.Name())
+		current, err := measure(path)
+		if err != nil {
+			logger.Warn("Failed to read inbox entry", zap.String("path", path), zap.Error(err))
+			continue
+		}
+		seen[path] = current
+		if previous, ok := w.sizes[path]; !ok || previous != current {
+			continue
+		}
+
+		items, err := itemsIn(path, entry.IsDir())
+		if err != nil {
+			w.Reject(path, err)
+			continue
+		}
+		ready = append(ready, items...)
+	}
+	w.sizes = seen
+	return ready
+}
+
+// Reject moves an inbox entry that couldn't be imported to FailedDir
+func (w *Watcher) Reject(path string, reason error) {
+	logger.Warn("Inbox entry rejected", zap.String("path", path), zap.Error(reason))
+
+	failedDir := filepath.Join(w.dir, FailedDir)
+	if err := os.MkdirAll(failedDir, 0755); err != nil {
+		logger.Error("Failed to create failed folder", zap.String("dir", failedDir), zap.Error(err))
+		return
+	}
+	target := filepath.Join(failedDir, filepath.Base(path))
+	if _, err := os.Stat(target); err == nil {
+		target += "." + time.Now().Format("20060102150405")
+	}
+	if err := os.Rename(path, target); err != nil {
+		logger.Error("Failed to move rejected inbox entry", zap.String("path", path), zap.Error(err))
+	}
+}
+
+// Done removes an imported item from the inbox, along with its series
+// folder once that is empty
+func (w *Watcher) Done(item Item) {
+	if err := os.RemoveAll(item.Path); err != nil {
+		logger.Error("Failed to remove imported inbox entry", zap.String("path", item.Path), zap.Error(err))
+		return
+	}
+	if parent := filepath.Dir(item.Path); parent != filepath.Clean(w.dir) {
+		// Fails, as intended, while other chapters are still in the folder
+		os.Remove(parent)
+	}
+}
+
+// itemsIn reads a top-level inbox entry as a chapter, or as a series folder
+// of chapters
+func itemsIn(path string, isDir bool) ([]Item, error) {
+	name := filepath.Base(path)
+	if !isDir {
+		if !strings.EqualFold(filepath.Ext(name), ".cbz") {
+			return nil, fmt.Errorf("not a CBZ archive or folder")
+		}
+		item, ok := parseItem(path, "", true)
+		if !ok || item.Series == "" {
+			return nil, fmt.Errorf("cannot read series and chapter from %q", name)
+		}
+		return []Item{item}, nil
+	}
+
+	if item, ok := parseItem(path, "", false); ok && item.Series != "" && hasImages(path) {
+		return []Item{item}, nil
+	}
+
+	// A series folder: every entry must be a chapter
+	entries, err := os.ReadDir(path)
+	if err != nil {
+		return nil, err
+	}
+	var items []Item
+	for _, entry := range entries {
+		if strings.HasPrefix(entry.Name(), ".") {
+			continue
+		}
+		child := filepath.Join(path, entry.Name())
+		isArchive := !entry.IsDir()
+		if isArchive && !strings.EqualFold(filepath.Ext(entry.Name()), ".cbz") {
+			continue
+		}
+		item, ok := parseItem(child, name, isArchive)
+		if !ok {
+			return nil, fmt.Errorf("cannot read chapter from %q", filepath.Join(name, entry.Name()))
+		}
+		items = append(items, item)
+	}
+	if len(items) == 0 {
+		return nil, fmt.Errorf("folder contains no chapters")
+	}
+	return items, nil
+}
+
+func parseItem(path, series string, archive bool) (Item, bool) {
+	named, number, volume, ok := Parse(filepath.Base(path))
+	if !ok {
+		return Item{}, false
+	}
+	if series == "" {
+		series = named
+	}
+	return Item{Path: path, Archive: archive, Series: series, Number: number, Volume: volume}, true
+}
+
+// hasImages reports whether a folder directly holds any files, i.e. pages
+func hasImages(dir string) bool {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return false
+	}
+	for _, entry := range entries {
+		if !entry.IsDir() && !strings.HasPrefix(entry.Name(), ".") && !strings.EqualFold(filepath.Ext(entry.Name()), ".cbz") {
+			return true
+		}
+	}
+	return false
+}
+
+// measure sums the sizes and finds the latest modification time of the
+// files under path
+func measure(path string) (snapshot, error) {
+	var s snapshot
+	err := filepath.Walk(path, func(_ string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		s.size += info.Size()
+		if info.ModTime().After(s.modTime) {
+			s.modTime = info.ModTime()
+		}
+		return nil
+	})
+	return s, err
+}
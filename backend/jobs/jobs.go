@@ -0,0 +1,224 @@
+// Package jobs runs long-running work (imports, downloads, maintenance) in the
+// background and tracks its progress so clients can poll for it.
+package jobs
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"sort"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// logger discards output until SetLogger installs the configured logger
+var logger = zap.NewNop()
+
+// SetLogger sets the logger used by this package
+func SetLogger(l *zap.Logger) {
+	logger = l
+}
+
+// Status is the lifecycle state of a job
+type Status string
+
+const (
+	StatusRunning   Status = "running"
+	StatusSucceeded Status = "succeeded"
+	StatusFailed    Status = "failed"
+	StatusCancelled Status = "cancelled"
+)
+
+// DefaultHistory is how many finished jobs a manager remembers
+const DefaultHistory = 200
+
+// ErrNotFound is returned for operations on an unknown job
+var ErrNotFound = errors.New("job not found")
+
+// Job is a snapshot of a background task
+type Job struct {
+	ID         string      `json:"id"`
+	Type       string      `json:"type"`
+	Status     Status      `json:"status"`
+	Done       int         `json:"done"`
+	Total      int         `json:"total"`
+	Message    string      `json:"message,omitempty"`
+	Error      string      `json:"error,omitempty"`
+	Result     interface{} `json:"result,omitempty"`
+	CreatedAt  time.Time   `json:"createdAt"`
+	FinishedAt *time.Time  `json:"finishedAt,omitempty"`
+}
+
+// Progress lets a running job report how far along it is
+type Progress struct {
+	m  *Manager
+	id string
+}
+
+// Update sets the number of steps done out of total and a status message
+func (p *Progress) Update(done, total int, message string) {
+	p.m.update(p.id, func(job *Job) {
+		job.Done = done
+		job.Total = total
+		job.Message = message
+	})
+}
+
+// Func is the work of a job. Its result is stored on the job when it succeeds.
+type Func func(ctx context.Context, progress *Progress) (interface{}, error)
+
+// Manager runs jobs and keeps their state in memory
+type Manager struct {
+	history int
+
+	mu      sync.Mutex
+	jobs    map[string]*Job
+	cancels map[string]context.CancelFunc
+}
+
+// NewManager creates a manager that remembers up to history finished jobs
+func NewManager(history int) *Manager {
+	return &Manager{
+		history: history,
+		jobs:    make(map[string]*Job),
+		cancels: make(map[string]context.CancelFunc),
+	}
+}
+
+// Start runs fn in the background and returns the new job
+func (m *Manager) Start(jobType string, fn Func) Job {
+	ctx, cancel := context.WithCancel(context.Background())
+	job := &Job{
+		ID:        newID(),
+		Type:      jobType,
+		Status:    StatusRunning,
+		CreatedAt: time.Now().UTC(),
+	}
+
+	m.mu.Lock()
+	m.jobs[job.ID] = job
+	m.cancels[job.ID] = cancel
+	snapshot := *job
+	m.mu.Unlock()
+
+	logger.Info("Job started", zap.String("jobID", job.ID), zap.String("type", jobType))
+	go m.run(ctx, job.ID, fn)
+	return snapshot
+}
+
+func (m *Manager) run(ctx context.Context, id string, fn Func) {
+	result, err := fn(ctx, &Progress{m: m, id: id})
+
+	m.update(id, func(job *Job) {
+		now := time.Now().UTC()
+		job.FinishedAt = &now
+		switch {
+		case ctx.Err() != nil:
+			job.Status = StatusCancelled
+			job.Error = ctx.Err().Error()
+		case err != nil:
+			job.Status = StatusFailed
+			job.Error = err.Error()
+		default:
+			job.Status = StatusSucceeded
+		}
+		job.Result = result
+
+		logger.Info("Job finished",
+			zap.String("jobID", job.ID),
+			zap.String("type", job.Type),
+			zap.String("status", string(job.Status)),
+			zap.String("error", job.Error),
+		)
+	})
+
+	m.mu.Lock()
+	if cancel, ok := m.cancels[id]; ok {
+		cancel()
+		delete(m.cancels, id)
+	}
+	m.pruneLocked()
+	m.mu.Unlock()
+}
+
+// Get returns a snapshot of one job
+func (m *Manager) Get(id string) (Job, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if job, ok := m.jobs[id]; ok {
+		return *job, nil
+	}
+	return Job{}, ErrNotFound
+}
+
+// List returns snapshots of all known jobs, newest first. An empty jobType
+// lists every type.
+func (m *Manager) List(jobType string) []Job {
+	m.mu.Lock()
+	list := make([]Job, 0, len(m.jobs))
+	for _, job := range m.jobs {
+		if jobType == "" || job.Type == jobType {
+			list = append(list, *job)
+		}
+	}
+	m.mu.Unlock()
+
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].CreatedAt.After(list[j].CreatedAt)
+	})
+	return list
+}
+
+// Cancel asks a running job to stop. Jobs notice at their next context check.
+func (m *Manager) Cancel(id string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.jobs[id]; !ok {
+		return ErrNotFound
+	}
+	if cancel, ok := m.cancels[id]; ok {
+		cancel()
+	}
+	return nil
+}
+
+func (m *Manager) update(id string, change func(*Job)) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if job, ok := m.jobs[id]; ok {
+		change(job)
+	}
+}
+
+// pruneLocked forgets the oldest finished jobs beyond the history limit
+func (m *Manager) pruneLocked() {
+	var finished []*Job
+	for _, job := range m.jobs {
+		if job.FinishedAt != nil {
+			finished = append(finished, job)
+		}
+	}
+	if len(finished) <= m.history {
+		return
+	}
+	sort.Slice(finished, func(i, j int) bool {
+		return finished[i].FinishedAt.Before(*finished[j].FinishedAt)
+	})
+	for _, job := range finished[:len(finished)-m.history] {
+		delete(m.jobs, job.ID)
+	}
+}
+
+func newID() string {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		panic("crypto/rand failed: " + err.Error())
+	}
+	return hex.EncodeToString(b)
+}
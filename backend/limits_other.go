@@ -0,0 +1,10 @@
+//go:build !linux && !darwin
+
+package main
+
+import "errors"
+
+// setOpenFileLimit is only supported on Linux and macOS
+func setOpenFileLimit(n int) error {
+	return errors.New("open file limits can only be set on Linux and macOS")
+}
@@ -0,0 +1,24 @@
+//go:build linux || darwin
+
+package main
+
+import (
+	"syscall"
+
+	"go.uber.org/zap"
+)
+
+// setOpenFileLimit sets the soft limit on open file descriptors, capped at
+// the hard limit
+func setOpenFileLimit(n int) error {
+	var limit syscall.Rlimit
+	if err := syscall.Getrlimit(syscall.RLIMIT_NOFILE, &limit); err != nil {
+		return err
+	}
+	limit.Cur = min(uint64(n), limit.Max)
+	if err := syscall.Setrlimit(syscall.RLIMIT_NOFILE, &limit); err != nil {
+		return err
+	}
+	zapLogger.Info("Open file limit set", zap.Uint64("openFiles", limit.Cur))
+	return nil
+}
@@ -0,0 +1,61 @@
+package logging
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"mangahub/backend/config"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"gopkg.in/natefinch/lumberjack.v2"
+)
+
+// Access log outputs accepted in config.AccessLogConfig.Output
+const (
+	AccessLogFile   = "file"
+	AccessLogSyslog = "syslog"
+)
+
+// NewAccessLogger creates the logger request records are written to, or
+// returns nil when they stay in the application log. Records are JSON, one
+// per line or syslog message, and are never sampled.
+func NewAccessLogger(cfg config.AccessLogConfig) (*zap.Logger, error) {
+	var sink zapcore.WriteSyncer
+	switch cfg.Output {
+	case "":
+		return nil, nil
+	case AccessLogFile:
+		if cfg.File == "" {
+			return nil, fmt.Errorf("the access log needs a file")
+		}
+		if err := os.MkdirAll(filepath.Dir(cfg.File), 0755); err != nil {
+			return nil, err
+		}
+		sink = zapcore.AddSync(&lumberjack.Logger{
+			Filename:   cfg.File,
+			MaxSize:    cfg.MaxSizeMB,
+			MaxBackups: cfg.MaxBackups,
+			MaxAge:     cfg.MaxAgeDays,
+			Compress:   cfg.Compress,
+		})
+	case AccessLogSyslog:
+		writer, err := dialSyslog(cfg.SyslogAddress, cfg.SyslogTag)
+		if err != nil {
+			return nil, fmt.Errorf("connecting to syslog: %w", err)
+		}
+		sink = zapcore.AddSync(writer)
+	default:
+		return nil, fmt.Errorf("unknown access log output %q; use %s or %s", cfg.Output, AccessLogFile, AccessLogSyslog)
+	}
+
+	// Every record is a request, so the level and message say nothing
+	encoderConfig := zap.NewProductionEncoderConfig()
+	encoderConfig.TimeKey = "time"
+	encoderConfig.EncodeTime = zapcore.ISO8601TimeEncoder
+	encoderConfig.LevelKey = zapcore.OmitKey
+	encoderConfig.MessageKey = zapcore.OmitKey
+	encoderConfig.CallerKey = zapcore.OmitKey
+	return zap.New(zapcore.NewCore(zapcore.NewJSONEncoder(encoderConfig), sink, zapcore.InfoLevel)), nil
+}
@@ -0,0 +1,23 @@
+//go:build !windows && !plan9
+
+package logging
+
+import (
+	"io"
+	"log/syslog"
+	"strings"
+)
+
+// dialSyslog connects to the syslog daemon at address, given as
+// "udp://host:port" or "tcp://host:port", or to the local daemon when it is
+// empty
+func dialSyslog(address, tag string) (io.Writer, error) {
+	network, host, found := strings.Cut(address, "://")
+	if !found {
+		network, host = "udp", address
+	}
+	if address == "" {
+		network = ""
+	}
+	return syslog.Dial(network, host, syslog.LOG_INFO|syslog.LOG_DAEMON, tag)
+}
@@ -0,0 +1,97 @@
+// Package mail sends account emails, such as password reset links, through
+// the configured SMTP server.
+package mail
+
+import (
+	"fmt"
+	"mime"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"strconv"
+	"strings"
+	"time"
+
+	"mangahub/backend/config"
+
+	"go.uber.org/zap"
+)
+
+// logger discards output until SetLogger installs the configured logger
+var logger = zap.NewNop()
+
+// SetLogger sets the logger used by this package
+func SetLogger(l *zap.Logger) {
+	logger = l
+}
+
+var server config.SMTPConfig
+
+// Init checks the SMTP settings. Without a host, mail stays disabled.
+func Init(cfg config.SMTPConfig) error {
+	if cfg.Host == "" {
+		logger.Info("No SMTP server configured; account emails are disabled")
+		return nil
+	}
+	if cfg.Port <= 0 {
+		return fmt.Errorf("smtp port must be positive, got %d", cfg.Port)
+	}
+	if _, err := mail.ParseAddress(cfg.From); err != nil {
+		return fmt.Errorf("smtp from address %q: %w", cfg.From, err)
+	}
+	server = cfg
+	logger.Info("SMTP server configured", zap.String("host", cfg.Host), zap.Int("port", cfg.Port))
+	return nil
+}
+
+// Enabled reports whether an SMTP server is configured
+func Enabled() bool {
+	return server.Host != ""
+}
+
+// Send delivers a plain-text message
+func Send(to, subject, body string) error {
+	if !Enabled() {
+		return fmt.Errorf("no SMTP server configured")
+	}
+	from, err := mail.ParseAddress(server.From)
+	if err != nil {
+		return err
+	}
+	recipient, err := mail.ParseAddress(to)
+	if err != nil {
+		return fmt.Errorf("invalid recipient %q: %w", to, err)
+	}
+
+	var msg strings.Builder
+	msg.WriteString("From: " + from.String() + "\r\n")
+	msg.WriteString("To: " + recipient.String() + "\r\n")
+	msg.WriteString("Subject: " + mimeHeader(subject) + "\r\n")
+	msg.WriteString("Date: " + time.Now().Format(time.RFC1123Z) + "\r\n")
+	msg.WriteString("MIME-Version: 1.0\r\n")
+	msg.WriteString("Content-Type: text/plain; charset=utf-8\r\n")
+	msg.WriteString("\r\n")
+	msg.WriteString(strings.ReplaceAll(body, "\n", "\r\n"))
+
+	var auth smtp.Auth
+	if server.Username != "" {
+		auth = smtp.PlainAuth("", server.Username, server.Password, server.Host)
+	}
+	addr := net.JoinHostPort(server.Host, strconv.Itoa(server.Port))
+	if err := smtp.SendMail(addr, auth, from.Address, []string{recipient.Address}, []byte(msg.String())); err != nil {
+		logger.Error("Failed to send mail", zap.String("to", recipient.Address), zap.String("subject", subject), zap.Error(err))
+		return err
+	}
+	logger.Info("Mail sent", zap.String("to", recipient.Address), zap.String("subject", subject))
+	return nil
+}
+
+// mimeHeader encodes a header value that isn't plain ASCII
+func mimeHeader(value string) string {
+	for _, r := range value {
+		if r > 127 {
+			return mime.QEncoding.Encode("utf-8", value)
+		}
+	}
+	return value
+}
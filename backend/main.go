@@ -2,7 +2,44 @@ package main
 
 import (
 	"fmt"
+	"io/fs"
+	"mangahub/backend/antivirus"
+	"mangahub/backend/bench"
+	"mangahub/backend/capture"
+	"mangahub/backend/collections"
+	"mangahub/backend/config"
+	"mangahub/backend/dedup"
+	"mangahub/backend/downloads"
+	"mangahub/backend/events"
+	"mangahub/backend/firstseen"
+	"mangahub/backend/geoip"
+	"mangahub/backend/hooks"
+	"mangahub/backend/imaging"
+	"mangahub/backend/importers"
+	"mangahub/backend/inbox"
+	"mangahub/backend/jobs"
+	"mangahub/backend/logging"
+	"mangahub/backend/mail"
+	"mangahub/backend/models"
+	"mangahub/backend/naming"
+	"mangahub/backend/pregen"
+	"mangahub/backend/presence"
+	"mangahub/backend/progress"
+	"mangahub/backend/readingorders"
+	"mangahub/backend/readsync"
+	"mangahub/backend/redirects"
+	"mangahub/backend/reporting"
+	"mangahub/backend/reviews"
 	"mangahub/backend/routes"
+	"mangahub/backend/scheduler"
+	"mangahub/backend/sharetokens"
+	"mangahub/backend/shelves"
+	"mangahub/backend/shortlinks"
+	"mangahub/backend/sources"
+	"mangahub/backend/tags"
+	"mangahub/backend/tombstones"
+	"mangahub/backend/users"
+	"mangahub/backend/web"
 	"net/http"
 	"os"
 	"path/filepath"
@@ -13,60 +50,76 @@ import (
 	"go.uber.org/zap"
 )
 
-// Config stores application configuration
-type Config struct {
-	Port         string
-	MangaRootDir string
-	LogFile      string
-}
-
-// In a real application, you might load this from a file or environment variables
-func loadConfig() Config {
-	return Config{
-		Port:         "8080",
-		MangaRootDir: "../manga",
-		LogFile:      "./manga-server.log",
-	}
-}
-
 // We'll use a package-level logger for convenience
 var zapLogger *zap.Logger
 
-// setupZapLogger initializes the Zap logger
-func setupZapLogger(config Config) {
-	// For production, you could use zap.NewProduction() instead
-	logger, err := zap.NewDevelopment()
+// setupZapLogger initializes the Zap logger from the configuration and hands a
+// named child of it to every package that logs
+func setupZapLogger(cfg config.Config) {
+	logger, err := logging.New(cfg.Log)
 	if err != nil {
 		panic("Failed to initialize Zap logger: " + err.Error())
 	}
-	zapLogger = logger
+	zapLogger = logger.Named("http")
+
+	models.SetLogger(logger.Named("models"))
+	routes.SetLogger(logger.Named("routes"))
+	users.SetLogger(logger.Named("users"))
+	reviews.SetLogger(logger.Named("reviews"))
+	tags.SetLogger(logger.Named("tags"))
+	events.SetLogger(logger.Named("events"))
+	scheduler.SetLogger(logger.Named("scheduler"))
+	readsync.SetLogger(logger.Named("readsync"))
+	reporting.SetLogger(logger.Named("reporting"))
+	progress.SetLogger(logger.Named("progress"))
+	collections.SetLogger(logger.Named("collections"))
+	importers.SetLogger(logger.Named("importers"))
+	shortlinks.SetLogger(logger.Named("shortlinks"))
+	sharetokens.SetLogger(logger.Named("sharetokens"))
+	shelves.SetLogger(logger.Named("shelves"))
+	readingorders.SetLogger(logger.Named("readingorders"))
+	jobs.SetLogger(logger.Named("jobs"))
+	sources.SetLogger(logger.Named("sources"))
+	hooks.SetLogger(logger.Named("hooks"))
+	inbox.SetLogger(logger.Named("inbox"))
+	dedup.SetLogger(logger.Named("dedup"))
+	mail.SetLogger(logger.Named("mail"))
+	antivirus.SetLogger(logger.Named("antivirus"))
+	presence.SetLogger(logger.Named("presence"))
+	redirects.SetLogger(logger.Named("redirects"))
+	pregen.SetLogger(logger.Named("pregen"))
+	tombstones.SetLogger(logger.Named("tombstones"))
+	firstseen.SetLogger(logger.Named("firstseen"))
+	geoip.SetLogger(logger.Named("geoip"))
+	capture.SetLogger(logger.Named("capture"))
+	downloads.SetLogger(logger.Named("downloads"))
 }
 
 // setupStaticDirs configures static file serving, including the "manga-images" folder
-func setupStaticDirs(config Config, router *gin.Engine) {
+func setupStaticDirs(cfg config.Config, router *gin.Engine) {
 	// Ensure manga directory exists
-	if _, err := os.Stat(config.MangaRootDir); os.IsNotExist(err) {
-		err := os.MkdirAll(config.MangaRootDir, 0755)
+	if _, err := os.Stat(cfg.MangaRootDir); os.IsNotExist(err) {
+		err := os.MkdirAll(cfg.MangaRootDir, 0755)
 		if err != nil {
 			zapLogger.Fatal("Failed to create manga directory",
-				zap.String("directory", config.MangaRootDir),
+				zap.String("directory", cfg.MangaRootDir),
 				zap.Error(err))
 		}
 	}
 
 	// Serve manga images
-	router.Static("/manga-images", config.MangaRootDir)
-
-	// First build the frontend if you haven't already:
-	// cd frontend && npm run build
+	router.Group("/manga-images", routes.AccessGate(routes.AccessGroupImages), routes.ImageAccess).Static("/", cfg.MangaRootDir)
 
-	// Serve static files from the assets directory if it exists
-	assetsPath := "./static/assets"
-	if _, err := os.Stat(assetsPath); err == nil {
-		router.Static("/assets", assetsPath)
+	frontend, err := web.FS(cfg.StaticDir)
+	if err != nil {
+		zapLogger.Fatal("Failed to open frontend directory", zap.String("directory", cfg.StaticDir), zap.Error(err))
+	}
+	if !web.Built(frontend) {
+		zapLogger.Warn("No frontend build found, only the API is served; run \"npm run build\" in frontend/ and rebuild")
 	}
 
-	// Serve individual static files with proper MIME types
+	// Serve the frontend's files with proper MIME types, and its index for
+	// every other path so the SPA can route it
 	router.NoRoute(func(c *gin.Context) {
 		path := c.Request.URL.Path
 
@@ -76,64 +129,210 @@ func setupStaticDirs(config Config, router *gin.Engine) {
 			return
 		}
 
-		filePath := "./static" + path
-		if _, err := os.Stat(filePath); err == nil {
+		name := strings.TrimPrefix(path, "/")
+		if info, err := fs.Stat(frontend, name); err == nil && !info.IsDir() {
 			ext := strings.ToLower(filepath.Ext(path))
 			if ext == ".js" || ext == ".mjs" {
 				c.Header("Content-Type", "application/javascript")
 			} else if ext == ".css" {
 				c.Header("Content-Type", "text/css")
 			}
-			c.File(filePath)
+			c.FileFromFS(name, http.FS(frontend))
 			return
 		}
 
 		// Default to index.html for SPA routing
-		c.File("./static/index.html")
+		routes.ServeIndex(c, frontend)
 	})
 }
 
+// importPolicy converts an import policy from the configuration
+func importPolicy(cfg config.ImportPolicyConfig) routes.ImportPolicy {
+	return routes.ImportPolicy{
+		Limits: imaging.Limits{
+			MaxWidth:  cfg.MaxWidth,
+			MaxHeight: cfg.MaxHeight,
+			MaxBytes:  int64(cfg.MaxKB) << 10,
+		},
+		KeepOriginals: cfg.KeepOriginals,
+	}
+}
+
+// accessRules converts the access rules from the configuration
+func accessRules(groups map[string]config.AccessRuleConfig) map[string]routes.AccessRule {
+	rules := make(map[string]routes.AccessRule, len(groups))
+	for group, cfg := range groups {
+		rules[group] = routes.AccessRule{
+			Allow:          cfg.Allow,
+			Deny:           cfg.Deny,
+			AllowCountries: cfg.AllowCountries,
+			DenyCountries:  cfg.DenyCountries,
+		}
+	}
+	return rules
+}
+
+// scanPolicies converts the library scan policies from the configuration
+func scanPolicies(libraries map[string]config.ScanPolicyConfig) map[string]routes.ScanPolicy {
+	policies := make(map[string]routes.ScanPolicy, len(libraries))
+	for root, cfg := range libraries {
+		policies[root] = routes.ScanPolicy{
+			IntervalMinutes:  cfg.IntervalMinutes,
+			Archives:         cfg.Archives,
+			GenerateMetadata: cfg.GenerateMetadata,
+			ContentRating:    cfg.ContentRating,
+		}
+	}
+	return policies
+}
+
+// saveScanPolicies writes scan policies edited through the API back to the
+// config file
+func saveScanPolicies(policies map[string]routes.ScanPolicy) error {
+	libraries := make(map[string]config.ScanPolicyConfig, len(policies))
+	for root, policy := range policies {
+		libraries[root] = config.ScanPolicyConfig{
+			IntervalMinutes:  policy.IntervalMinutes,
+			Archives:         policy.Archives,
+			GenerateMetadata: policy.GenerateMetadata,
+			ContentRating:    policy.ContentRating,
+		}
+	}
+	return config.SaveLibraries(config.Path(), libraries)
+}
+
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == "bench" {
+		os.Exit(bench.Run(os.Args[2:]))
+	}
+
 	// Set Gin to release mode in production
 	gin.SetMode(gin.ReleaseMode)
 
-	config := loadConfig()
+	cfg, err := config.Load()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Failed to load configuration:", err)
+		os.Exit(1)
+	}
 
 	// Initialize Zap logger
-	setupZapLogger(config)
+	setupZapLogger(cfg)
 	defer zapLogger.Sync()
 
+	if err := reporting.Init(cfg.Reporting); err != nil {
+		zapLogger.Fatal("Failed to set up error reporting", zap.Error(err))
+	}
+	defer reporting.Flush()
+
+	if err := mail.Init(cfg.SMTP); err != nil {
+		zapLogger.Fatal("Invalid SMTP settings", zap.Error(err))
+	}
+
+	if err := antivirus.Init(cfg.ClamAV); err != nil {
+		zapLogger.Fatal("Invalid ClamAV settings", zap.Error(err))
+	}
+
+	if err := hooks.Init(cfg.Hooks); err != nil {
+		zapLogger.Fatal("Failed to set up hooks", zap.Error(err))
+	}
+
 	router := gin.New()
+	router.HandleMethodNotAllowed = true
+	if err := routes.SetTrustedProxies(router, cfg.TrustedProxies, cfg.ClientIPHeaders); err != nil {
+		zapLogger.Fatal("Invalid trusted proxies", zap.Error(err))
+	}
 	router.Use(gin.Recovery())
+	router.Use(routes.HeadRequests)
+	router.Use(reporting.Middleware())
+	router.Use(routes.SecurityHeaders)
+	router.Use(routes.AccessGate(routes.AccessGroupAll))
 
-	// Custom logger middleware
-	router.Use(func(c *gin.Context) {
-		startTime := time.Now()
-		c.Next()
-		endTime := time.Now()
-
-		zapLogger.Info("HTTP request",
-			zap.String("method", c.Request.Method),
-			zap.String("path", c.Request.URL.Path),
-			zap.String("clientIP", c.ClientIP()),
-			zap.Int("status", c.Writer.Status()),
-			zap.Duration("latency", endTime.Sub(startTime)),
-		)
-	})
+	// Request records go to the access log, or to the application log
+	// when it isn't configured
+	accessLogger, err := logging.NewAccessLogger(cfg.AccessLog)
+	if err != nil {
+		zapLogger.Fatal("Failed to set up the access log", zap.Error(err))
+	}
+	router.Use(routes.AccessLog(accessLogger, zapLogger))
+	router.Use(routes.DebugCapture)
 
 	// Setup static directories and routes
-	setupStaticDirs(config, router)
+	setupStaticDirs(cfg, router)
+
+	// Start external source plugins; compiled-in sources registered themselves
+	loaded, err := sources.LoadExternal(cfg.SourcesDir)
+	if err != nil {
+		zapLogger.Error("Failed to load some source plugins", zap.String("dir", cfg.SourcesDir), zap.Error(err))
+	}
+	zapLogger.Info("Source plugins loaded", zap.Int("count", len(loaded)), zap.Int("total", len(sources.List())))
 
 	// Setup API routes
-	routes.InitRoutes(config.MangaRootDir)
+	routes.SetPublicURL(cfg.PublicURL)
+	scheme, err := naming.NewScheme(cfg.Naming.SeriesFolder, cfg.Naming.ChapterFolder)
+	if err != nil {
+		zapLogger.Fatal("Invalid naming templates", zap.Error(err))
+	}
+	routes.SetNaming(scheme)
+	routes.SetPageDedup(cfg.DedupPages)
+	routes.SetReencodeUploads(cfg.ReencodeUploads)
+	routes.SetImportPolicies(importPolicy(cfg.ImportPolicy), importPolicy(cfg.PersonalLibraries.ImportPolicy))
+	if err := routes.SetGuestAccess(cfg.GuestAccess); err != nil {
+		zapLogger.Fatal("Invalid guest access setting", zap.Error(err))
+	}
+	routes.SetContentSecurityPolicy(cfg.ContentSecurityPolicy)
+	routes.SetCORSOrigins(cfg.CORSOrigins)
+	var countries *geoip.DB
+	if cfg.GeoIPDatabase != "" {
+		if countries, err = geoip.Open(cfg.GeoIPDatabase); err != nil {
+			zapLogger.Fatal("Failed to open GeoIP database", zap.String("path", cfg.GeoIPDatabase), zap.Error(err))
+		}
+	}
+	if err := routes.SetAccessRules(accessRules(cfg.AccessRules), countries); err != nil {
+		zapLogger.Fatal("Invalid access rules", zap.Error(err))
+	}
+	routes.SetInviteOnly(cfg.InviteOnly)
+	routes.SetVerifyEmail(cfg.VerifyEmail)
+	routes.SetPersonalLibraries(cfg.PersonalLibraries.Enabled, cfg.PersonalLibraries.QuotaMB)
+	routes.SetPresence(cfg.Presence)
+	routes.SetKobo(cfg.Kobo)
+	if err := routes.SetExportCredits(cfg.ExportCredits.Enabled, cfg.ExportCredits.Template); err != nil {
+		zapLogger.Fatal("Invalid export credits template", zap.String("template", cfg.ExportCredits.Template), zap.Error(err))
+	}
+	if err := routes.SetWatermark(cfg.Watermark); err != nil {
+		zapLogger.Fatal("Invalid watermark setting", zap.Error(err))
+	}
+	routes.SetInbox(cfg.InboxDir, time.Duration(cfg.InboxIntervalSeconds)*time.Second)
+	routes.SetCountCheckInterval(time.Duration(cfg.CountCheckHours) * time.Hour)
+	routes.SetMetadataRefreshInterval(time.Duration(cfg.MetadataRefreshHours) * time.Hour)
+	routes.SetPregenerateWorkers(cfg.PregenerateWorkers)
+	routes.SetImageCacheLimit(cfg.CacheMaxMB)
+	routes.SetReadingSpeed(cfg.ReadingSecondsPerPage)
+	routes.SetPrefetchPages(cfg.PrefetchPages)
+	routes.SetDownloadsPerDay(cfg.DownloadsPerDay)
+	routes.SetPersistDerivedMetadata(cfg.PersistDerivedMetadata)
+	routes.SetScanWorkers(cfg.Limits.ScanWorkers)
+	imaging.SetMaxDecodes(cfg.Limits.ImageDecodes)
+	if cfg.Limits.OpenFiles > 0 {
+		if err := setOpenFileLimit(cfg.Limits.OpenFiles); err != nil {
+			zapLogger.Warn("Failed to set the open file limit", zap.Int("openFiles", cfg.Limits.OpenFiles), zap.Error(err))
+		}
+	}
+	if err := routes.SetScanPolicies(scanPolicies(cfg.Libraries), saveScanPolicies); err != nil {
+		zapLogger.Fatal("Invalid library scan policy", zap.Error(err))
+	}
+	if err := models.SetIgnoredFiles(cfg.IgnoredFiles); err != nil {
+		zapLogger.Fatal("Invalid ignored file patterns", zap.Error(err))
+	}
+	routes.InitRoutes(cfg.MangaRootDir, cfg.IndexFile, cfg.CacheDir, cfg.DataDir)
 	routes.SetupRoutes(router)
 
-	serverAddr := fmt.Sprintf(":%s", config.Port)
+	serverAddr := fmt.Sprintf(":%s", cfg.Port)
 	zapLogger.Info("Starting manga server",
 		zap.String("address", serverAddr),
 	)
 
-	if err := router.Run(serverAddr); err != nil {
+	// Methods answers HEAD and OPTIONS for every route
+	if err := http.ListenAndServe(serverAddr, routes.Methods(router)); err != nil {
 		zapLogger.Fatal("Failed to start server", zap.Error(err))
 	}
 }
@@ -5,21 +5,13 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
-	"sort"
-	"strconv"
 	"time"
 
 	"go.uber.org/zap"
 )
 
-// We'll use a package-level logger for convenience in this file.
-// If you've already set up a logger elsewhere, you can replace this init with that.
-var chapterLogger *zap.Logger
-
-func init() {
-	l, _ := zap.NewDevelopment()
-	chapterLogger = l
-}
+// chapterLogger is set together with the package logger by SetLogger
+var chapterLogger = zap.NewNop()
 
 // Chapter represents a manga chapter with its metadata
 type Chapter struct {
@@ -32,6 +24,28 @@ type Chapter struct {
 	Path        string    `json:"-"` // Internal use only, not exported to JSON
 	Volume      int       `json:"volume,omitempty"`
 	Special     bool      `json:"special,omitempty"`
+	Kind        string    `json:"kind,omitempty"` // One of ChapterKinds
+	Summary     string    `json:"summary,omitempty"`
+	SourceURL   string    `json:"sourceUrl,omitempty"` // Where the chapter was published
+
+	// Scanlators are the groups or people who translated the chapter
+	Scanlators []string `json:"scanlators,omitempty"`
+
+	// ReadingScreens is the length of the chapter in screens of reading,
+	// see MeasureReadingScreens; 0 until measured
+	ReadingScreens float64 `json:"readingScreens,omitempty"`
+
+	// PublishAt holds back a scheduled chapter until the given time
+	PublishAt *time.Time `json:"publishAt,omitempty"`
+
+	// PageHashes records the SHA-256 of each page file, keyed by file name,
+	// so later changes to the files can be detected
+	PageHashes map[string]string `json:"pageHashes,omitempty"`
+}
+
+// IsPublished reports whether the chapter is visible to readers at now
+func (c *Chapter) IsPublished(now time.Time) bool {
+	return c.PublishAt == nil || !c.PublishAt.After(now)
 }
 
 // Validate checks if the chapter has all required fields
@@ -52,6 +66,19 @@ func (c *Chapter) Validate() error {
 	return nil
 }
 
+// ParseReleaseDate reads a release date given as a date ("2024-05-01", taken
+// as midnight UTC) or an RFC 3339 timestamp, returning it in UTC
+func ParseReleaseDate(s string) (time.Time, error) {
+	if t, err := time.Parse(time.DateOnly, s); err == nil {
+		return t, nil
+	}
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		return time.Time{}, NewValidationError("release date must be a date (YYYY-MM-DD) or an RFC 3339 timestamp")
+	}
+	return t.UTC(), nil
+}
+
 // LoadFromJSON loads chapter metadata from a JSON file
 func (c *Chapter) LoadFromJSON(path string) error {
 	chapterLogger.Info("LoadFromJSON called", zap.String("path", path))
@@ -73,6 +100,16 @@ func (c *Chapter) LoadFromJSON(path string) error {
 	}
 
 	c.Path = filepath.Dir(path)
+	// Older files hold server-local times
+	c.ReleaseDate = c.ReleaseDate.UTC()
+	if c.PublishAt != nil {
+		utc := c.PublishAt.UTC()
+		c.PublishAt = &utc
+	}
+	// Files written before kinds existed are classified by title
+	if c.Kind == "" {
+		c.Classify(c.Title)
+	}
 
 	chapterLogger.Info("Chapter metadata loaded",
 		zap.String("chapterID", c.ID),
@@ -114,9 +151,9 @@ func (c *Chapter) GetPages() ([]Page, error) {
 		zap.String("path", c.Path),
 	)
 
-	files, err := os.ReadDir(c.Path)
+	dirInfo, err := os.Stat(c.Path)
 	if err != nil {
-		chapterLogger.Error("Cannot read pages for chapter directory",
+		chapterLogger.Error("Cannot stat chapter directory",
 			zap.String("chapterPath", c.Path),
 			zap.Error(err),
 		)
@@ -124,35 +161,47 @@ func (c *Chapter) GetPages() ([]Page, error) {
 			fmt.Sprintf("cannot read pages for chapter %v of manga %s", c.Number, c.MangaID))
 	}
 
-	var pages []Page
-	for _, file := range files {
-		if file.IsDir() || isMetadataFile(file.Name()) {
-			continue
-		}
-
-		pageNumStr := filepath.Base(file.Name())
-		pageNumStr = filepath.Ext(pageNumStr)
-		pageNumStr = pageNumStr[:len(pageNumStr)-len(filepath.Ext(pageNumStr))]
+	if pages, ok := pageCache.get(c.Path, dirInfo.ModTime()); ok {
+		c.PageCount = len(pages)
+		chapterLogger.Debug("Pages served from cache",
+			zap.String("chapterID", c.ID),
+			zap.Int("pageCount", c.PageCount),
+		)
+		return pages, nil
+	}
 
-		pageNum, convErr := strconv.Atoi(pageNumStr)
-		if convErr != nil {
-			pageNum = len(pages) + 1
-		}
+	files, err := readChapterFiles(c.Path)
+	if err != nil {
+		chapterLogger.Error("Cannot read pages for chapter directory",
+			zap.String("chapterPath", c.Path),
+			zap.Error(err),
+		)
+		return nil, NewChapterNotFoundError(
+			fmt.Sprintf("cannot read pages for chapter %v of manga %s", c.Number, c.MangaID))
+	}
+	if len(files.Unknown) > 0 {
+		chapterLogger.Warn("Skipping files that are neither pages nor sidecars",
+			zap.String("chapterPath", c.Path),
+			zap.Strings("files", files.Unknown),
+		)
+	}
 
+	pages := make([]Page, 0, len(files.Pages))
+	for i, name := range files.Pages {
 		page := Page{
-			Number:    pageNum,
-			ImagePath: filepath.Join(c.Path, file.Name()),
+			Number:    i + 1,
+			ImagePath: filepath.Join(c.Path, name),
 			ChapterID: c.ID,
 			MangaID:   c.MangaID, // Make sure we set MangaID here
 		}
+		if sidecar, ok := files.Sidecars[name]; ok {
+			page.SidecarPath = filepath.Join(c.Path, sidecar)
+		}
 		pages = append(pages, page)
 	}
 
-	sort.Slice(pages, func(i, j int) bool {
-		return pages[i].Number < pages[j].Number
-	})
-
 	c.PageCount = len(pages)
+	pageCache.put(c.Path, dirInfo.ModTime(), pages)
 
 	chapterLogger.Info("Pages found",
 		zap.String("chapterID", c.ID),
@@ -200,7 +249,14 @@ func (c *Chapter) GetPageByNumber(pageNumber int) (*Page, error) {
 		fmt.Sprintf("page %d not found in chapter %v", pageNumber, c.Number))
 }
 
-// Helper function to check if a file is a metadata file
-func isMetadataFile(filename string) bool {
-	return filename == "metadata.json" || filepath.Ext(filename) == ".json"
+// AdjacentChapters returns the chapters before and after chapters[index] in a
+// slice sorted by chapter number; either may be nil at the ends of the list
+func AdjacentChapters(chapters []Chapter, index int) (prev, next *Chapter) {
+	if index > 0 && index < len(chapters) {
+		prev = &chapters[index-1]
+	}
+	if index >= 0 && index < len(chapters)-1 {
+		next = &chapters[index+1]
+	}
+	return prev, next
 }
@@ -0,0 +1,98 @@
+package models
+
+import (
+	"math"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// Chapter kinds. Every kind but a regular chapter is special.
+const (
+	ChapterKindRegular   = "regular"
+	ChapterKindOneshot   = "oneshot"
+	ChapterKindExtra     = "extra"
+	ChapterKindOmake     = "omake"
+	ChapterKindInterlude = "interlude" // A decimal chapter such as 10.5
+)
+
+// ChapterKinds lists the accepted chapter kinds
+var ChapterKinds = []string{ChapterKindRegular, ChapterKindOneshot, ChapterKindExtra, ChapterKindOmake, ChapterKindInterlude}
+
+// kindPatterns recognize special chapters by name, most specific first
+var kindPatterns = []struct {
+	kind    string
+	pattern *regexp.Regexp
+}{
+	{ChapterKindOneshot, regexp.MustCompile(`(?i)\bone[\s_-]?shot\b`)},
+	{ChapterKindOmake, regexp.MustCompile(`(?i)\bomake\b`)},
+	{ChapterKindExtra, regexp.MustCompile(`(?i)\b(?:extras?|bonus|specials?|side[\s_-]?story)\b`)},
+}
+
+// ClassifyChapter tells a chapter's kind from its names, such as the folder
+// name, title or ComicInfo format, and its number
+func ClassifyChapter(number float64, names ...string) string {
+	for _, kp := range kindPatterns {
+		for _, name := range names {
+			if kp.pattern.MatchString(name) {
+				return kp.kind
+			}
+		}
+	}
+	if number != math.Trunc(number) {
+		return ChapterKindInterlude
+	}
+	return ChapterKindRegular
+}
+
+// ValidateChapterKind rejects unknown chapter kinds
+func ValidateChapterKind(kind string) error {
+	if !slices.Contains(ChapterKinds, kind) {
+		return NewValidationError("kind must be one of " + strings.Join(ChapterKinds, ", "))
+	}
+	return nil
+}
+
+// Classify sets the chapter's kind from its names and number, see
+// ClassifyChapter, and marks it special unless it's a regular chapter. A
+// chapter already marked special that looks regular keeps its special kind,
+// or becomes an extra.
+func (c *Chapter) Classify(names ...string) {
+	switch kind := ClassifyChapter(c.Number, names...); {
+	case kind != ChapterKindRegular:
+		c.SetKind(kind)
+	case c.Special:
+		c.SetSpecial(true)
+	default:
+		c.Kind = ChapterKindRegular
+	}
+}
+
+// SetKind sets the chapter's kind, marking it special accordingly
+func (c *Chapter) SetKind(kind string) {
+	c.Kind = kind
+	c.Special = kind != ChapterKindRegular
+}
+
+// SetSpecial marks the chapter special or regular, keeping its kind in step
+func (c *Chapter) SetSpecial(special bool) {
+	c.Special = special
+	if !special {
+		c.Kind = ChapterKindRegular
+	} else if c.Kind == "" || c.Kind == ChapterKindRegular {
+		c.Kind = ChapterKindExtra
+	}
+}
+
+// ChapterLess orders chapters for reading: by number, with regular chapters
+// before the specials that share their number, then by ID so the order is
+// stable
+func ChapterLess(a, b *Chapter) bool {
+	if a.Number != b.Number {
+		return a.Number < b.Number
+	}
+	if a.Special != b.Special {
+		return !a.Special
+	}
+	return a.ID < b.ID
+}
@@ -0,0 +1,100 @@
+package models
+
+import (
+	"os"
+	"path/filepath"
+
+	"go.uber.org/zap"
+)
+
+// Count fields checked by ReconcileCounts
+const (
+	FieldPageCount    = "pageCount"
+	FieldChapterCount = "chapterCount"
+)
+
+// CountMismatch is a page or chapter count in a metadata file that didn't
+// match the files on disk
+type CountMismatch struct {
+	MangaID   string  `json:"mangaId"`
+	ChapterID string  `json:"chapterId,omitempty"` // Empty for the series' chapter count
+	Number    float64 `json:"number,omitempty"`
+	Field     string  `json:"field"`
+	Recorded  int     `json:"recorded"`
+	Actual    int     `json:"actual"`
+}
+
+// ReconcileCounts recounts the pages of every chapter of a series, and its
+// chapters, and writes corrected counts back to the metadata files that
+// recorded wrong ones, measuring the reading screens of corrected chapters
+// again. Metadata derived from folder names has no file and is left alone.
+// The mismatches found are returned.
+func (mm *MetadataManager) ReconcileCounts(manga *MangaSeries) ([]CountMismatch, error) {
+	chapters, err := mm.ScanForChapters(manga)
+	if err != nil {
+		return nil, err
+	}
+
+	var mismatches []CountMismatch
+	for i := range chapters {
+		chapter := &chapters[i]
+		pages, err := mm.LoadPages(chapter)
+		if err != nil {
+			return mismatches, err
+		}
+
+		metadataPath := filepath.Join(chapter.Path, MetadataFileName)
+		if _, err := os.Stat(metadataPath); err != nil {
+			continue
+		}
+		// The listing reports cached counts, so compare with the file itself
+		var recorded Chapter
+		if err := recorded.LoadFromJSON(metadataPath); err != nil {
+			return mismatches, err
+		}
+		if recorded.PageCount == len(pages) {
+			continue
+		}
+		mismatches = append(mismatches, CountMismatch{
+			MangaID:   manga.ID,
+			ChapterID: recorded.ID,
+			Number:    recorded.Number,
+			Field:     FieldPageCount,
+			Recorded:  recorded.PageCount,
+			Actual:    len(pages),
+		})
+		recorded.PageCount = len(pages)
+		recorded.ReadingScreens = MeasureReadingScreens(pages)
+		if err := recorded.SaveToJSON(metadataPath); err != nil {
+			return mismatches, err
+		}
+	}
+
+	metadataPath := filepath.Join(manga.Path, MetadataFileName)
+	if _, err := os.Stat(metadataPath); err == nil {
+		var recorded MangaSeries
+		if err := recorded.LoadFromJSON(metadataPath); err != nil {
+			return mismatches, err
+		}
+		if recorded.ChapterCount != len(chapters) {
+			mismatches = append(mismatches, CountMismatch{
+				MangaID:  manga.ID,
+				Field:    FieldChapterCount,
+				Recorded: recorded.ChapterCount,
+				Actual:   len(chapters),
+			})
+			recorded.ChapterCount = len(chapters)
+			if err := recorded.SaveToJSON(metadataPath); err != nil {
+				return mismatches, err
+			}
+		}
+	}
+
+	if len(mismatches) > 0 {
+		logger.Info("Corrected recorded counts",
+			zap.String("mangaID", manga.ID),
+			zap.Int("mismatches", len(mismatches)),
+		)
+	}
+	return mismatches, nil
+}
@@ -0,0 +1,93 @@
+package models
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+
+	"mangahub/backend/imaging"
+	"mangahub/backend/storage"
+
+	"go.uber.org/zap"
+)
+
+const (
+	// GeneratedCoverFileName is where covers derived from the first page are written
+	GeneratedCoverFileName = "cover.jpg"
+
+	// GeneratedCoverWidth is the width generated covers are scaled down to
+	GeneratedCoverWidth = 350
+)
+
+// ensureCover makes sure a series has a usable cover. A missing CoverImage is
+// filled in from the covers already in the directory; if there are none, a
+// cover is generated from the first page of the first chapter. A locked
+// cover is kept even when its file is gone.
+func (mm *MetadataManager) ensureCover(manga *MangaSeries) {
+	if manga.CoverImage != "" && manga.FieldLocked(FieldCoverImage) {
+		return
+	}
+	if manga.CoverImage != "" {
+		if _, err := os.Stat(manga.GetCoverImagePath()); err == nil {
+			return
+		}
+	}
+
+	if covers, err := manga.ListCovers(); err == nil && len(covers) > 0 {
+		manga.CoverImage = covers[0]
+		return
+	}
+
+	if err := mm.generateCover(manga); err != nil {
+		logger.Warn("Failed to generate cover",
+			zap.String("mangaID", manga.ID),
+			zap.Error(err),
+		)
+	}
+}
+
+// generateCover renders the first page of the first chapter into
+// GeneratedCoverFileName and points CoverImage at it
+func (mm *MetadataManager) generateCover(manga *MangaSeries) error {
+	chapters, err := mm.ScanForChapters(manga)
+	if err != nil {
+		return err
+	}
+	if len(chapters) == 0 {
+		return NewMetadataError("series has no chapters to take a cover from")
+	}
+
+	pages, err := mm.LoadPages(&chapters[0])
+	if err != nil {
+		return err
+	}
+	if len(pages) == 0 {
+		return NewMetadataError("first chapter has no pages")
+	}
+
+	release := imaging.AcquireDecode()
+	defer release()
+	img, _, err := imaging.Decode(pages[0].ImagePath)
+	if err != nil {
+		return NewMetadataError("failed to decode first page: " + err.Error())
+	}
+	if width := img.Bounds().Dx(); width > GeneratedCoverWidth {
+		img = imaging.Scale(img, float64(GeneratedCoverWidth)/float64(width))
+	}
+
+	var buf bytes.Buffer
+	if err := imaging.Encode(&buf, img, "jpeg"); err != nil {
+		return NewMetadataError("failed to encode cover: " + err.Error())
+	}
+	coverPath := filepath.Join(manga.Path, GeneratedCoverFileName)
+	if err := storage.WriteFileAtomic(coverPath, buf.Bytes(), 0644); err != nil {
+		return NewMetadataError("failed to write cover: " + err.Error())
+	}
+
+	manga.CoverImage = GeneratedCoverFileName
+	logger.Info("Generated cover from first page",
+		zap.String("mangaID", manga.ID),
+		zap.String("coverPath", coverPath),
+	)
+	return nil
+}
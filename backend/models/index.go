@@ -0,0 +1,441 @@
+package models
+
+import (
+	"compress/gzip"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+const (
+	// indexProgressInterval controls how often warm-up progress is logged
+	indexProgressInterval = 50
+
+	// indexFormatVersion is bumped whenever the persisted index layout changes
+	indexFormatVersion = 1
+)
+
+// LibraryIndex keeps an in-memory view of every manga series in the library,
+// so listing and search don't have to rescan the whole tree on each request.
+// It is built in the background at startup; until then it serves whatever
+// has been indexed so far.
+type LibraryIndex struct {
+	mm *MetadataManager
+
+	// persistPath is where the index is saved between restarts; empty disables it
+	persistPath string
+
+	mu      sync.RWMutex
+	entries map[string]indexEntry // keyed by manga directory path
+	ready   bool
+	dirty   bool
+
+	// syncMu serializes Warm and Sync so only one pass walks the tree at a time
+	syncMu sync.Mutex
+
+	// workers is how many series directories a pass loads at once
+	workers int
+
+	// onRemove is told about series that disappeared, see OnRemove
+	onRemove func(MangaSeries)
+}
+
+// indexEntry is an indexed series plus the modification times it was loaded at
+type indexEntry struct {
+	Manga        MangaSeries
+	DirModTime   time.Time
+	MetaModTime  time.Time
+	MetadataSeen bool
+}
+
+// persistedIndex is the on-disk form of the index: gzip-compressed JSON
+type persistedIndex struct {
+	Version int                   `json:"version"`
+	RootDir string                `json:"rootDir"`
+	Entries map[string]indexEntry `json:"entries"`
+}
+
+// NewLibraryIndex creates an empty index backed by the given metadata manager.
+// If persistPath is set, the index is saved there and reloaded on startup.
+func NewLibraryIndex(mm *MetadataManager, persistPath string) *LibraryIndex {
+	return &LibraryIndex{
+		mm:          mm,
+		persistPath: persistPath,
+		entries:     make(map[string]indexEntry),
+		workers:     1,
+	}
+}
+
+// SetWorkers sets how many series directories are loaded at once when the
+// index walks the library; more suit fast disks and many cores
+func (li *LibraryIndex) SetWorkers(n int) {
+	li.syncMu.Lock()
+	defer li.syncMu.Unlock()
+	if n > 0 {
+		li.workers = n
+	}
+}
+
+// OnRemove registers fn to be called with every series that leaves the
+// index because its directory was removed or can no longer be loaded. It
+// must be set before the index is used.
+func (li *LibraryIndex) OnRemove(fn func(MangaSeries)) {
+	li.onRemove = fn
+}
+
+// removed reports series that left the index
+func (li *LibraryIndex) removed(series ...MangaSeries) {
+	if li.onRemove == nil {
+		return
+	}
+	for _, manga := range series {
+		li.onRemove(manga)
+	}
+}
+
+// Ready reports whether the initial warm-up has finished
+func (li *LibraryIndex) Ready() bool {
+	li.mu.RLock()
+	defer li.mu.RUnlock()
+	return li.ready
+}
+
+// Warm builds the index, logging progress as it goes. It is meant to run in
+// its own goroutine while the server is already accepting requests.
+func (li *LibraryIndex) Warm() {
+	start := time.Now()
+	logger.Info("Library warm-up started", zap.String("RootDir", li.mm.RootDir))
+
+	if err := li.load(); err != nil {
+		logger.Warn("Ignoring persisted library index", zap.Error(err))
+	}
+
+	indexed, err := li.sync(func(done, total int) {
+		if done%indexProgressInterval == 0 || done == total {
+			logger.Info("Library warm-up progress",
+				zap.Int("indexed", done),
+				zap.Int("total", total),
+			)
+		}
+	})
+	if err != nil {
+		logger.Error("Library warm-up failed", zap.Error(err))
+	}
+
+	li.mu.Lock()
+	li.ready = true
+	li.mu.Unlock()
+
+	li.save()
+
+	logger.Info("Library warm-up complete",
+		zap.Int("mangaCount", indexed),
+		zap.Duration("elapsed", time.Since(start)),
+	)
+}
+
+// Sync reconciles the index with the library on disk, reloading only series
+// whose directory or metadata file changed since they were indexed
+func (li *LibraryIndex) Sync() error {
+	if _, err := li.sync(nil); err != nil {
+		return err
+	}
+	li.save()
+	return nil
+}
+
+func (li *LibraryIndex) sync(progress func(done, total int)) (int, error) {
+	li.syncMu.Lock()
+	defer li.syncMu.Unlock()
+
+	dirs, err := os.ReadDir(li.mm.RootDir)
+	if err != nil {
+		return 0, NewMetadataError("failed to read root directory: " + err.Error())
+	}
+
+	var mangaDirs []string
+	for _, dir := range dirs {
+		if dir.IsDir() && !strings.HasPrefix(dir.Name(), ".") {
+			mangaDirs = append(mangaDirs, filepath.Join(li.mm.RootDir, dir.Name()))
+		}
+	}
+
+	seen := make(map[string]bool, len(mangaDirs))
+	for _, mangaPath := range mangaDirs {
+		seen[mangaPath] = true
+	}
+
+	paths := make(chan string)
+	var wg sync.WaitGroup
+	var progressMu sync.Mutex
+	done := 0
+	for i := 0; i < min(li.workers, len(mangaDirs)); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for mangaPath := range paths {
+				li.refreshDir(mangaPath)
+				if progress != nil {
+					progressMu.Lock()
+					done++
+					progress(done, len(mangaDirs))
+					progressMu.Unlock()
+				}
+			}
+		}()
+	}
+	for _, mangaPath := range mangaDirs {
+		paths <- mangaPath
+	}
+	close(paths)
+	wg.Wait()
+
+	var vanished []MangaSeries
+	li.mu.Lock()
+	for path, entry := range li.entries {
+		if !seen[path] {
+			logger.Info("Removing vanished manga from index", zap.String("mangaPath", path))
+			delete(li.entries, path)
+			li.dirty = true
+			vanished = append(vanished, entry.Manga)
+		}
+	}
+	count := len(li.entries)
+	li.mu.Unlock()
+	li.removed(vanished...)
+
+	return count, nil
+}
+
+// refreshDir (re)indexes a single manga directory if it changed on disk
+func (li *LibraryIndex) refreshDir(mangaPath string) {
+	dirInfo, err := os.Stat(mangaPath)
+	if err != nil {
+		if manga, ok := li.removePath(mangaPath); ok {
+			li.removed(manga)
+		}
+		return
+	}
+	metaInfo, metaErr := os.Stat(filepath.Join(mangaPath, MetadataFileName))
+
+	li.mu.RLock()
+	existing, ok := li.entries[mangaPath]
+	li.mu.RUnlock()
+
+	if ok && existing.DirModTime.Equal(dirInfo.ModTime()) &&
+		existing.MetadataSeen == (metaErr == nil) &&
+		(metaErr != nil || existing.MetaModTime.Equal(metaInfo.ModTime())) {
+		return
+	}
+
+	manga, err := li.mm.LoadMangaDir(mangaPath)
+	if err != nil {
+		if manga, ok := li.removePath(mangaPath); ok {
+			li.removed(manga)
+		}
+		return
+	}
+
+	entry := indexEntry{
+		Manga:        manga,
+		DirModTime:   dirInfo.ModTime(),
+		MetadataSeen: metaErr == nil,
+	}
+	if metaErr == nil {
+		entry.MetaModTime = metaInfo.ModTime()
+	}
+
+	li.mu.Lock()
+	li.entries[mangaPath] = entry
+	li.dirty = true
+	li.mu.Unlock()
+}
+
+// ReloadDerived reloads every series without a metadata.json, whose metadata
+// depends on the manager's settings, e.g. after they changed
+func (li *LibraryIndex) ReloadDerived() error {
+	li.mu.Lock()
+	for path, entry := range li.entries {
+		if !entry.MetadataSeen {
+			delete(li.entries, path)
+			li.dirty = true
+		}
+	}
+	li.mu.Unlock()
+	return li.Sync()
+}
+
+// Refresh forces a single series directory to be reloaded, e.g. after an admin edit
+func (li *LibraryIndex) Refresh(mangaPath string) {
+	old, existed := li.removePath(mangaPath)
+	li.refreshDir(mangaPath)
+	if _, ok := li.GetByPath(mangaPath); existed && !ok {
+		li.removed(old)
+	}
+	li.save()
+}
+
+// removePath drops a series from the index, returning it if it was there
+func (li *LibraryIndex) removePath(mangaPath string) (MangaSeries, bool) {
+	li.mu.Lock()
+	defer li.mu.Unlock()
+	entry, ok := li.entries[mangaPath]
+	if ok {
+		delete(li.entries, mangaPath)
+		li.dirty = true
+	}
+	return entry.Manga, ok
+}
+
+// load restores a previously persisted index. Entries are served as-is until
+// the warm-up pass revalidates them against the directory modification times.
+func (li *LibraryIndex) load() error {
+	if li.persistPath == "" {
+		return nil
+	}
+
+	file, err := os.Open(li.persistPath)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return NewMetadataError("failed to open library index: " + err.Error())
+	}
+	defer file.Close()
+
+	reader, err := gzip.NewReader(file)
+	if err != nil {
+		return NewMetadataError("failed to read library index: " + err.Error())
+	}
+	defer reader.Close()
+
+	var persisted persistedIndex
+	if err := json.NewDecoder(reader).Decode(&persisted); err != nil {
+		return NewMetadataError("failed to parse library index: " + err.Error())
+	}
+	if persisted.Version != indexFormatVersion || persisted.RootDir != li.mm.RootDir {
+		return NewMetadataError("library index is from a different version or root directory")
+	}
+
+	li.mu.Lock()
+	for path, entry := range persisted.Entries {
+		// Path is not serialized with the series, so restore it from the key
+		entry.Manga.Path = path
+		li.entries[path] = entry
+	}
+	li.mu.Unlock()
+
+	logger.Info("Loaded persisted library index",
+		zap.String("path", li.persistPath),
+		zap.Int("mangaCount", len(persisted.Entries)),
+	)
+	return nil
+}
+
+// save writes the index to disk if it changed since the last save
+func (li *LibraryIndex) save() {
+	if li.persistPath == "" {
+		return
+	}
+
+	li.mu.Lock()
+	if !li.dirty {
+		li.mu.Unlock()
+		return
+	}
+	persisted := persistedIndex{
+		Version: indexFormatVersion,
+		RootDir: li.mm.RootDir,
+		Entries: make(map[string]indexEntry, len(li.entries)),
+	}
+	for path, entry := range li.entries {
+		persisted.Entries[path] = entry
+	}
+	li.dirty = false
+	li.mu.Unlock()
+
+	if err := writeGzipJSON(li.persistPath, persisted); err != nil {
+		logger.Error("Failed to persist library index",
+			zap.String("path", li.persistPath),
+			zap.Error(err),
+		)
+		li.mu.Lock()
+		li.dirty = true
+		li.mu.Unlock()
+	}
+}
+
+// writeGzipJSON atomically writes v as gzip-compressed JSON to path
+func writeGzipJSON(path string, v interface{}) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	writer := gzip.NewWriter(tmp)
+	if err := json.NewEncoder(writer).Encode(v); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := writer.Close(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// List returns every indexed series ordered by directory name
+func (li *LibraryIndex) List() []MangaSeries {
+	li.mu.RLock()
+	paths := make([]string, 0, len(li.entries))
+	for path := range li.entries {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	mangas := make([]MangaSeries, 0, len(paths))
+	for _, path := range paths {
+		mangas = append(mangas, li.entries[path].Manga)
+	}
+	li.mu.RUnlock()
+	return mangas
+}
+
+// GetByPath looks up an indexed series by its directory
+func (li *LibraryIndex) GetByPath(mangaPath string) (*MangaSeries, bool) {
+	li.mu.RLock()
+	defer li.mu.RUnlock()
+	entry, ok := li.entries[mangaPath]
+	if !ok {
+		return nil, false
+	}
+	manga := entry.Manga
+	return &manga, true
+}
+
+// Get looks up an indexed series by its ID
+func (li *LibraryIndex) Get(id string) (*MangaSeries, bool) {
+	li.mu.RLock()
+	defer li.mu.RUnlock()
+	for _, entry := range li.entries {
+		if entry.Manga.ID == id {
+			manga := entry.Manga
+			return &manga, true
+		}
+	}
+	return nil, false
+}
@@ -0,0 +1,88 @@
+package models
+
+import (
+	"path/filepath"
+	"sort"
+
+	"mangahub/backend/storage"
+)
+
+// Page integrity statuses reported by VerifyPages
+const (
+	PageChanged    = "changed" // Contents differ from the recorded hash
+	PageMissing    = "missing" // Recorded but no longer on disk
+	PageAdded      = "added"   // On disk but never recorded
+	PageUnreadable = "unreadable"
+)
+
+// PageCheck is the integrity status of one page file
+type PageCheck struct {
+	File     string `json:"file,omitempty"`
+	Status   string `json:"status"`
+	Expected string `json:"expected,omitempty"`
+	Actual   string `json:"actual,omitempty"`
+	Error    string `json:"error,omitempty"`
+}
+
+// HashPages returns the SHA-256 of every page file in a chapter directory,
+// keyed by file name
+func HashPages(chapterPath string) (map[string]string, error) {
+	files, err := pageFiles(chapterPath)
+	if err != nil {
+		return nil, err
+	}
+	hashes := make(map[string]string, len(files))
+	for _, name := range files {
+		hash, err := storage.HashFile(filepath.Join(chapterPath, name))
+		if err != nil {
+			return nil, err
+		}
+		hashes[name] = hash
+	}
+	return hashes, nil
+}
+
+// VerifyPages re-hashes the pages of a chapter and compares them with the
+// recorded hashes. Only problems are returned, ordered by file name, along
+// with the number of files checked.
+func VerifyPages(chapterPath string, recorded map[string]string) ([]PageCheck, int, error) {
+	files, err := pageFiles(chapterPath)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var problems []PageCheck
+	onDisk := make(map[string]bool, len(files))
+	for _, name := range files {
+		onDisk[name] = true
+		expected, ok := recorded[name]
+		actual, err := storage.HashFile(filepath.Join(chapterPath, name))
+		switch {
+		case err != nil:
+			problems = append(problems, PageCheck{File: name, Status: PageUnreadable, Expected: expected, Error: err.Error()})
+		case !ok:
+			problems = append(problems, PageCheck{File: name, Status: PageAdded, Actual: actual})
+		case actual != expected:
+			problems = append(problems, PageCheck{File: name, Status: PageChanged, Expected: expected, Actual: actual})
+		}
+	}
+	for name, expected := range recorded {
+		if !onDisk[name] {
+			problems = append(problems, PageCheck{File: name, Status: PageMissing, Expected: expected})
+		}
+	}
+
+	sort.Slice(problems, func(i, j int) bool {
+		return problems[i].File < problems[j].File
+	})
+	return problems, len(files), nil
+}
+
+// pageFiles lists the page image files of a chapter directory
+func pageFiles(chapterPath string) ([]string, error) {
+	files, err := readChapterFiles(chapterPath)
+	if err != nil {
+		return nil, err
+	}
+	return files.Pages, nil
+}
@@ -0,0 +1,53 @@
+package models
+
+import (
+	"slices"
+	"strings"
+)
+
+// Series fields that can be locked, named as in metadata.json
+const (
+	FieldTitle         = "title"
+	FieldAltTitles     = "altTitles"
+	FieldDescription   = "description"
+	FieldAuthor        = "author"
+	FieldArtist        = "artist"
+	FieldCoverImage    = "coverImage"
+	FieldGenres        = "genres"
+	FieldTags          = "tags"
+	FieldStatus        = "status"
+	FieldPublishedYear = "publishedYear"
+	FieldPublisher     = "publisher"
+	FieldDemographic   = "demographic"
+	FieldSerialization = "serialization"
+)
+
+// LockableFields are the accepted values of MangaSeries.LockedFields
+var LockableFields = []string{
+	FieldTitle, FieldAltTitles, FieldDescription, FieldAuthor, FieldArtist, FieldCoverImage,
+	FieldGenres, FieldTags, FieldStatus, FieldPublishedYear, FieldPublisher, FieldDemographic,
+	FieldSerialization,
+}
+
+// FieldLocked reports whether imports, metadata refreshes and rescans must
+// leave a field as it is, because the whole series or the field is locked.
+// Edits made by an admin through the API are never blocked.
+func (m *MangaSeries) FieldLocked(field string) bool {
+	return m.Locked || slices.Contains(m.LockedFields, field)
+}
+
+// NormalizeLockedFields checks field names against LockableFields, matching
+// them case-insensitively, and drops duplicates
+func NormalizeLockedFields(fields []string) ([]string, error) {
+	normalized := []string{}
+	for _, field := range fields {
+		i := slices.IndexFunc(LockableFields, func(f string) bool { return strings.EqualFold(f, strings.TrimSpace(field)) })
+		if i < 0 {
+			return nil, NewValidationError("lockedFields must be among " + strings.Join(LockableFields, ", "))
+		}
+		if !slices.Contains(normalized, LockableFields[i]) {
+			normalized = append(normalized, LockableFields[i])
+		}
+	}
+	return normalized, nil
+}
@@ -4,32 +4,123 @@ import (
 	"encoding/json"
 	"os"
 	"path/filepath"
+	"strings"
 	"time"
 
 	"go.uber.org/zap"
 )
 
-var mangaLogger *zap.Logger
+var mangaLogger = zap.NewNop()
 
-func init() {
-	l, _ := zap.NewDevelopment()
-	mangaLogger = l
+type MangaSeries struct {
+	ID            string            `json:"id"`
+	Title         string            `json:"title"`
+	Description   string            `json:"description"`
+	Author        string            `json:"author"`
+	Artist        string            `json:"artist,omitempty"`
+	CoverImage    string            `json:"coverImage"`
+	Genres        []string          `json:"genres"`
+	Tags          []string          `json:"tags,omitempty"` // Freeform, canonicalized via the tag alias table
+	Status        string            `json:"status"`
+	PublishedYear int               `json:"publishedYear,omitempty"`
+	LastUpdated   time.Time         `json:"lastUpdated"`
+	ChapterCount  int               `json:"chapterCount"`
+	AltTitles     []string          `json:"altTitles,omitempty"`
+	Publisher     string            `json:"publisher,omitempty"`
+	Demographic   string            `json:"demographic,omitempty"`   // One of Demographics
+	Serialization string            `json:"serialization,omitempty"` // Magazine the series ran in
+	AutoCrop      bool              `json:"autoCrop,omitempty"`      // Trim page margins on delivery
+	NSFW          bool              `json:"nsfw,omitempty"`          // Adult content, hidden from guests in "safe" mode
+	CustomFields  map[string]string `json:"customFields,omitempty"`  // Arbitrary user-defined metadata
+	Owner         string            `json:"owner,omitempty"`         // User ID of a personal series; empty for the shared catalog
+	Visibility    string            `json:"visibility,omitempty"`    // Who may see a personal series; one of Visibilities
+	Locked        bool              `json:"locked,omitempty"`        // Automatic updates leave all metadata alone
+	LockedFields  []string          `json:"lockedFields,omitempty"`  // Fields automatic updates leave alone; see LockableFields
+	Path          string            `json:"-"`                       // Internal use only
 }
 
-type MangaSeries struct {
-	ID            string    `json:"id"`
-	Title         string    `json:"title"`
-	Description   string    `json:"description"`
-	Author        string    `json:"author"`
-	Artist        string    `json:"artist,omitempty"`
-	CoverImage    string    `json:"coverImage"`
-	Genres        []string  `json:"genres"`
-	Status        string    `json:"status"`
-	PublishedYear int       `json:"publishedYear,omitempty"`
-	LastUpdated   time.Time `json:"lastUpdated"`
-	ChapterCount  int       `json:"chapterCount"`
-	AltTitles     []string  `json:"altTitles,omitempty"`
-	Path          string    `json:"-"` // Internal use only
+// Visibilities of a personal series
+const (
+	VisibilityPrivate = "private" // Only the owner
+	VisibilityUsers   = "users"   // Any signed-in user
+	VisibilityPublic  = "public"  // Everyone, like the shared catalog
+)
+
+// Visibilities are the accepted values of MangaSeries.Visibility
+var Visibilities = []string{VisibilityPrivate, VisibilityUsers, VisibilityPublic}
+
+// NormalizeVisibility lower-cases a visibility and checks it against
+// Visibilities; the empty string means private
+func NormalizeVisibility(visibility string) (string, error) {
+	visibility = strings.ToLower(strings.TrimSpace(visibility))
+	if visibility == "" {
+		return VisibilityPrivate, nil
+	}
+	for _, v := range Visibilities {
+		if v == visibility {
+			return v, nil
+		}
+	}
+	return "", NewValidationError("visibility must be one of " + strings.Join(Visibilities, ", "))
+}
+
+// IsPublic reports whether anonymous visitors may see the series: every
+// series of the shared catalog, and personal series made public
+func (m *MangaSeries) IsPublic() bool {
+	return m.Owner == "" || m.Visibility == VisibilityPublic
+}
+
+// Demographics are the accepted values of MangaSeries.Demographic
+var Demographics = []string{"shounen", "shoujo", "seinen", "josei", "kodomo"}
+
+// NormalizeDemographic lower-cases a demographic and checks it against
+// Demographics; the empty string is allowed and means unknown
+func NormalizeDemographic(demographic string) (string, error) {
+	demographic = strings.ToLower(strings.TrimSpace(demographic))
+	if demographic == "" {
+		return "", nil
+	}
+	for _, d := range Demographics {
+		if d == demographic {
+			return d, nil
+		}
+	}
+	return "", NewValidationError("demographic must be one of " + strings.Join(Demographics, ", "))
+}
+
+const (
+	maxCustomFieldKeyLength   = 64
+	maxCustomFieldValueLength = 4096
+)
+
+// ValidateCustomField checks the key and value of a custom metadata field
+func ValidateCustomField(key, value string) error {
+	if strings.TrimSpace(key) == "" {
+		return NewValidationError("custom field key is required")
+	}
+	if len(key) > maxCustomFieldKeyLength {
+		return NewValidationError("custom field key must be at most 64 bytes")
+	}
+	if len(value) > maxCustomFieldValueLength {
+		return NewValidationError("custom field value must be at most 4096 bytes")
+	}
+	return nil
+}
+
+// SetCustomField sets a custom field; an empty value removes it
+func (m *MangaSeries) SetCustomField(key, value string) error {
+	if err := ValidateCustomField(key, value); err != nil {
+		return err
+	}
+	if value == "" {
+		delete(m.CustomFields, key)
+		return nil
+	}
+	if m.CustomFields == nil {
+		m.CustomFields = make(map[string]string)
+	}
+	m.CustomFields[key] = value
+	return nil
 }
 
 func (m *MangaSeries) Validate() error {
@@ -45,6 +136,13 @@ func (m *MangaSeries) Validate() error {
 		mangaLogger.Warn("Validation failed: title is empty", zap.String("mangaID", m.ID))
 		return NewValidationError("manga title is required")
 	}
+	if _, err := NormalizeDemographic(m.Demographic); err != nil {
+		mangaLogger.Warn("Validation failed: unknown demographic",
+			zap.String("mangaID", m.ID),
+			zap.String("demographic", m.Demographic),
+		)
+		return err
+	}
 	return nil
 }
 
@@ -71,6 +169,7 @@ func (m *MangaSeries) LoadFromJSON(path string) error {
 	}
 
 	m.Path = filepath.Dir(path)
+	m.LastUpdated = m.LastUpdated.UTC()
 
 	mangaLogger.Info("Manga metadata loaded",
 		zap.String("mangaID", m.ID),
@@ -124,8 +223,58 @@ func (m *MangaSeries) GetCoverImagePath() string {
 	return fullPath
 }
 
+// CoverImageExtensions are the file types accepted as cover images
+var CoverImageExtensions = []string{".jpg", ".jpeg", ".png", ".webp"}
+
+// IsCoverFileName reports whether a file in the series directory is a cover
+// image: an image whose name contains "cover" or starts with "thumbnail"
+func IsCoverFileName(name string) bool {
+	lower := strings.ToLower(name)
+	if !isImageExtension(filepath.Ext(lower)) {
+		return false
+	}
+	return strings.Contains(lower, "cover") || strings.HasPrefix(lower, "thumbnail")
+}
+
+// ListCovers returns the file names of every cover image stored in the series
+// directory, including the primary cover, sorted by name
+func (m *MangaSeries) ListCovers() ([]string, error) {
+	entries, err := os.ReadDir(m.Path)
+	if err != nil {
+		return nil, NewMetadataError("failed to read manga directory: " + err.Error())
+	}
+
+	var covers []string
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		if IsCoverFileName(entry.Name()) || entry.Name() == filepath.Base(m.CoverImage) {
+			covers = append(covers, entry.Name())
+		}
+	}
+	mangaLogger.Debug("ListCovers called",
+		zap.String("mangaID", m.ID),
+		zap.Int("coverCount", len(covers)),
+	)
+	return covers, nil
+}
+
+func isImageExtension(ext string) bool {
+	ext = strings.ToLower(ext)
+	for _, e := range CoverImageExtensions {
+		if e == ext {
+			return true
+		}
+	}
+	return false
+}
+
 func (m *MangaSeries) GetCoverImageURL() string {
-	url := "/manga-images/" + m.ID + "/" + filepath.Base(m.CoverImage)
+	url := ImageURL(m.GetCoverImagePath())
+	if url == "" {
+		url = ImageURLPrefix + "/" + escapePathSegments(m.ID+"/"+filepath.Base(m.CoverImage))
+	}
 	mangaLogger.Debug("GetCoverImageURL called",
 		zap.String("mangaID", m.ID),
 		zap.String("coverImageURL", url),
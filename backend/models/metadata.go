@@ -1,12 +1,16 @@
 package models
 
 import (
-	"encoding/json"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
+	"mangahub/backend/naming"
+
 	"go.uber.org/zap"
 )
 
@@ -15,18 +19,38 @@ const (
 	MetadataFileName = "metadata.json"
 )
 
-// We'll use a package-level logger for convenience
-var logger *zap.Logger
+// We'll use a package-level logger for convenience; it discards output until
+// SetLogger installs the configured logger
+var logger = zap.NewNop()
 
-// init sets up zap logger. Replace zap.NewDevelopment() with zap.NewProduction() if desired.
-func init() {
-	logr, _ := zap.NewDevelopment()
-	logger = logr
+// SetLogger sets the loggers used by this package, with named children for
+// manga and chapter handling
+func SetLogger(l *zap.Logger) {
+	logger = l
+	mangaLogger = l.Named("manga")
+	chapterLogger = l.Named("chapter")
 }
 
 // MetadataManager provides utilities for managing metadata
 type MetadataManager struct {
 	RootDir string // Root directory for manga storage
+
+	// pageCounts remembers the page count of every chapter directory whose
+	// pages have been enumerated, so chapter listings never have to open
+	// chapter directories themselves.
+	pageCountsMu sync.RWMutex
+	pageCounts   map[string]int
+
+	// readingScreens remembers the measured reading screens of chapters
+	// whose metadata doesn't record them, see ReadingScreens
+	readingScreensMu sync.RWMutex
+	readingScreens   map[string]float64
+
+	// persistDerived saves derived metadata, see SetPersistDerived
+	persistDerived atomic.Bool
+
+	// defaultNSFW marks derived series NSFW, see SetDefaultNSFW
+	defaultNSFW atomic.Bool
 }
 
 // NewMetadataManager creates a new metadata manager
@@ -34,8 +58,11 @@ func NewMetadataManager(rootDir string) *MetadataManager {
 	logger.Info("NewMetadataManager called",
 		zap.String("RootDir", rootDir),
 	)
+	setLibraryRoot(rootDir)
 	return &MetadataManager{
-		RootDir: rootDir,
+		RootDir:        rootDir,
+		pageCounts:     make(map[string]int),
+		readingScreens: make(map[string]float64),
 	}
 }
 
@@ -56,48 +83,18 @@ func (mm *MetadataManager) ScanForManga() ([]MangaSeries, error) {
 		return nil, NewMetadataError("failed to read root directory: " + err.Error())
 	}
 
-	// Look for manga directories
+	// Look for manga directories; hidden ones hold server data, not series
 	for _, dir := range dirs {
-		if !dir.IsDir() {
+		if !dir.IsDir() || strings.HasPrefix(dir.Name(), ".") {
 			continue
 		}
 
-		// Check for metadata.json
-		mangaPath := filepath.Join(mm.RootDir, dir.Name())
-		metadataPath := filepath.Join(mangaPath, MetadataFileName)
-
-		// If metadata exists, load it
-		if _, err := os.Stat(metadataPath); err == nil {
-			logger.Info("Found metadata file",
-				zap.String("mangaPath", mangaPath),
-				zap.String("metadataPath", metadataPath),
-			)
-
-			var manga MangaSeries
-			if err := manga.LoadFromJSON(metadataPath); err != nil {
-				// Log the error but continue with other manga
-				logger.Warn("Failed to load metadata",
-					zap.String("metadataPath", metadataPath),
-					zap.Error(err),
-				)
-				continue
-			}
-			mangas = append(mangas, manga)
-		} else {
-			// Try to create metadata from directory structure
-			logger.Info("No metadata file found; creating from directory",
-				zap.String("mangaPath", mangaPath),
-			)
-
-			if manga, err := mm.CreateMangaFromDirectory(mangaPath); err == nil {
-				mangas = append(mangas, manga)
-			} else {
-				logger.Warn("Failed to create manga from directory",
-					zap.String("mangaPath", mangaPath),
-					zap.Error(err),
-				)
-			}
+		manga, err := mm.LoadMangaDir(filepath.Join(mm.RootDir, dir.Name()))
+		if err != nil {
+			// Log the error but continue with other manga
+			continue
 		}
+		mangas = append(mangas, manga)
 	}
 
 	logger.Info("ScanForManga complete",
@@ -106,6 +103,46 @@ func (mm *MetadataManager) ScanForManga() ([]MangaSeries, error) {
 	return mangas, nil
 }
 
+// LoadMangaDir loads a single manga series directory, reading its metadata.json
+// when present and deriving metadata from the directory structure otherwise
+func (mm *MetadataManager) LoadMangaDir(mangaPath string) (MangaSeries, error) {
+	metadataPath := filepath.Join(mangaPath, MetadataFileName)
+
+	// If metadata exists, load it
+	if _, err := os.Stat(metadataPath); err == nil {
+		logger.Info("Found metadata file",
+			zap.String("mangaPath", mangaPath),
+			zap.String("metadataPath", metadataPath),
+		)
+
+		var manga MangaSeries
+		if err := manga.LoadFromJSON(metadataPath); err != nil {
+			logger.Warn("Failed to load metadata",
+				zap.String("metadataPath", metadataPath),
+				zap.Error(err),
+			)
+			return MangaSeries{}, err
+		}
+		mm.ensureCover(&manga)
+		return manga, nil
+	}
+
+	// Try to create metadata from directory structure
+	logger.Info("No metadata file found; creating from directory",
+		zap.String("mangaPath", mangaPath),
+	)
+
+	manga, err := mm.CreateMangaFromDirectory(mangaPath)
+	if err != nil {
+		logger.Warn("Failed to create manga from directory",
+			zap.String("mangaPath", mangaPath),
+			zap.Error(err),
+		)
+		return MangaSeries{}, err
+	}
+	return manga, nil
+}
+
 // GetMangaByID returns a specific manga by its ID
 func (mm *MetadataManager) GetMangaByID(id string) (*MangaSeries, error) {
 	logger.Info("GetMangaByID called",
@@ -131,6 +168,7 @@ func (mm *MetadataManager) GetMangaByID(id string) (*MangaSeries, error) {
 			)
 			return nil, err
 		}
+		mm.ensureCover(&manga)
 		return &manga, nil
 	}
 
@@ -171,8 +209,9 @@ func (mm *MetadataManager) CreateMangaFromDirectory(dirPath string) (MangaSeries
 		Title:       strings.ReplaceAll(filepath.Base(dirPath), "-", " "),
 		Description: "No description available",
 		Path:        dirPath,
-		LastUpdated: time.Now(),
+		LastUpdated: time.Now().UTC(),
 		Status:      "Unknown",
+		NSFW:        mm.defaultNSFW.Load(),
 	}
 
 	// Look for a cover image
@@ -208,9 +247,16 @@ func (mm *MetadataManager) CreateMangaFromDirectory(dirPath string) (MangaSeries
 		}
 	}
 
+	if manga.CoverImage == "" {
+		mm.ensureCover(&manga)
+	}
+
 	// Count chapters
 	chapters, _ := mm.ScanForChapters(&manga)
 	manga.ChapterCount = len(chapters)
+	mm.persistIfEnabled(func() error {
+		return manga.SaveToJSON(filepath.Join(dirPath, MetadataFileName))
+	}, dirPath)
 	logger.Info("Created MangaSeries from directory",
 		zap.String("mangaID", manga.ID),
 		zap.Int("chapterCount", manga.ChapterCount),
@@ -262,6 +308,9 @@ func (mm *MetadataManager) ScanForChapters(manga *MangaSeries) ([]Chapter, error
 				)
 				continue
 			}
+			if count, ok := mm.cachedPageCount(chapter.Path); ok {
+				chapter.PageCount = count
+			}
 			chapters = append(chapters, chapter)
 		} else {
 			// Try to create chapter metadata from directory name
@@ -279,6 +328,12 @@ func (mm *MetadataManager) ScanForChapters(manga *MangaSeries) ([]Chapter, error
 		}
 	}
 
+	// Keep chapters in reading order so callers can navigate by index; the
+	// final tie-break by ID keeps the order stable for cursor pagination
+	sort.SliceStable(chapters, func(i, j int) bool {
+		return ChapterLess(&chapters[i], &chapters[j])
+	})
+
 	logger.Info("ScanForChapters complete",
 		zap.String("mangaID", manga.ID),
 		zap.Int("chapterCount", len(chapters)),
@@ -295,46 +350,35 @@ func (mm *MetadataManager) CreateChapterFromDirectory(mangaID, dirPath string) (
 		zap.String("dirName", dirName),
 	)
 
-	var chapterNum float64 = 0
-	processedName := strings.ToLower(dirName)
-	processedName = strings.ReplaceAll(processedName, "chapter-", "")
-	processedName = strings.ReplaceAll(processedName, "chapter", "")
-	processedName = strings.ReplaceAll(processedName, "ch", "")
-
-	_, err := json.Marshal(processedName)
-	if err == nil {
-		if num, err := jsonNumberToFloat(processedName); err == nil {
-			chapterNum = num
-		}
+	// Chapters need a positive number, so names without one become chapter 1
+	parsed, ok := naming.ParseChapter(dirName)
+	if !ok || parsed.Number == 0 {
+		logger.Warn("No chapter number in directory name, using 1", zap.String("dirName", dirName))
+		parsed.Number = 1
 	}
-
-	if chapterNum == 0 {
-		chapterNum = 1
+	title := parsed.Title
+	if title == "" {
+		title = strings.ReplaceAll(dirName, "-", " ")
 	}
 
-	// Count pages
-	var pageCount int
-	entries, _ := os.ReadDir(dirPath)
-	for _, entry := range entries {
-		if entry.IsDir() {
-			continue
-		}
-
-		ext := strings.ToLower(filepath.Ext(entry.Name()))
-		if ext == ".jpg" || ext == ".png" || ext == ".jpeg" {
-			pageCount++
-		}
-	}
+	// Pages are only enumerated by LoadPages; until then the count stays unknown (0)
+	pageCount, _ := mm.cachedPageCount(dirPath)
 
 	chapter := Chapter{
 		ID:          dirName,
 		MangaID:     mangaID,
-		Number:      chapterNum,
-		Title:       strings.ReplaceAll(dirName, "-", " "),
-		ReleaseDate: time.Now(),
+		Number:      parsed.Number,
+		Title:       title,
+		Volume:      parsed.Volume,
+		ReleaseDate: time.Now().UTC(),
 		PageCount:   pageCount,
 		Path:        dirPath,
 	}
+	chapter.Classify(dirName, parsed.Title)
+	mm.persistIfEnabled(func() error {
+		_, err := mm.saveDerivedChapter(&chapter)
+		return err
+	}, dirPath)
 
 	logger.Info("CreateChapterFromDirectory complete",
 		zap.String("chapterID", chapter.ID),
@@ -346,8 +390,47 @@ func (mm *MetadataManager) CreateChapterFromDirectory(mangaID, dirPath string) (
 	return chapter, nil
 }
 
-func jsonNumberToFloat(s string) (float64, error) {
-	var num float64
-	err := json.Unmarshal([]byte(s), &num)
-	return num, err
+// LoadPages enumerates the pages of a chapter and remembers the resulting page
+// count, so later chapter listings can report it without reading the directory.
+func (mm *MetadataManager) LoadPages(chapter *Chapter) ([]Page, error) {
+	pages, err := chapter.GetPages()
+	if err != nil {
+		return nil, err
+	}
+
+	mm.pageCountsMu.Lock()
+	previous, known := mm.pageCounts[chapter.Path]
+	mm.pageCounts[chapter.Path] = chapter.PageCount
+	mm.pageCountsMu.Unlock()
+
+	// Pages were added or removed, so a remembered measurement is stale
+	if known && previous != chapter.PageCount {
+		mm.readingScreensMu.Lock()
+		delete(mm.readingScreens, chapter.Path)
+		mm.readingScreensMu.Unlock()
+	}
+
+	return pages, nil
+}
+
+// ForgetChapter drops everything remembered about the chapter directory at
+// chapterPath, e.g. after it was moved away
+func (mm *MetadataManager) ForgetChapter(chapterPath string) {
+	mm.pageCountsMu.Lock()
+	delete(mm.pageCounts, chapterPath)
+	mm.pageCountsMu.Unlock()
+
+	mm.readingScreensMu.Lock()
+	delete(mm.readingScreens, chapterPath)
+	mm.readingScreensMu.Unlock()
+
+	pageCache.remove(chapterPath)
+}
+
+// cachedPageCount returns the last known page count for a chapter directory
+func (mm *MetadataManager) cachedPageCount(chapterPath string) (int, bool) {
+	mm.pageCountsMu.RLock()
+	defer mm.pageCountsMu.RUnlock()
+	count, ok := mm.pageCounts[chapterPath]
+	return count, ok
 }
@@ -0,0 +1,62 @@
+package models
+
+import "strconv"
+
+// Navigation describes where a reader can go from the current page. Page
+// fields are 0 and chapter fields empty when there is nothing in that direction.
+type Navigation struct {
+	HasNext bool `json:"hasNext"`
+	HasPrev bool `json:"hasPrev"`
+
+	HasNextPage bool `json:"hasNextPage"`
+	HasPrevPage bool `json:"hasPrevPage"`
+	NextPage    int  `json:"nextPage"`
+	PrevPage    int  `json:"prevPage"`
+
+	HasNextChapter bool   `json:"hasNextChapter"`
+	HasPrevChapter bool   `json:"hasPrevChapter"`
+	NextChapter    string `json:"nextChapter,omitempty"`
+	PrevChapter    string `json:"prevChapter,omitempty"`
+	NextChapterID  string `json:"nextChapterId,omitempty"`
+	PrevChapterID  string `json:"prevChapterId,omitempty"`
+
+	// PrevChapterLastPage lets readers step back from page 1 straight onto the
+	// last page of the previous chapter
+	PrevChapterLastPage int `json:"prevChapterLastPage,omitempty"`
+}
+
+// NewNavigation builds the navigation for page within pages of
+// chapters[chapterIndex]. chapters must be sorted by chapter number.
+// prevChapterPages may be nil if the previous chapter's pages are unknown.
+func NewNavigation(chapters []Chapter, chapterIndex int, pages []Page, page *Page, prevChapterPages []Page) Navigation {
+	nav := Navigation{
+		NextPage: page.GetNextPageNumber(pages),
+		PrevPage: page.GetPrevPageNumber(pages),
+	}
+	nav.HasNextPage = nav.NextPage != 0
+	nav.HasPrevPage = nav.PrevPage != 0
+
+	prevChapter, nextChapter := AdjacentChapters(chapters, chapterIndex)
+	if nextChapter != nil {
+		nav.HasNextChapter = true
+		nav.NextChapter = FormatChapterNumber(nextChapter.Number)
+		nav.NextChapterID = nextChapter.ID
+	}
+	if prevChapter != nil {
+		nav.HasPrevChapter = true
+		nav.PrevChapter = FormatChapterNumber(prevChapter.Number)
+		nav.PrevChapterID = prevChapter.ID
+		if len(prevChapterPages) > 0 {
+			nav.PrevChapterLastPage = prevChapterPages[len(prevChapterPages)-1].Number
+		}
+	}
+
+	nav.HasNext = nav.HasNextPage || nav.HasNextChapter
+	nav.HasPrev = nav.HasPrevPage || nav.HasPrevChapter
+	return nav
+}
+
+// FormatChapterNumber renders a chapter number the way it appears in URLs
+func FormatChapterNumber(number float64) string {
+	return strconv.FormatFloat(number, 'f', -1, 64)
+}
@@ -0,0 +1,142 @@
+package models
+
+import (
+	"encoding/json"
+	"fmt"
+	"mangahub/backend/storage"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"go.uber.org/zap"
+)
+
+// OverlaysFileName is the per-chapter file holding translation overlays
+const OverlaysFileName = "overlays.json"
+
+// TextRegion is a block of text on a page together with its translation.
+// Coordinates are fractions (0-1) of the page size so they apply to every
+// resolution variant of the image.
+type TextRegion struct {
+	ID             string  `json:"id"`
+	X              float64 `json:"x"`
+	Y              float64 `json:"y"`
+	Width          float64 `json:"width"`
+	Height         float64 `json:"height"`
+	OriginalText   string  `json:"originalText"`
+	TranslatedText string  `json:"translatedText"`
+	Language       string  `json:"language,omitempty"`
+}
+
+// Validate checks that the region lies within the page
+func (r *TextRegion) Validate() error {
+	if r.Width <= 0 || r.Height <= 0 {
+		return NewValidationError("region width and height must be positive")
+	}
+	if r.X < 0 || r.Y < 0 || r.X+r.Width > 1 || r.Y+r.Height > 1 {
+		return NewValidationError("region must lie within the page (coordinates are fractions of 0-1)")
+	}
+	return nil
+}
+
+// ChapterOverlays holds the text regions of every page in a chapter
+type ChapterOverlays struct {
+	Pages map[int][]TextRegion `json:"pages"`
+}
+
+// LoadOverlays reads the overlays of the chapter at chapterPath. A chapter
+// without an overlays file simply has no regions.
+func LoadOverlays(chapterPath string) (*ChapterOverlays, error) {
+	overlays := &ChapterOverlays{Pages: make(map[int][]TextRegion)}
+
+	data, err := os.ReadFile(filepath.Join(chapterPath, OverlaysFileName))
+	if os.IsNotExist(err) {
+		return overlays, nil
+	}
+	if err != nil {
+		logger.Error("Failed to read overlays", zap.String("chapterPath", chapterPath), zap.Error(err))
+		return nil, NewMetadataError("failed to read overlays: " + err.Error())
+	}
+
+	if err := json.Unmarshal(data, overlays); err != nil {
+		logger.Error("Failed to parse overlays", zap.String("chapterPath", chapterPath), zap.Error(err))
+		return nil, NewMetadataError("failed to parse overlays: " + err.Error())
+	}
+	if overlays.Pages == nil {
+		overlays.Pages = make(map[int][]TextRegion)
+	}
+	return overlays, nil
+}
+
+var (
+	overlayLocksMu sync.Mutex
+	overlayLocks   = make(map[string]*sync.Mutex) // By chapter path
+)
+
+// overlayLock returns the mutex serializing overlay writes to a chapter
+func overlayLock(chapterPath string) *sync.Mutex {
+	overlayLocksMu.Lock()
+	defer overlayLocksMu.Unlock()
+	lock, ok := overlayLocks[chapterPath]
+	if !ok {
+		lock = &sync.Mutex{}
+		overlayLocks[chapterPath] = lock
+	}
+	return lock
+}
+
+// UpdateOverlays loads the overlays of the chapter at chapterPath, applies
+// update and saves them, holding the chapter's lock throughout so concurrent
+// edits of different pages don't overwrite each other. Nothing is saved when
+// update fails.
+func UpdateOverlays(chapterPath string, update func(*ChapterOverlays) error) (*ChapterOverlays, error) {
+	lock := overlayLock(chapterPath)
+	lock.Lock()
+	defer lock.Unlock()
+
+	overlays, err := LoadOverlays(chapterPath)
+	if err != nil {
+		return nil, err
+	}
+	if err := update(overlays); err != nil {
+		return nil, err
+	}
+	if err := overlays.Save(chapterPath); err != nil {
+		return nil, err
+	}
+	return overlays, nil
+}
+
+// SetPage replaces the regions of one page, assigning IDs to new regions
+func (o *ChapterOverlays) SetPage(pageNumber int, regions []TextRegion) error {
+	for i := range regions {
+		if err := regions[i].Validate(); err != nil {
+			return err
+		}
+		if regions[i].ID == "" {
+			regions[i].ID = fmt.Sprintf("p%d-r%d", pageNumber, i+1)
+		}
+	}
+
+	if len(regions) == 0 {
+		delete(o.Pages, pageNumber)
+	} else {
+		o.Pages[pageNumber] = regions
+	}
+	return nil
+}
+
+// Save writes the overlays back to the chapter directory. Use UpdateOverlays
+// to change the overlays of a chapter others may edit at the same time.
+func (o *ChapterOverlays) Save(chapterPath string) error {
+	path := filepath.Join(chapterPath, OverlaysFileName)
+	data, err := json.MarshalIndent(o, "", "  ")
+	if err != nil {
+		return NewMetadataError("failed to marshal overlays: " + err.Error())
+	}
+	if err := storage.WriteFileAtomic(path, data, 0644); err != nil {
+		logger.Error("Failed to write overlays", zap.String("path", path), zap.Error(err))
+		return NewMetadataError("failed to write overlays: " + err.Error())
+	}
+	return nil
+}
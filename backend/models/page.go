@@ -1,12 +1,12 @@
 package models
 
 import (
-	"fmt"
 	"image"
 	_ "image/jpeg" // Register JPEG format
 	_ "image/png"  // Register PNG format
 	"os"
 	"path/filepath"
+	"strings"
 )
 
 // Page represents a single page in a manga chapter
@@ -19,9 +19,13 @@ type Page struct {
 	Height    int    `json:"height,omitempty"`
 	FileSize  int64  `json:"fileSize,omitempty"`
 	MimeType  string `json:"mimeType,omitempty"`
+
+	// SidecarPath is the page's JSON sidecar, see LoadSidecar
+	SidecarPath string `json:"-"`
 }
 
-// LoadImageMetadata loads image dimensions and other metadata
+// LoadImageMetadata loads image dimensions and other metadata. Dimensions are
+// cached per file size and mtime, so only new or replaced pages are decoded.
 func (p *Page) LoadImageMetadata() error {
 	// Get file info for size
 	fileInfo, err := os.Stat(p.ImagePath)
@@ -30,6 +34,13 @@ func (p *Page) LoadImageMetadata() error {
 	}
 	p.FileSize = fileInfo.Size()
 
+	if cached, ok := imageInfoCache.get(p.ImagePath, fileInfo.Size(), fileInfo.ModTime()); ok {
+		p.Width = cached.width
+		p.Height = cached.height
+		p.MimeType = "image/" + cached.format
+		return nil
+	}
+
 	// Open the image to get dimensions and type
 	file, err := os.Open(p.ImagePath)
 	if err != nil {
@@ -47,39 +58,28 @@ func (p *Page) LoadImageMetadata() error {
 	p.Height = img.Height
 	p.MimeType = "image/" + format
 
+	imageInfoCache.put(imageHeaderEntry{
+		path:    p.ImagePath,
+		size:    fileInfo.Size(),
+		modTime: fileInfo.ModTime(),
+		width:   img.Width,
+		height:  img.Height,
+		format:  format,
+	})
 	return nil
 }
 
-// GetImageURL returns the URL for accessing this page
+// GetImageURL returns the URL for accessing this page, derived from the image
+// path relative to the library root
 func (p *Page) GetImageURL() string {
-	// Extract the relative path components we need
-	dir := filepath.Dir(p.ImagePath)
-	parts := filepath.SplitList(dir)
-
-	// Construct a URL path suitable for your static file serving
-	// This assumes your manga is organized as /manga/[manga-id]/[chapter-id]/[page].jpg
-	mangaID := p.MangaID
-	if mangaID == "" {
-		// Try to extract from path if not set
-		if len(parts) >= 2 {
-			mangaID = parts[len(parts)-2]
-		} else {
-			mangaID = "unknown"
-		}
+	if url := ImageURL(p.ImagePath); url != "" {
+		return url
 	}
 
-	chapterID := p.ChapterID
-	if chapterID == "" {
-		// Try to extract from path if not set
-		if len(parts) >= 1 {
-			chapterID = parts[len(parts)-1]
-		} else {
-			chapterID = "unknown"
-		}
-	}
-
-	filename := filepath.Base(p.ImagePath)
-	return fmt.Sprintf("/manga-images/%s/%s/%s", mangaID, chapterID, filename)
+	// The image lives outside the library root; fall back to the conventional
+	// /manga/[manga-id]/[chapter-id]/[page] layout
+	return ImageURLPrefix + "/" + escapePathSegments(
+		strings.Join([]string{p.MangaID, p.ChapterID, filepath.Base(p.ImagePath)}, "/"))
 }
 
 // Validate checks if the page has all required fields
@@ -102,15 +102,30 @@ func (p *Page) ImageExists() bool {
 	return err == nil
 }
 
-// GetNextPageNumber returns the next page number or 0 if this is the last page
-func (p *Page) GetNextPageNumber() int {
-	return p.Number + 1
+// GetNextPageNumber returns the number of the page following this one in
+// pages, or 0 if this is the last page
+func (p *Page) GetNextPageNumber(pages []Page) int {
+	for i := range pages {
+		if pages[i].Number == p.Number {
+			if i+1 < len(pages) {
+				return pages[i+1].Number
+			}
+			return 0
+		}
+	}
+	return 0
 }
 
-// GetPrevPageNumber returns the previous page number or 0 if this is the first page
-func (p *Page) GetPrevPageNumber() int {
-	if p.Number <= 1 {
-		return 0
+// GetPrevPageNumber returns the number of the page preceding this one in
+// pages, or 0 if this is the first page
+func (p *Page) GetPrevPageNumber(pages []Page) int {
+	for i := range pages {
+		if pages[i].Number == p.Number {
+			if i > 0 {
+				return pages[i-1].Number
+			}
+			return 0
+		}
 	}
-	return p.Number - 1
+	return 0
 }
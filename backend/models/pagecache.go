@@ -0,0 +1,167 @@
+package models
+
+import (
+	"container/list"
+	"sync"
+	"time"
+)
+
+// DefaultPageCacheSize is the number of chapter page listings kept in memory
+const DefaultPageCacheSize = 256
+
+// pageCache holds recent GetPages results so page-by-page navigation in the
+// reader doesn't re-read the chapter directory on every request
+var pageCache = newPageListCache(DefaultPageCacheSize)
+
+// pageListCache is a size-bounded LRU of chapter page listings. Entries are
+// keyed by chapter directory and are only valid for the directory mtime they
+// were read at, so adding or removing a page invalidates them.
+type pageListCache struct {
+	mu       sync.Mutex
+	capacity int
+	order    *list.List
+	items    map[string]*list.Element
+}
+
+type pageListEntry struct {
+	path    string
+	modTime time.Time
+	pages   []Page
+}
+
+func newPageListCache(capacity int) *pageListCache {
+	return &pageListCache{
+		capacity: capacity,
+		order:    list.New(),
+		items:    make(map[string]*list.Element),
+	}
+}
+
+// get returns a copy of the cached listing for path if it is still current
+func (pc *pageListCache) get(path string, modTime time.Time) ([]Page, bool) {
+	pc.mu.Lock()
+	defer pc.mu.Unlock()
+
+	elem, ok := pc.items[path]
+	if !ok {
+		return nil, false
+	}
+	entry := elem.Value.(*pageListEntry)
+	if !entry.modTime.Equal(modTime) {
+		pc.order.Remove(elem)
+		delete(pc.items, path)
+		return nil, false
+	}
+
+	pc.order.MoveToFront(elem)
+	return append([]Page(nil), entry.pages...), true
+}
+
+// put stores a copy of a listing, evicting the least recently used entry when full
+func (pc *pageListCache) put(path string, modTime time.Time, pages []Page) {
+	pc.mu.Lock()
+	defer pc.mu.Unlock()
+
+	entry := &pageListEntry{
+		path:    path,
+		modTime: modTime,
+		pages:   append([]Page(nil), pages...),
+	}
+
+	if elem, ok := pc.items[path]; ok {
+		elem.Value = entry
+		pc.order.MoveToFront(elem)
+		return
+	}
+
+	pc.items[path] = pc.order.PushFront(entry)
+	for pc.order.Len() > pc.capacity {
+		oldest := pc.order.Back()
+		pc.order.Remove(oldest)
+		delete(pc.items, oldest.Value.(*pageListEntry).path)
+	}
+}
+
+// remove drops the listing of path, if cached
+func (pc *pageListCache) remove(path string) {
+	pc.mu.Lock()
+	defer pc.mu.Unlock()
+
+	if elem, ok := pc.items[path]; ok {
+		pc.order.Remove(elem)
+		delete(pc.items, path)
+	}
+}
+
+// DefaultImageInfoCacheSize is the number of page image headers kept in memory
+const DefaultImageInfoCacheSize = 16384
+
+// imageInfoCache holds the decoded dimensions of recently served pages, so
+// chapter responses don't open and decode every page file on each request
+var imageInfoCache = newImageHeaderCache(DefaultImageInfoCacheSize)
+
+// imageHeaderCache is a size-bounded LRU of page image dimensions and types.
+// Entries are keyed by image path and are only valid for the file size and
+// mtime they were read at, so replacing a page invalidates them.
+type imageHeaderCache struct {
+	mu       sync.Mutex
+	capacity int
+	order    *list.List
+	items    map[string]*list.Element
+}
+
+type imageHeaderEntry struct {
+	path    string
+	size    int64
+	modTime time.Time
+	width   int
+	height  int
+	format  string
+}
+
+func newImageHeaderCache(capacity int) *imageHeaderCache {
+	return &imageHeaderCache{
+		capacity: capacity,
+		order:    list.New(),
+		items:    make(map[string]*list.Element),
+	}
+}
+
+// get returns the cached header of path if the file is unchanged
+func (hc *imageHeaderCache) get(path string, size int64, modTime time.Time) (imageHeaderEntry, bool) {
+	hc.mu.Lock()
+	defer hc.mu.Unlock()
+
+	elem, ok := hc.items[path]
+	if !ok {
+		return imageHeaderEntry{}, false
+	}
+	entry := elem.Value.(*imageHeaderEntry)
+	if entry.size != size || !entry.modTime.Equal(modTime) {
+		hc.order.Remove(elem)
+		delete(hc.items, path)
+		return imageHeaderEntry{}, false
+	}
+
+	hc.order.MoveToFront(elem)
+	return *entry, true
+}
+
+// put stores a header, evicting the least recently used entry when full
+func (hc *imageHeaderCache) put(entry imageHeaderEntry) {
+	hc.mu.Lock()
+	defer hc.mu.Unlock()
+
+	if elem, ok := hc.items[entry.path]; ok {
+		elem.Value = &entry
+		hc.order.MoveToFront(elem)
+		return
+	}
+
+	hc.items[entry.path] = hc.order.PushFront(&entry)
+	for hc.order.Len() > hc.capacity {
+		oldest := hc.order.Back()
+		hc.order.Remove(oldest)
+		delete(hc.items, oldest.Value.(*imageHeaderEntry).path)
+	}
+}
@@ -0,0 +1,88 @@
+package models
+
+import (
+	"os"
+	"path/filepath"
+
+	"go.uber.org/zap"
+)
+
+// SetPersistDerived makes the manager save the metadata it derives for
+// directories without a metadata.json, so later scans read the file instead
+// of deriving it again and admins have a starting point to edit
+func (mm *MetadataManager) SetPersistDerived(enabled bool) {
+	mm.persistDerived.Store(enabled)
+}
+
+// SetDefaultNSFW marks the series derived for directories without a
+// metadata.json as NSFW, as the library's default content rating
+func (mm *MetadataManager) SetDefaultNSFW(nsfw bool) {
+	mm.defaultNSFW.Store(nsfw)
+}
+
+// PersistDerived writes a metadata.json for a series and each of its
+// chapters that don't have one yet, returning the paths of the files written
+func (mm *MetadataManager) PersistDerived(manga *MangaSeries) ([]string, error) {
+	var written []string
+
+	chapters, err := mm.ScanForChapters(manga)
+	if err != nil {
+		return nil, err
+	}
+	for i := range chapters {
+		chapter := &chapters[i]
+		if hasMetadataFile(chapter.Path) {
+			continue
+		}
+		path, err := mm.saveDerivedChapter(chapter)
+		if err != nil {
+			return written, err
+		}
+		written = append(written, path)
+	}
+
+	if !hasMetadataFile(manga.Path) {
+		derived := *manga
+		derived.ChapterCount = len(chapters)
+		path := filepath.Join(manga.Path, MetadataFileName)
+		if err := derived.SaveToJSON(path); err != nil {
+			return written, err
+		}
+		written = append(written, path)
+	}
+
+	if len(written) > 0 {
+		logger.Info("Saved derived metadata",
+			zap.String("mangaID", manga.ID),
+			zap.Int("fileCount", len(written)),
+		)
+	}
+	return written, nil
+}
+
+// saveDerivedChapter writes a derived chapter's metadata.json, counting its
+// pages first so the file doesn't record an unknown page count
+func (mm *MetadataManager) saveDerivedChapter(chapter *Chapter) (string, error) {
+	if _, err := mm.LoadPages(chapter); err != nil {
+		return "", err
+	}
+	path := filepath.Join(chapter.Path, MetadataFileName)
+	return path, chapter.SaveToJSON(path)
+}
+
+// persistIfEnabled saves derived metadata when SetPersistDerived is on.
+// Failures only mean the metadata is derived again next time, so they are
+// logged rather than returned.
+func (mm *MetadataManager) persistIfEnabled(save func() error, dirPath string) {
+	if !mm.persistDerived.Load() {
+		return
+	}
+	if err := save(); err != nil {
+		logger.Warn("Failed to save derived metadata", zap.String("dirPath", dirPath), zap.Error(err))
+	}
+}
+
+func hasMetadataFile(dirPath string) bool {
+	_, err := os.Stat(filepath.Join(dirPath, MetadataFileName))
+	return err == nil
+}
@@ -0,0 +1,73 @@
+package models
+
+import (
+	"math"
+
+	"go.uber.org/zap"
+)
+
+// screenAspect is the height-to-width ratio of a typical manga page. Pages
+// much taller than that are webtoon strips, read a screenful at a time.
+const screenAspect = 1.5
+
+// MeasureReadingScreens counts how many screens of reading the pages amount
+// to: one per ordinary page, and one per screen height of a long strip.
+// Pages whose dimensions can't be read count as ordinary pages.
+func MeasureReadingScreens(pages []Page) float64 {
+	screens := 0.0
+	for i := range pages {
+		page := &pages[i]
+		if page.Width == 0 {
+			if err := page.LoadImageMetadata(); err != nil {
+				chapterLogger.Warn("Failed to read page dimensions",
+					zap.String("imagePath", page.ImagePath),
+					zap.Error(err),
+				)
+			}
+		}
+		if page.Width > 0 && float64(page.Height)/float64(page.Width) > 2*screenAspect {
+			screens += float64(page.Height) / (float64(page.Width) * screenAspect)
+		} else {
+			screens++
+		}
+	}
+	// Tenths are precise enough and keep the metadata readable
+	return math.Round(screens*10) / 10
+}
+
+// ReadingScreens returns a chapter's reading screens, measuring and
+// remembering them when the chapter's metadata doesn't record them yet
+func (mm *MetadataManager) ReadingScreens(chapter *Chapter) (float64, error) {
+	if chapter.ReadingScreens > 0 {
+		return chapter.ReadingScreens, nil
+	}
+
+	mm.readingScreensMu.RLock()
+	screens, ok := mm.readingScreens[chapter.Path]
+	mm.readingScreensMu.RUnlock()
+	if ok {
+		return screens, nil
+	}
+
+	pages, err := mm.LoadPages(chapter)
+	if err != nil {
+		return 0, err
+	}
+	screens = MeasureReadingScreens(pages)
+
+	mm.readingScreensMu.Lock()
+	mm.readingScreens[chapter.Path] = screens
+	mm.readingScreensMu.Unlock()
+	return screens, nil
+}
+
+// CachedReadingScreens returns a chapter's reading screens if they are known
+// without opening the chapter directory, or 0
+func (mm *MetadataManager) CachedReadingScreens(chapter *Chapter) float64 {
+	if chapter.ReadingScreens > 0 {
+		return chapter.ReadingScreens
+	}
+	mm.readingScreensMu.RLock()
+	defer mm.readingScreensMu.RUnlock()
+	return mm.readingScreens[chapter.Path]
+}
@@ -0,0 +1,188 @@
+package models
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"go.uber.org/zap"
+)
+
+// Files a chapter folder may hold next to its pages
+const (
+	// PagesFileName lists the page files in reading order, for folders whose
+	// file names don't sort that way. Pages it doesn't list follow in name order.
+	PagesFileName = "pages.json"
+
+	// ComicInfoFileName is the ComicInfo.xml kept by some archive tools
+	ComicInfoFileName = "ComicInfo.xml"
+)
+
+// chapterSidecars are the chapter-wide files in a chapter folder
+var chapterSidecars = []string{MetadataFileName, PagesFileName, OverlaysFileName, ComicInfoFileName}
+
+// pageExtensions are the image file extensions pages are recognized by
+var pageExtensions = map[string]bool{".jpg": true, ".jpeg": true, ".png": true, ".gif": true, ".webp": true}
+
+// defaultIgnoredFiles are never pages: hidden files and the thumbnail
+// caches operating systems leave behind
+var defaultIgnoredFiles = []string{".*", "Thumbs.db", "desktop.ini"}
+
+// ignoredFiles are the glob patterns of files chapter folders may hold that
+// are neither pages nor sidecars
+var ignoredFiles = defaultIgnoredFiles
+
+// SetIgnoredFiles adds glob patterns (e.g. "*.txt" or "credits.*") matching
+// files in chapter folders that are neither pages nor sidecars, so they are
+// skipped without a warning. Matching ignores case.
+func SetIgnoredFiles(patterns []string) error {
+	for _, pattern := range patterns {
+		if _, err := filepath.Match(pattern, ""); err != nil {
+			return fmt.Errorf("invalid pattern %q: %w", pattern, err)
+		}
+	}
+	ignoredFiles = append(append([]string(nil), defaultIgnoredFiles...), patterns...)
+	return nil
+}
+
+// isIgnoredFile reports whether name matches one of the ignored patterns
+func isIgnoredFile(name string) bool {
+	lower := strings.ToLower(name)
+	for _, pattern := range ignoredFiles {
+		if ok, _ := filepath.Match(strings.ToLower(pattern), lower); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// isChapterSidecar reports whether name is one of the chapter-wide files
+func isChapterSidecar(name string) bool {
+	for _, sidecar := range chapterSidecars {
+		if strings.EqualFold(name, sidecar) {
+			return true
+		}
+	}
+	return false
+}
+
+// isPageImage reports whether name has the extension of a page image
+func isPageImage(name string) bool {
+	return pageExtensions[strings.ToLower(filepath.Ext(name))]
+}
+
+// chapterFiles sorts the files of a chapter folder
+type chapterFiles struct {
+	Pages    []string          // Page images, in reading order
+	Sidecars map[string]string // Per-page JSON sidecars, keyed by page file
+	Unknown  []string          // Files that are nothing of the above
+}
+
+// readChapterFiles lists a chapter folder's pages with their sidecars.
+// A page's sidecar is the JSON file named after it, either "001.json" or
+// "001.png.json". Other files are sidecars of the chapter, ignored (see
+// SetIgnoredFiles) or unknown.
+func readChapterFiles(chapterPath string) (chapterFiles, error) {
+	entries, err := os.ReadDir(chapterPath)
+	if err != nil {
+		return chapterFiles{}, err
+	}
+
+	files := chapterFiles{Sidecars: make(map[string]string)}
+	var jsonFiles []string
+	for _, entry := range entries {
+		name := entry.Name()
+		switch {
+		case entry.IsDir() || isIgnoredFile(name) || isChapterSidecar(name):
+		case isPageImage(name):
+			files.Pages = append(files.Pages, name)
+		case strings.EqualFold(filepath.Ext(name), ".json"):
+			jsonFiles = append(jsonFiles, name)
+		default:
+			files.Unknown = append(files.Unknown, name)
+		}
+	}
+
+	pageByBase := make(map[string]string, len(files.Pages))
+	for _, page := range files.Pages {
+		pageByBase[strings.ToLower(page)] = page
+		pageByBase[strings.ToLower(strings.TrimSuffix(page, filepath.Ext(page)))] = page
+	}
+	for _, name := range jsonFiles {
+		base := strings.ToLower(strings.TrimSuffix(name, filepath.Ext(name)))
+		if page, ok := pageByBase[base]; ok {
+			files.Sidecars[page] = name
+		} else {
+			files.Unknown = append(files.Unknown, name)
+		}
+	}
+
+	order, err := loadPageOrder(chapterPath)
+	if err != nil {
+		chapterLogger.Warn("Ignoring unreadable page order",
+			zap.String("chapterPath", chapterPath),
+			zap.Error(err),
+		)
+	}
+	files.Pages = applyPageOrder(files.Pages, order)
+	return files, nil
+}
+
+// loadPageOrder reads a chapter's pages.json, returning nil without one
+func loadPageOrder(chapterPath string) ([]string, error) {
+	data, err := os.ReadFile(filepath.Join(chapterPath, PagesFileName))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var order []string
+	if err := json.Unmarshal(data, &order); err != nil {
+		return nil, err
+	}
+	return order, nil
+}
+
+// applyPageOrder puts the pages listed in order first, in that order, and
+// the others after them in their existing order
+func applyPageOrder(pages, order []string) []string {
+	if len(order) == 0 {
+		return pages
+	}
+	present := make(map[string]bool, len(pages))
+	for _, page := range pages {
+		present[page] = true
+	}
+	ordered := make([]string, 0, len(pages))
+	for _, page := range order {
+		if present[page] {
+			ordered = append(ordered, page)
+			delete(present, page)
+		}
+	}
+	for _, page := range pages {
+		if present[page] {
+			ordered = append(ordered, page)
+		}
+	}
+	return ordered
+}
+
+// LoadSidecar returns the contents of the page's JSON sidecar, or nil when
+// it has none
+func (p *Page) LoadSidecar() (json.RawMessage, error) {
+	if p.SidecarPath == "" {
+		return nil, nil
+	}
+	data, err := os.ReadFile(p.SidecarPath)
+	if err != nil {
+		return nil, err
+	}
+	if !json.Valid(data) {
+		return nil, NewMetadataError("page sidecar " + filepath.Base(p.SidecarPath) + " is not valid JSON")
+	}
+	return data, nil
+}
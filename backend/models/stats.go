@@ -0,0 +1,116 @@
+package models
+
+import (
+	"io/fs"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// largestSeriesCount is how many series LibraryStats.Largest lists
+const largestSeriesCount = 10
+
+// LibraryStats summarizes the library for dashboards
+type LibraryStats struct {
+	SeriesCount  int   `json:"seriesCount"`
+	ChapterCount int   `json:"chapterCount"`
+	PageCount    int   `json:"pageCount"`
+	Bytes        int64 `json:"bytes"`
+
+	ByStatus map[string]int `json:"byStatus"`
+	ByGenre  map[string]int `json:"byGenre"`
+	ByYear   map[string]int `json:"byYear"` // "unknown" for series without a published year
+
+	Largest []SeriesSize `json:"largest"`
+
+	ComputedAt time.Time `json:"computedAt"`
+	DurationMs int64     `json:"durationMs"`
+}
+
+// SeriesSize is the on-disk footprint of one series
+type SeriesSize struct {
+	ID           string `json:"id"`
+	Title        string `json:"title"`
+	ChapterCount int    `json:"chapterCount"`
+	PageCount    int    `json:"pageCount"`
+	Bytes        int64  `json:"bytes"`
+}
+
+// ComputeStats gathers library statistics for the given series. Page counts
+// and sizes come from walking each series directory, so this is meant to be
+// run occasionally and cached rather than on every request.
+func (mm *MetadataManager) ComputeStats(mangas []MangaSeries) LibraryStats {
+	start := time.Now()
+	stats := LibraryStats{
+		SeriesCount: len(mangas),
+		ByStatus:    make(map[string]int),
+		ByGenre:     make(map[string]int),
+		ByYear:      make(map[string]int),
+	}
+
+	sizes := make([]SeriesSize, 0, len(mangas))
+	for i := range mangas {
+		manga := &mangas[i]
+
+		status := manga.Status
+		if status == "" {
+			status = "Unknown"
+		}
+		stats.ByStatus[status]++
+		for _, genre := range manga.Genres {
+			stats.ByGenre[genre]++
+		}
+		year := "unknown"
+		if manga.PublishedYear > 0 {
+			year = strconv.Itoa(manga.PublishedYear)
+		}
+		stats.ByYear[year]++
+
+		size := SeriesSize{ID: manga.ID, Title: manga.Title}
+		if chapters, err := mm.ScanForChapters(manga); err == nil {
+			size.ChapterCount = len(chapters)
+		}
+		size.PageCount, size.Bytes = directoryUsage(manga.Path)
+		sizes = append(sizes, size)
+
+		stats.ChapterCount += size.ChapterCount
+		stats.PageCount += size.PageCount
+		stats.Bytes += size.Bytes
+	}
+
+	sort.Slice(sizes, func(i, j int) bool { return sizes[i].Bytes > sizes[j].Bytes })
+	stats.Largest = sizes[:min(len(sizes), largestSeriesCount)]
+
+	stats.ComputedAt = time.Now().UTC()
+	stats.DurationMs = time.Since(start).Milliseconds()
+	logger.Info("Library statistics computed",
+		zap.Int("seriesCount", stats.SeriesCount),
+		zap.Duration("elapsed", time.Since(start)),
+	)
+	return stats
+}
+
+// directoryUsage counts the page images under a series directory, skipping
+// cover images at its top level, and sums the size of every file
+func directoryUsage(root string) (int, int64) {
+	pages := 0
+	var bytes int64
+	filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return nil
+		}
+		bytes += info.Size()
+		if filepath.Dir(path) != root && isImageExtension(filepath.Ext(path)) {
+			pages++
+		}
+		return nil
+	})
+	return pages, bytes
+}
@@ -0,0 +1,59 @@
+package models
+
+import (
+	"net/url"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// ImageURLPrefix is the URL path the library root is served under
+const ImageURLPrefix = "/manga-images"
+
+var (
+	libraryRootMu sync.RWMutex
+	libraryRoot   string
+)
+
+// setLibraryRoot registers the directory that ImageURLPrefix maps to
+func setLibraryRoot(root string) {
+	if abs, err := filepath.Abs(root); err == nil {
+		root = abs
+	}
+	libraryRootMu.Lock()
+	libraryRoot = root
+	libraryRootMu.Unlock()
+}
+
+// ImageURL returns the public URL of a file inside the library root. The path
+// is made relative to the root and every segment is URL-encoded, so names with
+// spaces or non-ASCII characters produce valid URLs. It returns "" for files
+// outside the library root.
+func ImageURL(path string) string {
+	libraryRootMu.RLock()
+	root := libraryRoot
+	libraryRootMu.RUnlock()
+	if root == "" {
+		return ""
+	}
+
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return ""
+	}
+	rel, err := filepath.Rel(root, absPath)
+	if err != nil || rel == "." || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return ""
+	}
+
+	return ImageURLPrefix + "/" + escapePathSegments(filepath.ToSlash(rel))
+}
+
+// escapePathSegments URL-encodes each segment of a slash-separated path
+func escapePathSegments(p string) string {
+	segments := strings.Split(p, "/")
+	for i, segment := range segments {
+		segments[i] = url.PathEscape(segment)
+	}
+	return strings.Join(segments, "/")
+}
@@ -0,0 +1,56 @@
+package models
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestPageGetImageURL(t *testing.T) {
+	root := t.TempDir()
+	setLibraryRoot(root)
+	t.Cleanup(func() { setLibraryRoot("") })
+
+	tests := []struct {
+		name string
+		page Page
+		want string
+	}{
+		{
+			name: "nested path",
+			page: Page{ImagePath: filepath.Join(root, "Berserk", "Chapter 1", "001.jpg")},
+			want: "/manga-images/Berserk/Chapter%201/001.jpg",
+		},
+		{
+			name: "spaces",
+			page: Page{ImagePath: filepath.Join(root, "One Piece", "Vol 1 Ch 2", "page 03.png")},
+			want: "/manga-images/One%20Piece/Vol%201%20Ch%202/page%2003.png",
+		},
+		{
+			name: "unicode",
+			page: Page{ImagePath: filepath.Join(root, "進撃の巨人", "第1話", "01.jpg")},
+			want: "/manga-images/%E9%80%B2%E6%92%83%E3%81%AE%E5%B7%A8%E4%BA%BA/%E7%AC%AC1%E8%A9%B1/01.jpg",
+		},
+		{
+			name: "reserved characters",
+			page: Page{ImagePath: filepath.Join(root, "100% Done", "#1?", "a&b.jpg")},
+			want: "/manga-images/100%25%20Done/%231%3F/a&b.jpg",
+		},
+		{
+			name: "outside the library root",
+			page: Page{
+				ImagePath: filepath.Join(filepath.Dir(root), "elsewhere", "Chapter 1", "001.jpg"),
+				MangaID:   "some manga",
+				ChapterID: "chapter-1",
+			},
+			want: "/manga-images/some%20manga/chapter-1/001.jpg",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.page.GetImageURL(); got != tt.want {
+				t.Errorf("GetImageURL() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,179 @@
+// Package naming renders the folder names that imported and uploaded series
+// and chapters are stored under, from admin-defined templates such as
+// "{series}" and "{series}[ v{volume:00}] c{chapter:000}".
+//
+// Placeholders are {series} (series title), {id} (series ID), {chapter}
+// (chapter number), {volume} and {title} (chapter title). A number can be
+// zero-padded with a width pattern, e.g. {chapter:000} renders chapter 7.5 as
+// "007.5". Text in square brackets is dropped when a placeholder in it is
+// empty, e.g. the volume of a chapter without one.
+package naming
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Values are what templates are rendered with
+type Values struct {
+	SeriesID    string
+	SeriesTitle string
+	Chapter     float64
+	Volume      int
+	Title       string
+}
+
+// seriesPlaceholders may appear in series templates; chapter templates may
+// use every placeholder
+var seriesPlaceholders = map[string]bool{"series": true, "id": true}
+
+var chapterPlaceholders = map[string]bool{"series": true, "id": true, "chapter": true, "volume": true, "title": true}
+
+// placeholderPattern matches {name} and {name:000}
+var placeholderPattern = regexp.MustCompile(`\{([a-z]+)(?::(0+))?\}`)
+
+// Scheme is a validated pair of templates. An empty template keeps the
+// default name chosen by the caller.
+type Scheme struct {
+	Series  string
+	Chapter string
+}
+
+// NewScheme validates the series and chapter folder templates
+func NewScheme(series, chapter string) (Scheme, error) {
+	if err := validate(series, seriesPlaceholders); err != nil {
+		return Scheme{}, fmt.Errorf("series folder template: %w", err)
+	}
+	if err := validate(chapter, chapterPlaceholders); err != nil {
+		return Scheme{}, fmt.Errorf("chapter folder template: %w", err)
+	}
+	return Scheme{Series: series, Chapter: chapter}, nil
+}
+
+// SeriesFolder renders the series folder name, or returns fallback when no
+// series template is set
+func (s Scheme) SeriesFolder(v Values, fallback string) (string, error) {
+	if s.Series == "" {
+		return fallback, nil
+	}
+	return Render(s.Series, v)
+}
+
+// ChapterFolder renders the chapter folder name, or returns fallback when no
+// chapter template is set
+func (s Scheme) ChapterFolder(v Values, fallback string) (string, error) {
+	if s.Chapter == "" {
+		return fallback, nil
+	}
+	return Render(s.Chapter, v)
+}
+
+func validate(template string, allowed map[string]bool) error {
+	if template == "" {
+		return nil
+	}
+	if strings.ContainsAny(template, `/\`) {
+		return fmt.Errorf("%q must be a single folder name", template)
+	}
+	if strings.Count(template, "[") != strings.Count(template, "]") {
+		return fmt.Errorf("%q has unbalanced brackets", template)
+	}
+	for _, m := range placeholderPattern.FindAllStringSubmatch(template, -1) {
+		if !allowed[m[1]] {
+			return fmt.Errorf("unknown placeholder {%s}", m[1])
+		}
+	}
+	rest := placeholderPattern.ReplaceAllString(template, "")
+	if strings.ContainsAny(rest, "{}") {
+		return fmt.Errorf("%q has a malformed placeholder", template)
+	}
+	return nil
+}
+
+// Render fills in a template, producing a name that is safe to use as a
+// single folder on common file systems
+func Render(template string, v Values) (string, error) {
+	original := template
+	var out strings.Builder
+	for template != "" {
+		open := strings.IndexByte(template, '[')
+		if open < 0 {
+			out.WriteString(fill(template, v, nil))
+			break
+		}
+		out.WriteString(fill(template[:open], v, nil))
+
+		end := strings.IndexByte(template[open:], ']')
+		if end < 0 {
+			return "", fmt.Errorf("unbalanced brackets in %q", original)
+		}
+		empty := false
+		section := fill(template[open+1:open+end], v, &empty)
+		if !empty {
+			out.WriteString(section)
+		}
+		template = template[open+end+1:]
+	}
+
+	name := sanitize(out.String())
+	if name == "" {
+		return "", fmt.Errorf("template %q renders an empty name", original)
+	}
+	return name, nil
+}
+
+// fill replaces the placeholders in text. If empty is non-nil it is set when
+// any placeholder renders empty.
+func fill(text string, v Values, empty *bool) string {
+	return placeholderPattern.ReplaceAllStringFunc(text, func(match string) string {
+		m := placeholderPattern.FindStringSubmatch(match)
+		width := len(m[2])
+
+		var value string
+		switch m[1] {
+		case "series":
+			value = v.SeriesTitle
+		case "id":
+			value = v.SeriesID
+		case "title":
+			value = v.Title
+		case "chapter":
+			value = pad(strconv.FormatFloat(v.Chapter, 'f', -1, 64), width)
+		case "volume":
+			if v.Volume > 0 {
+				value = pad(strconv.Itoa(v.Volume), width)
+			}
+		}
+		if value == "" && empty != nil {
+			*empty = true
+		}
+		return value
+	})
+}
+
+// pad zero-pads the integer part of a number to width digits
+func pad(number string, width int) string {
+	integer := number
+	if i := strings.IndexByte(number, '.'); i >= 0 {
+		integer = number[:i]
+	}
+	if missing := width - len(integer); missing > 0 {
+		return strings.Repeat("0", missing) + number
+	}
+	return number
+}
+
+// sanitize drops characters that aren't allowed in file names on common file
+// systems, collapses whitespace and trims leading and trailing dots and spaces
+func sanitize(name string) string {
+	name = strings.Map(func(r rune) rune {
+		if unicode.IsControl(r) || strings.ContainsRune(`/\:*?"<>|`, r) {
+			return -1
+		}
+		return r
+	}, name)
+	return strings.Trim(strings.Join(strings.Fields(name), " "), ". ")
+}
@@ -0,0 +1,55 @@
+package naming
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Chapter is what a chapter folder name says about the chapter
+type Chapter struct {
+	Number float64
+	Volume int    // 0 when not named
+	Title  string // Empty when not named
+}
+
+var (
+	// markedPattern matches "[series] [v|vol.|volume N] (c|ch.|chapter|#) N[.N] [- title]"
+	markedPattern = regexp.MustCompile(`(?i)^(?:.*?[\s.-])??(?:\bv(?:ol(?:ume)?)?\.?\s*(\d+)[\s.-]*)?(?:\bc(?:h(?:apter)?)?\.?|#)[\s-]*(\d+(?:\.\d+)?)(?:[\s.:)\]-]+(.*))?$`)
+
+	// barePattern matches "[series -] N[.N] [- title]"
+	barePattern = regexp.MustCompile(`^(?:.*?\s+-\s+)?(\d+(?:\.\d+)?)(?:\s+-\s+(.*))?$`)
+
+	// groupPattern matches bracketed notes such as scanlation group tags
+	groupPattern = regexp.MustCompile(`[\[(][^\])]*[\])]`)
+)
+
+// ParseChapter reads the chapter number, volume and title from a chapter
+// folder name following common conventions, e.g. "Ch. 10.5", "c001",
+// "chapter-12", "Vol.02 Ch.015 - Title", "Berserk v02 c012" or "012". It
+// reports false when the name has no recognizable chapter number.
+func ParseChapter(name string) (Chapter, bool) {
+	name = strings.TrimSpace(strings.ReplaceAll(name, "_", " "))
+
+	var numberText, volumeText, title string
+	if m := markedPattern.FindStringSubmatch(name); m != nil {
+		volumeText, numberText, title = m[1], m[2], m[3]
+	} else if m := barePattern.FindStringSubmatch(name); m != nil {
+		numberText, title = m[1], m[2]
+	} else {
+		return Chapter{}, false
+	}
+
+	number, err := strconv.ParseFloat(numberText, 64)
+	if err != nil || number < 0 {
+		return Chapter{}, false
+	}
+	chapter := Chapter{
+		Number: number,
+		Title:  strings.Trim(strings.Join(strings.Fields(groupPattern.ReplaceAllString(title, "")), " "), " .-:"),
+	}
+	if volumeText != "" {
+		chapter.Volume, _ = strconv.Atoi(volumeText)
+	}
+	return chapter, true
+}
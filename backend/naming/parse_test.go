@@ -0,0 +1,35 @@
+package naming
+
+import "testing"
+
+func TestParseChapter(t *testing.T) {
+	tests := []struct {
+		name   string
+		want   Chapter
+		wantOK bool
+	}{
+		{name: "Ch. 10.5", want: Chapter{Number: 10.5}, wantOK: true},
+		{name: "c001", want: Chapter{Number: 1}, wantOK: true},
+		{name: "Vol.02 Ch.015 - Title", want: Chapter{Number: 15, Volume: 2, Title: "Title"}, wantOK: true},
+		{name: "chapter-12", want: Chapter{Number: 12}, wantOK: true},
+		{name: "Berserk v02 c012", want: Chapter{Number: 12, Volume: 2}, wantOK: true},
+		{name: "012", want: Chapter{Number: 12}, wantOK: true},
+		{name: "7.5", want: Chapter{Number: 7.5}, wantOK: true},
+		{name: "Vol. 3", wantOK: false},
+		{name: "Volume 03", wantOK: false},
+		{name: "Extras", wantOK: false},
+		{name: "", wantOK: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := ParseChapter(tt.name)
+			if ok != tt.wantOK {
+				t.Fatalf("ParseChapter(%q) ok = %v, want %v", tt.name, ok, tt.wantOK)
+			}
+			if got != tt.want {
+				t.Errorf("ParseChapter(%q) = %+v, want %+v", tt.name, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,224 @@
+// Package pregen generates the image variants of chapters ahead of time, so
+// readers don't wait for resizing on their first page turns. Chapters are
+// queued with a priority and worked off by a fixed number of workers, which
+// step aside while the server is busy with requests.
+package pregen
+
+import (
+	"container/heap"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// logger discards output until SetLogger installs the configured logger
+var logger = zap.NewNop()
+
+// SetLogger sets the logger used by this package
+func SetLogger(l *zap.Logger) {
+	logger = l
+}
+
+// Priorities of queued chapters; higher ones are generated first
+const (
+	PriorityBackfill = iota // Warming up the whole library
+	PriorityNew             // Chapters just added
+	PriorityReading         // Chapters of series someone is reading
+)
+
+// busyPollInterval is how often a waiting worker checks whether the server
+// is still busy
+const busyPollInterval = 200 * time.Millisecond
+
+// Item is a chapter to generate the image variants of
+type Item struct {
+	MangaID     string `json:"mangaId"`
+	ChapterPath string `json:"chapterPath"`
+	Priority    int    `json:"priority"`
+
+	seq   uint64 // Order of arrival, so equal priorities go first come first served
+	index int    // Position in the heap
+}
+
+// Stats describes the state of a queue
+type Stats struct {
+	Workers int         `json:"workers"`
+	Queued  map[int]int `json:"queued"` // Waiting chapters by priority
+	Running []string    `json:"running"`
+	Done    int         `json:"done"`
+	Failed  int         `json:"failed"`
+}
+
+// Queue holds chapters waiting for their image variants
+type Queue struct {
+	workers int
+	run     func(Item) error
+	busy    func() bool
+
+	mu      sync.Mutex
+	items   itemHeap
+	byPath  map[string]*Item
+	running map[string]bool
+	seq     uint64
+	done    int
+	failed  int
+	wake    chan struct{}
+}
+
+// New creates a queue that generates chapters with run on the given number
+// of workers. While busy reports true workers don't start on a chapter's
+// next page; busy may be nil.
+func New(workers int, run func(Item) error, busy func() bool) *Queue {
+	return &Queue{
+		workers: workers,
+		run:     run,
+		busy:    busy,
+		byPath:  make(map[string]*Item),
+		running: make(map[string]bool),
+		wake:    make(chan struct{}, 1),
+	}
+}
+
+// Start launches the workers; they run until the process exits
+func (q *Queue) Start() {
+	for i := 0; i < q.workers; i++ {
+		go q.work()
+	}
+	logger.Info("Image pregeneration started", zap.Int("workers", q.workers))
+}
+
+// Add queues a chapter. A chapter already waiting keeps its place, moving up
+// if the new priority is higher; one being generated isn't queued again.
+func (q *Queue) Add(mangaID, chapterPath string, priority int) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if q.running[chapterPath] {
+		return
+	}
+	if item, ok := q.byPath[chapterPath]; ok {
+		if priority > item.Priority {
+			item.Priority = priority
+			heap.Fix(&q.items, item.index)
+		}
+		return
+	}
+	q.seq++
+	item := &Item{MangaID: mangaID, ChapterPath: chapterPath, Priority: priority, seq: q.seq}
+	heap.Push(&q.items, item)
+	q.byPath[chapterPath] = item
+
+	select {
+	case q.wake <- struct{}{}:
+	default:
+	}
+}
+
+// Stats reports what is queued and how much was generated
+func (q *Queue) Stats() Stats {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	stats := Stats{
+		Workers: q.workers,
+		Queued:  make(map[int]int),
+		Running: []string{},
+		Done:    q.done,
+		Failed:  q.failed,
+	}
+	for _, item := range q.items {
+		stats.Queued[item.Priority]++
+	}
+	for path := range q.running {
+		stats.Running = append(stats.Running, path)
+	}
+	return stats
+}
+
+// WaitWhileBusy blocks while the server is busy serving requests. Run
+// functions call it between pages, so a long chapter yields too.
+func (q *Queue) WaitWhileBusy() {
+	for q.busy != nil && q.busy() {
+		time.Sleep(busyPollInterval)
+	}
+}
+
+func (q *Queue) work() {
+	for {
+		item, ok := q.next()
+		if !ok {
+			<-q.wake
+			continue
+		}
+
+		q.WaitWhileBusy()
+		err := q.run(item)
+
+		q.mu.Lock()
+		delete(q.running, item.ChapterPath)
+		if err != nil {
+			q.failed++
+		} else {
+			q.done++
+		}
+		q.mu.Unlock()
+
+		if err != nil {
+			logger.Warn("Failed to pregenerate chapter images", zap.String("chapterPath", item.ChapterPath), zap.Error(err))
+		}
+	}
+}
+
+// next takes the most urgent chapter off the queue
+func (q *Queue) next() (Item, bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if len(q.items) == 0 {
+		return Item{}, false
+	}
+	item := heap.Pop(&q.items).(*Item)
+	delete(q.byPath, item.ChapterPath)
+	q.running[item.ChapterPath] = true
+	if len(q.items) > 0 {
+		// Pass the wake-up on to another idle worker
+		select {
+		case q.wake <- struct{}{}:
+		default:
+		}
+	}
+	return *item, true
+}
+
+// itemHeap orders items by priority, then by arrival
+type itemHeap []*Item
+
+func (h itemHeap) Len() int { return len(h) }
+
+func (h itemHeap) Less(i, j int) bool {
+	if h[i].Priority != h[j].Priority {
+		return h[i].Priority > h[j].Priority
+	}
+	return h[i].seq < h[j].seq
+}
+
+func (h itemHeap) Swap(i, j int) {
+	h[i], h[j] = h[j], h[i]
+	h[i].index = i
+	h[j].index = j
+}
+
+func (h *itemHeap) Push(x interface{}) {
+	item := x.(*Item)
+	item.index = len(*h)
+	*h = append(*h, item)
+}
+
+func (h *itemHeap) Pop() interface{} {
+	old := *h
+	item := old[len(old)-1]
+	old[len(old)-1] = nil
+	*h = old[:len(old)-1]
+	return item
+}
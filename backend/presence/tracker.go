@@ -0,0 +1,236 @@
+package presence
+
+import (
+	"encoding/json"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"go.uber.org/zap"
+)
+
+const (
+	// writeWait bounds how long a single message write may take
+	writeWait = 10 * time.Second
+
+	// pongWait is how long a connection may stay silent before it's dropped
+	pongWait = 60 * time.Second
+
+	// pingInterval must be shorter than pongWait
+	pingInterval = pongWait * 9 / 10
+
+	// maxMessageSize caps incoming messages; chapter changes are tiny
+	maxMessageSize = 512
+
+	// sendBuffer is how many outgoing messages may queue per connection
+	sendBuffer = 4
+)
+
+// logger discards output until SetLogger installs the configured logger
+var logger = zap.NewNop()
+
+// SetLogger sets the logger used by this package
+func SetLogger(l *zap.Logger) {
+	logger = l
+}
+
+// Counts is how many readers are on a series, and on the chapter a
+// connection is reading
+type Counts struct {
+	Readers        int `json:"readers"`
+	ChapterReaders int `json:"chapterReaders"`
+}
+
+// message is the envelope exchanged over the socket
+type message struct {
+	Type    string   `json:"type"`
+	Chapter *float64 `json:"chapter,omitempty"`
+	*Counts
+}
+
+// Message types
+const (
+	typeChapter = "chapter" // client -> server: now reading this chapter, or none
+	typeCount   = "count"   // server -> client: the counts changed
+)
+
+// Tracker counts the readers connected to each series and chapter. A
+// signed-in user with several tabs or devices open counts once; every guest
+// connection counts on its own.
+type Tracker struct {
+	mu      sync.Mutex
+	clients map[string]map[*client]struct{} // keyed by manga ID
+}
+
+// NewTracker creates an empty tracker
+func NewTracker() *Tracker {
+	return &Tracker{clients: make(map[string]map[*client]struct{})}
+}
+
+// client is one open WebSocket connection
+type client struct {
+	tracker *Tracker
+	conn    *websocket.Conn
+	viewer  string // user ID, empty for guests
+	mangaID string
+	chapter *float64
+	send    chan []byte
+}
+
+// Serve runs a connection for a reader of mangaID until it closes. viewer is
+// the user ID, or empty for a guest; chapter is the chapter being read, if
+// any. The client reports chapter changes and receives updated counts.
+func (t *Tracker) Serve(conn *websocket.Conn, viewer, mangaID string, chapter *float64) {
+	cl := &client{
+		tracker: t,
+		conn:    conn,
+		viewer:  viewer,
+		mangaID: mangaID,
+		chapter: chapter,
+		send:    make(chan []byte, sendBuffer),
+	}
+
+	t.mu.Lock()
+	if t.clients[mangaID] == nil {
+		t.clients[mangaID] = make(map[*client]struct{})
+	}
+	t.clients[mangaID][cl] = struct{}{}
+	t.broadcastLocked(mangaID)
+	t.mu.Unlock()
+
+	go cl.writePump()
+	cl.readPump()
+}
+
+// Readers returns how many readers are on a series
+func (t *Tracker) Readers(mangaID string) int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.countLocked(mangaID, nil)
+}
+
+// ChapterReaders returns how many readers are on a chapter of a series
+func (t *Tracker) ChapterReaders(mangaID string, chapter float64) int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.countLocked(mangaID, &chapter)
+}
+
+// countLocked counts the distinct readers of a series, or of one of its
+// chapters when chapter is set
+func (t *Tracker) countLocked(mangaID string, chapter *float64) int {
+	seen := make(map[string]bool)
+	n := 0
+	for cl := range t.clients[mangaID] {
+		if chapter != nil && (cl.chapter == nil || *cl.chapter != *chapter) {
+			continue
+		}
+		if cl.viewer == "" {
+			n++
+		} else if !seen[cl.viewer] {
+			seen[cl.viewer] = true
+			n++
+		}
+	}
+	return n
+}
+
+// broadcastLocked pushes the current counts to every connection on a series,
+// dropping the update for clients too slow to keep up; the next one
+// supersedes it anyway
+func (t *Tracker) broadcastLocked(mangaID string) {
+	readers := t.countLocked(mangaID, nil)
+	for cl := range t.clients[mangaID] {
+		counts := Counts{Readers: readers}
+		if cl.chapter != nil {
+			counts.ChapterReaders = t.countLocked(mangaID, cl.chapter)
+		}
+		data, err := json.Marshal(message{Type: typeCount, Counts: &counts})
+		if err != nil {
+			continue
+		}
+		select {
+		case cl.send <- data:
+		default:
+		}
+	}
+}
+
+// move records the chapter a connection is reading now
+func (t *Tracker) move(cl *client, chapter *float64) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	cl.chapter = chapter
+	t.broadcastLocked(cl.mangaID)
+}
+
+func (t *Tracker) remove(cl *client) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if readers, ok := t.clients[cl.mangaID]; ok {
+		if _, ok := readers[cl]; ok {
+			delete(readers, cl)
+			close(cl.send)
+		}
+		if len(readers) == 0 {
+			delete(t.clients, cl.mangaID)
+		} else {
+			t.broadcastLocked(cl.mangaID)
+		}
+	}
+}
+
+func (cl *client) readPump() {
+	defer func() {
+		cl.tracker.remove(cl)
+		cl.conn.Close()
+	}()
+
+	cl.conn.SetReadLimit(maxMessageSize)
+	cl.conn.SetReadDeadline(time.Now().Add(pongWait))
+	cl.conn.SetPongHandler(func(string) error {
+		return cl.conn.SetReadDeadline(time.Now().Add(pongWait))
+	})
+
+	for {
+		var msg message
+		if err := cl.conn.ReadJSON(&msg); err != nil {
+			if websocket.IsUnexpectedCloseError(err, websocket.CloseGoingAway, websocket.CloseNormalClosure) {
+				logger.Warn("Presence connection error", zap.Error(err))
+			}
+			return
+		}
+		if msg.Type != typeChapter {
+			continue
+		}
+		cl.tracker.move(cl, msg.Chapter)
+	}
+}
+
+func (cl *client) writePump() {
+	ticker := time.NewTicker(pingInterval)
+	defer func() {
+		ticker.Stop()
+		cl.conn.Close()
+	}()
+
+	for {
+		select {
+		case data, ok := <-cl.send:
+			cl.conn.SetWriteDeadline(time.Now().Add(writeWait))
+			if !ok {
+				cl.conn.WriteMessage(websocket.CloseMessage, []byte{})
+				return
+			}
+			if err := cl.conn.WriteMessage(websocket.TextMessage, data); err != nil {
+				return
+			}
+		case <-ticker.C:
+			cl.conn.SetWriteDeadline(time.Now().Add(writeWait))
+			if err := cl.conn.WriteMessage(websocket.PingMessage, nil); err != nil {
+				return
+			}
+		}
+	}
+}
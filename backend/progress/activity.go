@@ -0,0 +1,178 @@
+package progress
+
+import (
+	"path/filepath"
+	"sync"
+	"time"
+
+	"mangahub/backend/storage"
+
+	"go.uber.org/zap"
+)
+
+const activityFileName = "activity.json"
+
+// Activity keeps how many chapters each user finished per day, keyed by user
+// ID and then by UTC date (YYYY-MM-DD)
+type Activity struct {
+	path string
+
+	mu   sync.RWMutex
+	days map[string]map[string]int
+}
+
+// NewActivity loads the reading activity from dataDir
+func NewActivity(dataDir string) (*Activity, error) {
+	a := &Activity{path: filepath.Join(dataDir, activityFileName)}
+	if err := storage.LoadJSON(a.path, &a.days); err != nil {
+		return nil, err
+	}
+	if a.days == nil {
+		a.days = make(map[string]map[string]int)
+	}
+	logger.Info("Reading activity loaded", zap.Int("userCount", len(a.days)))
+	return a, nil
+}
+
+// Record adds chapters finished by a user at the given time
+func (a *Activity) Record(userID string, at time.Time, chapters int) error {
+	if chapters <= 0 {
+		return nil
+	}
+	day := at.UTC().Format(time.DateOnly)
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.days[userID] == nil {
+		a.days[userID] = make(map[string]int)
+	}
+	a.days[userID][day] += chapters
+
+	if err := storage.SaveJSON(a.path, a.days); err != nil {
+		logger.Error("Failed to save reading activity", zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+// Days returns how many chapters a user finished on each day they read,
+// keyed by UTC date
+func (a *Activity) Days(userID string) map[string]int {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	days := make(map[string]int, len(a.days[userID]))
+	for day, n := range a.days[userID] {
+		days[day] = n
+	}
+	return days
+}
+
+// DeleteUser forgets a user's reading activity
+func (a *Activity) DeleteUser(userID string) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	days, ok := a.days[userID]
+	if !ok {
+		return nil
+	}
+	delete(a.days, userID)
+	if err := storage.SaveJSON(a.path, a.days); err != nil {
+		a.days[userID] = days
+		logger.Error("Failed to save reading activity", zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+// Day is one cell of the activity calendar. Level buckets the count from 0
+// (nothing read) to 4 (one of the user's busiest days) for shading.
+type Day struct {
+	Date     string `json:"date"`
+	Chapters int    `json:"chapters"`
+	Level    int    `json:"level"`
+}
+
+// Stats summarizes a user's reading activity
+type Stats struct {
+	TotalChapters int    `json:"totalChapters"`
+	ActiveDays    int    `json:"activeDays"`
+	CurrentStreak int    `json:"currentStreak"` // Consecutive days read up to today, or yesterday
+	LongestStreak int    `json:"longestStreak"`
+	Since         string `json:"since"`    // First day of the calendar
+	Calendar      []Day  `json:"calendar"` // One entry per day, oldest first, ending today
+}
+
+// Stats summarizes a user's activity with a calendar of the last days days
+// up to today
+func (a *Activity) Stats(userID string, today time.Time, days int) Stats {
+	a.mu.RLock()
+	counts := make(map[string]int, len(a.days[userID]))
+	for day, n := range a.days[userID] {
+		counts[day] = n
+	}
+	a.mu.RUnlock()
+
+	var stats Stats
+	var dates []string
+	for day, n := range counts {
+		stats.TotalChapters += n
+		stats.ActiveDays++
+		dates = append(dates, day)
+	}
+	stats.LongestStreak = longestStreak(dates)
+
+	today = today.UTC()
+	for day := today; counts[day.Format(time.DateOnly)] > 0; day = day.AddDate(0, 0, -1) {
+		stats.CurrentStreak++
+	}
+	if stats.CurrentStreak == 0 {
+		// Today isn't over yet, so a streak running until yesterday still counts
+		for day := today.AddDate(0, 0, -1); counts[day.Format(time.DateOnly)] > 0; day = day.AddDate(0, 0, -1) {
+			stats.CurrentStreak++
+		}
+	}
+
+	start := today.AddDate(0, 0, 1-days)
+	busiest := 0
+	stats.Calendar = make([]Day, 0, days)
+	for day := start; !day.After(today); day = day.AddDate(0, 0, 1) {
+		date := day.Format(time.DateOnly)
+		stats.Calendar = append(stats.Calendar, Day{Date: date, Chapters: counts[date]})
+		busiest = max(busiest, counts[date])
+	}
+	for i := range stats.Calendar {
+		if n := stats.Calendar[i].Chapters; n > 0 {
+			stats.Calendar[i].Level = (n*4 + busiest - 1) / busiest
+		}
+	}
+	stats.Since = start.Format(time.DateOnly)
+	return stats
+}
+
+// longestStreak finds the longest run of consecutive dates
+func longestStreak(dates []string) int {
+	active := make(map[string]bool, len(dates))
+	for _, date := range dates {
+		active[date] = true
+	}
+	longest := 0
+	for _, date := range dates {
+		day, err := time.Parse(time.DateOnly, date)
+		if err != nil {
+			continue
+		}
+		// Only count from the first day of each run
+		if active[day.AddDate(0, 0, -1).Format(time.DateOnly)] {
+			continue
+		}
+		n := 0
+		for ; active[day.Format(time.DateOnly)]; day = day.AddDate(0, 0, 1) {
+			n++
+		}
+		longest = max(longest, n)
+	}
+	return longest
+}
@@ -0,0 +1,173 @@
+package progress
+
+import (
+	"path/filepath"
+	"slices"
+	"sort"
+	"sync"
+	"time"
+
+	"mangahub/backend/storage"
+
+	"go.uber.org/zap"
+)
+
+const progressFileName = "progress.json"
+
+// logger discards output until SetLogger installs the configured logger
+var logger = zap.NewNop()
+
+// SetLogger sets the logger used by this package
+func SetLogger(l *zap.Logger) {
+	logger = l
+}
+
+// Reading statuses a user can give a series, as on list trackers
+const (
+	StatusReading   = "reading"
+	StatusCompleted = "completed"
+	StatusOnHold    = "on-hold"
+	StatusDropped   = "dropped"
+	StatusPlanned   = "planned"
+)
+
+// Statuses are the accepted values of Entry.Status
+var Statuses = []string{StatusReading, StatusCompleted, StatusOnHold, StatusDropped, StatusPlanned}
+
+// Entry is how far a user has read in one series
+type Entry struct {
+	UserID    string    `json:"userId"`
+	MangaID   string    `json:"mangaId"`
+	Chapter   float64   `json:"chapter"`             // Chapter number of the furthest position
+	Page      int       `json:"page"`                // 1-based page within Chapter
+	Completed bool      `json:"completed,omitempty"` // The whole series has been read
+	Status    string    `json:"status,omitempty"`    // One of Statuses; empty when never set
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+// ValidStatus reports whether status is one of Statuses or empty
+func ValidStatus(status string) bool {
+	return status == "" || slices.Contains(Statuses, status)
+}
+
+// Store keeps reading progress in a JSON file in the data directory, one
+// entry per user and series
+type Store struct {
+	path string
+
+	mu      sync.RWMutex
+	entries []*Entry
+}
+
+// NewStore loads the progress store from dataDir
+func NewStore(dataDir string) (*Store, error) {
+	s := &Store{path: filepath.Join(dataDir, progressFileName)}
+	if err := storage.LoadJSON(s.path, &s.entries); err != nil {
+		return nil, err
+	}
+	logger.Info("Progress store loaded", zap.Int("entryCount", len(s.entries)))
+	return s, nil
+}
+
+// Get returns the user's progress in a series
+func (s *Store) Get(userID, mangaID string) (Entry, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if e := s.findLocked(userID, mangaID); e != nil {
+		return *e, true
+	}
+	return Entry{}, false
+}
+
+// List returns all of a user's progress, most recently updated first
+func (s *Store) List(userID string) []Entry {
+	s.mu.RLock()
+	var list []Entry
+	for _, e := range s.entries {
+		if e.UserID == userID {
+			list = append(list, *e)
+		}
+	}
+	s.mu.RUnlock()
+
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].UpdatedAt.After(list[j].UpdatedAt)
+	})
+	return list
+}
+
+// Set creates or replaces the user's progress in a series. A zero UpdatedAt
+// is stamped with the current time.
+func (s *Store) Set(entry Entry) (Entry, error) {
+	if entry.UpdatedAt.IsZero() {
+		entry.UpdatedAt = time.Now().UTC()
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if e := s.findLocked(entry.UserID, entry.MangaID); e != nil {
+		*e = entry
+	} else {
+		copied := entry
+		s.entries = append(s.entries, &copied)
+	}
+
+	if err := storage.SaveJSON(s.path, s.entries); err != nil {
+		logger.Error("Failed to save progress", zap.Error(err))
+		return Entry{}, err
+	}
+	return entry, nil
+}
+
+// Delete removes the user's progress in a series, reporting whether it existed
+func (s *Store) Delete(userID, mangaID string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i, e := range s.entries {
+		if e.UserID == userID && e.MangaID == mangaID {
+			s.entries = append(s.entries[:i], s.entries[i+1:]...)
+			if err := storage.SaveJSON(s.path, s.entries); err != nil {
+				logger.Error("Failed to save progress", zap.Error(err))
+				return false, err
+			}
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// DeleteUser removes all of a user's progress and returns how many series
+// it covered
+func (s *Store) DeleteUser(userID string) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	kept := s.entries[:0:0]
+	for _, e := range s.entries {
+		if e.UserID != userID {
+			kept = append(kept, e)
+		}
+	}
+	removed := len(s.entries) - len(kept)
+	if removed == 0 {
+		return 0, nil
+	}
+	if err := storage.SaveJSON(s.path, kept); err != nil {
+		logger.Error("Failed to save progress", zap.Error(err))
+		return 0, err
+	}
+	s.entries = kept
+	return removed, nil
+}
+
+func (s *Store) findLocked(userID, mangaID string) *Entry {
+	for _, e := range s.entries {
+		if e.UserID == userID && e.MangaID == mangaID {
+			return e
+		}
+	}
+	return nil
+}
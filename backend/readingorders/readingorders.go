@@ -0,0 +1,212 @@
+// Package readingorders stores curated reading orders: chapters from several
+// series, such as a franchise and its spin-offs, interleaved into the order
+// they are best read in.
+package readingorders
+
+import (
+	"errors"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"mangahub/backend/slug"
+	"mangahub/backend/storage"
+
+	"go.uber.org/zap"
+)
+
+const ordersFileName = "reading-orders.json"
+
+// ErrNotFound is returned for operations on a reading order that doesn't exist
+var ErrNotFound = errors.New("reading order not found")
+
+// logger discards output until SetLogger installs the configured logger
+var logger = zap.NewNop()
+
+// SetLogger sets the logger used by this package
+func SetLogger(l *zap.Logger) {
+	logger = l
+}
+
+// Entry is one chapter of a reading order
+type Entry struct {
+	MangaID string  `json:"mangaId"`
+	Chapter float64 `json:"chapter"`
+}
+
+// Order is a named sequence of chapters across series
+type Order struct {
+	ID        string    `json:"id"`
+	Name      string    `json:"name"`
+	Summary   string    `json:"summary,omitempty"`
+	Entries   []Entry   `json:"entries"`
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+// Position returns the index of a chapter in the order, or -1
+func (o *Order) Position(mangaID string, chapter float64) int {
+	for i, entry := range o.Entries {
+		if entry.MangaID == mangaID && entry.Chapter == chapter {
+			return i
+		}
+	}
+	return -1
+}
+
+// Store keeps reading orders in a JSON file in the data directory
+type Store struct {
+	path string
+
+	mu     sync.RWMutex
+	orders []*Order
+}
+
+// NewStore loads the reading order store from dataDir
+func NewStore(dataDir string) (*Store, error) {
+	s := &Store{path: filepath.Join(dataDir, ordersFileName)}
+	if err := storage.LoadJSON(s.path, &s.orders); err != nil {
+		return nil, err
+	}
+	logger.Info("Reading order store loaded", zap.Int("orderCount", len(s.orders)))
+	return s, nil
+}
+
+// List returns every reading order sorted by name
+func (s *Store) List() []Order {
+	s.mu.RLock()
+	list := make([]Order, 0, len(s.orders))
+	for _, o := range s.orders {
+		list = append(list, *o)
+	}
+	s.mu.RUnlock()
+
+	sort.Slice(list, func(i, j int) bool {
+		return strings.ToLower(list[i].Name) < strings.ToLower(list[j].Name)
+	})
+	return list
+}
+
+// Get returns one reading order
+func (s *Store) Get(id string) (Order, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if o := s.findLocked(id); o != nil {
+		return *o, nil
+	}
+	return Order{}, ErrNotFound
+}
+
+// Containing returns the reading orders a chapter is part of, sorted by name
+func (s *Store) Containing(mangaID string, chapter float64) []Order {
+	var list []Order
+	for _, o := range s.List() {
+		if o.Position(mangaID, chapter) >= 0 {
+			list = append(list, o)
+		}
+	}
+	return list
+}
+
+// Create adds a reading order, deriving its ID from the name
+func (s *Store) Create(name, summary string, entries []Entry) (Order, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	base := slug.Make(name)
+	if base == "" {
+		base = slug.Fallback("reading-order", name)
+	}
+	id := base
+	for n := 2; s.findLocked(id) != nil; n++ {
+		id = base + "-" + strconv.Itoa(n)
+	}
+
+	now := time.Now().UTC()
+	o := &Order{
+		ID:        id,
+		Name:      strings.TrimSpace(name),
+		Summary:   summary,
+		Entries:   dedupe(entries),
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	s.orders = append(s.orders, o)
+	if err := s.saveLocked(); err != nil {
+		s.orders = s.orders[:len(s.orders)-1]
+		return Order{}, err
+	}
+	logger.Info("Reading order created", zap.String("orderID", id), zap.Int("entryCount", len(o.Entries)))
+	return *o, nil
+}
+
+// Update replaces a reading order's name, summary and chapters
+func (s *Store) Update(id, name, summary string, entries []Entry) (Order, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	o := s.findLocked(id)
+	if o == nil {
+		return Order{}, ErrNotFound
+	}
+	previous := *o
+	o.Name = strings.TrimSpace(name)
+	o.Summary = summary
+	o.Entries = dedupe(entries)
+	o.UpdatedAt = time.Now().UTC()
+	if err := s.saveLocked(); err != nil {
+		*o = previous
+		return Order{}, err
+	}
+	return *o, nil
+}
+
+// Delete removes a reading order, reporting whether it existed
+func (s *Store) Delete(id string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i, o := range s.orders {
+		if o.ID == id {
+			s.orders = append(s.orders[:i], s.orders[i+1:]...)
+			return true, s.saveLocked()
+		}
+	}
+	return false, nil
+}
+
+func (s *Store) findLocked(id string) *Order {
+	for _, o := range s.orders {
+		if o.ID == id {
+			return o
+		}
+	}
+	return nil
+}
+
+func (s *Store) saveLocked() error {
+	if err := storage.SaveJSON(s.path, s.orders); err != nil {
+		logger.Error("Failed to save reading orders", zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+// dedupe drops entries without a series and repeated chapters, keeping the
+// first occurrence
+func dedupe(entries []Entry) []Entry {
+	seen := make(map[Entry]bool, len(entries))
+	result := []Entry{}
+	for _, entry := range entries {
+		if entry.MangaID == "" || seen[entry] {
+			continue
+		}
+		seen[entry] = true
+		result = append(result, entry)
+	}
+	return result
+}
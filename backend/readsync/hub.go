@@ -0,0 +1,234 @@
+package readsync
+
+import (
+	"encoding/json"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"go.uber.org/zap"
+)
+
+const (
+	// writeWait bounds how long a single message write may take
+	writeWait = 10 * time.Second
+
+	// pongWait is how long a connection may stay silent before it's dropped
+	pongWait = 60 * time.Second
+
+	// pingInterval must be shorter than pongWait
+	pingInterval = pongWait * 9 / 10
+
+	// maxMessageSize caps incoming messages; position updates are tiny
+	maxMessageSize = 4096
+
+	// sendBuffer is how many outgoing messages may queue per connection
+	sendBuffer = 16
+)
+
+// logger discards output until SetLogger installs the configured logger
+var logger = zap.NewNop()
+
+// SetLogger sets the logger used by this package
+func SetLogger(l *zap.Logger) {
+	logger = l
+}
+
+// Position is where a user is reading
+type Position struct {
+	MangaID   string    `json:"mangaId"`
+	Chapter   float64   `json:"chapter"`
+	Page      int       `json:"page"`
+	SessionID string    `json:"sessionId"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+// message is the envelope exchanged over the socket
+type message struct {
+	Type     string    `json:"type"`
+	Position *Position `json:"position,omitempty"`
+}
+
+// Message types
+const (
+	typePosition = "position" // client -> server: page turned; server -> client: another session moved
+	typeResume   = "resume"   // server -> client on connect: the latest known position
+)
+
+// Hub relays reading positions between the open sessions of each user, so a
+// page turn on one device is pushed to the user's other devices immediately
+type Hub struct {
+	mu        sync.Mutex
+	clients   map[string]map[*client]struct{} // keyed by user ID
+	positions map[string]Position             // latest position per user
+}
+
+// NewHub creates an empty hub
+func NewHub() *Hub {
+	return &Hub{
+		clients:   make(map[string]map[*client]struct{}),
+		positions: make(map[string]Position),
+	}
+}
+
+// client is one open WebSocket connection
+type client struct {
+	hub       *Hub
+	conn      *websocket.Conn
+	userID    string
+	sessionID string
+	send      chan []byte
+}
+
+// Serve runs a connection for userID until it closes. sessionID identifies the
+// device so it doesn't receive its own updates back.
+func (h *Hub) Serve(conn *websocket.Conn, userID, sessionID string) {
+	cl := &client{
+		hub:       h,
+		conn:      conn,
+		userID:    userID,
+		sessionID: sessionID,
+		send:      make(chan []byte, sendBuffer),
+	}
+
+	h.mu.Lock()
+	if h.clients[userID] == nil {
+		h.clients[userID] = make(map[*client]struct{})
+	}
+	h.clients[userID][cl] = struct{}{}
+	latest, hasLatest := h.positions[userID]
+	h.mu.Unlock()
+
+	logger.Info("Reading sync session connected",
+		zap.String("userID", userID),
+		zap.String("sessionID", sessionID),
+	)
+
+	if hasLatest {
+		cl.queue(message{Type: typeResume, Position: &latest})
+	}
+
+	go cl.writePump()
+	cl.readPump()
+}
+
+// Latest returns the most recent position reported for a user
+func (h *Hub) Latest(userID string) (Position, bool) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	pos, ok := h.positions[userID]
+	return pos, ok
+}
+
+// update records a position and pushes it to the user's other sessions
+func (h *Hub) update(from *client, pos Position) {
+	pos.SessionID = from.sessionID
+	pos.UpdatedAt = time.Now().UTC()
+
+	h.mu.Lock()
+	h.positions[from.userID] = pos
+	var targets []*client
+	for cl := range h.clients[from.userID] {
+		if cl != from {
+			targets = append(targets, cl)
+		}
+	}
+	h.mu.Unlock()
+
+	for _, cl := range targets {
+		cl.queue(message{Type: typePosition, Position: &pos})
+	}
+}
+
+func (h *Hub) remove(cl *client) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if sessions, ok := h.clients[cl.userID]; ok {
+		if _, ok := sessions[cl]; ok {
+			delete(sessions, cl)
+			close(cl.send)
+		}
+		if len(sessions) == 0 {
+			delete(h.clients, cl.userID)
+		}
+	}
+}
+
+// queue hands a message to the write pump, dropping it if the client is too
+// slow to keep up; only the latest position matters anyway
+func (cl *client) queue(msg message) {
+	data, err := json.Marshal(msg)
+	if err != nil {
+		return
+	}
+
+	cl.hub.mu.Lock()
+	defer cl.hub.mu.Unlock()
+	if _, open := cl.hub.clients[cl.userID][cl]; !open {
+		return
+	}
+	select {
+	case cl.send <- data:
+	default:
+		logger.Warn("Dropping reading sync message for slow session", zap.String("sessionID", cl.sessionID))
+	}
+}
+
+func (cl *client) readPump() {
+	defer func() {
+		cl.hub.remove(cl)
+		cl.conn.Close()
+		logger.Info("Reading sync session disconnected",
+			zap.String("userID", cl.userID),
+			zap.String("sessionID", cl.sessionID),
+		)
+	}()
+
+	cl.conn.SetReadLimit(maxMessageSize)
+	cl.conn.SetReadDeadline(time.Now().Add(pongWait))
+	cl.conn.SetPongHandler(func(string) error {
+		return cl.conn.SetReadDeadline(time.Now().Add(pongWait))
+	})
+
+	for {
+		var msg message
+		if err := cl.conn.ReadJSON(&msg); err != nil {
+			if websocket.IsUnexpectedCloseError(err, websocket.CloseGoingAway, websocket.CloseNormalClosure) {
+				logger.Warn("Reading sync connection error", zap.Error(err))
+			}
+			return
+		}
+		if msg.Type != typePosition || msg.Position == nil || msg.Position.MangaID == "" || msg.Position.Page < 1 {
+			continue
+		}
+		cl.hub.update(cl, *msg.Position)
+	}
+}
+
+func (cl *client) writePump() {
+	ticker := time.NewTicker(pingInterval)
+	defer func() {
+		ticker.Stop()
+		cl.conn.Close()
+	}()
+
+	for {
+		select {
+		case data, ok := <-cl.send:
+			cl.conn.SetWriteDeadline(time.Now().Add(writeWait))
+			if !ok {
+				cl.conn.WriteMessage(websocket.CloseMessage, []byte{})
+				return
+			}
+			if err := cl.conn.WriteMessage(websocket.TextMessage, data); err != nil {
+				return
+			}
+		case <-ticker.C:
+			cl.conn.SetWriteDeadline(time.Now().Add(writeWait))
+			if err := cl.conn.WriteMessage(websocket.PingMessage, nil); err != nil {
+				return
+			}
+		}
+	}
+}
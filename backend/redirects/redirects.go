@@ -0,0 +1,72 @@
+// Package redirects remembers the IDs of series that were merged into
+// others, so links to a removed series lead to the one that absorbed it.
+package redirects
+
+import (
+	"path/filepath"
+	"sync"
+
+	"mangahub/backend/storage"
+
+	"go.uber.org/zap"
+)
+
+const fileName = "series-redirects.json"
+
+// logger discards output until SetLogger installs the configured logger
+var logger = zap.NewNop()
+
+// SetLogger sets the logger used by this package
+func SetLogger(l *zap.Logger) {
+	logger = l
+}
+
+// Store maps removed series IDs to the series that replaced them
+type Store struct {
+	path string
+
+	mu      sync.RWMutex
+	targets map[string]string // removed ID -> current ID
+}
+
+// NewStore loads the redirects from dataDir
+func NewStore(dataDir string) (*Store, error) {
+	s := &Store{
+		path:    filepath.Join(dataDir, fileName),
+		targets: make(map[string]string),
+	}
+	if err := storage.LoadJSON(s.path, &s.targets); err != nil {
+		return nil, err
+	}
+	logger.Info("Series redirects loaded", zap.Int("redirectCount", len(s.targets)))
+	return s, nil
+}
+
+// Resolve returns the series a removed ID redirects to
+func (s *Store) Resolve(id string) (string, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	target, ok := s.targets[id]
+	return target, ok
+}
+
+// Add redirects from to to. Redirects pointing at from are updated so lookups
+// never need more than one hop, and a redirect away from to is dropped since
+// that ID is in use again.
+func (s *Store) Add(from, to string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.targets, to)
+	s.targets[from] = to
+	for id, target := range s.targets {
+		if target == from {
+			s.targets[id] = to
+		}
+	}
+	if err := storage.SaveJSON(s.path, s.targets); err != nil {
+		logger.Error("Failed to save series redirects", zap.Error(err))
+		return err
+	}
+	return nil
+}
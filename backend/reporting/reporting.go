@@ -0,0 +1,96 @@
+// Package reporting sends panics and server errors to a Sentry-compatible
+// service (Sentry, GlitchTip). It does nothing unless a DSN is configured.
+package reporting
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"mangahub/backend/config"
+
+	"github.com/getsentry/sentry-go"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// flushTimeout bounds how long Flush waits for queued events to be sent
+const flushTimeout = 2 * time.Second
+
+// logger discards output until SetLogger installs the configured logger
+var logger = zap.NewNop()
+
+// SetLogger sets the logger used by this package
+func SetLogger(l *zap.Logger) {
+	logger = l
+}
+
+var enabled bool
+
+// Init configures the reporter. With an empty DSN reporting stays disabled.
+func Init(cfg config.ReportingConfig) error {
+	if cfg.DSN == "" {
+		logger.Info("Error reporting disabled; no DSN configured")
+		return nil
+	}
+
+	err := sentry.Init(sentry.ClientOptions{
+		Dsn:         cfg.DSN,
+		Environment: cfg.Environment,
+		Release:     cfg.Release,
+		SampleRate:  cfg.SampleRate,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to initialize error reporting: %w", err)
+	}
+	enabled = true
+	logger.Info("Error reporting enabled", zap.String("environment", cfg.Environment))
+	return nil
+}
+
+// Flush waits briefly for queued events to be delivered, e.g. before exiting
+func Flush() {
+	if enabled {
+		sentry.Flush(flushTimeout)
+	}
+}
+
+// Middleware reports panics and 5xx responses with the request's context.
+// Panics are re-raised afterwards so gin.Recovery still writes the response;
+// register it after gin.Recovery.
+func Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !enabled {
+			c.Next()
+			return
+		}
+
+		hub := sentry.CurrentHub().Clone()
+		hub.Scope().SetRequest(c.Request)
+
+		defer func() {
+			if err := recover(); err != nil {
+				hub.RecoverWithContext(c.Request.Context(), err)
+				panic(err)
+			}
+		}()
+
+		c.Next()
+
+		status := c.Writer.Status()
+		if status < http.StatusInternalServerError {
+			return
+		}
+		hub.WithScope(func(scope *sentry.Scope) {
+			scope.SetTag("route", c.FullPath())
+			scope.SetTag("status", fmt.Sprint(status))
+			if len(c.Errors) > 0 {
+				for _, ginErr := range c.Errors {
+					hub.CaptureException(ginErr.Err)
+				}
+				return
+			}
+			hub.CaptureMessage(fmt.Sprintf("%d %s %s", status, c.Request.Method, c.FullPath()))
+		})
+	}
+}
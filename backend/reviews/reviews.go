@@ -0,0 +1,213 @@
+package reviews
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"mangahub/backend/storage"
+
+	"go.uber.org/zap"
+)
+
+const (
+	reviewsFileName = "reviews.json"
+
+	// MinRating and MaxRating bound the score a user can give a series
+	MinRating = 1
+	MaxRating = 10
+)
+
+// logger discards output until SetLogger installs the configured logger
+var logger = zap.NewNop()
+
+// SetLogger sets the logger used by this package
+func SetLogger(l *zap.Logger) {
+	logger = l
+}
+
+// Review is a user's rating of a series with an optional written review
+type Review struct {
+	ID        string    `json:"id"`
+	MangaID   string    `json:"mangaId"`
+	UserID    string    `json:"userId"`
+	Username  string    `json:"username"`
+	Rating    int       `json:"rating"`
+	Body      string    `json:"body,omitempty"`
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+// Summary is the aggregate rating of a series
+type Summary struct {
+	Average float64 `json:"average"`
+	Count   int     `json:"count"`
+}
+
+// Store keeps reviews in a JSON file in the data directory. Each user has at
+// most one review per series.
+type Store struct {
+	path string
+
+	mu      sync.RWMutex
+	reviews []*Review
+}
+
+// NewStore loads the review store from dataDir
+func NewStore(dataDir string) (*Store, error) {
+	s := &Store{path: filepath.Join(dataDir, reviewsFileName)}
+	if err := storage.LoadJSON(s.path, &s.reviews); err != nil {
+		return nil, err
+	}
+	logger.Info("Review store loaded", zap.Int("reviewCount", len(s.reviews)))
+	return s, nil
+}
+
+// Upsert creates or replaces the user's review of a series
+func (s *Store) Upsert(mangaID, userID, username string, rating int, body string) (*Review, error) {
+	if rating < MinRating || rating > MaxRating {
+		return nil, fmt.Errorf("rating must be between %d and %d", MinRating, MaxRating)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now().UTC()
+	review := s.findLocked(mangaID, userID)
+	if review == nil {
+		review = &Review{
+			ID:        fmt.Sprintf("%s-%s", mangaID, userID),
+			MangaID:   mangaID,
+			UserID:    userID,
+			CreatedAt: now,
+		}
+		s.reviews = append(s.reviews, review)
+	}
+	review.Username = username
+	review.Rating = rating
+	review.Body = body
+	review.UpdatedAt = now
+
+	if err := storage.SaveJSON(s.path, s.reviews); err != nil {
+		logger.Error("Failed to save reviews", zap.Error(err))
+		return nil, err
+	}
+
+	copied := *review
+	return &copied, nil
+}
+
+// Delete removes the user's review of a series, reporting whether one existed
+func (s *Store) Delete(mangaID, userID string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i, r := range s.reviews {
+		if r.MangaID == mangaID && r.UserID == userID {
+			s.reviews = append(s.reviews[:i], s.reviews[i+1:]...)
+			if err := storage.SaveJSON(s.path, s.reviews); err != nil {
+				logger.Error("Failed to save reviews", zap.Error(err))
+				return false, err
+			}
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// ListByManga returns a page of a series' reviews, newest first, plus the total
+func (s *Store) ListByManga(mangaID string, offset, limit int) ([]Review, int) {
+	s.mu.RLock()
+	var matching []Review
+	for _, r := range s.reviews {
+		if r.MangaID == mangaID {
+			matching = append(matching, *r)
+		}
+	}
+	s.mu.RUnlock()
+
+	sort.Slice(matching, func(i, j int) bool {
+		return matching[i].UpdatedAt.After(matching[j].UpdatedAt)
+	})
+
+	total := len(matching)
+	if offset >= total {
+		return []Review{}, total
+	}
+	end := min(offset+limit, total)
+	return matching[offset:end], total
+}
+
+// ListByUser returns every review a user wrote, newest first
+func (s *Store) ListByUser(userID string) []Review {
+	s.mu.RLock()
+	list := []Review{}
+	for _, r := range s.reviews {
+		if r.UserID == userID {
+			list = append(list, *r)
+		}
+	}
+	s.mu.RUnlock()
+
+	sort.Slice(list, func(i, j int) bool { return list[i].UpdatedAt.After(list[j].UpdatedAt) })
+	return list
+}
+
+// DeleteByUser removes every review a user wrote and returns how many there were
+func (s *Store) DeleteByUser(userID string) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	kept := s.reviews[:0:0]
+	for _, r := range s.reviews {
+		if r.UserID != userID {
+			kept = append(kept, r)
+		}
+	}
+	removed := len(s.reviews) - len(kept)
+	if removed == 0 {
+		return 0, nil
+	}
+	if err := storage.SaveJSON(s.path, kept); err != nil {
+		logger.Error("Failed to save reviews", zap.Error(err))
+		return 0, err
+	}
+	s.reviews = kept
+	return removed, nil
+}
+
+// Summaries returns the aggregate rating of every reviewed series
+func (s *Store) Summaries() map[string]Summary {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	sums := make(map[string]int)
+	summaries := make(map[string]Summary)
+	for _, r := range s.reviews {
+		sums[r.MangaID] += r.Rating
+		summary := summaries[r.MangaID]
+		summary.Count++
+		summaries[r.MangaID] = summary
+	}
+	for id, summary := range summaries {
+		summary.Average = float64(sums[id]) / float64(summary.Count)
+		summaries[id] = summary
+	}
+	return summaries
+}
+
+// SummaryFor returns the aggregate rating of one series
+func (s *Store) SummaryFor(mangaID string) Summary {
+	return s.Summaries()[mangaID]
+}
+
+func (s *Store) findLocked(mangaID, userID string) *Review {
+	for _, r := range s.reviews {
+		if r.MangaID == mangaID && r.UserID == userID {
+			return r
+		}
+	}
+	return nil
+}
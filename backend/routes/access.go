@@ -0,0 +1,162 @@
+package routes
+
+import (
+	"fmt"
+	"mangahub/backend/geoip"
+	"net/http"
+	"net/netip"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// Route groups access rules can be set for
+const (
+	AccessGroupAll    = "all"    // Every request
+	AccessGroupAPI    = "api"    // /api, including the admin API
+	AccessGroupAdmin  = "admin"  // /api/admin
+	AccessGroupKobo   = "kobo"   // The Kobo sync endpoints
+	AccessGroupImages = "images" // Page images served straight from the library
+)
+
+// AccessRule limits a route group by client address. Deny wins over allow.
+// When any allow list is set, only addresses on it, or in one of its
+// countries, get through; addresses without a country, such as LAN ones,
+// only pass through Allow.
+type AccessRule struct {
+	Allow          []string // Addresses or CIDR ranges
+	Deny           []string
+	AllowCountries []string // ISO 3166-1 codes, e.g. "JP"
+	DenyCountries  []string
+}
+
+// accessRule is an AccessRule with its addresses parsed
+type accessRule struct {
+	allow, deny                   []netip.Prefix
+	allowCountries, denyCountries map[string]bool
+}
+
+var (
+	accessRules = map[string]*accessRule{}
+
+	// countryDB looks up countries for country rules; nil when none are set
+	countryDB *geoip.DB
+)
+
+// SetAccessRules sets the access rules of route groups. Country rules need
+// a MaxMind country database.
+func SetAccessRules(rules map[string]AccessRule, countries *geoip.DB) error {
+	compiled := make(map[string]*accessRule, len(rules))
+	for group, rule := range rules {
+		switch group {
+		case AccessGroupAll, AccessGroupAPI, AccessGroupAdmin, AccessGroupKobo, AccessGroupImages:
+		default:
+			return fmt.Errorf("unknown route group %q", group)
+		}
+		r, err := compileAccessRule(rule)
+		if err != nil {
+			return fmt.Errorf("%s: %w", group, err)
+		}
+		if (len(r.allowCountries) > 0 || len(r.denyCountries) > 0) && countries == nil {
+			return fmt.Errorf("%s: country rules need a GeoIP database", group)
+		}
+		compiled[group] = r
+	}
+	accessRules = compiled
+	countryDB = countries
+	return nil
+}
+
+func compileAccessRule(rule AccessRule) (*accessRule, error) {
+	r := &accessRule{
+		allowCountries: countrySet(rule.AllowCountries),
+		denyCountries:  countrySet(rule.DenyCountries),
+	}
+	var err error
+	if r.allow, err = parsePrefixes(rule.Allow); err != nil {
+		return nil, err
+	}
+	if r.deny, err = parsePrefixes(rule.Deny); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// parsePrefixes parses CIDR ranges and single addresses
+func parsePrefixes(list []string) ([]netip.Prefix, error) {
+	prefixes := make([]netip.Prefix, 0, len(list))
+	for _, s := range list {
+		s = strings.TrimSpace(s)
+		if !strings.Contains(s, "/") {
+			addr, err := netip.ParseAddr(s)
+			if err != nil {
+				return nil, fmt.Errorf("invalid address %q", s)
+			}
+			prefixes = append(prefixes, netip.PrefixFrom(addr.Unmap(), addr.Unmap().BitLen()))
+			continue
+		}
+		prefix, err := netip.ParsePrefix(s)
+		if err != nil {
+			return nil, fmt.Errorf("invalid CIDR range %q", s)
+		}
+		prefixes = append(prefixes, prefix.Masked())
+	}
+	return prefixes, nil
+}
+
+func countrySet(codes []string) map[string]bool {
+	set := make(map[string]bool, len(codes))
+	for _, code := range codes {
+		set[strings.ToUpper(strings.TrimSpace(code))] = true
+	}
+	return set
+}
+
+func containsAddr(prefixes []netip.Prefix, addr netip.Addr) bool {
+	for _, prefix := range prefixes {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+// permits reports whether the rule lets a client address through
+func (r *accessRule) permits(addr netip.Addr) bool {
+	if containsAddr(r.deny, addr) {
+		return false
+	}
+	country := ""
+	if countryDB != nil && (len(r.allowCountries) > 0 || len(r.denyCountries) > 0) {
+		country = countryDB.Country(addr)
+	}
+	if country != "" && r.denyCountries[country] {
+		return false
+	}
+	if len(r.allow) == 0 && len(r.allowCountries) == 0 {
+		return true
+	}
+	return containsAddr(r.allow, addr) || (country != "" && r.allowCountries[country])
+}
+
+// AccessGate enforces the access rule of a route group, if it has one
+func AccessGate(group string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		rule, ok := accessRules[group]
+		if !ok {
+			c.Next()
+			return
+		}
+		addr, err := netip.ParseAddr(c.ClientIP())
+		if err != nil || !rule.permits(addr.Unmap()) {
+			zapLogger.Warn("Rejected request by access rule",
+				zap.String("group", group),
+				zap.String("clientIP", c.ClientIP()),
+				zap.String("path", c.Request.URL.Path))
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "Access from your address is not allowed"})
+			return
+		}
+		c.Next()
+	}
+}
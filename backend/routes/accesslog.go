@@ -0,0 +1,34 @@
+package routes
+
+import (
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// AccessLog records every request once it is handled, to sink when it is
+// set and to the application logger fallback otherwise
+func AccessLog(sink, fallback *zap.Logger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		startTime := time.Now()
+		c.Next()
+
+		fields := []zap.Field{
+			zap.String("method", c.Request.Method),
+			zap.String("path", c.Request.URL.Path),
+			zap.Int("status", c.Writer.Status()),
+			zap.Int("bytes", max(c.Writer.Size(), 0)),
+			zap.Duration("latency", time.Since(startTime)),
+			zap.String("clientIP", c.ClientIP()),
+		}
+		if user := currentUser(c); user != nil {
+			fields = append(fields, zap.String("user", user.Username))
+		}
+		if sink != nil {
+			sink.Info("", fields...)
+			return
+		}
+		fallback.Info("HTTP request", fields...)
+	}
+}
@@ -0,0 +1,107 @@
+package routes
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// exportUserData returns everything the server keeps about the signed-in
+// user as a JSON file download: the account, reader settings, reading
+// progress and activity, reviews, sessions, API tokens (without secrets) and
+// personal library series
+func exportUserData(c *gin.Context) {
+	user := currentUser(c)
+	zapLogger.Info("exportUserData handler called", zap.String("userID", user.ID))
+
+	tokens := []gin.H{}
+	for _, token := range userStore.APITokens(user.ID) {
+		tokens = append(tokens, apiTokenResponse(token))
+	}
+	library := []gin.H{}
+	for _, manga := range ownedSeries(user.ID) {
+		library = append(library, gin.H{"id": manga.ID, "title": manga.Title, "chapterCount": manga.ChapterCount})
+	}
+
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", "mangahub-export-"+user.Username+".json"))
+	c.IndentedJSON(http.StatusOK, gin.H{
+		"exportedAt":      timeNow(),
+		"account":         user.Public(),
+		"preferences":     userStore.Preferences(user.ID),
+		"progress":        progressStore.List(user.ID),
+		"readingActivity": activityStore.Days(user.ID),
+		"reviews":         reviewStore.ListByUser(user.ID),
+		"sessions":        userStore.Sessions(user.ID),
+		"apiTokens":       tokens,
+		"library":         library,
+	})
+}
+
+// deleteAccount deletes the signed-in user's account after checking their
+// password, and purges their progress, activity, reviews and personal
+// library series with it
+func deleteAccount(c *gin.Context) {
+	user := currentUser(c)
+	zapLogger.Info("deleteAccount handler called", zap.String("userID", user.ID))
+
+	var request struct {
+		Password string `json:"password" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&request); err != nil {
+		zapLogger.Warn("Invalid request data", zap.Error(err))
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request: " + err.Error()})
+		return
+	}
+	if _, err := userStore.Authenticate(user.Username, request.Password); err != nil {
+		respondUserError(c, err)
+		return
+	}
+
+	// Take the account itself first, so nothing is purged for an account
+	// that stays, such as the last admin
+	if err := userStore.Delete(user.ID); err != nil {
+		respondUserError(c, err)
+		return
+	}
+
+	purged := gin.H{}
+	failed := false
+	if n, err := progressStore.DeleteUser(user.ID); err != nil {
+		zapLogger.Error("Failed to delete progress", zap.String("userID", user.ID), zap.Error(err))
+		failed = true
+	} else {
+		purged["progress"] = n
+	}
+	if err := activityStore.DeleteUser(user.ID); err != nil {
+		zapLogger.Error("Failed to delete reading activity", zap.String("userID", user.ID), zap.Error(err))
+		failed = true
+	}
+	if n, err := reviewStore.DeleteByUser(user.ID); err != nil {
+		zapLogger.Error("Failed to delete reviews", zap.String("userID", user.ID), zap.Error(err))
+		failed = true
+	} else {
+		purged["reviews"] = n
+	}
+	series := 0
+	for _, manga := range ownedSeries(user.ID) {
+		if err := os.RemoveAll(manga.Path); err != nil {
+			zapLogger.Error("Failed to delete manga directory", zap.String("mangaPath", manga.Path), zap.Error(err))
+			failed = true
+			continue
+		}
+		libraryIndex.Refresh(manga.Path)
+		series++
+	}
+	purged["series"] = series
+
+	c.SetCookie(sessionCookieName, "", -1, "/", "", false, true)
+	setCSRFCookie(c, "", -1)
+	if failed {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Account deleted, but some of its data could not be removed", "purged": purged})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"status": "deleted", "id": user.ID, "purged": purged})
+}
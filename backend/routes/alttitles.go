@@ -0,0 +1,81 @@
+package routes
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// addAltTitles adds alternative titles to a series, skipping ones it already
+// has (compared case-insensitively)
+func addAltTitles(c *gin.Context) {
+	mangaID := c.Param("id")
+	zapLogger.Info("addAltTitles handler called", zap.String("mangaID", mangaID))
+
+	var request struct {
+		Titles []string `json:"titles" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&request); err != nil {
+		zapLogger.Warn("Invalid request data", zap.Error(err))
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request: " + err.Error()})
+		return
+	}
+
+	manga, ok := lookupManga(c, mangaID)
+	if !ok {
+		return
+	}
+
+	for _, title := range request.Titles {
+		title = strings.TrimSpace(title)
+		if title == "" || equalIgnoreCase(title, manga.Title) || hasAltTitle(manga.AltTitles, title) {
+			continue
+		}
+		manga.AltTitles = append(manga.AltTitles, title)
+	}
+
+	// saveManga refreshes the index entry, so the new titles are searchable right away
+	if !saveManga(c, manga) {
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"id": manga.ID, "altTitles": manga.AltTitles})
+}
+
+// removeAltTitle removes an alternative title from a series
+func removeAltTitle(c *gin.Context) {
+	mangaID := c.Param("id")
+	title := c.Param("title")
+	zapLogger.Info("removeAltTitle handler called", zap.String("mangaID", mangaID), zap.String("title", title))
+
+	manga, ok := lookupManga(c, mangaID)
+	if !ok {
+		return
+	}
+	if !hasAltTitle(manga.AltTitles, title) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Alternative title not found"})
+		return
+	}
+
+	var remaining []string
+	for _, alt := range manga.AltTitles {
+		if !equalIgnoreCase(alt, title) {
+			remaining = append(remaining, alt)
+		}
+	}
+	manga.AltTitles = remaining
+	if !saveManga(c, manga) {
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"id": manga.ID, "altTitles": manga.AltTitles})
+}
+
+func hasAltTitle(altTitles []string, title string) bool {
+	for _, alt := range altTitles {
+		if equalIgnoreCase(alt, title) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,118 @@
+package routes
+
+import (
+	"mangahub/backend/users"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// apiTokenContextKey is where authenticate stores the personal API token a
+// request was made with
+const apiTokenContextKey = "apiToken"
+
+// progressRoutes are the routes the progress scope grants
+var progressRoutes = []string{"/api/user/progress", "/api/user/sync", "/api/user/position"}
+
+// currentAPIToken returns the personal API token of the request, or nil for
+// requests made with a login session or anonymously
+func currentAPIToken(c *gin.Context) *users.APIToken {
+	if value, ok := c.Get(apiTokenContextKey); ok {
+		token := value.(users.APIToken)
+		return &token
+	}
+	return nil
+}
+
+// tokenAllows reports whether a personal API token grants a request. Tokens
+// never reach admin routes or account settings, such as minting more tokens.
+func tokenAllows(token *users.APIToken, method, path string) bool {
+	for _, route := range progressRoutes {
+		if strings.HasPrefix(path, route) {
+			return token.HasScope(users.ScopeProgress)
+		}
+	}
+	switch {
+	case path == "/api/auth/me":
+		return true
+	case strings.HasPrefix(path, "/api/admin/"), strings.HasPrefix(path, "/api/auth/"):
+		return false
+	case strings.HasPrefix(path, "/api/user/") && path != "/api/user/preferences":
+		return false
+	case method == http.MethodGet || method == http.MethodHead || path == "/api/manga/batch":
+		return token.HasScope(users.ScopeRead)
+	}
+	return false
+}
+
+// enforceTokenScopes rejects requests a personal API token doesn't grant
+func enforceTokenScopes(c *gin.Context) {
+	if token := currentAPIToken(c); token != nil && !tokenAllows(token, c.Request.Method, c.FullPath()) {
+		c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "This API token does not grant access to " + c.FullPath()})
+		return
+	}
+	c.Next()
+}
+
+// listAPITokens returns the current user's personal tokens, without secrets
+func listAPITokens(c *gin.Context) {
+	response := []gin.H{}
+	for _, token := range userStore.APITokens(currentUser(c).ID) {
+		response = append(response, apiTokenResponse(token))
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+// createAPIToken mints a personal token. Its secret is only in this response.
+func createAPIToken(c *gin.Context) {
+	user := currentUser(c)
+	zapLogger.Info("createAPIToken handler called", zap.String("userID", user.ID))
+
+	var request struct {
+		Name          string   `json:"name" binding:"required"`
+		Scopes        []string `json:"scopes" binding:"required"`
+		ExpiresInDays int      `json:"expiresInDays"` // 0 never expires
+	}
+	if err := c.ShouldBindJSON(&request); err != nil {
+		zapLogger.Warn("Invalid request data", zap.Error(err))
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request: " + err.Error()})
+		return
+	}
+
+	ttl := time.Duration(request.ExpiresInDays) * 24 * time.Hour
+	token, secret, err := userStore.CreateAPIToken(user.ID, request.Name, request.Scopes, ttl)
+	if err != nil {
+		respondUserError(c, err)
+		return
+	}
+	response := apiTokenResponse(token)
+	response["token"] = secret
+	c.JSON(http.StatusCreated, response)
+}
+
+// revokeAPIToken deletes one of the current user's personal tokens
+func revokeAPIToken(c *gin.Context) {
+	user := currentUser(c)
+	tokenID := c.Param("id")
+	zapLogger.Info("revokeAPIToken handler called", zap.String("userID", user.ID), zap.String("tokenID", tokenID))
+
+	if err := userStore.RevokeAPIToken(user.ID, tokenID); err != nil {
+		respondUserError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"status": "revoked", "id": tokenID})
+}
+
+func apiTokenResponse(token users.APIToken) gin.H {
+	return gin.H{
+		"id":        token.ID,
+		"name":      token.Name,
+		"scopes":    token.Scopes,
+		"createdAt": token.CreatedAt,
+		"lastUsed":  token.LastUsed,
+		"expiresAt": token.ExpiresAt,
+	}
+}
@@ -0,0 +1,221 @@
+package routes
+
+import (
+	"mangahub/backend/sharetokens"
+	"mangahub/backend/users"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+const (
+	// sessionCookieName carries the login token for browser clients
+	sessionCookieName = "mangahub_session"
+
+	// userContextKey is where authenticate stores the current user
+	userContextKey = "user"
+
+	// sessionContextKey is where authenticate stores the current session
+	sessionContextKey = "session"
+)
+
+// authenticate resolves the login token from the Authorization header or the
+// session cookie and stores the user in the context. Requests without a valid
+// token continue anonymously.
+func authenticate(c *gin.Context) {
+	resolveUser(c)
+	c.Next()
+}
+
+// resolveUser stores the user owning the request's login token or personal
+// API token, and the share token the request carries, if any, in the context
+func resolveUser(c *gin.Context) {
+	resolveShareToken(c)
+
+	token := bearerToken(c)
+	if token == "" {
+		if cookie, err := c.Cookie(sessionCookieName); err == nil {
+			token = cookie
+		}
+	}
+
+	if strings.HasPrefix(token, users.APITokenPrefix) {
+		if user, apiToken, err := userStore.UserForAPIToken(token); err == nil {
+			c.Set(userContextKey, user)
+			c.Set(apiTokenContextKey, apiToken)
+		} else {
+			zapLogger.Debug("Ignoring invalid API token", zap.Error(err))
+		}
+	} else if token != "" && !strings.HasPrefix(token, sharetokens.Prefix) {
+		if user, session, err := userStore.UserForToken(token, c.ClientIP()); err == nil {
+			c.Set(userContextKey, user)
+			c.Set(sessionContextKey, session)
+		} else {
+			zapLogger.Debug("Ignoring invalid token", zap.Error(err))
+		}
+	}
+}
+
+// requireUser rejects anonymous requests
+func requireUser(c *gin.Context) {
+	if currentUser(c) == nil {
+		c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Authentication required"})
+		return
+	}
+	c.Next()
+}
+
+// requireAdmin rejects anonymous requests and those of users without the
+// admin role
+func requireAdmin(c *gin.Context) {
+	user := currentUser(c)
+	if user == nil {
+		c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Authentication required"})
+		return
+	}
+	if !user.IsAdmin() {
+		c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "Admin role required"})
+		return
+	}
+	c.Next()
+}
+
+// currentUser returns the authenticated user, or nil for anonymous requests
+func currentUser(c *gin.Context) *users.User {
+	if value, ok := c.Get(userContextKey); ok {
+		return value.(*users.User)
+	}
+	return nil
+}
+
+// currentSession returns the session of the login token, or nil for
+// anonymous requests
+func currentSession(c *gin.Context) *users.Session {
+	if value, ok := c.Get(sessionContextKey); ok {
+		session := value.(users.Session)
+		return &session
+	}
+	return nil
+}
+
+func bearerToken(c *gin.Context) string {
+	header := c.GetHeader("Authorization")
+	if token, ok := strings.CutPrefix(header, "Bearer "); ok {
+		return strings.TrimSpace(token)
+	}
+	return ""
+}
+
+// register creates an account and logs it in
+func register(c *gin.Context) {
+	zapLogger.Info("register handler called")
+
+	var request struct {
+		Username string `json:"username" binding:"required"`
+		Password string `json:"password" binding:"required"`
+		Email    string `json:"email"`
+		Invite   string `json:"invite"` // Required while registration is invite-only
+	}
+	if err := c.ShouldBindJSON(&request); err != nil {
+		zapLogger.Warn("Invalid request data", zap.Error(err))
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request: " + err.Error()})
+		return
+	}
+
+	user, err := userStore.Register(request.Username, request.Password, request.Email, request.Invite)
+	if err != nil {
+		respondUserError(c, err)
+		return
+	}
+	if verifyEmail && user.Email != "" && accountMailEnabled() {
+		if err := sendVerification(user); err != nil {
+			// The account works regardless; the user can ask for a new link
+			zapLogger.Warn("Failed to send verification email", zap.String("userID", user.ID), zap.Error(err))
+		}
+	}
+
+	issueSession(c, user, http.StatusCreated)
+}
+
+// login exchanges a username and password for a token
+func login(c *gin.Context) {
+	zapLogger.Info("login handler called")
+
+	var request struct {
+		Username string `json:"username" binding:"required"`
+		Password string `json:"password" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&request); err != nil {
+		zapLogger.Warn("Invalid request data", zap.Error(err))
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request: " + err.Error()})
+		return
+	}
+
+	user, err := userStore.Authenticate(request.Username, request.Password)
+	if err != nil {
+		respondUserError(c, err)
+		return
+	}
+
+	issueSession(c, user, http.StatusOK)
+}
+
+// logout ends the current session and clears the session cookie
+func logout(c *gin.Context) {
+	if user, session := currentUser(c), currentSession(c); user != nil && session != nil {
+		if err := userStore.RevokeSession(user.ID, session.ID); err != nil {
+			zapLogger.Warn("Failed to end session", zap.String("sessionID", session.ID), zap.Error(err))
+		}
+	}
+	c.SetCookie(sessionCookieName, "", -1, "/", "", false, true)
+	setCSRFCookie(c, "", -1)
+	c.JSON(http.StatusOK, gin.H{"status": "logged out"})
+}
+
+// getCurrentUser returns the authenticated user's account
+func getCurrentUser(c *gin.Context) {
+	c.JSON(http.StatusOK, currentUser(c).Public())
+}
+
+// issueSession responds with a fresh token and sets it as a cookie
+func issueSession(c *gin.Context, user *users.User, status int) {
+	token, expiresAt, err := userStore.IssueToken(user, c.Request.UserAgent(), c.ClientIP())
+	if err != nil {
+		zapLogger.Error("Failed to issue token", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to issue token: " + err.Error()})
+		return
+	}
+
+	maxAge := int(users.TokenLifetime.Seconds())
+	c.SetSameSite(http.SameSiteLaxMode)
+	c.SetCookie(sessionCookieName, token, maxAge, "/", "", false, true)
+	csrfToken := userStore.CSRFToken(token)
+	setCSRFCookie(c, csrfToken, maxAge)
+
+	zapLogger.Info("Session issued", zap.String("userID", user.ID))
+	c.JSON(status, gin.H{
+		"token":     token,
+		"expiresAt": expiresAt,
+		"csrfToken": csrfToken,
+		"user":      user.Public(),
+	})
+}
+
+// respondUserError maps user store errors onto HTTP responses
+func respondUserError(c *gin.Context, err error) {
+	switch {
+	case users.IsValidationError(err):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case users.IsAuthError(err):
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+	case users.IsConflictError(err):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case users.IsUserNotFoundError(err):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	default:
+		zapLogger.Error("User operation failed", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal error: " + err.Error()})
+	}
+}
@@ -0,0 +1,64 @@
+package routes
+
+import (
+	"mangahub/backend/models"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// maxBatchSize caps how many series a single batch request may ask for
+const maxBatchSize = 200
+
+// batchGetManga returns the summaries of several series in one response, in
+// the order requested. IDs that don't exist are listed under "missing".
+func batchGetManga(c *gin.Context) {
+	var request struct {
+		IDs []string `json:"ids" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&request); err != nil {
+		zapLogger.Warn("Invalid request data", zap.Error(err))
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request: " + err.Error()})
+		return
+	}
+	if len(request.IDs) > maxBatchSize {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Too many IDs; at most 200 per request"})
+		return
+	}
+	zapLogger.Info("batchGetManga handler called", zap.Int("idCount", len(request.IDs)))
+
+	mangas, err := indexedManga(c)
+	if err != nil {
+		zapLogger.Error("Failed to retrieve manga list", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve manga list: " + err.Error()})
+		return
+	}
+
+	byID := make(map[string]*models.MangaSeries, len(mangas))
+	for i := range mangas {
+		byID[mangas[i].ID] = &mangas[i]
+	}
+
+	ratings := reviewStore.Summaries()
+	found := make([]gin.H, 0, len(request.IDs))
+	missing := []string{}
+	seen := make(map[string]bool, len(request.IDs))
+	for _, id := range request.IDs {
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+
+		if manga, ok := byID[id]; ok {
+			found = append(found, mangaSummary(manga, ratings[id]))
+		} else {
+			missing = append(missing, id)
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"manga":   applySparseFields(c, found),
+		"missing": missing,
+	})
+}
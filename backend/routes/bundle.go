@@ -0,0 +1,140 @@
+package routes
+
+import (
+	"archive/tar"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mangahub/backend/models"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// bundleManifestFile is the first entry of a chapter bundle
+const bundleManifestFile = "manifest.json"
+
+// tarBlockSize is the unit tar headers and contents are padded to
+const tarBlockSize = 512
+
+// bundleEntryName names a page in a chapter bundle. Names stay short and
+// ASCII so every entry takes a single USTAR header and the bundle's size is
+// known before it is written.
+func bundleEntryName(page *models.Page) string {
+	return fmt.Sprintf("pages/%04d%s", page.Number, strings.ToLower(filepath.Ext(page.ImagePath)))
+}
+
+// tarEntrySize is the space an entry of the given content size takes in a
+// tar archive: its header and its content padded to whole blocks
+func tarEntrySize(size int64) int64 {
+	return tarBlockSize + (size+tarBlockSize-1)/tarBlockSize*tarBlockSize
+}
+
+// getChapterBundle returns a chapter as one tar archive holding its manifest
+// followed by every page, for service workers to cache a chapter for offline
+// reading in a single request. Each page's "file" in the manifest names its
+// entry. The archive's exact size is sent up front.
+func getChapterBundle(c *gin.Context) {
+	lookup, ok := lookupChapter(c)
+	if !ok {
+		return
+	}
+	chapter := lookup.Chapter()
+
+	pages, infos, ok := loadPageFiles(c, chapter)
+	if !ok {
+		return
+	}
+	if checkNotModified(c, `"`+contentVersion(chapter, infos)+`"`) {
+		return
+	}
+	if c.Request.Method != http.MethodHead && !takeDownload(c, chapter) {
+		return
+	}
+
+	manifest, err := buildManifest(chapter, pages, infos)
+	if err != nil {
+		zapLogger.Error("Failed to build chapter manifest", zap.String("chapterID", chapter.ID), zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to read page files: " + err.Error()})
+		return
+	}
+	for i := range manifest.Pages {
+		manifest.Pages[i].File = bundleEntryName(&pages[i])
+	}
+	marked, err := markBundlePages(c, pages, manifest)
+	if err != nil {
+		zapLogger.Error("Failed to watermark pages", zap.String("chapterID", chapter.ID), zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to watermark pages: " + err.Error()})
+		return
+	}
+	manifestJSON, err := json.Marshal(manifest)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal error: " + err.Error()})
+		return
+	}
+
+	size := tarEntrySize(int64(len(manifestJSON))) + 2*tarBlockSize
+	for _, page := range manifest.Pages {
+		size += tarEntrySize(page.Size)
+	}
+	filename := fmt.Sprintf("%s-%s.tar", chapter.MangaID, chapter.ID)
+	c.Header("Content-Type", "application/x-tar")
+	c.Header("Content-Length", strconv.FormatInt(size, 10))
+	c.Header("Content-Disposition", `attachment; filename="`+filename+`"`)
+	if c.Request.Method == http.MethodHead {
+		return
+	}
+
+	now := time.Now().Truncate(time.Second)
+	tw := tar.NewWriter(c.Writer)
+	err = writeBundleEntry(tw, bundleManifestFile, int64(len(manifestJSON)), now, bytes.NewReader(manifestJSON))
+	for i := 0; err == nil && i < len(pages); i++ {
+		if marked != nil {
+			err = writeBundleEntry(tw, manifest.Pages[i].File, int64(len(marked[i].Data)), now, bytes.NewReader(marked[i].Data))
+			continue
+		}
+		err = writeBundlePage(tw, manifest.Pages[i].File, pages[i].ImagePath, infos[i])
+	}
+	if err == nil {
+		err = tw.Close()
+	}
+	if err != nil {
+		// The status is already sent; the client sees a truncated archive
+		zapLogger.Error("Failed to write chapter bundle", zap.String("chapterID", chapter.ID), zap.Error(err))
+	}
+}
+
+// writeBundlePage writes a page file as a bundle entry of the size it was
+// stat'ed with
+func writeBundlePage(tw *tar.Writer, name, path string, info os.FileInfo) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	return writeBundleEntry(tw, name, info.Size(), info.ModTime(), file)
+}
+
+// writeBundleEntry writes one USTAR entry with exactly size bytes of content
+func writeBundleEntry(tw *tar.Writer, name string, size int64, modTime time.Time, content io.Reader) error {
+	header := &tar.Header{
+		Typeflag: tar.TypeReg,
+		Name:     name,
+		Mode:     0644,
+		Size:     size,
+		ModTime:  modTime.Truncate(time.Second),
+		Format:   tar.FormatUSTAR,
+	}
+	if err := tw.WriteHeader(header); err != nil {
+		return err
+	}
+	_, err := io.CopyN(tw, content, size)
+	return err
+}
@@ -0,0 +1,97 @@
+package routes
+
+import (
+	"mangahub/backend/models"
+	"mangahub/backend/tombstones"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// tombstoneOwner is the user a series' tombstones are shown to: its owner
+// when only they could see it, otherwise nobody in particular
+func tombstoneOwner(manga *models.MangaSeries) string {
+	if manga.Owner != "" && !manga.IsPublic() && manga.Visibility != models.VisibilityUsers {
+		return manga.Owner
+	}
+	return ""
+}
+
+// recordRemovedSeries leaves a tombstone for a series that left the library
+// index. A merged series points to the one it was merged into.
+func recordRemovedSeries(manga models.MangaSeries) {
+	tombstone := tombstones.Tombstone{Kind: tombstones.KindSeries, MangaID: manga.ID}
+	tombstone.MovedTo, _ = redirectStore.Resolve(manga.ID)
+	tombstone.Owner = tombstoneOwner(&manga)
+	if err := tombstoneStore.Add(tombstone); err != nil {
+		zapLogger.Error("Failed to record removed series", zap.String("mangaID", manga.ID), zap.Error(err))
+	}
+}
+
+// recordRemovedChapter leaves a tombstone for a chapter removed from a
+// series, or moved out of it into movedTo
+func recordRemovedChapter(manga *models.MangaSeries, chapter *models.Chapter, movedTo string) {
+	tombstone := tombstones.Tombstone{
+		Kind:      tombstones.KindChapter,
+		MangaID:   manga.ID,
+		ChapterID: chapter.ID,
+		Number:    chapter.Number,
+		MovedTo:   movedTo,
+		Owner:     tombstoneOwner(manga),
+	}
+	if err := tombstoneStore.Add(tombstone); err != nil {
+		zapLogger.Error("Failed to record removed chapter", zap.String("chapterID", chapter.ID), zap.Error(err))
+	}
+}
+
+// visibleTombstones returns the tombstones recorded between since and until
+// that the requester may see, leaving out series that exist again, and
+// whether none that may matter were pruned
+func visibleTombstones(c *gin.Context, since, until time.Time) ([]tombstones.Tombstone, bool) {
+	user := currentUser(c)
+	removed, complete := tombstoneStore.Since(since)
+	visible := make([]tombstones.Tombstone, 0, len(removed))
+	for _, t := range removed {
+		if t.DeletedAt.After(until) {
+			break
+		}
+		// A series can come back, e.g. restored from a backup
+		if _, exists := libraryIndex.Get(t.MangaID); exists && t.Kind == tombstones.KindSeries {
+			continue
+		}
+		if t.Owner == "" || (user != nil && (user.ID == t.Owner || user.IsAdmin())) {
+			t.Owner = ""
+			visible = append(visible, t)
+		}
+	}
+	return visible, complete
+}
+
+// getChanges lists the series and chapters removed since the RFC 3339
+// timestamp ?since=, or all that are still remembered without it, leaving
+// out series that exist again. Clients
+// pass the returned "until" as the next since. "fullSync" is true when
+// tombstones that may matter were already forgotten, and the client should
+// reconcile against full listings instead.
+func getChanges(c *gin.Context) {
+	var since time.Time
+	if raw := c.Query("since"); raw != "" {
+		var err error
+		if since, err = time.Parse(time.RFC3339, raw); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid since; use an RFC 3339 timestamp"})
+			return
+		}
+	}
+	zapLogger.Info("getChanges handler called", zap.Time("since", since))
+
+	until := timeNow()
+	deleted, complete := visibleTombstones(c, since, until)
+	c.JSON(http.StatusOK, gin.H{
+		"since":    since,
+		"until":    until,
+		"deleted":  deleted,
+		"fullSync": !complete,
+	})
+}
@@ -0,0 +1,154 @@
+package routes
+
+import (
+	"fmt"
+	"mangahub/backend/models"
+	"mangahub/backend/scheduler"
+	"net/http"
+	"os"
+	"path/filepath"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// moveChapter moves a chapter's folder into another series, named by that
+// series' naming scheme, and records its new series in its metadata. It
+// fails if the target folder already exists. What was cached about the old
+// folder is dropped and a pending publish schedule follows the chapter.
+func moveChapter(chapter *models.Chapter, target *models.MangaSeries) error {
+	moved := *chapter
+	moved.MangaID = target.ID
+	newPath, err := chapterFolderPath(target, &moved)
+	if err != nil {
+		return err
+	}
+	if _, err := os.Stat(newPath); err == nil {
+		return fmt.Errorf("%s already has a folder named %s", target.ID, filepath.Base(newPath))
+	}
+	if err := os.Rename(chapter.Path, newPath); err != nil {
+		return err
+	}
+	moved.Path = newPath
+	if err := moved.SaveToJSON(filepath.Join(newPath, models.MetadataFileName)); err != nil {
+		// Put the folder back so the chapter isn't left half moved
+		if rollbackErr := os.Rename(newPath, chapter.Path); rollbackErr != nil {
+			zapLogger.Error("Failed to move chapter back", zap.String("path", newPath), zap.Error(rollbackErr))
+		}
+		return err
+	}
+
+	metadataManager.ForgetChapter(chapter.Path)
+	forgetPageHashes(chapter.Path)
+	if moved.PublishAt != nil {
+		if err := publishScheduler.Cancel(chapter.Path); err != nil {
+			zapLogger.Error("Failed to cancel schedule of moved chapter", zap.String("chapterID", chapter.ID), zap.Error(err))
+		}
+		entry := scheduler.Entry{
+			MangaID:     moved.MangaID,
+			ChapterID:   moved.ID,
+			ChapterPath: moved.Path,
+			PublishAt:   *moved.PublishAt,
+		}
+		if err := publishScheduler.Schedule(entry); err != nil {
+			zapLogger.Error("Failed to schedule moved chapter", zap.String("chapterID", chapter.ID), zap.Error(err))
+		}
+	}
+	*chapter = moved
+	return nil
+}
+
+// recordChapterCount updates the chapter count in a series' metadata.json
+// after chapters were added or removed; derived metadata has no file
+func recordChapterCount(manga *models.MangaSeries) error {
+	metadataPath := filepath.Join(manga.Path, models.MetadataFileName)
+	if _, err := os.Stat(metadataPath); err != nil {
+		return nil
+	}
+	chapters, err := metadataManager.ScanForChapters(manga)
+	if err != nil {
+		return err
+	}
+	var recorded models.MangaSeries
+	if err := recorded.LoadFromJSON(metadataPath); err != nil {
+		return err
+	}
+	if recorded.ChapterCount == len(chapters) {
+		return nil
+	}
+	recorded.ChapterCount = len(chapters)
+	return recorded.SaveToJSON(metadataPath)
+}
+
+// moveChapterToSeries moves a chapter, e.g. one uploaded to the wrong series,
+// into the series named by "to". It keeps its number, so the target must not
+// have a chapter with that number yet.
+func moveChapterToSeries(c *gin.Context) {
+	var request struct {
+		To string `json:"to" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request: " + err.Error()})
+		return
+	}
+
+	lookup, ok := lookupChapter(c)
+	if !ok {
+		return
+	}
+	source, chapter := lookup.Manga, lookup.Chapter()
+	zapLogger.Info("moveChapterToSeries handler called",
+		zap.String("mangaID", source.ID),
+		zap.String("chapterID", chapter.ID),
+		zap.String("to", request.To))
+
+	if request.To == source.ID {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Chapter is already in that series"})
+		return
+	}
+	target, ok := lookupManga(c, request.To)
+	if !ok {
+		return
+	}
+	if source.Owner != target.Owner {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Series must belong to the same library"})
+		return
+	}
+
+	targetChapters, err := metadataManager.ScanForChapters(target)
+	if err != nil {
+		zapLogger.Error("Failed to retrieve chapters", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve chapters: " + err.Error()})
+		return
+	}
+	for i := range targetChapters {
+		if targetChapters[i].Number == chapter.Number {
+			c.JSON(http.StatusConflict, gin.H{"error": "Chapter already exists"})
+			return
+		}
+	}
+
+	if err := moveChapter(chapter, target); err != nil {
+		zapLogger.Error("Failed to move chapter",
+			zap.String("chapterID", chapter.ID),
+			zap.String("to", target.ID),
+			zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to move chapter: " + err.Error()})
+		return
+	}
+	recordRemovedChapter(source, chapter, target.ID)
+
+	for _, manga := range []*models.MangaSeries{source, target} {
+		if err := recordChapterCount(manga); err != nil {
+			zapLogger.Warn("Failed to update chapter count", zap.String("mangaID", manga.ID), zap.Error(err))
+		}
+		libraryIndex.Refresh(manga.Path)
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"mangaId":   target.ID,
+		"chapterId": chapter.ID,
+		"number":    chapter.Number,
+		"from":      source.ID,
+	})
+}
@@ -0,0 +1,131 @@
+package routes
+
+import (
+	"mangahub/backend/collections"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// collectionRequest is the body accepted when creating or updating a collection
+type collectionRequest struct {
+	Name     string   `json:"name" binding:"required"`
+	Summary  string   `json:"summary"`
+	MangaIDs []string `json:"mangaIds"`
+}
+
+// listCollections returns every collection
+func listCollections(c *gin.Context) {
+	c.JSON(http.StatusOK, collectionStore.List())
+}
+
+// getCollection returns one collection with the summaries of its series, in
+// collection order. Series that no longer exist, or that the requester may not
+// see, are skipped.
+func getCollection(c *gin.Context) {
+	collection, err := collectionStore.Get(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Collection not found"})
+		return
+	}
+
+	ratings := reviewStore.Summaries()
+	series := []gin.H{}
+	for _, id := range collection.MangaIDs {
+		manga, ok := libraryIndex.Get(id)
+		if !ok || !canSeeSeries(c, manga) {
+			continue
+		}
+		series = append(series, mangaSummary(manga, ratings[id]))
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"id":        collection.ID,
+		"name":      collection.Name,
+		"summary":   collection.Summary,
+		"mangaIds":  collection.MangaIDs,
+		"manga":     series,
+		"createdAt": collection.CreatedAt,
+		"updatedAt": collection.UpdatedAt,
+	})
+}
+
+// createCollection adds a collection
+func createCollection(c *gin.Context) {
+	var request collectionRequest
+	if !bindCollection(c, &request) {
+		return
+	}
+	zapLogger.Info("createCollection handler called", zap.String("name", request.Name))
+
+	collection, err := collectionStore.Create(request.Name, request.Summary, request.MangaIDs)
+	if err != nil {
+		zapLogger.Error("Failed to save collection", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save collection: " + err.Error()})
+		return
+	}
+	c.JSON(http.StatusCreated, collection)
+}
+
+// updateCollection replaces a collection's name, summary and series
+func updateCollection(c *gin.Context) {
+	id := c.Param("id")
+	var request collectionRequest
+	if !bindCollection(c, &request) {
+		return
+	}
+	zapLogger.Info("updateCollection handler called", zap.String("collectionID", id))
+
+	collection, err := collectionStore.Update(id, request.Name, request.Summary, request.MangaIDs)
+	if err == collections.ErrNotFound {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Collection not found"})
+		return
+	}
+	if err != nil {
+		zapLogger.Error("Failed to save collection", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save collection: " + err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, collection)
+}
+
+// deleteCollection removes a collection; its series are untouched
+func deleteCollection(c *gin.Context) {
+	id := c.Param("id")
+	zapLogger.Info("deleteCollection handler called", zap.String("collectionID", id))
+
+	removed, err := collectionStore.Delete(id)
+	if err != nil {
+		zapLogger.Error("Failed to save collections", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save collections: " + err.Error()})
+		return
+	}
+	if !removed {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Collection not found"})
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// bindCollection parses a collection request and checks that every series it
+// names exists. On failure it writes a 400 and returns false.
+func bindCollection(c *gin.Context, request *collectionRequest) bool {
+	if err := c.ShouldBindJSON(request); err != nil {
+		zapLogger.Warn("Invalid request data", zap.Error(err))
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request: " + err.Error()})
+		return false
+	}
+	if strings.TrimSpace(request.Name) == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Collection name is required"})
+		return false
+	}
+	for _, id := range request.MangaIDs {
+		if !mangaIDTaken(id) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Unknown manga: " + id})
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,95 @@
+package routes
+
+import (
+	"context"
+	"fmt"
+	"mangahub/backend/jobs"
+	"mangahub/backend/models"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// countCheckJobType identifies page and chapter count checks in the jobs API
+const countCheckJobType = "count-check"
+
+// countCheckInterval is how often recorded counts are checked; 0 only checks
+// when an admin asks
+var countCheckInterval = 24 * time.Hour
+
+// SetCountCheckInterval sets how often the recorded page and chapter counts
+// are checked against the files; 0 disables the periodic check
+func SetCountCheckInterval(interval time.Duration) {
+	countCheckInterval = interval
+}
+
+// countReport is the result of a count check
+type countReport struct {
+	Series     int                    `json:"series"`
+	Mismatches []models.CountMismatch `json:"mismatches"`
+	Failed     []gin.H                `json:"failed"`
+}
+
+// startCountChecks checks the counts once the library index is ready and
+// then every countCheckInterval
+func startCountChecks() {
+	if countCheckInterval <= 0 {
+		zapLogger.Info("Periodic count checks disabled")
+		return
+	}
+	go func() {
+		for !libraryIndex.Ready() {
+			time.Sleep(10 * time.Second)
+		}
+		for {
+			if len(runningJobs(countCheckJobType)) == 0 {
+				startCountCheck()
+			}
+			time.Sleep(countCheckInterval)
+		}
+	}()
+}
+
+// startCountCheck recounts the pages and chapters of every series as a job,
+// correcting the metadata files and reporting what was wrong
+func startCountCheck() jobs.Job {
+	return jobManager.Start(countCheckJobType, func(ctx context.Context, progress *jobs.Progress) (interface{}, error) {
+		series := libraryIndex.List()
+		report := countReport{Series: len(series), Mismatches: []models.CountMismatch{}, Failed: []gin.H{}}
+		for i := range series {
+			if err := ctx.Err(); err != nil {
+				return report, err
+			}
+			manga := &series[i]
+			progress.Update(i, len(series), fmt.Sprintf("Checked %d of %d series", i, len(series)))
+
+			mismatches, err := metadataManager.ReconcileCounts(manga)
+			report.Mismatches = append(report.Mismatches, mismatches...)
+			if err != nil {
+				zapLogger.Warn("Failed to check counts", zap.String("mangaID", manga.ID), zap.Error(err))
+				report.Failed = append(report.Failed, gin.H{"mangaId": manga.ID, "error": err.Error()})
+			}
+			if len(mismatches) > 0 {
+				libraryIndex.Refresh(manga.Path)
+			}
+		}
+
+		if len(report.Mismatches) > 0 {
+			zapLogger.Warn("Corrected page and chapter counts", zap.Int("mismatches", len(report.Mismatches)))
+		}
+		return report, nil
+	})
+}
+
+// checkCounts starts a count check right away; the report is the job result
+func checkCounts(c *gin.Context) {
+	zapLogger.Info("checkCounts handler called")
+
+	if running := runningJobs(countCheckJobType); len(running) > 0 {
+		c.JSON(http.StatusConflict, gin.H{"error": "A count check is already running", "job": running[0]})
+		return
+	}
+	c.JSON(http.StatusAccepted, startCountCheck())
+}
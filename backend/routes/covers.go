@@ -0,0 +1,301 @@
+package routes
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"io"
+	"mangahub/backend/antivirus"
+	"mangahub/backend/imaging"
+	"mangahub/backend/models"
+	"mangahub/backend/storage"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// maxCoverUploadSize caps uploaded and downloaded cover images
+const maxCoverUploadSize = 20 << 20
+
+// coverFetchClient downloads remote covers; the timeout keeps a slow host
+// from tying up the request
+var coverFetchClient = &http.Client{Timeout: 30 * time.Second}
+
+// listCovers returns every cover image stored for a series
+func listCovers(c *gin.Context) {
+	mangaID := c.Param("id")
+	zapLogger.Info("listCovers handler called", zap.String("mangaID", mangaID))
+
+	manga, ok := lookupManga(c, mangaID)
+	if !ok {
+		return
+	}
+
+	covers, err := manga.ListCovers()
+	if err != nil {
+		zapLogger.Error("Failed to list covers", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list covers: " + err.Error()})
+		return
+	}
+
+	response := make([]gin.H, 0, len(covers))
+	for _, name := range covers {
+		response = append(response, gin.H{
+			"file":     name,
+			"imageUrl": models.ImageURL(filepath.Join(manga.Path, name)),
+			"primary":  name == filepath.Base(manga.CoverImage),
+		})
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+// uploadCover stores an additional cover image (multipart field "file"). An
+// optional "label" names it, e.g. "vol02" -> cover-vol02.jpg, and
+// "primary=true" selects it right away. Its metadata is stripped first.
+func uploadCover(c *gin.Context) {
+	mangaID := c.Param("id")
+	zapLogger.Info("uploadCover handler called", zap.String("mangaID", mangaID))
+
+	manga, ok := lookupManga(c, mangaID)
+	if !ok {
+		return
+	}
+
+	header, err := c.FormFile("file")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request: missing cover file"})
+		return
+	}
+	if header.Size > maxCoverUploadSize {
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": "Cover image is too large"})
+		return
+	}
+
+	ext := strings.ToLower(filepath.Ext(header.Filename))
+	if !models.IsCoverFileName("cover" + ext) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Unsupported cover image type"})
+		return
+	}
+
+	file, err := header.Open()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to read upload: " + err.Error()})
+		return
+	}
+	data, err := io.ReadAll(file)
+	file.Close()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to read upload: " + err.Error()})
+		return
+	}
+
+	if !scanned(c, antivirus.ScanBytes(c.Request.Context(), data)) {
+		return
+	}
+	data, ext, err = sanitizeUpload(data, ext)
+	if err != nil {
+		zapLogger.Warn("Rejected uploaded cover", zap.Error(err))
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid cover image: " + err.Error()})
+		return
+	}
+
+	label := createSlug(c.PostForm("label"))
+	if label == "" {
+		label = time.Now().UTC().Format("20060102-150405")
+	}
+	name := fmt.Sprintf("cover-%s%s", label, ext)
+	coverPath := filepath.Join(manga.Path, name)
+
+	if err := storage.WriteFileAtomic(coverPath, data, 0644); err != nil {
+		zapLogger.Error("Failed to store cover", zap.String("coverPath", coverPath), zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to store cover: " + err.Error()})
+		return
+	}
+
+	if c.PostForm("primary") == "true" || manga.CoverImage == "" {
+		manga.CoverImage = name
+		if !saveManga(c, manga) {
+			return
+		}
+	}
+
+	zapLogger.Info("Cover uploaded", zap.String("mangaID", manga.ID), zap.String("file", name))
+	c.JSON(http.StatusCreated, gin.H{
+		"file":     name,
+		"imageUrl": models.ImageURL(coverPath),
+		"primary":  name == manga.CoverImage,
+	})
+}
+
+// fetchCover downloads a cover image from a remote URL, re-encodes it and
+// makes it the primary cover
+func fetchCover(c *gin.Context) {
+	mangaID := c.Param("id")
+
+	var request struct {
+		URL   string `json:"url" binding:"required"`
+		Label string `json:"label"`
+	}
+	if err := c.ShouldBindJSON(&request); err != nil {
+		zapLogger.Warn("Invalid request data", zap.Error(err))
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request: " + err.Error()})
+		return
+	}
+	zapLogger.Info("fetchCover handler called", zap.String("mangaID", mangaID), zap.String("url", request.URL))
+
+	source, err := url.Parse(request.URL)
+	if err != nil || (source.Scheme != "http" && source.Scheme != "https") || source.Host == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request: url must be an absolute http(s) URL"})
+		return
+	}
+
+	manga, ok := lookupManga(c, mangaID)
+	if !ok {
+		return
+	}
+
+	resp, err := coverFetchClient.Get(source.String())
+	if err != nil {
+		zapLogger.Warn("Failed to download cover", zap.String("url", request.URL), zap.Error(err))
+		c.JSON(http.StatusBadGateway, gin.H{"error": "Failed to download cover: " + err.Error()})
+		return
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		c.JSON(http.StatusBadGateway, gin.H{"error": fmt.Sprintf("Failed to download cover: remote returned %s", resp.Status)})
+		return
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxCoverUploadSize+1))
+	if err != nil {
+		c.JSON(http.StatusBadGateway, gin.H{"error": "Failed to download cover: " + err.Error()})
+		return
+	}
+	if len(data) > maxCoverUploadSize {
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": "Cover image is too large"})
+		return
+	}
+
+	// Re-encoding drops anything that isn't pixel data and normalizes the format
+	release := imaging.AcquireDecode()
+	defer release()
+	img, format, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		zapLogger.Warn("Downloaded cover is not a valid image", zap.String("url", request.URL), zap.Error(err))
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Downloaded file is not a valid image"})
+		return
+	}
+	var encoded bytes.Buffer
+	if err := imaging.Encode(&encoded, img, format); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to encode cover: " + err.Error()})
+		return
+	}
+
+	label := createSlug(request.Label)
+	if label == "" {
+		label = time.Now().UTC().Format("20060102-150405")
+	}
+	ext := ".jpg"
+	if imaging.OutputFormat(format) == "png" {
+		ext = ".png"
+	}
+	name := fmt.Sprintf("cover-%s%s", label, ext)
+	coverPath := filepath.Join(manga.Path, name)
+
+	if err := storage.WriteFileAtomic(coverPath, encoded.Bytes(), 0644); err != nil {
+		zapLogger.Error("Failed to store cover", zap.String("coverPath", coverPath), zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to store cover: " + err.Error()})
+		return
+	}
+
+	manga.CoverImage = name
+	if !saveManga(c, manga) {
+		return
+	}
+
+	zapLogger.Info("Cover fetched", zap.String("mangaID", manga.ID), zap.String("file", name))
+	c.JSON(http.StatusCreated, gin.H{
+		"file":     name,
+		"imageUrl": models.ImageURL(coverPath),
+		"primary":  true,
+	})
+}
+
+// selectCover makes one of the stored covers the primary cover
+func selectCover(c *gin.Context) {
+	mangaID := c.Param("id")
+
+	var request struct {
+		File string `json:"file" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&request); err != nil {
+		zapLogger.Warn("Invalid request data", zap.Error(err))
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request: " + err.Error()})
+		return
+	}
+	zapLogger.Info("selectCover handler called", zap.String("mangaID", mangaID), zap.String("file", request.File))
+
+	manga, ok := lookupManga(c, mangaID)
+	if !ok {
+		return
+	}
+	if !hasCover(c, manga, request.File) {
+		return
+	}
+
+	manga.CoverImage = request.File
+	if !saveManga(c, manga) {
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"id": manga.ID, "coverImage": manga.GetCoverImageURL()})
+}
+
+// deleteCover removes a stored cover; the primary cover cannot be deleted
+func deleteCover(c *gin.Context) {
+	mangaID := c.Param("id")
+	name := c.Param("file")
+	zapLogger.Info("deleteCover handler called", zap.String("mangaID", mangaID), zap.String("file", name))
+
+	manga, ok := lookupManga(c, mangaID)
+	if !ok {
+		return
+	}
+	if !hasCover(c, manga, name) {
+		return
+	}
+	if name == filepath.Base(manga.CoverImage) {
+		c.JSON(http.StatusConflict, gin.H{"error": "Cannot delete the primary cover; select another one first"})
+		return
+	}
+
+	if err := os.Remove(filepath.Join(manga.Path, name)); err != nil {
+		zapLogger.Error("Failed to delete cover", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete cover: " + err.Error()})
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// hasCover checks that name is one of the series' covers, writing a 404 if not
+func hasCover(c *gin.Context, manga *models.MangaSeries, name string) bool {
+	covers, err := manga.ListCovers()
+	if err != nil {
+		zapLogger.Error("Failed to list covers", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list covers: " + err.Error()})
+		return false
+	}
+	for _, cover := range covers {
+		if cover == name {
+			return true
+		}
+	}
+	c.JSON(http.StatusNotFound, gin.H{"error": "Cover not found"})
+	return false
+}
@@ -0,0 +1,112 @@
+package routes
+
+import (
+	"io"
+	"mangahub/backend/epub"
+	"mangahub/backend/imaging"
+	"mangahub/backend/models"
+	"os"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// defaultCreditsTemplate renders the credits page unless the server names
+// its own template. The first line is the page title.
+const defaultCreditsTemplate = `{{.Series.Title}}
+{{if .Chapter.Volume}}Volume {{.Chapter.Volume}}, {{end}}Chapter {{.Chapter.Number}}{{with .Chapter.Title}}: {{.}}{{end}}
+
+{{with .Series.Author}}Story: {{.}}
+{{end}}{{with .Series.Artist}}Art: {{.}}
+{{end}}{{with .Series.Publisher}}Publisher: {{.}}
+{{end}}{{with .Chapter.Scanlators}}Translation: {{join . ", "}}
+{{end}}{{with .Chapter.SourceURL}}Source: {{.}}
+{{end}}
+Exported {{.ExportedAt.Format "2 January 2006"}}{{with .ExportedBy}} by {{.}}{{end}} from {{.Server}}
+`
+
+// creditsTemplate renders the credits page put in front of exported
+// chapters; nil when exports have none
+var creditsTemplate *template.Template
+
+// creditsData is what the credits template is executed with
+type creditsData struct {
+	Series     *models.MangaSeries
+	Chapter    *models.Chapter
+	ExportedAt time.Time
+	ExportedBy string // Username of the exporting user
+	Server     string // External base URL
+}
+
+// SetExportCredits puts a generated credits page in front of exported
+// chapters, rendered from the text/template at templatePath, or from the
+// built-in one when it is empty
+func SetExportCredits(enabled bool, templatePath string) error {
+	if !enabled {
+		creditsTemplate = nil
+		return nil
+	}
+	text := defaultCreditsTemplate
+	if templatePath != "" {
+		data, err := os.ReadFile(templatePath)
+		if err != nil {
+			return err
+		}
+		text = string(data)
+	}
+	parsed, err := template.New("credits").Funcs(template.FuncMap{"join": strings.Join}).Parse(text)
+	if err != nil {
+		return err
+	}
+	creditsTemplate = parsed
+	return nil
+}
+
+// creditsPage renders the credits page of an exported chapter. It reports
+// false when exports have none or it couldn't be rendered; the export goes
+// ahead without it.
+func creditsPage(c *gin.Context, manga *models.MangaSeries, chapter *models.Chapter) (epub.Page, bool) {
+	if creditsTemplate == nil {
+		return epub.Page{}, false
+	}
+	data := creditsData{
+		Series:     manga,
+		Chapter:    chapter,
+		ExportedAt: timeNow().UTC(),
+		Server:     baseURL(c),
+	}
+	if user := currentUser(c); user != nil {
+		data.ExportedBy = user.Username
+	}
+
+	var b strings.Builder
+	if err := creditsTemplate.Execute(&b, data); err != nil {
+		zapLogger.Warn("Failed to render credits page", zap.String("chapterID", chapter.ID), zap.Error(err))
+		return epub.Page{}, false
+	}
+	title, body, _ := strings.Cut(strings.TrimLeft(b.String(), "\n"), "\n")
+	file, err := openCached(func() (string, error) {
+		return imageCache.CreditsPage(strings.TrimSpace(title), strings.Split(strings.TrimRight(body, "\n"), "\n"))
+	})
+	if err != nil {
+		zapLogger.Warn("Failed to render credits page", zap.String("chapterID", chapter.ID), zap.Error(err))
+		return epub.Page{}, false
+	}
+	defer file.Close()
+	// Read the page now, as the cache may evict it before the export is written
+	content, err := io.ReadAll(file)
+	if err != nil {
+		zapLogger.Warn("Failed to read credits page", zap.String("chapterID", chapter.ID), zap.Error(err))
+		return epub.Page{}, false
+	}
+	return epub.Page{
+		Path:      file.Name(),
+		Data:      content,
+		MediaType: "image/png",
+		Width:     imaging.CreditsWidth,
+		Height:    imaging.CreditsHeight,
+	}, true
+}
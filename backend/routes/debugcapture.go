@@ -0,0 +1,161 @@
+package routes
+
+import (
+	"bytes"
+	"io"
+	"mangahub/backend/capture"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// debugCapturePath is never captured, so reading the buffer doesn't fill it
+const debugCapturePath = "/api/admin/debug/capture"
+
+// Debug capture switches itself off after defaultCaptureMinutes unless an
+// admin asks for longer, up to maxCaptureMinutes
+const (
+	defaultCaptureMinutes = 60
+	maxCaptureMinutes     = 24 * 60
+)
+
+var debugRecorder = capture.NewRecorder(capture.DefaultCapacity)
+
+// captureWriter keeps the start of the response body as it is written
+type captureWriter struct {
+	gin.ResponseWriter
+	body  bytes.Buffer
+	limit int
+	size  int
+}
+
+func (w *captureWriter) keep(b []byte) {
+	w.size += len(b)
+	if room := w.limit - w.body.Len(); room > 0 {
+		w.body.Write(b[:min(len(b), room)])
+	}
+}
+
+func (w *captureWriter) Write(b []byte) (int, error) {
+	w.keep(b)
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *captureWriter) WriteString(s string) (int, error) {
+	w.keep([]byte(s))
+	return w.ResponseWriter.WriteString(s)
+}
+
+// readCloser reads from one reader and closes another
+type readCloser struct {
+	io.Reader
+	io.Closer
+}
+
+// DebugCapture records the requests and responses debug capture is
+// switched on for
+func DebugCapture(c *gin.Context) {
+	start := time.Now()
+	path := c.Request.URL.Path
+	wanted, limit := debugRecorder.Wants(c.Request.Method, path, start)
+	if !wanted || strings.HasPrefix(path, debugCapturePath) {
+		c.Next()
+		return
+	}
+
+	var requestBody []byte
+	if c.Request.Body != nil && c.Request.Body != http.NoBody {
+		var err error
+		if requestBody, err = io.ReadAll(io.LimitReader(c.Request.Body, int64(limit))); err != nil {
+			zapLogger.Warn("Failed to capture request body", zap.String("path", path), zap.Error(err))
+		}
+		// The handler still reads the whole body
+		c.Request.Body = readCloser{io.MultiReader(bytes.NewReader(requestBody), c.Request.Body), c.Request.Body}
+	}
+	requestSize := max(int(c.Request.ContentLength), len(requestBody))
+
+	writer := &captureWriter{ResponseWriter: c.Writer, limit: limit}
+	c.Writer = writer
+	c.Next()
+	c.Writer = writer.ResponseWriter
+
+	exchange := capture.Exchange{
+		Time:            start.UTC(),
+		Method:          c.Request.Method,
+		Path:            capture.Path(path, c.FullPath()),
+		Query:           capture.Query(c.Request.URL.RawQuery),
+		RequestHeaders:  capture.Headers(c.Request.Header),
+		RequestBody:     capture.Body(requestBody, requestSize, c.ContentType()),
+		Status:          writer.Status(),
+		ResponseHeaders: capture.Headers(writer.Header()),
+		ResponseBody:    capture.Body(writer.body.Bytes(), writer.size, writer.Header().Get("Content-Type")),
+		Truncated:       requestSize > len(requestBody) || writer.size > writer.body.Len(),
+		LatencyMS:       float64(time.Since(start).Microseconds()) / 1000,
+	}
+	if user := currentUser(c); user != nil {
+		exchange.User = user.Username
+	}
+	debugRecorder.Add(exchange)
+}
+
+// getDebugCapture returns the debug capture settings and the captured
+// exchanges, oldest first
+func getDebugCapture(c *gin.Context) {
+	filter, until, maxBodyBytes := debugRecorder.Status(time.Now())
+	response := gin.H{
+		"enabled":      !until.IsZero(),
+		"paths":        filter.Paths,
+		"methods":      filter.Methods,
+		"maxBodyBytes": maxBodyBytes,
+		"exchanges":    debugRecorder.Exchanges(),
+	}
+	if !until.IsZero() {
+		response["until"] = until.UTC()
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+// updateDebugCapture switches debug capture on for requests whose path
+// starts with one of paths and whose method is one of methods (empty lists
+// match everything) for the given minutes, or switches it off
+func updateDebugCapture(c *gin.Context) {
+	var request struct {
+		Enabled      *bool    `json:"enabled" binding:"required"`
+		Paths        []string `json:"paths"`
+		Methods      []string `json:"methods"`
+		Minutes      int      `json:"minutes"`
+		MaxBodyBytes int      `json:"maxBodyBytes"`
+	}
+	if err := c.ShouldBindJSON(&request); err != nil {
+		zapLogger.Warn("Invalid request data", zap.Error(err))
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request: " + err.Error()})
+		return
+	}
+	zapLogger.Info("updateDebugCapture handler called", zap.Bool("enabled", *request.Enabled))
+
+	if !*request.Enabled {
+		debugRecorder.Stop()
+		getDebugCapture(c)
+		return
+	}
+	if request.Minutes < 0 || request.Minutes > maxCaptureMinutes || request.MaxBodyBytes < 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request: minutes must be from 1 to 1440 and maxBodyBytes not negative"})
+		return
+	}
+	if request.Minutes == 0 {
+		request.Minutes = defaultCaptureMinutes
+	}
+	filter := capture.Filter{Paths: request.Paths, Methods: request.Methods}
+	debugRecorder.Start(filter, time.Now().Add(time.Duration(request.Minutes)*time.Minute), request.MaxBodyBytes)
+	getDebugCapture(c)
+}
+
+// clearDebugCapture drops the captured exchanges
+func clearDebugCapture(c *gin.Context) {
+	zapLogger.Info("clearDebugCapture handler called")
+	debugRecorder.Clear()
+	c.JSON(http.StatusOK, gin.H{"status": "cleared"})
+}
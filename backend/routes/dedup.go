@@ -0,0 +1,82 @@
+package routes
+
+import (
+	"context"
+	"fmt"
+	"mangahub/backend/dedup"
+	"mangahub/backend/jobs"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// dedupJobType identifies library deduplication runs in the jobs API
+const dedupJobType = "dedup"
+
+var (
+	dedupEnabled bool
+
+	// pageStore holds page images by content hash; nil unless deduplication
+	// is enabled
+	pageStore *dedup.Store
+)
+
+// SetPageDedup enables storing page images by content hash
+func SetPageDedup(enabled bool) {
+	dedupEnabled = enabled
+}
+
+// dedupChapter links a newly imported chapter's pages into the page store.
+// Failures only cost disk space, so they are logged rather than returned.
+func dedupChapter(chapterPath string) {
+	if pageStore == nil {
+		return
+	}
+	stats, err := pageStore.LinkDir(chapterPath)
+	if err != nil {
+		zapLogger.Warn("Failed to deduplicate chapter pages", zap.String("chapterPath", chapterPath), zap.Error(err))
+		return
+	}
+	if stats.Linked > 0 {
+		zapLogger.Info("Deduplicated chapter pages",
+			zap.String("chapterPath", chapterPath),
+			zap.Int("linked", stats.Linked),
+			zap.Int64("bytesSaved", stats.BytesSaved),
+		)
+	}
+}
+
+// dedupLibrary moves every existing page into the page store, e.g. after
+// deduplication is first enabled, and prunes unused objects. It runs as a job.
+func dedupLibrary(c *gin.Context) {
+	zapLogger.Info("dedupLibrary handler called")
+
+	if pageStore == nil {
+		c.JSON(http.StatusConflict, gin.H{"error": "Page deduplication is not enabled"})
+		return
+	}
+	if running := runningJobs(dedupJobType); len(running) > 0 {
+		c.JSON(http.StatusConflict, gin.H{"error": "Deduplication is already running", "job": running[0]})
+		return
+	}
+
+	job := jobManager.Start(dedupJobType, func(ctx context.Context, progress *jobs.Progress) (interface{}, error) {
+		stats, err := pageStore.Migrate(ctx, func(done, total int) {
+			progress.Update(done, total, fmt.Sprintf("Processed %d of %d series", done, total))
+		})
+		return stats, err
+	})
+	c.JSON(http.StatusAccepted, job)
+}
+
+// runningJobs returns the jobs of a type that haven't finished yet
+func runningJobs(jobType string) []jobs.Job {
+	var running []jobs.Job
+	for _, job := range jobManager.List(jobType) {
+		if job.Status == jobs.StatusRunning {
+			running = append(running, job)
+		}
+	}
+	return running
+}
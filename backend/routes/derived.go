@@ -0,0 +1,76 @@
+package routes
+
+import (
+	"context"
+	"fmt"
+	"mangahub/backend/jobs"
+	"net/http"
+	"path/filepath"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// persistMetadataJobType identifies runs that save derived metadata in the jobs API
+const persistMetadataJobType = "persist-metadata"
+
+// persistDerivedMetadata saves the metadata derived for folders without a
+// metadata.json as they are scanned
+var persistDerivedMetadata bool
+
+// SetPersistDerivedMetadata makes scans save the metadata they derive for
+// series and chapter folders without a metadata.json
+func SetPersistDerivedMetadata(enabled bool) {
+	persistDerivedMetadata = enabled
+}
+
+// persistReport is the result of saving derived metadata
+type persistReport struct {
+	Series  int      `json:"series"`
+	Written []string `json:"written"` // Paths relative to the library root
+	Failed  []gin.H  `json:"failed"`
+}
+
+// startPersistMetadata writes a metadata.json for every series and chapter
+// folder that lacks one, as a job
+func startPersistMetadata() jobs.Job {
+	return jobManager.Start(persistMetadataJobType, func(ctx context.Context, progress *jobs.Progress) (interface{}, error) {
+		series := libraryIndex.List()
+		report := persistReport{Series: len(series), Written: []string{}, Failed: []gin.H{}}
+		for i := range series {
+			if err := ctx.Err(); err != nil {
+				return report, err
+			}
+			manga := &series[i]
+			progress.Update(i, len(series), fmt.Sprintf("Saved metadata of %d of %d series", i, len(series)))
+
+			written, err := metadataManager.PersistDerived(manga)
+			for _, path := range written {
+				if rel, err := filepath.Rel(metadataManager.RootDir, path); err == nil {
+					path = rel
+				}
+				report.Written = append(report.Written, filepath.ToSlash(path))
+			}
+			if err != nil {
+				zapLogger.Warn("Failed to save derived metadata", zap.String("mangaID", manga.ID), zap.Error(err))
+				report.Failed = append(report.Failed, gin.H{"mangaId": manga.ID, "error": err.Error()})
+			}
+			if len(written) > 0 {
+				libraryIndex.Refresh(manga.Path)
+			}
+		}
+		return report, nil
+	})
+}
+
+// persistMetadata saves the derived metadata of the whole library right
+// away; the report is the job result
+func persistMetadata(c *gin.Context) {
+	zapLogger.Info("persistMetadata handler called")
+
+	if running := runningJobs(persistMetadataJobType); len(running) > 0 {
+		c.JSON(http.StatusConflict, gin.H{"error": "Derived metadata is already being saved", "job": running[0]})
+		return
+	}
+	c.JSON(http.StatusAccepted, startPersistMetadata())
+}
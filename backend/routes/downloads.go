@@ -0,0 +1,122 @@
+package routes
+
+import (
+	"errors"
+	"mangahub/backend/downloads"
+	"mangahub/backend/models"
+	"mangahub/backend/users"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// defaultDownloadsPerDay is the daily chapter download limit of users
+// without their own, and of each guest address; 0 is unlimited
+var defaultDownloadsPerDay int
+
+// SetDownloadsPerDay sets the default daily chapter download limit
+func SetDownloadsPerDay(n int) {
+	defaultDownloadsPerDay = max(n, 0)
+}
+
+// downloadLimit is how many chapters a user, or a guest when user is nil, may
+// download per day. Admins are never limited.
+func downloadLimit(user *users.User) int {
+	switch {
+	case user == nil:
+		return defaultDownloadsPerDay
+	case user.IsAdmin():
+		return 0
+	case user.DownloadsPerDay > 0:
+		return user.DownloadsPerDay
+	}
+	return defaultDownloadsPerDay
+}
+
+// downloaderKey identifies whoever is downloading: the signed-in user, or
+// the address of a guest
+func downloaderKey(c *gin.Context) string {
+	if user := currentUser(c); user != nil {
+		return user.ID
+	}
+	return "guest:" + c.ClientIP()
+}
+
+// takeDownload counts a chapter download against the requester's daily
+// limit and the chapter's download count. It answers 429 and returns false
+// once the limit is reached.
+func takeDownload(c *gin.Context, chapter *models.Chapter) bool {
+	now := timeNow()
+	err := downloadStore.Take(downloaderKey(c), chapter.MangaID, chapter.ID, downloadLimit(currentUser(c)), now)
+	if errors.Is(err, downloads.ErrLimitReached) {
+		resetsAt := downloads.NextReset(now)
+		c.Header("Retry-After", strconv.Itoa(int(resetsAt.Sub(now).Seconds())+1))
+		c.JSON(http.StatusTooManyRequests, gin.H{"error": "Daily download limit reached", "resetsAt": resetsAt})
+		return false
+	}
+	if err != nil {
+		// Failing to count shouldn't cost the reader the download
+		zapLogger.Warn("Failed to record download", zap.String("chapterID", chapter.ID), zap.Error(err))
+	}
+	return true
+}
+
+// getDownloadUsage returns the requester's daily download limit and how much
+// of it is used; a limit of 0 is unlimited
+func getDownloadUsage(c *gin.Context) {
+	now := timeNow()
+	limit := downloadLimit(currentUser(c))
+	used := downloadStore.Used(downloaderKey(c), now)
+	response := gin.H{
+		"limit":    limit,
+		"used":     used,
+		"resetsAt": downloads.NextReset(now),
+	}
+	if limit > 0 {
+		response["remaining"] = max(limit-used, 0)
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+// getMangaDownloads returns how often each chapter of a series was
+// downloaded
+func getMangaDownloads(c *gin.Context) {
+	mangaID := c.Param("id")
+	zapLogger.Info("getMangaDownloads handler called", zap.String("mangaID", mangaID))
+
+	if _, ok := libraryIndex.Get(mangaID); !ok {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Manga not found"})
+		return
+	}
+	counts := downloadStore.Counts(mangaID)
+	total := 0
+	for _, n := range counts {
+		total += n
+	}
+	c.JSON(http.StatusOK, gin.H{"mangaId": mangaID, "total": total, "chapters": counts})
+}
+
+// setUserDownloadLimit overrides how many chapters a user may download per
+// day; 0 restores the default
+func setUserDownloadLimit(c *gin.Context) {
+	userID := c.Param("id")
+	zapLogger.Info("setUserDownloadLimit handler called", zap.String("userID", userID))
+
+	var request struct {
+		DownloadsPerDay *int `json:"downloadsPerDay" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&request); err != nil {
+		zapLogger.Warn("Invalid request data", zap.Error(err))
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request: " + err.Error()})
+		return
+	}
+
+	user, err := userStore.SetDownloadLimit(userID, *request.DownloadsPerDay)
+	if err != nil {
+		respondUserError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, user.Public())
+}
@@ -0,0 +1,117 @@
+package routes
+
+import (
+	"mangahub/backend/models"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// getCustomFields returns the custom key/value metadata of a series
+func getCustomFields(c *gin.Context) {
+	manga, ok := lookupManga(c, c.Param("id"))
+	if !ok {
+		return
+	}
+	c.JSON(http.StatusOK, customFieldsOf(manga))
+}
+
+// updateCustomFields merges the given fields into a series' custom fields.
+// Fields set to an empty string are removed.
+func updateCustomFields(c *gin.Context) {
+	mangaID := c.Param("id")
+	zapLogger.Info("updateCustomFields handler called", zap.String("mangaID", mangaID))
+
+	var fields map[string]string
+	if err := c.ShouldBindJSON(&fields); err != nil {
+		zapLogger.Warn("Invalid request data", zap.Error(err))
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request: " + err.Error()})
+		return
+	}
+
+	manga, ok := lookupManga(c, mangaID)
+	if !ok {
+		return
+	}
+	if !applyCustomFields(c, manga, fields) || !saveManga(c, manga) {
+		return
+	}
+	c.JSON(http.StatusOK, customFieldsOf(manga))
+}
+
+// setCustomField sets a single custom field
+func setCustomField(c *gin.Context) {
+	mangaID := c.Param("id")
+	key := c.Param("key")
+	zapLogger.Info("setCustomField handler called", zap.String("mangaID", mangaID), zap.String("key", key))
+
+	var request struct {
+		Value string `json:"value"`
+	}
+	if err := c.ShouldBindJSON(&request); err != nil {
+		zapLogger.Warn("Invalid request data", zap.Error(err))
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request: " + err.Error()})
+		return
+	}
+
+	manga, ok := lookupManga(c, mangaID)
+	if !ok {
+		return
+	}
+	if !applyCustomFields(c, manga, map[string]string{key: request.Value}) || !saveManga(c, manga) {
+		return
+	}
+	c.JSON(http.StatusOK, customFieldsOf(manga))
+}
+
+// deleteCustomField removes a single custom field
+func deleteCustomField(c *gin.Context) {
+	mangaID := c.Param("id")
+	key := c.Param("key")
+	zapLogger.Info("deleteCustomField handler called", zap.String("mangaID", mangaID), zap.String("key", key))
+
+	manga, ok := lookupManga(c, mangaID)
+	if !ok {
+		return
+	}
+	if _, exists := manga.CustomFields[key]; !exists {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Custom field not found"})
+		return
+	}
+	delete(manga.CustomFields, key)
+	if !saveManga(c, manga) {
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// validateCustomFields checks fields, writing a 400 on bad input
+func validateCustomFields(c *gin.Context, fields map[string]string) bool {
+	for key, value := range fields {
+		if err := models.ValidateCustomField(key, value); err != nil {
+			zapLogger.Warn("Invalid custom field", zap.String("key", key), zap.Error(err))
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return false
+		}
+	}
+	return true
+}
+
+// applyCustomFields validates and applies fields, writing a 400 on bad input
+func applyCustomFields(c *gin.Context, manga *models.MangaSeries, fields map[string]string) bool {
+	if !validateCustomFields(c, fields) {
+		return false
+	}
+	for key, value := range fields {
+		manga.SetCustomField(key, value)
+	}
+	return true
+}
+
+func customFieldsOf(manga *models.MangaSeries) map[string]string {
+	if manga.CustomFields == nil {
+		return map[string]string{}
+	}
+	return manga.CustomFields
+}
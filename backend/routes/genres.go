@@ -0,0 +1,106 @@
+package routes
+
+import (
+	"context"
+	"fmt"
+	"mangahub/backend/jobs"
+	"mangahub/backend/models"
+	"net/http"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// genreRenameJobType identifies library-wide genre renames in the jobs API
+const genreRenameJobType = "genre-rename"
+
+// genreRenameReport is the result of a genre rename
+type genreRenameReport struct {
+	From    string   `json:"from"`
+	To      string   `json:"to"`
+	Updated []string `json:"updated"` // IDs of the series rewritten
+	Failed  []gin.H  `json:"failed"`
+}
+
+// renameGenreIn replaces from (compared case-insensitively) with to in a
+// genre list, dropping the duplicate when the list already has to. It
+// reports whether anything changed.
+func renameGenreIn(genres []string, from, to string) ([]string, bool) {
+	renamed := make([]string, 0, len(genres))
+	changed := false
+	for _, genre := range genres {
+		if equalIgnoreCase(genre, from) {
+			genre, changed = to, true
+		}
+		if slices.ContainsFunc(renamed, func(g string) bool { return equalIgnoreCase(g, genre) }) {
+			continue
+		}
+		renamed = append(renamed, genre)
+	}
+	return renamed, changed
+}
+
+// startGenreRename renames a genre on every series that has it as a job,
+// rewriting their metadata files and refreshing them in the library index
+// so searches see the new name
+func startGenreRename(from, to string) jobs.Job {
+	return jobManager.Start(genreRenameJobType, func(ctx context.Context, progress *jobs.Progress) (interface{}, error) {
+		report := genreRenameReport{From: from, To: to, Updated: []string{}, Failed: []gin.H{}}
+		series := libraryIndex.List()
+		for i := range series {
+			if err := ctx.Err(); err != nil {
+				return report, err
+			}
+			manga := &series[i]
+			progress.Update(i, len(series), fmt.Sprintf("Checked %d of %d series", i, len(series)))
+
+			genres, changed := renameGenreIn(manga.Genres, from, to)
+			if !changed {
+				continue
+			}
+			manga.Genres = genres
+			if err := manga.SaveToJSON(filepath.Join(manga.Path, models.MetadataFileName)); err != nil {
+				zapLogger.Warn("Failed to rename genre", zap.String("mangaID", manga.ID), zap.Error(err))
+				report.Failed = append(report.Failed, gin.H{"mangaId": manga.ID, "error": err.Error()})
+				continue
+			}
+			libraryIndex.Refresh(manga.Path)
+			report.Updated = append(report.Updated, manga.ID)
+		}
+		progress.Update(len(series), len(series), "")
+
+		if len(report.Failed) > 0 {
+			return report, fmt.Errorf("%d of %d series failed to update", len(report.Failed), len(report.Failed)+len(report.Updated))
+		}
+		return report, nil
+	})
+}
+
+// renameGenre renames the genre :genre to "name" across the library, which
+// merges it into "name" on series that have both. The rewrite runs as a job.
+func renameGenre(c *gin.Context) {
+	from := strings.TrimSpace(c.Param("genre"))
+	var request struct {
+		Name string `json:"name" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&request); err != nil {
+		zapLogger.Warn("Invalid request data", zap.Error(err))
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request: " + err.Error()})
+		return
+	}
+	to := strings.Join(strings.Fields(request.Name), " ")
+	zapLogger.Info("renameGenre handler called", zap.String("from", from), zap.String("to", to))
+
+	if from == "" || to == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request: genre names must not be empty"})
+		return
+	}
+	if running := runningJobs(genreRenameJobType); len(running) > 0 {
+		c.JSON(http.StatusConflict, gin.H{"error": "A genre rename is already running", "job": running[0]})
+		return
+	}
+	c.JSON(http.StatusAccepted, startGenreRename(from, to))
+}
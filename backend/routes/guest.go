@@ -0,0 +1,114 @@
+package routes
+
+import (
+	"fmt"
+	"mangahub/backend/models"
+	"mangahub/backend/users"
+	"net/http"
+	"path/filepath"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Guest access levels: what visitors who aren't signed in may do
+const (
+	guestRead   = "read"   // Browse and read the public catalog
+	guestSafe   = "safe"   // Browse and read, except series marked NSFW
+	guestBrowse = "browse" // Browse the catalog, but not open chapters
+	guestNone   = "none"   // Nothing but sign in
+)
+
+var guestLevels = []string{guestRead, guestSafe, guestBrowse, guestNone}
+
+// guestAccess is the configured guest access level
+var guestAccess = guestRead
+
+// SetGuestAccess sets what visitors who aren't signed in may do; empty keeps
+// the default, "read"
+func SetGuestAccess(level string) error {
+	level = strings.ToLower(strings.TrimSpace(level))
+	if level == "" {
+		level = guestRead
+	}
+	for _, l := range guestLevels {
+		if l == level {
+			guestAccess = level
+			return nil
+		}
+	}
+	return fmt.Errorf("guest access must be one of %s, got %q", strings.Join(guestLevels, ", "), level)
+}
+
+// guestCanSee reports whether visitors who aren't signed in may see a series
+func guestCanSee(manga *models.MangaSeries) bool {
+	switch {
+	case !manga.IsPublic() || guestAccess == guestNone:
+		return false
+	case guestAccess == guestSafe:
+		return !manga.NSFW
+	}
+	return true
+}
+
+// guestGate holds API requests from visitors who aren't signed in to the
+// guest access level, except on the series their share token is for. Which
+// series they see is up to canSeeSeries.
+func guestGate(c *gin.Context) {
+	path := c.FullPath()
+	if currentUser(c) != nil || (strings.HasPrefix(path, "/api/manga/:id") && sharedWithRequester(c, c.Param("id"))) {
+		c.Next()
+		return
+	}
+
+	switch {
+	case guestAccess == guestNone && !strings.HasPrefix(path, "/api/auth/"),
+		guestAccess == guestBrowse && strings.HasPrefix(path, "/api/manga/:id/chapter/"):
+		c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Authentication required"})
+		return
+	}
+	c.Next()
+}
+
+// ImageAccess guards the library files served under /manga-images: series
+// the requester may not see and chapters not published yet are hidden, as
+// are the page images of every series when guests may only browse, unless a
+// share token grants them
+func ImageAccess(c *gin.Context) {
+	resolveUser(c)
+	if token := currentAPIToken(c); token != nil && !token.HasScope(users.ScopeRead) {
+		c.AbortWithStatus(http.StatusForbidden)
+		return
+	}
+
+	parts := strings.Split(strings.TrimPrefix(c.Param("filepath"), "/"), "/")
+	if strings.HasPrefix(parts[0], ".") {
+		c.AbortWithStatus(http.StatusNotFound)
+		return
+	}
+
+	mangaPath := filepath.Join(metadataManager.RootDir, parts[0])
+	manga, ok := libraryIndex.GetByPath(mangaPath)
+	if !ok {
+		// Not indexed yet, during warm-up or until the next sync, so load the
+		// series to apply the same rules
+		loaded, err := metadataManager.LoadMangaDir(mangaPath)
+		if err != nil {
+			c.AbortWithStatus(http.StatusNotFound)
+			return
+		}
+		manga = &loaded
+	}
+	if currentUser(c) == nil && !sharedWithRequester(c, manga.ID) {
+		// Series folders hold covers; anything deeper is a chapter
+		if guestAccess == guestNone || (guestAccess == guestBrowse && len(parts) > 2) {
+			c.AbortWithStatus(http.StatusUnauthorized)
+			return
+		}
+	}
+	if !canSeeSeries(c, manga) || (len(parts) > 2 && (!sharedChapterDir(c, manga, parts[1]) || !publishedChapterDir(c, manga, parts[1]))) {
+		c.AbortWithStatus(http.StatusNotFound)
+		return
+	}
+	c.Next()
+}
@@ -0,0 +1,126 @@
+package routes
+
+import (
+	"mangahub/backend/models"
+	"mangahub/backend/reviews"
+	"net/http"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// mangaSummary is the listing representation of a series
+func mangaSummary(manga *models.MangaSeries, rating reviews.Summary) gin.H {
+	return gin.H{
+		"id":           manga.ID,
+		"title":        manga.Title,
+		"description":  manga.Description,
+		"coverImage":   manga.GetCoverImageURL(),
+		"genres":       manga.Genres,
+		"tags":         tagStore.CanonicalList(manga.Tags),
+		"author":       manga.Author,
+		"status":       manga.Status,
+		"chapterCount": manga.ChapterCount,
+		"publisher":    manga.Publisher,
+		"demographic":  manga.Demographic,
+		"rating":       rating.Average,
+		"ratingCount":  rating.Count,
+	}
+}
+
+// chapterSummary is the listing representation of a chapter
+func chapterSummary(manga *models.MangaSeries, chapter *models.Chapter) gin.H {
+	return gin.H{
+		"id":           chapter.ID,
+		"mangaId":      chapter.MangaID,
+		"number":       chapter.Number,
+		"title":        chapter.Title,
+		"releaseDate":  timestamp(chapter.ReleaseDate),
+		"pageCount":    chapter.PageCount,
+		"volume":       chapter.Volume,
+		"special":      chapter.Special,
+		"kind":         chapter.Kind,
+		"summary":      chapter.Summary,
+		"sourceUrl":    chapter.SourceURL,
+		"scanlators":   chapter.Scanlators,
+		"thumbnailUrl": chapterThumbnailURL(manga.ID, chapter.Number),
+		"publishAt":    optionalTimestamp(chapter.PublishAt),
+
+		// Listings don't open chapter directories, so this stays null
+		// until the chapter has been measured
+		"readingMinutes": readingMinutes(metadataManager.CachedReadingScreens(chapter)),
+	}
+}
+
+// firstPageOf describes a chapter's first page, or returns nil if the chapter
+// has no readable pages
+func firstPageOf(chapter *models.Chapter) gin.H {
+	pages, err := metadataManager.LoadPages(chapter)
+	if err != nil || len(pages) == 0 {
+		return nil
+	}
+	page := &pages[0]
+	if err := page.LoadImageMetadata(); err != nil {
+		zapLogger.Warn("Failed to read page dimensions", zap.String("imagePath", page.ImagePath), zap.Error(err))
+	}
+	variants, srcset := pageVariants(page, chapter.Number)
+	return gin.H{
+		"number":   page.Number,
+		"imageUrl": page.GetImageURL(),
+		"width":    page.Width,
+		"height":   page.Height,
+		"variants": variants,
+		"srcset":   srcset,
+	}
+}
+
+// parseIncludes reads the comma-separated ?include= parameter, rejecting
+// anything not in allowed. On failure it writes a 400 and returns false.
+func parseIncludes(c *gin.Context, allowed ...string) (map[string]bool, bool) {
+	includes := make(map[string]bool)
+	raw := c.Query("include")
+	if raw == "" {
+		return includes, true
+	}
+	for _, name := range strings.Split(raw, ",") {
+		name = strings.TrimSpace(name)
+		if !slices.Contains(allowed, name) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid include: " + name + "; allowed values are " + strings.Join(allowed, ", ")})
+			return nil, false
+		}
+		includes[name] = true
+	}
+	return includes, true
+}
+
+// saveManga writes a series' metadata.json and refreshes it in the library
+// index. On failure it writes the error response and returns false.
+func saveManga(c *gin.Context, manga *models.MangaSeries) bool {
+	metadataPath := filepath.Join(manga.Path, models.MetadataFileName)
+	if err := manga.SaveToJSON(metadataPath); err != nil {
+		zapLogger.Error("Failed to save manga metadata",
+			zap.String("metadataPath", metadataPath),
+			zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save manga metadata: " + err.Error()})
+		return false
+	}
+	libraryIndex.Refresh(manga.Path)
+	return true
+}
+
+// timestamp formats a time for responses: RFC 3339 in UTC, to the second
+func timestamp(t time.Time) string {
+	return t.UTC().Format(time.RFC3339)
+}
+
+// optionalTimestamp formats a time that may be unset, see timestamp
+func optionalTimestamp(t *time.Time) interface{} {
+	if t == nil {
+		return nil
+	}
+	return timestamp(*t)
+}
@@ -0,0 +1,108 @@
+package routes
+
+import (
+	"bytes"
+	"encoding/json"
+	"mangahub/backend/i18n"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// translatedFields are the response fields holding messages meant for people
+var translatedFields = []string{"error", "message", "status"}
+
+// localize translates the messages in JSON responses into the language the
+// client asks for with Accept-Language. English responses pass through
+// untouched; others are buffered so their messages can be replaced.
+func localize(c *gin.Context) {
+	lang := i18n.Match(c.GetHeader("Accept-Language"))
+	c.Header("Content-Language", lang)
+	c.Writer.Header().Add("Vary", "Accept-Language")
+	if lang == i18n.Default {
+		c.Next()
+		return
+	}
+
+	writer := &localizingWriter{ResponseWriter: c.Writer}
+	c.Writer = writer
+	c.Next()
+	c.Writer = writer.ResponseWriter
+	writer.flush(lang)
+}
+
+// localizingWriter holds back JSON object bodies until the handler is done.
+// Anything else, such as a streamed array, passes straight through.
+type localizingWriter struct {
+	gin.ResponseWriter
+	body        bytes.Buffer
+	passthrough bool
+}
+
+// buffering decides on the first write whether the body is held back
+func (w *localizingWriter) buffering(first byte) bool {
+	if w.body.Len() > 0 {
+		return true
+	}
+	if !w.passthrough && !(strings.HasPrefix(w.Header().Get("Content-Type"), "application/json") && first == '{') {
+		w.passthrough = true
+	}
+	return !w.passthrough
+}
+
+func (w *localizingWriter) Write(data []byte) (int, error) {
+	if len(data) == 0 || !w.buffering(data[0]) {
+		return w.ResponseWriter.Write(data)
+	}
+	return w.body.Write(data)
+}
+
+func (w *localizingWriter) WriteString(s string) (int, error) {
+	if len(s) == 0 || !w.buffering(s[0]) {
+		return w.ResponseWriter.WriteString(s)
+	}
+	return w.body.WriteString(s)
+}
+
+// flush writes the buffered body with its messages translated
+func (w *localizingWriter) flush(lang string) {
+	if w.body.Len() == 0 {
+		return
+	}
+	body := w.body.Bytes()
+	if translated, ok := translateMessages(body, lang); ok {
+		body = translated
+	}
+	w.ResponseWriter.Write(body)
+}
+
+// translateMessages translates the message fields of a JSON object
+func translateMessages(body []byte, lang string) ([]byte, bool) {
+	if len(body) == 0 || body[0] != '{' {
+		return nil, false
+	}
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+	var object map[string]interface{}
+	if err := decoder.Decode(&object); err != nil {
+		return nil, false
+	}
+
+	changed := false
+	for _, field := range translatedFields {
+		if message, ok := object[field].(string); ok {
+			if translated := i18n.Translate(lang, message); translated != message {
+				object[field] = translated
+				changed = true
+			}
+		}
+	}
+	if !changed {
+		return nil, false
+	}
+	translated, err := json.Marshal(object)
+	if err != nil {
+		return nil, false
+	}
+	return translated, true
+}
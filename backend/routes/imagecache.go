@@ -0,0 +1,56 @@
+package routes
+
+import (
+	"errors"
+	"io/fs"
+	"net/http"
+	"os"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// imageCacheMaxBytes caps the size of the image cache; 0 is unlimited
+var imageCacheMaxBytes int64
+
+// SetImageCacheLimit caps the image cache at maxMB megabytes, removing the
+// least recently used images to stay under it; 0 is unlimited
+func SetImageCacheLimit(maxMB int) {
+	if maxMB >= 0 {
+		imageCacheMaxBytes = int64(maxMB) << 20
+	}
+}
+
+// openCached opens a file of the image cache whose path get returns, calling
+// get again to regenerate the file if it was evicted before it could be
+// opened. An open file can still be served if it is evicted meanwhile.
+func openCached(get func() (string, error)) (*os.File, error) {
+	path, err := get()
+	if err != nil {
+		return nil, err
+	}
+	file, err := os.Open(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		zapLogger.Debug("Cached image evicted before it was served, regenerating", zap.String("path", path))
+		if path, err = get(); err != nil {
+			return nil, err
+		}
+		file, err = os.Open(path)
+	}
+	return file, err
+}
+
+// getImageCache returns the size of the image cache and how often it was
+// hit since the server started
+func getImageCache(c *gin.Context) {
+	c.JSON(http.StatusOK, imageCache.Stats())
+}
+
+// flushImageCache removes every cached image; they are generated again as
+// they are requested
+func flushImageCache(c *gin.Context) {
+	zapLogger.Info("flushImageCache handler called")
+	files, bytes := imageCache.Flush()
+	zapLogger.Info("Flushed image cache", zap.Int("files", files), zap.Int64("bytes", bytes))
+	c.JSON(http.StatusOK, gin.H{"status": "flushed", "files": files, "bytes": bytes})
+}
@@ -0,0 +1,226 @@
+package routes
+
+import (
+	"fmt"
+	"mangahub/backend/imaging"
+	"mangahub/backend/models"
+	"mime"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// imageCacheMaxAge is how long clients may cache page images, in seconds
+const imageCacheMaxAge = 7 * 24 * 60 * 60
+
+// variantScales are the resolution variants offered for every page, largest first
+var variantScales = []float64{1, 0.5, 0.25}
+
+// chapterThumbnailWidth is the width of chapter preview thumbnails
+const chapterThumbnailWidth = 160
+
+// imageCache holds generated image variants
+var imageCache *imaging.Cache
+
+// chapterThumbnailURL returns the API URL serving a chapter's thumbnail
+func chapterThumbnailURL(mangaID string, chapterNumber float64) string {
+	return fmt.Sprintf("/api/manga/%s/chapter/%s/thumbnail",
+		url.PathEscape(mangaID), models.FormatChapterNumber(chapterNumber))
+}
+
+// pageImageURL returns the API URL serving a page image at the given scale
+func pageImageURL(mangaID string, chapterNumber float64, pageNumber int, scale float64) string {
+	u := fmt.Sprintf("/api/manga/%s/chapter/%s/page/%d/image",
+		url.PathEscape(mangaID), models.FormatChapterNumber(chapterNumber), pageNumber)
+	if scale != 1 {
+		u += "?scale=" + strconv.FormatFloat(scale, 'f', -1, 64)
+	}
+	return u
+}
+
+// pageVariants describes the resolution variants of a page for building srcset
+// attributes. Widths are only included when the page dimensions are known.
+func pageVariants(page *models.Page, chapterNumber float64) ([]gin.H, string) {
+	variants := make([]gin.H, 0, len(variantScales))
+	srcset := make([]string, 0, len(variantScales))
+	for _, scale := range variantScales {
+		u := pageImageURL(page.MangaID, chapterNumber, page.Number, scale)
+		variant := gin.H{"scale": scale, "url": u}
+		if page.Width > 0 {
+			width := max(int(float64(page.Width)*scale+0.5), 1)
+			variant["width"] = width
+			srcset = append(srcset, fmt.Sprintf("%s %dw", u, width))
+		}
+		variants = append(variants, variant)
+	}
+	return variants, strings.Join(srcset, ", ")
+}
+
+// parseVariantScale validates the ?scale query parameter against variantScales
+func parseVariantScale(c *gin.Context) (float64, bool) {
+	raw := c.Query("scale")
+	if raw == "" {
+		return 1, true
+	}
+	scale, err := strconv.ParseFloat(raw, 64)
+	if err != nil {
+		return 0, false
+	}
+	for _, allowed := range variantScales {
+		if scale == allowed {
+			return scale, true
+		}
+	}
+	return 0, false
+}
+
+// parseImageFilters reads the comma-separated ?filter= query parameter
+func parseImageFilters(c *gin.Context) ([]string, bool) {
+	raw := c.Query("filter")
+	if raw == "" {
+		return nil, true
+	}
+	var filters []string
+	for _, f := range strings.Split(raw, ",") {
+		f = strings.ToLower(strings.TrimSpace(f))
+		if !imaging.IsFilter(f) {
+			return nil, false
+		}
+		filters = append(filters, f)
+	}
+	return filters, true
+}
+
+// getPageImage streams the image file of a single page. Optional query
+// parameters select a resolution variant (scale=0.5) and enhancement filters
+// (filter=grayscale,autocontrast,invert). Series with autoCrop enabled have
+// their page margins trimmed. Results are cached per combination.
+func getPageImage(c *gin.Context) {
+	imageRequests.Add(1)
+	defer imageRequests.Add(-1)
+
+	zapLogger.Info("getPageImage handler called",
+		zap.String("mangaID", c.Param("id")),
+		zap.String("chapterNumber", c.Param("chapterNumber")),
+		zap.String("pageNumber", c.Param("pageNumber")),
+	)
+
+	scale, ok := parseVariantScale(c)
+	if !ok {
+		zapLogger.Warn("Invalid image scale", zap.String("scale", c.Query("scale")))
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid scale; allowed values are 1, 0.5 and 0.25"})
+		return
+	}
+
+	filters, ok := parseImageFilters(c)
+	if !ok {
+		zapLogger.Warn("Invalid image filter", zap.String("filter", c.Query("filter")))
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid filter; allowed values are grayscale, autocontrast and invert"})
+		return
+	}
+
+	lookup, ok := lookupChapter(c)
+	if !ok {
+		return
+	}
+	_, page, ok := lookupPage(c, lookup.Chapter())
+	if !ok {
+		return
+	}
+
+	opts := imaging.Options{
+		Scale:       scale,
+		Filters:     filters,
+		CropMargins: lookup.Manga.AutoCrop,
+	}
+	file, err := openCached(func() (string, error) { return imageCache.Get(page.ImagePath, opts) })
+	if err != nil {
+		zapLogger.Error("Failed to generate image variant",
+			zap.String("imagePath", page.ImagePath),
+			zap.Float64("scale", scale),
+			zap.Error(err),
+		)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate image: " + err.Error()})
+		return
+	}
+	defer file.Close()
+
+	serveImageFile(c, file)
+}
+
+// getChapterThumbnail serves a small preview of a chapter's first page,
+// generating it on first request
+func getChapterThumbnail(c *gin.Context) {
+	imageRequests.Add(1)
+	defer imageRequests.Add(-1)
+
+	zapLogger.Info("getChapterThumbnail handler called",
+		zap.String("mangaID", c.Param("id")),
+		zap.String("chapterNumber", c.Param("chapterNumber")),
+	)
+
+	lookup, ok := lookupChapter(c)
+	if !ok {
+		return
+	}
+	pages, err := metadataManager.LoadPages(lookup.Chapter())
+	if err != nil {
+		zapLogger.Error("Failed to retrieve pages", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve pages: " + err.Error()})
+		return
+	}
+	if len(pages) == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Chapter has no pages"})
+		return
+	}
+
+	opts := imaging.Options{
+		MaxWidth:    chapterThumbnailWidth,
+		CropMargins: lookup.Manga.AutoCrop,
+	}
+	file, err := openCached(func() (string, error) { return imageCache.Get(pages[0].ImagePath, opts) })
+	if err != nil {
+		zapLogger.Error("Failed to generate chapter thumbnail",
+			zap.String("imagePath", pages[0].ImagePath),
+			zap.Error(err),
+		)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate thumbnail: " + err.Error()})
+		return
+	}
+	defer file.Close()
+
+	serveImageFile(c, file)
+}
+
+// serveImageFile writes an open image with its content type and caching
+// headers. Conditional and range requests are handled by http.ServeContent.
+func serveImageFile(c *gin.Context, file *os.File) {
+	info, err := file.Stat()
+	if err != nil {
+		zapLogger.Error("Failed to stat image", zap.String("path", file.Name()), zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to read image: " + err.Error()})
+		return
+	}
+
+	contentType := mime.TypeByExtension(filepath.Ext(file.Name()))
+	if contentType == "" {
+		header := make([]byte, 512)
+		n, _ := file.Read(header)
+		contentType = http.DetectContentType(header[:n])
+		if _, err := file.Seek(0, 0); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to read image: " + err.Error()})
+			return
+		}
+	}
+
+	c.Header("Content-Type", contentType)
+	c.Header("Cache-Control", fmt.Sprintf("public, max-age=%d", imageCacheMaxAge))
+	c.Header("ETag", fmt.Sprintf(`"%x-%x"`, info.ModTime().UnixNano(), info.Size()))
+	http.ServeContent(c.Writer, c.Request, "", info.ModTime(), file)
+}
@@ -0,0 +1,87 @@
+package routes
+
+import (
+	"fmt"
+	"mangahub/backend/imaging"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// OriginalsDir is the hidden folder inside a chapter that keeps the pages an
+// import policy replaced
+const OriginalsDir = ".originals"
+
+// ImportPolicy caps the size of imported pages. Pages over the limits are
+// downscaled or recompressed; KeepOriginals saves the untouched files.
+type ImportPolicy struct {
+	Limits        imaging.Limits
+	KeepOriginals bool
+}
+
+var libraryImportPolicy, personalImportPolicy ImportPolicy
+
+// SetImportPolicies sets the import policies of the shared library and of
+// personal libraries
+func SetImportPolicies(library, personal ImportPolicy) {
+	libraryImportPolicy = library
+	personalImportPolicy = personal
+}
+
+// importPolicyFor returns the policy of the library the series at mangaPath
+// belongs to
+func importPolicyFor(mangaPath string) ImportPolicy {
+	if manga, ok := libraryIndex.GetByPath(mangaPath); ok && manga.Owner != "" {
+		return personalImportPolicy
+	}
+	return libraryImportPolicy
+}
+
+// applyImportPolicy shrinks the oversized pages gathered in a staging
+// directory and returns how many it replaced
+func applyImportPolicy(stagingPath string, policy ImportPolicy) (int, error) {
+	if policy.Limits.IsZero() {
+		return 0, nil
+	}
+	entries, err := os.ReadDir(stagingPath)
+	if err != nil {
+		return 0, err
+	}
+
+	replaced := 0
+	for _, entry := range entries {
+		if entry.IsDir() || strings.HasPrefix(entry.Name(), ".") {
+			continue
+		}
+		path := filepath.Join(stagingPath, entry.Name())
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return replaced, err
+		}
+		fitted, format, changed, err := imaging.Fit(data, policy.Limits)
+		if err != nil {
+			return replaced, fmt.Errorf("%s: %w", entry.Name(), err)
+		}
+		if !changed {
+			continue
+		}
+
+		if policy.KeepOriginals {
+			originals := filepath.Join(stagingPath, OriginalsDir)
+			if err := os.MkdirAll(originals, 0755); err != nil {
+				return replaced, err
+			}
+			if err := os.Rename(path, filepath.Join(originals, entry.Name())); err != nil {
+				return replaced, err
+			}
+		} else if err := os.Remove(path); err != nil {
+			return replaced, err
+		}
+		target := strings.TrimSuffix(path, filepath.Ext(path)) + imaging.Extension(format)
+		if err := os.WriteFile(target, fitted, 0644); err != nil {
+			return replaced, err
+		}
+		replaced++
+	}
+	return replaced, nil
+}
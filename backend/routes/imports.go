@@ -0,0 +1,314 @@
+package routes
+
+import (
+	"mangahub/backend/importers"
+	"mangahub/backend/models"
+	"mangahub/backend/progress"
+	"mangahub/backend/slug"
+	"mangahub/backend/users"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// importLibrary reads a Komga or Kavita server's library, ComicRack reading
+// lists or a Calibre library folder, and recreates its series metadata, read
+// progress and collections here. Servers are reached at url; reading lists and
+// Calibre libraries are read from path on this machine. Series are matched to
+// MangaHub series by folder name, then by title. Read progress is written for
+// userId, or the signed-in user when it is omitted.
+func importLibrary(c *gin.Context) {
+	sourceName := c.Param("source")
+	zapLogger.Info("importLibrary handler called", zap.String("source", sourceName))
+
+	var request struct {
+		URL      string `json:"url"`
+		Path     string `json:"path"`
+		Username string `json:"username"`
+		Password string `json:"password"`
+		APIKey   string `json:"apiKey"`
+		UserID   string `json:"userId"`
+	}
+	if err := c.ShouldBindJSON(&request); err != nil {
+		zapLogger.Warn("Invalid request data", zap.Error(err))
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request: " + err.Error()})
+		return
+	}
+
+	// Servers are reached over HTTP; the other sources are files on this machine
+	local := false
+	switch sourceName {
+	case "komga", "kavita":
+		if !strings.HasPrefix(request.URL, "http://") && !strings.HasPrefix(request.URL, "https://") {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "URL must be http or https"})
+			return
+		}
+	case "comicrack", "calibre":
+		if request.Path == "" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "A path is required"})
+			return
+		}
+		local = true
+	}
+
+	var source importers.Source
+	switch sourceName {
+	case "komga":
+		if request.APIKey == "" && request.Username == "" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Komga requires an apiKey or a username and password"})
+			return
+		}
+		source = &importers.Komga{BaseURL: request.URL, Username: request.Username, Password: request.Password, APIKey: request.APIKey}
+	case "kavita":
+		if request.APIKey == "" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Kavita requires an apiKey"})
+			return
+		}
+		source = &importers.Kavita{BaseURL: request.URL, APIKey: request.APIKey}
+	case "comicrack":
+		source = &importers.ComicRack{Path: request.Path}
+	case "calibre":
+		source = &importers.Calibre{Path: request.Path}
+	default:
+		c.JSON(http.StatusNotFound, gin.H{"error": "Unknown import source: " + sourceName})
+		return
+	}
+
+	reader := currentUser(c)
+	if request.UserID != "" {
+		user, err := userStore.Get(request.UserID)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Unknown user: " + request.UserID})
+			return
+		}
+		reader = user
+	}
+
+	library, err := source.Fetch(c.Request.Context())
+	if err != nil {
+		zapLogger.Error("Failed to read library", zap.String("source", sourceName), zap.Error(err))
+		status := http.StatusBadGateway
+		if local {
+			status = http.StatusUnprocessableEntity
+		}
+		c.JSON(status, gin.H{"error": "Failed to read " + sourceName + " library: " + err.Error()})
+		return
+	}
+
+	mangas, err := indexedManga(c)
+	if err != nil {
+		zapLogger.Error("Failed to retrieve manga list", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve manga list: " + err.Error()})
+		return
+	}
+	match := newSeriesMatcher(sharedSeries(mangas))
+
+	matched := []gin.H{}
+	unmatched := []gin.H{}
+	mangaIDs := make(map[string]string) // source series ID -> MangaHub ID
+	progressImported := 0
+	for _, series := range library.Series {
+		manga := match(series)
+		if manga == nil {
+			unmatched = append(unmatched, gin.H{"sourceId": series.SourceID, "title": series.Title})
+			continue
+		}
+		mangaIDs[series.SourceID] = manga.ID
+
+		applyImportedMetadata(manga, series)
+		if !saveManga(c, manga) {
+			return
+		}
+		matched = append(matched, gin.H{"sourceId": series.SourceID, "title": series.Title, "mangaId": manga.ID})
+
+		if reader == nil {
+			continue
+		}
+		imported, err := importProgress(reader, manga, series.Progress)
+		if err != nil {
+			zapLogger.Error("Failed to save progress", zap.Error(err))
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save progress: " + err.Error()})
+			return
+		}
+		if imported {
+			progressImported++
+		}
+	}
+
+	collectionIDs := []string{}
+	for _, collection := range library.Collections {
+		var ids []string
+		for _, sourceID := range collection.SeriesIDs {
+			if id, ok := mangaIDs[sourceID]; ok {
+				ids = append(ids, id)
+			}
+		}
+		if len(ids) == 0 {
+			continue
+		}
+		saved, err := collectionStore.Merge(collection.Name, collection.Summary, ids)
+		if err != nil {
+			zapLogger.Error("Failed to save collection", zap.Error(err))
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save collection: " + err.Error()})
+			return
+		}
+		collectionIDs = append(collectionIDs, saved.ID)
+	}
+
+	zapLogger.Info("Library import complete",
+		zap.String("source", sourceName),
+		zap.Int("matched", len(matched)),
+		zap.Int("unmatched", len(unmatched)),
+		zap.Int("progressImported", progressImported),
+		zap.Int("collections", len(collectionIDs)),
+	)
+
+	response := gin.H{
+		"source":           library.Source,
+		"matched":          matched,
+		"unmatched":        unmatched,
+		"progressImported": progressImported,
+		"collections":      collectionIDs,
+	}
+	if reader != nil {
+		response["userId"] = reader.ID
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+// newSeriesMatcher returns a function finding the local series an imported
+// series corresponds to: by folder name first, then by slug, then by title or
+// alternative title
+func newSeriesMatcher(mangas []models.MangaSeries) func(importers.Series) *models.MangaSeries {
+	byFolder := make(map[string]*models.MangaSeries)
+	byID := make(map[string]*models.MangaSeries)
+	byTitle := make(map[string]*models.MangaSeries)
+	for i := range mangas {
+		manga := &mangas[i]
+		byFolder[strings.ToLower(filepath.Base(manga.Path))] = manga
+		byID[manga.ID] = manga
+		for _, title := range append([]string{manga.Title}, manga.AltTitles...) {
+			if _, taken := byTitle[strings.ToLower(title)]; !taken {
+				byTitle[strings.ToLower(title)] = manga
+			}
+		}
+	}
+
+	return func(series importers.Series) *models.MangaSeries {
+		if folder := sourceFolderName(series.Path); folder != "" {
+			if manga, ok := byFolder[strings.ToLower(folder)]; ok {
+				return manga
+			}
+		}
+		if manga, ok := byID[slug.Make(series.Title)]; ok {
+			return manga
+		}
+		for _, title := range append([]string{series.Title}, series.AltTitles...) {
+			if manga, ok := byTitle[strings.ToLower(title)]; ok {
+				return manga
+			}
+		}
+		return nil
+	}
+}
+
+// sourceFolderName returns the last element of a path from the source server,
+// which may use either slash style or be a file:// URL
+func sourceFolderName(path string) string {
+	path = strings.TrimRight(path, `/\`)
+	if i := strings.LastIndexAny(path, `/\`); i >= 0 {
+		path = path[i+1:]
+	}
+	return path
+}
+
+// applyImportedMetadata overwrites a series' metadata with the non-empty
+// values read from the source server, except for locked fields
+func applyImportedMetadata(manga *models.MangaSeries, series importers.Series) {
+	titles := series.AltTitles
+	if series.Title != "" && series.Title != manga.Title && !manga.FieldLocked(models.FieldTitle) {
+		titles = append(titles, manga.Title)
+		manga.Title = series.Title
+	} else if series.Title != "" {
+		titles = append(titles, series.Title)
+	}
+	if !manga.FieldLocked(models.FieldAltTitles) {
+		for _, title := range titles {
+			title = strings.TrimSpace(title)
+			if title == "" || equalIgnoreCase(title, manga.Title) || hasAltTitle(manga.AltTitles, title) {
+				continue
+			}
+			manga.AltTitles = append(manga.AltTitles, title)
+		}
+	}
+	if series.Summary != "" && !manga.FieldLocked(models.FieldDescription) {
+		manga.Description = series.Summary
+	}
+	if series.Status != "" && !manga.FieldLocked(models.FieldStatus) {
+		manga.Status = series.Status
+	}
+	if len(series.Genres) > 0 && !manga.FieldLocked(models.FieldGenres) {
+		manga.Genres = series.Genres
+	}
+	if len(series.Tags) > 0 && !manga.FieldLocked(models.FieldTags) {
+		manga.Tags = tagStore.CanonicalList(append(manga.Tags, series.Tags...))
+	}
+	if series.Publisher != "" && !manga.FieldLocked(models.FieldPublisher) {
+		manga.Publisher = series.Publisher
+	}
+	if demographic, err := models.NormalizeDemographic(series.Demographic); err == nil && demographic != "" && !manga.FieldLocked(models.FieldDemographic) {
+		manga.Demographic = demographic
+	}
+	if len(series.Authors) > 0 && !manga.FieldLocked(models.FieldAuthor) {
+		manga.Author = strings.Join(series.Authors, ", ")
+	}
+	if len(series.Artists) > 0 && !manga.FieldLocked(models.FieldArtist) {
+		manga.Artist = strings.Join(series.Artists, ", ")
+	}
+	if series.Year > 0 && !manga.FieldLocked(models.FieldPublishedYear) {
+		manga.PublishedYear = series.Year
+	}
+	manga.LastUpdated = timeNow()
+}
+
+// importProgress turns per-chapter progress into the user's position in the
+// series: the furthest chapter touched. Progress already recorded here that is
+// newer than the imported progress is kept.
+func importProgress(user *users.User, manga *models.MangaSeries, chapters []importers.ChapterProgress) (bool, error) {
+	if len(chapters) == 0 {
+		return false, nil
+	}
+
+	furthest := chapters[0]
+	completed := 0
+	var readAt time.Time
+	for _, chapter := range chapters {
+		if chapter.Number > furthest.Number {
+			furthest = chapter
+		}
+		if chapter.Completed {
+			completed++
+		}
+		if chapter.ReadAt.After(readAt) {
+			readAt = chapter.ReadAt
+		}
+	}
+
+	if existing, ok := progressStore.Get(user.ID, manga.ID); ok && !readAt.IsZero() && existing.UpdatedAt.After(readAt) {
+		return false, nil
+	}
+
+	_, err := progressStore.Set(progress.Entry{
+		UserID:    user.ID,
+		MangaID:   manga.ID,
+		Chapter:   furthest.Number,
+		Page:      max(furthest.Page, 1),
+		Completed: manga.ChapterCount > 0 && completed >= manga.ChapterCount,
+		UpdatedAt: readAt.UTC(),
+	})
+	return err == nil, err
+}
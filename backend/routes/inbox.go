@@ -0,0 +1,175 @@
+package routes
+
+import (
+	"context"
+	"fmt"
+	"mangahub/backend/importers"
+	"mangahub/backend/inbox"
+	"mangahub/backend/jobs"
+	"mangahub/backend/models"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// inboxJobType identifies inbox imports in the jobs API
+const inboxJobType = "inbox-import"
+
+var (
+	inboxDir      string
+	inboxInterval = inbox.DefaultInterval
+)
+
+// SetInbox sets the folder watched for chapters to import automatically and
+// how often it is scanned; an empty dir disables the inbox
+func SetInbox(dir string, interval time.Duration) {
+	inboxDir = dir
+	if interval > 0 {
+		inboxInterval = interval
+	}
+}
+
+// startInbox begins watching the inbox, if one is configured
+func startInbox() {
+	if inboxDir == "" {
+		return
+	}
+	watcher, err := inbox.NewWatcher(inboxDir)
+	if err != nil {
+		zapLogger.Error("Failed to set up inbox", zap.String("dir", inboxDir), zap.Error(err))
+		return
+	}
+	zapLogger.Info("Watching inbox", zap.String("dir", inboxDir), zap.Duration("interval", inboxInterval))
+	go watcher.Run(inboxInterval, nil, func(items []inbox.Item) {
+		if !libraryIndex.Ready() {
+			// Matching needs the full library; the items are picked up again
+			// on a later scan
+			return
+		}
+		importInboxItems(watcher, items)
+	})
+}
+
+// importInboxItems imports the chapters found in one inbox scan as a job and
+// waits for it, so the next scan doesn't see the same items again
+func importInboxItems(watcher *inbox.Watcher, items []inbox.Item) {
+	done := make(chan struct{})
+	jobManager.Start(inboxJobType, func(ctx context.Context, progress *jobs.Progress) (interface{}, error) {
+		defer close(done)
+
+		imported := []gin.H{}
+		failed := []gin.H{}
+		for i, item := range items {
+			progress.Update(i, len(items), "Importing "+item.Name())
+			if err := ctx.Err(); err != nil {
+				// Cancelled; the rest stay in the inbox for the next scan
+				break
+			}
+
+			chapter, err := importInboxItem(ctx, item)
+			if err != nil {
+				watcher.Reject(item.Path, err)
+				failed = append(failed, gin.H{"file": item.Name(), "error": err.Error()})
+				continue
+			}
+			watcher.Done(item)
+			imported = append(imported, gin.H{
+				"file":      item.Name(),
+				"mangaId":   chapter.MangaID,
+				"chapterId": chapter.ID,
+				"number":    chapter.Number,
+				"pageCount": chapter.PageCount,
+			})
+		}
+		progress.Update(len(imported)+len(failed), len(items), "")
+
+		result := gin.H{"imported": imported, "failed": failed}
+		if len(failed) > 0 {
+			return result, fmt.Errorf("%d of %d inbox items failed to import", len(failed), len(items))
+		}
+		return result, nil
+	})
+	<-done
+}
+
+// importInboxItem adds one inbox chapter to its series, creating the series
+// if no existing one matches
+func importInboxItem(ctx context.Context, item inbox.Item) (*models.Chapter, error) {
+	manga := newSeriesMatcher(sharedSeries(libraryIndex.List()))(importers.Series{Title: item.Series})
+	if manga == nil {
+		var err error
+		if manga, err = createInboxSeries(item.Series); err != nil {
+			return nil, fmt.Errorf("creating series: %w", err)
+		}
+	}
+
+	chapter := models.Chapter{
+		ID:      chapterIDFor(item.Number),
+		MangaID: manga.ID,
+		Number:  item.Number,
+		Volume:  item.Volume,
+	}
+	chapter.Classify(item.Name())
+	chapterPath, err := chapterFolderPath(manga, &chapter)
+	if err != nil {
+		return nil, err
+	}
+	chapter.Path = chapterPath
+	if _, err := os.Stat(chapter.Path); err == nil {
+		return nil, fmt.Errorf("%s already has chapter %v", manga.ID, item.Number)
+	}
+
+	stagingPath, err := newStagingDir(manga.Path, chapter.ID)
+	if err != nil {
+		return nil, err
+	}
+	var pageCount int
+	if item.Archive {
+		pageCount, err = importers.ExtractArchive(ctx, item.Path, stagingPath, nil)
+	} else {
+		pageCount, err = importers.CopyPages(ctx, item.Path, stagingPath, nil)
+	}
+	if err == nil {
+		err = finishStagedChapter(&chapter, stagingPath, pageCount)
+	}
+	if err != nil {
+		os.RemoveAll(stagingPath)
+		return nil, err
+	}
+
+	libraryIndex.Refresh(manga.Path)
+	notifyChapterPublished(&chapter)
+	notifyChapterImported(&chapter)
+	return &chapter, nil
+}
+
+// createInboxSeries creates an empty series for chapters of an unknown title
+func createInboxSeries(title string) (*models.MangaSeries, error) {
+	id := uniqueMangaID(title)
+	mangaPath, err := seriesFolderPath(id, title)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := os.Stat(mangaPath); err == nil {
+		return nil, fmt.Errorf("series folder %s already exists", filepath.Base(mangaPath))
+	}
+
+	manga := models.MangaSeries{
+		ID:    id,
+		Title: title,
+		Path:  mangaPath,
+	}
+	if err := os.MkdirAll(manga.Path, 0755); err != nil {
+		return nil, err
+	}
+	if err := manga.SaveToJSON(filepath.Join(manga.Path, models.MetadataFileName)); err != nil {
+		return nil, err
+	}
+	libraryIndex.Refresh(manga.Path)
+
+	zapLogger.Info("Manga created from inbox", zap.String("mangaID", manga.ID), zap.String("title", title))
+	return &manga, nil
+}
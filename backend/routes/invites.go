@@ -0,0 +1,74 @@
+package routes
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// inviteOnly makes registration require an invite once the first account exists
+var inviteOnly bool
+
+// SetInviteOnly makes registration require an invite from an admin
+func SetInviteOnly(enabled bool) {
+	inviteOnly = enabled
+}
+
+// listInvites returns every invite, used or not
+func listInvites(c *gin.Context) {
+	c.JSON(http.StatusOK, userStore.Invites())
+}
+
+// createInvite mints an invite code to hand out, optionally limited in uses
+// and lifetime
+func createInvite(c *gin.Context) {
+	var request struct {
+		Note           string `json:"note"`
+		MaxUses        int    `json:"maxUses"`        // 0 for unlimited
+		ExpiresInHours int    `json:"expiresInHours"` // 0 never expires
+	}
+	if err := c.ShouldBindJSON(&request); err != nil {
+		zapLogger.Warn("Invalid request data", zap.Error(err))
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request: " + err.Error()})
+		return
+	}
+
+	createdBy := currentUser(c).ID
+	zapLogger.Info("createInvite handler called", zap.String("createdBy", createdBy))
+
+	ttl := time.Duration(request.ExpiresInHours) * time.Hour
+	invite, err := userStore.CreateInvite(createdBy, request.Note, request.MaxUses, ttl)
+	if err != nil {
+		respondUserError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, invite)
+}
+
+// revokeInvite deletes an invite so it can't be used any more
+func revokeInvite(c *gin.Context) {
+	code := c.Param("code")
+	zapLogger.Info("revokeInvite handler called", zap.String("code", code))
+
+	if err := userStore.RevokeInvite(code); err != nil {
+		respondUserError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"status": "revoked", "code": code})
+}
+
+// checkInvite lets the sign-up page validate an invite link before asking
+// for account details
+func checkInvite(c *gin.Context) {
+	invite, err := userStore.CheckInvite(c.Param("code"))
+	if err != nil {
+		respondUserError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"valid":     true,
+		"expiresAt": invite.ExpiresAt,
+	})
+}
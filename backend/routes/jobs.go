@@ -0,0 +1,40 @@
+package routes
+
+import (
+	"mangahub/backend/jobs"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// jobManager runs background work such as remote chapter downloads
+var jobManager = jobs.NewManager(jobs.DefaultHistory)
+
+// listJobs returns recent jobs, newest first, optionally filtered by ?type=
+func listJobs(c *gin.Context) {
+	c.JSON(http.StatusOK, jobManager.List(c.Query("type")))
+}
+
+// getJob returns one job, including its progress and, once finished, its result
+func getJob(c *gin.Context) {
+	job, err := jobManager.Get(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Job not found"})
+		return
+	}
+	c.JSON(http.StatusOK, job)
+}
+
+// cancelJob asks a running job to stop
+func cancelJob(c *gin.Context) {
+	id := c.Param("id")
+	zapLogger.Info("cancelJob handler called", zap.String("jobID", id))
+
+	if err := jobManager.Cancel(id); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Job not found"})
+		return
+	}
+	job, _ := jobManager.Get(id)
+	c.JSON(http.StatusAccepted, job)
+}
@@ -0,0 +1,714 @@
+package routes
+
+import (
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"mangahub/backend/epub"
+	"mangahub/backend/imaging"
+	"mangahub/backend/models"
+	"mangahub/backend/progress"
+	"mangahub/backend/users"
+	"math"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// Kobo e-readers sync with the subset of the Kobo store API implemented
+// here, as they do with Calibre-Web or Komga: point api_endpoint in
+// .kobo/Kobo/Kobo eReader.conf at <server>/kobo/<personal API token>. Every
+// chapter is a book, downloaded as a fixed-layout EPUB, and reading states
+// map onto the user's progress in the series. The token needs the read
+// scope, and the progress scope for reading states to be saved.
+
+const (
+	// koboSyncLimit is how many books one sync response carries; the
+	// device asks again while the sync header says to continue
+	koboSyncLimit = 100
+
+	koboSyncTokenHeader = "x-kobo-synctoken"
+	koboSyncHeader      = "x-kobo-sync"
+)
+
+// Kobo reading statuses
+const (
+	koboReadyToRead = "ReadyToRead"
+	koboReading     = "Reading"
+	koboFinished    = "Finished"
+)
+
+// koboEnabled turns on the Kobo sync endpoints
+var koboEnabled bool
+
+// SetKobo enables Kobo sync
+func SetKobo(enabled bool) {
+	koboEnabled = enabled
+}
+
+// requireKobo hides the Kobo endpoints unless Kobo sync is enabled
+func requireKobo(c *gin.Context) {
+	if !koboEnabled {
+		c.AbortWithStatusJSON(http.StatusNotFound, gin.H{"error": "Kobo sync is not enabled"})
+		return
+	}
+	c.Next()
+}
+
+// koboAuth authenticates Kobo requests by the personal API token in the path
+func koboAuth(c *gin.Context) {
+	user, token, err := userStore.UserForAPIToken(c.Param("token"))
+	if err != nil || !token.HasScope(users.ScopeRead) {
+		zapLogger.Warn("Rejected Kobo request without a valid API token", zap.Error(err))
+		c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Invalid or expired API token"})
+		return
+	}
+	c.Set(userContextKey, user)
+	c.Set(apiTokenContextKey, token)
+	c.Next()
+}
+
+// koboBook is a chapter as a Kobo book
+type koboBook struct {
+	ID       string
+	Manga    *models.MangaSeries
+	Chapter  models.Chapter
+	Modified time.Time
+}
+
+// koboRef is what a Kobo ID stands for; ChapterID is empty for a series
+type koboRef struct {
+	MangaID   string
+	ChapterID string
+}
+
+var (
+	koboIDsMu sync.RWMutex
+	koboIDs   = make(map[string]koboRef)
+)
+
+// koboUUID derives a stable UUID, the form of IDs the Kobo API expects,
+// from a name
+func koboUUID(name string) string {
+	sum := sha1.Sum([]byte("mangahub:" + name))
+	sum[6] = sum[6]&0x0f | 0x50 // Version 5
+	sum[8] = sum[8]&0x3f | 0x80 // RFC 4122 variant
+	return fmt.Sprintf("%x-%x-%x-%x-%x", sum[0:4], sum[4:6], sum[6:8], sum[8:10], sum[10:16])
+}
+
+// koboID derives the ID of a series, or of a chapter when chapterID is set.
+// Derived IDs are remembered so requests naming them can be resolved.
+func koboID(mangaID, chapterID string) string {
+	id := koboUUID(mangaID + "/" + chapterID)
+	koboIDsMu.Lock()
+	koboIDs[id] = koboRef{MangaID: mangaID, ChapterID: chapterID}
+	koboIDsMu.Unlock()
+	return id
+}
+
+// resolveKoboID returns what an ID from koboID stands for, deriving the IDs
+// of the whole library when it was not seen since the server started
+func resolveKoboID(c *gin.Context, id string) (koboRef, bool) {
+	koboIDsMu.RLock()
+	ref, ok := koboIDs[id]
+	koboIDsMu.RUnlock()
+	if ok {
+		return ref, true
+	}
+	koboLibrary(c)
+	koboIDsMu.RLock()
+	ref, ok = koboIDs[id]
+	koboIDsMu.RUnlock()
+	return ref, ok
+}
+
+// koboLibrary lists the chapters the user may read as books, least recently
+// modified first
+func koboLibrary(c *gin.Context) []koboBook {
+	var books []koboBook
+	for _, manga := range visibleSeries(c, libraryIndex.List()) {
+		chapters, err := metadataManager.ScanForChapters(&manga)
+		if err != nil {
+			zapLogger.Warn("Failed to retrieve chapters", zap.String("mangaID", manga.ID), zap.Error(err))
+			continue
+		}
+		koboID(manga.ID, "") // Covers are requested by series ID
+		for _, chapter := range visibleChapters(c, chapters) {
+			books = append(books, newKoboBook(&manga, chapter))
+		}
+	}
+	sort.Slice(books, func(i, j int) bool {
+		if !books[i].Modified.Equal(books[j].Modified) {
+			return books[i].Modified.Before(books[j].Modified)
+		}
+		return books[i].ID < books[j].ID
+	})
+	return books
+}
+
+func newKoboBook(manga *models.MangaSeries, chapter models.Chapter) koboBook {
+	book := koboBook{ID: koboID(manga.ID, chapter.ID), Manga: manga, Chapter: chapter}
+	if info, err := os.Stat(chapter.Path); err == nil {
+		book.Modified = info.ModTime().UTC()
+	}
+	return book
+}
+
+// lookupKoboBook resolves the :bookID param, writing a 404 when the book is
+// unknown or not visible to the user
+func lookupKoboBook(c *gin.Context) (*koboBook, bool) {
+	ref, ok := resolveKoboID(c, c.Param("bookID"))
+	if ok && ref.ChapterID != "" {
+		if manga, err := metadataManager.GetMangaByID(ref.MangaID); err == nil && canSeeSeries(c, manga) {
+			if chapters, err := metadataManager.ScanForChapters(manga); err == nil {
+				for _, chapter := range visibleChapters(c, chapters) {
+					if chapter.ID == ref.ChapterID {
+						book := newKoboBook(manga, chapter)
+						return &book, true
+					}
+				}
+			}
+		}
+	}
+	c.JSON(http.StatusNotFound, gin.H{"error": "Book not found"})
+	return nil, false
+}
+
+// koboBase is the URL the device reaches these endpoints under
+func koboBase(c *gin.Context) string {
+	return baseURL(c) + "/kobo/" + c.Param("token")
+}
+
+// koboInitialization tells the device where the endpoints it uses are
+func koboInitialization(c *gin.Context) {
+	base := koboBase(c)
+	c.Header("x-kobo-apitoken", "e30=")
+	c.JSON(http.StatusOK, gin.H{"Resources": gin.H{
+		"image_host":                 baseURL(c),
+		"image_url_template":         base + "/{ImageId}/{Width}/{Height}/100/false/image.jpg",
+		"image_url_quality_template": base + "/{ImageId}/{Width}/{Height}/{Quality}/{IsGreyscale}/image.jpg",
+		"library_sync":               base + "/v1/library/sync",
+		"library_metadata":           base + "/v1/library/{Ids}/metadata",
+		"reading_state":              base + "/v1/library/{Ids}/state",
+		"tags":                       base + "/v1/library/tags",
+		"user_profile":               base + "/v1/user/profile",
+		"get_tests_request":          base + "/v1/analytics/gettests",
+		"post_analytics_event":       base + "/v1/analytics/event",
+		"device_auth":                base + "/v1/auth/device",
+		"device_refresh":             base + "/v1/auth/refresh",
+	}})
+}
+
+// koboAuthDevice accepts the device; the token in the path authenticates it
+func koboAuthDevice(c *gin.Context) {
+	id := koboUUID("user/" + currentUser(c).ID)
+	c.JSON(http.StatusOK, gin.H{
+		"AccessToken":  id,
+		"RefreshToken": id,
+		"TokenType":    "Bearer",
+		"TrackingId":   id,
+		"UserKey":      id,
+	})
+}
+
+// koboSyncToken is where the previous sync stopped. The device sends it
+// back with the next sync.
+type koboSyncToken struct {
+	BooksModified time.Time `json:"booksModified"` // Books modified after this are sent...
+	BookID        string    `json:"bookId"`        // ...and those modified at it with a greater ID
+	StatesSynced  time.Time `json:"statesSynced"`  // Reading states changed after this are sent
+}
+
+func parseKoboSyncToken(header string) koboSyncToken {
+	var token koboSyncToken
+	if data, err := base64.StdEncoding.DecodeString(header); err == nil {
+		if err := json.Unmarshal(data, &token); err != nil {
+			zapLogger.Debug("Ignoring invalid Kobo sync token", zap.Error(err))
+		}
+	}
+	return token
+}
+
+func (t koboSyncToken) encode() string {
+	data, _ := json.Marshal(t)
+	return base64.StdEncoding.EncodeToString(data)
+}
+
+// isAfter reports whether a book comes after the position of the token
+func (t koboSyncToken) isAfter(book *koboBook) bool {
+	return book.Modified.After(t.BooksModified) || (book.Modified.Equal(t.BooksModified) && book.ID > t.BookID)
+}
+
+// koboSync sends the books added or changed since the last sync, a page
+// at a time, and the reading states changed on other devices
+func koboSync(c *gin.Context) {
+	user := currentUser(c)
+	token := parseKoboSyncToken(c.GetHeader(koboSyncTokenHeader))
+	now := timeNow().UTC()
+
+	library := koboLibrary(c)
+	var changes []gin.H
+	more := false
+	for i := range library {
+		book := &library[i]
+		if !token.isAfter(book) {
+			continue
+		}
+		if len(changes) == koboSyncLimit {
+			more = true
+			break
+		}
+		changes = append(changes, gin.H{"NewEntitlement": gin.H{
+			"BookEntitlement": koboEntitlement(book),
+			"BookMetadata":    koboMetadata(c, book),
+			"ReadingState":    koboReadingState(user.ID, book),
+		}})
+		token.BooksModified, token.BookID = book.Modified, book.ID
+	}
+
+	if !token.StatesSynced.IsZero() {
+		changes = append(changes, koboChangedStates(user.ID, library, token.StatesSynced)...)
+	}
+	token.StatesSynced = now
+
+	if more {
+		c.Header(koboSyncHeader, "continue")
+	}
+	c.Header(koboSyncTokenHeader, token.encode())
+	if changes == nil {
+		changes = []gin.H{}
+	}
+	c.JSON(http.StatusOK, changes)
+}
+
+// koboChangedStates lists the reading states of the series whose progress
+// changed since the last sync: the chapter at the new position and the one
+// before it, which that position finished
+func koboChangedStates(userID string, library []koboBook, since time.Time) []gin.H {
+	changed := make(map[string]bool)
+	for _, entry := range progressStore.List(userID) {
+		if entry.UpdatedAt.After(since) {
+			changed[entry.MangaID] = true
+		}
+	}
+	if len(changed) == 0 {
+		return nil
+	}
+
+	bySeries := make(map[string][]*koboBook)
+	for i := range library {
+		if book := &library[i]; changed[book.Manga.ID] {
+			bySeries[book.Manga.ID] = append(bySeries[book.Manga.ID], book)
+		}
+	}
+	var states []gin.H
+	for mangaID, books := range bySeries {
+		entry, _ := progressStore.Get(userID, mangaID)
+		sort.Slice(books, func(i, j int) bool {
+			return models.ChapterLess(&books[i].Chapter, &books[j].Chapter)
+		})
+		for i, book := range books {
+			next := i+1 < len(books) && books[i+1].Chapter.Number < entry.Chapter
+			if book.Chapter.Number == entry.Chapter || (book.Chapter.Number < entry.Chapter && !next) {
+				states = append(states, gin.H{"ChangedReadingState": gin.H{"ReadingState": koboReadingState(userID, book)}})
+			}
+		}
+	}
+	return states
+}
+
+// koboEntitlement is the device's record of owning a book
+func koboEntitlement(book *koboBook) gin.H {
+	modified := timestamp(book.Modified)
+	return gin.H{
+		"Accessibility":       "Full",
+		"ActivePeriod":        gin.H{"From": modified},
+		"Created":             modified,
+		"CrossRevisionId":     book.ID,
+		"Id":                  book.ID,
+		"IsHiddenFromArchive": false,
+		"IsLocked":            false,
+		"IsRemoved":           false,
+		"LastModified":        modified,
+		"OriginCategory":      "Imported",
+		"RevisionId":          book.ID,
+		"Status":              "Active",
+	}
+}
+
+// koboTitle names a chapter book after its series and number
+func koboTitle(book *koboBook) string {
+	title := book.Manga.Title + " " + strconv.FormatFloat(book.Chapter.Number, 'f', -1, 64)
+	if book.Chapter.Title != "" {
+		title += ": " + book.Chapter.Title
+	}
+	return title
+}
+
+// koboContributors lists a series' author, and its artist when that's
+// someone else
+func koboContributors(manga *models.MangaSeries) []string {
+	var names []string
+	if manga.Author != "" {
+		names = append(names, manga.Author)
+	}
+	if manga.Artist != "" && manga.Artist != manga.Author {
+		names = append(names, manga.Artist)
+	}
+	return names
+}
+
+// koboMetadata describes a book and where to download it. The download
+// size is the size of its pages; the EPUB around them adds a little.
+func koboMetadata(c *gin.Context, book *koboBook) gin.H {
+	var size int64
+	if pages, err := metadataManager.LoadPages(&book.Chapter); err == nil {
+		if infos, err := statPages(pages); err == nil {
+			for _, info := range infos {
+				size += info.Size()
+			}
+		}
+	}
+
+	contributors := koboContributors(book.Manga)
+	roles := []gin.H{}
+	for _, name := range contributors {
+		roles = append(roles, gin.H{"Name": name})
+	}
+	description := book.Chapter.Summary
+	if description == "" {
+		description = book.Manga.Description
+	}
+
+	return gin.H{
+		"Categories":              []string{"00000000-0000-0000-0000-000000000001"},
+		"ContributorRoles":        roles,
+		"Contributors":            contributors,
+		"CoverImageId":            koboID(book.Manga.ID, ""),
+		"CrossRevisionId":         book.ID,
+		"CurrentDisplayPrice":     gin.H{"CurrencyCode": "USD", "TotalAmount": 0},
+		"CurrentLoveDisplayPrice": gin.H{"TotalAmount": 0},
+		"Description":             description,
+		"DownloadUrls": []gin.H{{
+			"Format":   "EPUB3",
+			"Platform": "Generic",
+			"Size":     size,
+			"Url":      koboBase(c) + "/v1/library/" + book.ID + "/download",
+		}},
+		"EntitlementId":          book.ID,
+		"ExternalIds":            []string{},
+		"Genre":                  "00000000-0000-0000-0000-000000000001",
+		"IsEligibleForKoboLove":  false,
+		"IsInternetArchive":      false,
+		"IsPreOrder":             false,
+		"IsSocialEnabled":        true,
+		"Language":               "en",
+		"PhoneticPronunciations": gin.H{},
+		"PublicationDate":        timestamp(book.Chapter.ReleaseDate),
+		"Publisher":              gin.H{"Imprint": "", "Name": book.Manga.Publisher},
+		"RevisionId":             book.ID,
+		"Series": gin.H{
+			"Id":          koboID(book.Manga.ID, ""),
+			"Name":        book.Manga.Title,
+			"Number":      strconv.FormatFloat(book.Chapter.Number, 'f', -1, 64),
+			"NumberFloat": book.Chapter.Number,
+		},
+		"Title":  koboTitle(book),
+		"WorkId": book.ID,
+	}
+}
+
+// koboStatus derives a book's reading status and percentage from the user's
+// progress in its series
+func koboStatus(book *koboBook, entry progress.Entry, ok bool) (string, int) {
+	number := book.Chapter.Number
+	switch {
+	case !ok || (entry.Chapter < number && !entry.Completed):
+		return koboReadyToRead, 0
+	case entry.Completed || entry.Chapter > number:
+		return koboFinished, 100
+	}
+	pageCount := koboPageCount(book)
+	switch {
+	case pageCount > 0 && entry.Page >= pageCount:
+		return koboFinished, 100
+	case entry.Page > 0 && pageCount > 0:
+		return koboReading, entry.Page * 100 / pageCount
+	}
+	return koboReadyToRead, 0
+}
+
+func koboPageCount(book *koboBook) int {
+	pages, err := metadataManager.LoadPages(&book.Chapter)
+	if err != nil {
+		return book.Chapter.PageCount
+	}
+	return len(pages)
+}
+
+// koboReadingState is a book's reading state in the form the device keeps
+func koboReadingState(userID string, book *koboBook) gin.H {
+	entry, ok := progressStore.Get(userID, book.Manga.ID)
+	status, percent := koboStatus(book, entry, ok)
+	modified := book.Modified
+	if ok {
+		modified = entry.UpdatedAt
+	}
+	at := timestamp(modified)
+	return gin.H{
+		"EntitlementId":     book.ID,
+		"Created":           at,
+		"LastModified":      at,
+		"PriorityTimestamp": at,
+		"StatusInfo": gin.H{
+			"LastModified": at,
+			"Status":       status,
+		},
+		"Statistics": gin.H{"LastModified": at},
+		"CurrentBookmark": gin.H{
+			"LastModified":                 at,
+			"ProgressPercent":              percent,
+			"ContentSourceProgressPercent": percent,
+		},
+	}
+}
+
+// koboBookMetadata describes one book
+func koboBookMetadata(c *gin.Context) {
+	book, ok := lookupKoboBook(c)
+	if !ok {
+		return
+	}
+	c.JSON(http.StatusOK, []gin.H{koboMetadata(c, book)})
+}
+
+// koboGetState returns a book's reading state
+func koboGetState(c *gin.Context) {
+	book, ok := lookupKoboBook(c)
+	if !ok {
+		return
+	}
+	c.JSON(http.StatusOK, []gin.H{koboReadingState(currentUser(c).ID, book)})
+}
+
+// koboPutState saves the position the device reports. Progress only moves
+// forward, so rereading an earlier chapter on the device keeps the furthest
+// position.
+func koboPutState(c *gin.Context) {
+	user := currentUser(c)
+	if token := currentAPIToken(c); !token.HasScope(users.ScopeProgress) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "This API token does not grant access to reading progress"})
+		return
+	}
+	book, ok := lookupKoboBook(c)
+	if !ok {
+		return
+	}
+
+	var request struct {
+		ReadingStates []struct {
+			StatusInfo struct {
+				Status string `json:"Status"`
+			} `json:"StatusInfo"`
+			CurrentBookmark struct {
+				ProgressPercent float64 `json:"ProgressPercent"`
+			} `json:"CurrentBookmark"`
+		} `json:"ReadingStates"`
+	}
+	if err := c.ShouldBindJSON(&request); err != nil || len(request.ReadingStates) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request: missing ReadingStates"})
+		return
+	}
+	state := request.ReadingStates[0]
+
+	pageCount := koboPageCount(book)
+	page := 0
+	switch state.StatusInfo.Status {
+	case koboFinished:
+		page = pageCount
+	case koboReading:
+		page = max(1, int(math.Round(state.CurrentBookmark.ProgressPercent/100*float64(pageCount))))
+	}
+
+	entry, ok := progressStore.Get(user.ID, book.Manga.ID)
+	number := book.Chapter.Number
+	ahead := ok && (entry.Completed || entry.Chapter > number || (entry.Chapter == number && entry.Page >= page))
+	if page > 0 && !ahead {
+		if _, err := saveProgress(book.Manga, progress.Entry{
+			UserID:    user.ID,
+			MangaID:   book.Manga.ID,
+			Chapter:   number,
+			Page:      page,
+			Completed: page >= pageCount && koboIsLastChapter(c, book),
+		}); err != nil {
+			zapLogger.Error("Failed to save progress", zap.Error(err))
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save progress: " + err.Error()})
+			return
+		}
+	}
+
+	success := gin.H{"Result": "Success"}
+	c.JSON(http.StatusOK, gin.H{
+		"RequestResult": "Success",
+		"UpdateResults": []gin.H{{
+			"EntitlementId":         book.ID,
+			"CurrentBookmarkResult": success,
+			"StatisticsResult":      gin.H{"Result": "Ignored"},
+			"StatusInfoResult":      success,
+		}},
+	})
+}
+
+// koboIsLastChapter reports whether no visible chapter follows the book's
+func koboIsLastChapter(c *gin.Context, book *koboBook) bool {
+	chapters, err := metadataManager.ScanForChapters(book.Manga)
+	if err != nil {
+		return false
+	}
+	for _, chapter := range visibleChapters(c, chapters) {
+		if models.ChapterLess(&book.Chapter, &chapter) {
+			return false
+		}
+	}
+	return true
+}
+
+// koboArchive acknowledges the device removing a book. Books can't be
+// removed from the library this way, so the next sync leaves it alone.
+func koboArchive(c *gin.Context) {
+	if _, ok := lookupKoboBook(c); !ok {
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// koboDownload sends a book as a fixed-layout EPUB, its pages turning in the
+// user's reading direction for the series
+func koboDownload(c *gin.Context) {
+	book, ok := lookupKoboBook(c)
+	if !ok {
+		return
+	}
+	pages, err := metadataManager.LoadPages(&book.Chapter)
+	if err != nil {
+		zapLogger.Error("Failed to retrieve pages", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve pages: " + err.Error()})
+		return
+	}
+	if len(pages) == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Chapter has no pages"})
+		return
+	}
+	if !takeDownload(c, &book.Chapter) {
+		return
+	}
+
+	prefs := userStore.Preferences(currentUser(c).ID)
+	direction := prefs.ReadingDirection
+	if override, ok := prefs.DirectionOverrides[book.Manga.ID]; ok {
+		direction = override
+	}
+	ebook := epub.Book{
+		ID:          book.ID,
+		Title:       koboTitle(book),
+		Series:      book.Manga.Title,
+		SeriesIndex: book.Chapter.Number,
+		Authors:     koboContributors(book.Manga),
+		Publisher:   book.Manga.Publisher,
+		Modified:    book.Modified,
+		RightToLeft: direction == "rtl",
+	}
+	if credits, ok := creditsPage(c, book.Manga, &book.Chapter); ok {
+		ebook.Pages = append(ebook.Pages, credits)
+	}
+	mark := watermarkText(c, timeNow())
+	for i := range pages {
+		if err := pages[i].LoadImageMetadata(); err != nil {
+			zapLogger.Warn("Failed to read page dimensions", zap.String("imagePath", pages[i].ImagePath), zap.Error(err))
+		}
+		page := epub.Page{
+			Path:      pages[i].ImagePath,
+			MediaType: pages[i].MimeType,
+			Width:     pages[i].Width,
+			Height:    pages[i].Height,
+		}
+		marked, err := markPage(pages[i].ImagePath, mark)
+		if err != nil {
+			zapLogger.Error("Failed to watermark page", zap.String("imagePath", pages[i].ImagePath), zap.Error(err))
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to watermark pages: " + err.Error()})
+			return
+		}
+		if marked != nil {
+			page.Path = strings.TrimSuffix(page.Path, filepath.Ext(page.Path)) + marked.Ext
+			page.Data, page.MediaType = marked.Data, marked.MimeType
+		}
+		ebook.Pages = append(ebook.Pages, page)
+	}
+
+	c.Header("Content-Type", "application/epub+zip")
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s-%s.epub"`, book.Manga.ID, book.Chapter.ID))
+	c.Status(http.StatusOK)
+	if err := epub.Write(c.Writer, ebook); err != nil {
+		// The status is already sent; the device sees a broken download
+		zapLogger.Error("Failed to write EPUB", zap.String("chapterID", book.Chapter.ID), zap.Error(err))
+	}
+}
+
+// koboCover serves a series cover at the size the device asks for
+func koboCover(c *gin.Context) {
+	ref, ok := resolveKoboID(c, c.Param("imageID"))
+	if !ok {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Image not found"})
+		return
+	}
+	manga, err := metadataManager.GetMangaByID(ref.MangaID)
+	if err != nil || !canSeeSeries(c, manga) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Image not found"})
+		return
+	}
+	coverPath := manga.GetCoverImagePath()
+	if _, err := os.Stat(coverPath); coverPath == "" || err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Image not found"})
+		return
+	}
+
+	opts := imaging.Options{}
+	if width, err := strconv.Atoi(c.Param("width")); err == nil && width > 0 {
+		opts.MaxWidth = width
+	}
+	if greyscale, _ := strconv.ParseBool(c.Param("greyscale")); greyscale {
+		opts.Filters = []string{"grayscale"}
+	}
+	file, err := openCached(func() (string, error) { return imageCache.Get(coverPath, opts) })
+	if err != nil {
+		zapLogger.Error("Failed to generate cover", zap.String("coverPath", coverPath), zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate image: " + err.Error()})
+		return
+	}
+	defer file.Close()
+	serveImageFile(c, file)
+}
+
+// koboEmpty answers store features MangaHub has no equivalent of
+func koboEmpty(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{})
+}
+
+// koboEmptyList answers store listings MangaHub has no equivalent of
+func koboEmptyList(c *gin.Context) {
+	c.JSON(http.StatusOK, []gin.H{})
+}
+
+// koboAnalytics accepts and drops the device's analytics
+func koboAnalytics(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"Result": "Success", "TestKey": "", "Tests": gin.H{}})
+}
@@ -0,0 +1,560 @@
+package routes
+
+import (
+	"encoding/json"
+	"io/fs"
+	"mangahub/backend/antivirus"
+	"mangahub/backend/importers"
+	"mangahub/backend/models"
+	"mangahub/backend/users"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+var (
+	personalLibrariesEnabled bool
+
+	// defaultQuotaMB is the personal library quota of users without their own
+	defaultQuotaMB int
+
+	// uploadLocks serializes each user's chapter uploads, so concurrent ones
+	// can't each pass the quota check, keyed by user ID
+	uploadLocksMu sync.Mutex
+	uploadLocks   = make(map[string]*sync.Mutex)
+)
+
+// SetPersonalLibraries enables personal libraries with the given default quota
+func SetPersonalLibraries(enabled bool, quotaMB int) {
+	personalLibrariesEnabled = enabled
+	defaultQuotaMB = quotaMB
+}
+
+// canSeeSeries reports whether the requester may see a series, on their
+// own account or through a share token
+func canSeeSeries(c *gin.Context, manga *models.MangaSeries) bool {
+	return accountCanSeeSeries(c, manga) || sharedWithRequester(c, manga.ID)
+}
+
+// accountCanSeeSeries reports whether the requester may see a series without
+// a share token. The shared catalog is visible to everyone; personal series
+// according to their visibility, and always to their owner and admins.
+func accountCanSeeSeries(c *gin.Context, manga *models.MangaSeries) bool {
+	if strings.HasPrefix(c.FullPath(), "/api/admin/") {
+		return true
+	}
+	user := currentUser(c)
+	if user == nil {
+		return guestCanSee(manga)
+	}
+	return manga.IsPublic() || user.IsAdmin() || user.ID == manga.Owner || manga.Visibility == models.VisibilityUsers
+}
+
+// visibleSeries drops the series the requester may not see
+func visibleSeries(c *gin.Context, mangas []models.MangaSeries) []models.MangaSeries {
+	visible := mangas[:0:0]
+	for i := range mangas {
+		if canSeeSeries(c, &mangas[i]) {
+			visible = append(visible, mangas[i])
+		}
+	}
+	return visible
+}
+
+// publicSeries keeps the series anonymous visitors may see
+func publicSeries(mangas []models.MangaSeries) []models.MangaSeries {
+	public := mangas[:0:0]
+	for i := range mangas {
+		if guestCanSee(&mangas[i]) {
+			public = append(public, mangas[i])
+		}
+	}
+	return public
+}
+
+// sharedSeries keeps the series of the shared catalog, so imports never
+// match chapters into someone's personal library
+func sharedSeries(mangas []models.MangaSeries) []models.MangaSeries {
+	shared := mangas[:0:0]
+	for _, manga := range mangas {
+		if manga.Owner == "" {
+			shared = append(shared, manga)
+		}
+	}
+	return shared
+}
+
+// requirePersonalLibraries hides the personal library endpoints unless the
+// feature is enabled
+func requirePersonalLibraries(c *gin.Context) {
+	if !personalLibrariesEnabled {
+		c.AbortWithStatusJSON(http.StatusNotFound, gin.H{"error": "Personal libraries are not enabled"})
+		return
+	}
+	c.Next()
+}
+
+// quotaBytes is a user's personal library quota
+func quotaBytes(user *users.User) int64 {
+	quotaMB := defaultQuotaMB
+	if user.QuotaMB > 0 {
+		quotaMB = user.QuotaMB
+	}
+	return int64(quotaMB) << 20
+}
+
+// ownedSeries returns the personal series of a user
+func ownedSeries(userID string) []models.MangaSeries {
+	var owned []models.MangaSeries
+	for _, manga := range libraryIndex.List() {
+		if manga.Owner == userID {
+			owned = append(owned, manga)
+		}
+	}
+	return owned
+}
+
+// ownedSeriesPaths returns the folders of a user's personal series, read
+// from the series metadata files rather than the library index, so none are
+// missed while it warms up
+func ownedSeriesPaths(userID string) ([]string, error) {
+	entries, err := os.ReadDir(metadataManager.RootDir)
+	if err != nil {
+		return nil, err
+	}
+	var paths []string
+	for _, entry := range entries {
+		if !entry.IsDir() || strings.HasPrefix(entry.Name(), ".") {
+			continue
+		}
+		mangaPath := filepath.Join(metadataManager.RootDir, entry.Name())
+		// Series without a metadata file are derived from the folder and
+		// never personal
+		data, err := os.ReadFile(filepath.Join(mangaPath, models.MetadataFileName))
+		if err != nil {
+			continue
+		}
+		var metadata struct {
+			Owner string `json:"owner"`
+		}
+		if json.Unmarshal(data, &metadata) == nil && metadata.Owner == userID {
+			paths = append(paths, mangaPath)
+		}
+	}
+	return paths, nil
+}
+
+// usedBytes is the disk space taken up by a user's personal series
+func usedBytes(userID string) (int64, error) {
+	paths, err := ownedSeriesPaths(userID)
+	if err != nil {
+		return 0, err
+	}
+	var total int64
+	for _, mangaPath := range paths {
+		size, err := dirSize(mangaPath)
+		if err != nil && !os.IsNotExist(err) {
+			return 0, err
+		}
+		total += size
+	}
+	return total, nil
+}
+
+// dirSize adds up the sizes of the files in a directory tree
+func dirSize(dir string) (int64, error) {
+	var total int64
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		total += info.Size()
+		return nil
+	})
+	return total, err
+}
+
+// lockUploads blocks until no other upload of the user is running,
+// returning the function that lets the next one go ahead
+func lockUploads(userID string) func() {
+	uploadLocksMu.Lock()
+	lock, ok := uploadLocks[userID]
+	if !ok {
+		lock = &sync.Mutex{}
+		uploadLocks[userID] = lock
+	}
+	uploadLocksMu.Unlock()
+
+	lock.Lock()
+	return lock.Unlock
+}
+
+// lookupOwnedManga resolves :id to one of the current user's personal
+// series, writing a 404 response otherwise
+func lookupOwnedManga(c *gin.Context) (*models.MangaSeries, bool) {
+	mangaID := c.Param("id")
+	manga, err := metadataManager.GetMangaByID(mangaID)
+	if err != nil || manga.Owner != currentUser(c).ID {
+		zapLogger.Warn("Personal series not found", zap.String("mangaID", mangaID), zap.Error(err))
+		c.JSON(http.StatusNotFound, gin.H{"error": "Manga not found"})
+		return nil, false
+	}
+	return manga, true
+}
+
+// librarySeries is the JSON shape of a personal series
+func librarySeries(manga *models.MangaSeries) gin.H {
+	return gin.H{
+		"id":          manga.ID,
+		"title":       manga.Title,
+		"description": manga.Description,
+		"visibility":  manga.Visibility,
+		"coverImage":  manga.GetCoverImageURL(),
+		"lastUpdated": timestamp(manga.LastUpdated),
+	}
+}
+
+// getLibrary lists the current user's personal series and storage use
+func getLibrary(c *gin.Context) {
+	user := currentUser(c)
+	zapLogger.Info("getLibrary handler called", zap.String("userID", user.ID))
+
+	used, err := usedBytes(user.ID)
+	if err != nil {
+		zapLogger.Error("Failed to measure library size", zap.String("userID", user.ID), zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to measure library size: " + err.Error()})
+		return
+	}
+
+	series := []gin.H{}
+	for _, manga := range ownedSeries(user.ID) {
+		series = append(series, librarySeries(&manga))
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"manga":      series,
+		"usedBytes":  used,
+		"quotaBytes": quotaBytes(user),
+	})
+}
+
+// createLibrarySeries adds a series to the current user's personal library
+func createLibrarySeries(c *gin.Context) {
+	user := currentUser(c)
+	zapLogger.Info("createLibrarySeries handler called", zap.String("userID", user.ID))
+
+	var request struct {
+		Title       string `json:"title" binding:"required"`
+		Description string `json:"description"`
+		Visibility  string `json:"visibility"`
+	}
+	if err := c.ShouldBindJSON(&request); err != nil {
+		zapLogger.Warn("Invalid request data", zap.Error(err))
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request: " + err.Error()})
+		return
+	}
+	visibility, err := models.NormalizeVisibility(request.Visibility)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request: " + err.Error()})
+		return
+	}
+
+	id := uniqueMangaID(request.Title)
+	mangaPath, err := seriesFolderPath(id, request.Title)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request: " + err.Error()})
+		return
+	}
+	if _, err := os.Stat(mangaPath); err == nil {
+		c.JSON(http.StatusConflict, gin.H{"error": "A series folder named " + filepath.Base(mangaPath) + " already exists"})
+		return
+	}
+	if err := os.MkdirAll(mangaPath, 0755); err != nil {
+		zapLogger.Error("Failed to create manga directory", zap.String("mangaPath", mangaPath), zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create manga directory: " + err.Error()})
+		return
+	}
+
+	manga := models.MangaSeries{
+		ID:          id,
+		Title:       request.Title,
+		Description: request.Description,
+		Owner:       user.ID,
+		Visibility:  visibility,
+		Path:        mangaPath,
+	}
+	if err := manga.SaveToJSON(filepath.Join(mangaPath, models.MetadataFileName)); err != nil {
+		zapLogger.Error("Failed to save manga metadata", zap.String("mangaPath", mangaPath), zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save manga metadata: " + err.Error()})
+		return
+	}
+	libraryIndex.Refresh(mangaPath)
+
+	zapLogger.Info("Personal series created", zap.String("mangaID", manga.ID), zap.String("userID", user.ID))
+	c.JSON(http.StatusCreated, librarySeries(&manga))
+}
+
+// updateLibrarySeries changes the title, description or visibility of a
+// personal series
+func updateLibrarySeries(c *gin.Context) {
+	manga, ok := lookupOwnedManga(c)
+	if !ok {
+		return
+	}
+	zapLogger.Info("updateLibrarySeries handler called", zap.String("mangaID", manga.ID))
+
+	var request struct {
+		Title       *string `json:"title"`
+		Description *string `json:"description"`
+		Visibility  *string `json:"visibility"`
+	}
+	if err := c.ShouldBindJSON(&request); err != nil {
+		zapLogger.Warn("Invalid request data", zap.Error(err))
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request: " + err.Error()})
+		return
+	}
+
+	if request.Visibility != nil {
+		visibility, err := models.NormalizeVisibility(*request.Visibility)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request: " + err.Error()})
+			return
+		}
+		manga.Visibility = visibility
+	}
+	if request.Title != nil && strings.TrimSpace(*request.Title) != "" {
+		manga.Title = strings.TrimSpace(*request.Title)
+	}
+	if request.Description != nil {
+		manga.Description = *request.Description
+	}
+
+	if err := manga.SaveToJSON(filepath.Join(manga.Path, models.MetadataFileName)); err != nil {
+		zapLogger.Error("Failed to save manga metadata", zap.String("mangaPath", manga.Path), zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save manga metadata: " + err.Error()})
+		return
+	}
+	libraryIndex.Refresh(manga.Path)
+	c.JSON(http.StatusOK, librarySeries(manga))
+}
+
+// deleteLibrarySeries removes a personal series and all of its chapters
+func deleteLibrarySeries(c *gin.Context) {
+	manga, ok := lookupOwnedManga(c)
+	if !ok {
+		return
+	}
+	zapLogger.Info("deleteLibrarySeries handler called", zap.String("mangaID", manga.ID))
+
+	if err := os.RemoveAll(manga.Path); err != nil {
+		zapLogger.Error("Failed to delete manga directory", zap.String("mangaPath", manga.Path), zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete manga: " + err.Error()})
+		return
+	}
+	libraryIndex.Refresh(manga.Path)
+	c.JSON(http.StatusOK, gin.H{"status": "deleted", "id": manga.ID})
+}
+
+// uploadLibraryChapter adds a chapter, uploaded as a CBZ in the "file" form
+// field, to a personal series. Uploads that would exceed the user's quota are
+// rejected, measured by the extracted pages; a user's uploads run one at a
+// time so they can't overtake each other's checks.
+func uploadLibraryChapter(c *gin.Context) {
+	manga, ok := lookupOwnedManga(c)
+	if !ok {
+		return
+	}
+	user := currentUser(c)
+	zapLogger.Info("uploadLibraryChapter handler called", zap.String("mangaID", manga.ID))
+
+	number, err := strconv.ParseFloat(c.PostForm("number"), 64)
+	if err != nil || number < 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request: number must be a chapter number"})
+		return
+	}
+	volume, _ := strconv.Atoi(c.PostForm("volume"))
+	header, err := c.FormFile("file")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request: missing chapter file"})
+		return
+	}
+
+	defer lockUploads(user.ID)()
+	used, err := usedBytes(user.ID)
+	if err != nil {
+		zapLogger.Error("Failed to measure library size", zap.String("userID", user.ID), zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to measure library size: " + err.Error()})
+		return
+	}
+	// Rejects oversized archives before anything is extracted; the pages are
+	// measured again once they are
+	quota := quotaBytes(user)
+	if used+header.Size > quota {
+		respondQuotaExceeded(c, user, used, quota)
+		return
+	}
+
+	chapter := models.Chapter{
+		ID:      chapterIDFor(number),
+		MangaID: manga.ID,
+		Number:  number,
+		Title:   c.PostForm("title"),
+		Volume:  volume,
+	}
+	chapterPath, err := chapterFolderPath(manga, &chapter)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request: " + err.Error()})
+		return
+	}
+	chapter.Path = chapterPath
+	if _, err := os.Stat(chapter.Path); err == nil {
+		c.JSON(http.StatusConflict, gin.H{"error": "Chapter already exists"})
+		return
+	}
+
+	archive, err := os.CreateTemp("", "mangahub-upload-*.cbz")
+	if err != nil {
+		zapLogger.Error("Failed to create upload file", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to store upload: " + err.Error()})
+		return
+	}
+	archive.Close()
+	defer os.Remove(archive.Name())
+	if err := c.SaveUploadedFile(header, archive.Name()); err != nil {
+		zapLogger.Error("Failed to store upload", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to store upload: " + err.Error()})
+		return
+	}
+	if !scanned(c, antivirus.ScanFile(c.Request.Context(), archive.Name())) {
+		return
+	}
+
+	stagingPath, err := newStagingDir(manga.Path, chapter.ID)
+	if err != nil {
+		zapLogger.Error("Failed to create staging directory", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create chapter directory: " + err.Error()})
+		return
+	}
+	pageCount, err := importers.ExtractArchive(c.Request.Context(), archive.Name(), stagingPath, nil)
+	if err != nil {
+		os.RemoveAll(stagingPath)
+		zapLogger.Warn("Failed to extract upload", zap.String("mangaID", manga.ID), zap.Error(err))
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid chapter archive: " + err.Error()})
+		return
+	}
+	if err := sanitizePages(stagingPath); err != nil {
+		os.RemoveAll(stagingPath)
+		zapLogger.Warn("Rejected uploaded chapter page", zap.String("mangaID", manga.ID), zap.Error(err))
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid chapter page: " + err.Error()})
+		return
+	}
+	// The staging directory lies inside the series folder, so used, measured
+	// before it was created, doesn't include it
+	staged, err := dirSize(stagingPath)
+	if err != nil {
+		os.RemoveAll(stagingPath)
+		zapLogger.Error("Failed to measure uploaded chapter", zap.String("mangaID", manga.ID), zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to measure uploaded chapter: " + err.Error()})
+		return
+	}
+	if used+staged > quota {
+		os.RemoveAll(stagingPath)
+		respondQuotaExceeded(c, user, used, quota)
+		return
+	}
+	if err := finishStagedChapter(&chapter, stagingPath, pageCount); err != nil {
+		os.RemoveAll(stagingPath)
+		zapLogger.Error("Failed to import chapter", zap.String("mangaID", manga.ID), zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to import chapter: " + err.Error()})
+		return
+	}
+
+	libraryIndex.Refresh(manga.Path)
+	notifyChapterPublished(&chapter)
+	notifyChapterImported(&chapter)
+	c.JSON(http.StatusCreated, gin.H{
+		"id":        chapter.ID,
+		"mangaId":   chapter.MangaID,
+		"number":    chapter.Number,
+		"title":     chapter.Title,
+		"volume":    chapter.Volume,
+		"pageCount": chapter.PageCount,
+	})
+}
+
+// respondQuotaExceeded rejects an upload that doesn't fit in the user's quota
+func respondQuotaExceeded(c *gin.Context, user *users.User, used, quota int64) {
+	zapLogger.Warn("Personal library quota exceeded", zap.String("userID", user.ID), zap.Int64("usedBytes", used))
+	c.JSON(http.StatusRequestEntityTooLarge, gin.H{
+		"error":      "Upload would exceed your storage quota",
+		"usedBytes":  used,
+		"quotaBytes": quota,
+	})
+}
+
+// deleteLibraryChapter removes a chapter from a personal series
+func deleteLibraryChapter(c *gin.Context) {
+	manga, ok := lookupOwnedManga(c)
+	if !ok {
+		return
+	}
+	number, err := strconv.ParseFloat(c.Param("chapterNumber"), 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid chapter number"})
+		return
+	}
+	zapLogger.Info("deleteLibraryChapter handler called", zap.String("mangaID", manga.ID), zap.Float64("chapterNumber", number))
+
+	chapters, err := metadataManager.ScanForChapters(manga)
+	if err != nil {
+		zapLogger.Error("Failed to retrieve chapters", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve chapters: " + err.Error()})
+		return
+	}
+	for _, chapter := range chapters {
+		if chapter.Number != number {
+			continue
+		}
+		if err := os.RemoveAll(chapter.Path); err != nil {
+			zapLogger.Error("Failed to delete chapter directory", zap.String("chapterPath", chapter.Path), zap.Error(err))
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete chapter: " + err.Error()})
+			return
+		}
+		recordRemovedChapter(manga, &chapter, "")
+		libraryIndex.Refresh(manga.Path)
+		c.JSON(http.StatusOK, gin.H{"status": "deleted", "id": chapter.ID})
+		return
+	}
+	c.JSON(http.StatusNotFound, gin.H{"error": "Chapter not found"})
+}
+
+// setUserQuota overrides a user's personal library quota; 0 restores the default
+func setUserQuota(c *gin.Context) {
+	userID := c.Param("id")
+	zapLogger.Info("setUserQuota handler called", zap.String("userID", userID))
+
+	var request struct {
+		QuotaMB *int `json:"quotaMB" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&request); err != nil {
+		zapLogger.Warn("Invalid request data", zap.Error(err))
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request: " + err.Error()})
+		return
+	}
+
+	user, err := userStore.SetQuota(userID, *request.QuotaMB)
+	if err != nil {
+		respondUserError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, user.Public())
+}
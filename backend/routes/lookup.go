@@ -0,0 +1,119 @@
+package routes
+
+import (
+	"mangahub/backend/models"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// chapterLookup bundles a resolved manga, its sorted chapters and the index of
+// the chapter addressed by the request
+type chapterLookup struct {
+	Manga        *models.MangaSeries
+	Chapters     []models.Chapter
+	ChapterIndex int
+}
+
+// Chapter returns the chapter addressed by the request
+func (l *chapterLookup) Chapter() *models.Chapter {
+	return &l.Chapters[l.ChapterIndex]
+}
+
+// lookupChapter resolves the :id and :chapterNumber params. On failure it
+// writes the error response itself and returns false.
+func lookupChapter(c *gin.Context) (*chapterLookup, bool) {
+	mangaID := c.Param("id")
+	chapterNumberStr := c.Param("chapterNumber")
+
+	chapterNumber, err := strconv.ParseFloat(chapterNumberStr, 64)
+	if err != nil {
+		zapLogger.Warn("Invalid chapter number", zap.String("chapterNumberStr", chapterNumberStr))
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid chapter number"})
+		return nil, false
+	}
+
+	manga, ok := lookupManga(c, mangaID)
+	if !ok {
+		return nil, false
+	}
+
+	chapters, err := metadataManager.ScanForChapters(manga)
+	if err != nil {
+		zapLogger.Error("Failed to retrieve chapters", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve chapters: " + err.Error()})
+		return nil, false
+	}
+	chapters = visibleChapters(c, chapters)
+
+	for i := range chapters {
+		if chapters[i].Number == chapterNumber {
+			return &chapterLookup{Manga: manga, Chapters: chapters, ChapterIndex: i}, true
+		}
+	}
+
+	zapLogger.Warn("Chapter not found",
+		zap.String("mangaID", mangaID),
+		zap.Float64("chapterNumber", chapterNumber),
+	)
+	c.JSON(http.StatusNotFound, gin.H{"error": "Chapter not found"})
+	return nil, false
+}
+
+// lookupManga resolves a manga by ID, writing a 404/500 response on failure
+func lookupManga(c *gin.Context, mangaID string) (*models.MangaSeries, bool) {
+	manga, err := metadataManager.GetMangaByID(mangaID)
+	if err != nil {
+		if models.IsMangaNotFoundError(err) {
+			if redirectMergedSeries(c, mangaID) {
+				return nil, false
+			}
+			zapLogger.Warn("Manga not found", zap.String("mangaID", mangaID))
+			c.JSON(http.StatusNotFound, gin.H{"error": "Manga not found"})
+		} else {
+			zapLogger.Error("Failed to retrieve manga", zap.Error(err))
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve manga: " + err.Error()})
+		}
+		return nil, false
+	}
+	if !canSeeSeries(c, manga) {
+		zapLogger.Warn("Manga not visible to requester", zap.String("mangaID", mangaID))
+		c.JSON(http.StatusNotFound, gin.H{"error": "Manga not found"})
+		return nil, false
+	}
+	return manga, true
+}
+
+// lookupPage resolves :pageNumber within an already resolved chapter
+func lookupPage(c *gin.Context, chapter *models.Chapter) ([]models.Page, *models.Page, bool) {
+	pageNumberStr := c.Param("pageNumber")
+	pageNumber, err := strconv.Atoi(pageNumberStr)
+	if err != nil {
+		zapLogger.Warn("Invalid page number", zap.String("pageNumberStr", pageNumberStr))
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid page number"})
+		return nil, nil, false
+	}
+
+	pages, err := metadataManager.LoadPages(chapter)
+	if err != nil {
+		zapLogger.Error("Failed to retrieve pages", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve pages: " + err.Error()})
+		return nil, nil, false
+	}
+
+	for i := range pages {
+		if pages[i].Number == pageNumber {
+			return pages, &pages[i], true
+		}
+	}
+
+	zapLogger.Warn("Page not found",
+		zap.String("mangaID", chapter.MangaID),
+		zap.Float64("chapterNumber", chapter.Number),
+		zap.Int("pageNumber", pageNumber),
+	)
+	c.JSON(http.StatusNotFound, gin.H{"error": "Page not found"})
+	return nil, nil, false
+}
@@ -0,0 +1,226 @@
+package routes
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"mangahub/backend/models"
+	"mangahub/backend/storage"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// maxCachedPageHashes bounds the page hash cache; it starts over when full
+const maxCachedPageHashes = 100000
+
+// manifestPage is one page of a chapter manifest
+type manifestPage struct {
+	Number int    `json:"number"`
+	URL    string `json:"url"`
+	Width  int    `json:"width,omitempty"`
+	Height int    `json:"height,omitempty"`
+	Type   string `json:"type,omitempty"`
+	Size   int64  `json:"size"`
+	Hash   string `json:"sha256"`
+	File   string `json:"file,omitempty"` // Entry name in a chapter bundle
+}
+
+// chapterManifest lists everything an offline reader needs to fetch a
+// chapter. Version changes whenever a page does.
+type chapterManifest struct {
+	MangaID   string         `json:"mangaId"`
+	ChapterID string         `json:"chapterId"`
+	Number    float64        `json:"number"`
+	Title     string         `json:"title"`
+	Version   string         `json:"version"`
+	TotalSize int64          `json:"totalSize"`
+	Pages     []manifestPage `json:"pages"`
+}
+
+// cachedPageHash is a page's SHA-256, valid while its size and modification
+// time are unchanged
+type cachedPageHash struct {
+	size    int64
+	modTime time.Time
+	hash    string
+}
+
+var (
+	pageHashesMu sync.Mutex
+	pageHashes   = make(map[string]cachedPageHash)
+)
+
+// pageHash returns the SHA-256 of a page file, hashing it only when it
+// changed since it was last hashed
+func pageHash(path string, info os.FileInfo) (string, error) {
+	pageHashesMu.Lock()
+	cached, ok := pageHashes[path]
+	pageHashesMu.Unlock()
+	if ok && cached.size == info.Size() && cached.modTime.Equal(info.ModTime()) {
+		return cached.hash, nil
+	}
+
+	hash, err := storage.HashFile(path)
+	if err != nil {
+		return "", err
+	}
+
+	pageHashesMu.Lock()
+	if len(pageHashes) >= maxCachedPageHashes {
+		pageHashes = make(map[string]cachedPageHash)
+	}
+	pageHashes[path] = cachedPageHash{size: info.Size(), modTime: info.ModTime(), hash: hash}
+	pageHashesMu.Unlock()
+	return hash, nil
+}
+
+// forgetPageHashes drops the cached hashes of the pages in a chapter folder
+func forgetPageHashes(chapterPath string) {
+	prefix := chapterPath + string(filepath.Separator)
+	pageHashesMu.Lock()
+	for path := range pageHashes {
+		if strings.HasPrefix(path, prefix) {
+			delete(pageHashes, path)
+		}
+	}
+	pageHashesMu.Unlock()
+}
+
+// contentVersion identifies the state of a chapter's page files from their
+// names, sizes and modification times, without reading them. Offline readers
+// compare it to invalidate a saved chapter only when its pages changed.
+func contentVersion(chapter *models.Chapter, infos []os.FileInfo) string {
+	h := sha256.New()
+	fmt.Fprintf(h, "%s\n", chapter.ID)
+	for _, info := range infos {
+		fmt.Fprintf(h, "%s %d %d\n", info.Name(), info.Size(), info.ModTime().UnixNano())
+	}
+	return hex.EncodeToString(h.Sum(nil))[:16]
+}
+
+// chapterVersion loads a chapter's pages to derive its content version
+func chapterVersion(chapter *models.Chapter) (string, error) {
+	pages, err := metadataManager.LoadPages(chapter)
+	if err != nil {
+		return "", err
+	}
+	infos, err := statPages(pages)
+	if err != nil {
+		return "", err
+	}
+	return contentVersion(chapter, infos), nil
+}
+
+// statPages stats the image files of a chapter's pages
+func statPages(pages []models.Page) ([]os.FileInfo, error) {
+	infos := make([]os.FileInfo, len(pages))
+	for i := range pages {
+		info, err := os.Stat(pages[i].ImagePath)
+		if err != nil {
+			return nil, err
+		}
+		infos[i] = info
+	}
+	return infos, nil
+}
+
+// buildManifest lists a chapter's pages with their dimensions, sizes and
+// hashes
+func buildManifest(chapter *models.Chapter, pages []models.Page, infos []os.FileInfo) (*chapterManifest, error) {
+	manifest := &chapterManifest{
+		MangaID:   chapter.MangaID,
+		ChapterID: chapter.ID,
+		Number:    chapter.Number,
+		Title:     chapter.Title,
+		Version:   contentVersion(chapter, infos),
+		Pages:     make([]manifestPage, 0, len(pages)),
+	}
+	for i := range pages {
+		page := &pages[i]
+		if err := page.LoadImageMetadata(); err != nil {
+			zapLogger.Warn("Failed to read page dimensions",
+				zap.String("imagePath", page.ImagePath),
+				zap.Error(err),
+			)
+		}
+		hash, err := pageHash(page.ImagePath, infos[i])
+		if err != nil {
+			return nil, err
+		}
+		manifest.Pages = append(manifest.Pages, manifestPage{
+			Number: page.Number,
+			URL:    page.GetImageURL(),
+			Width:  page.Width,
+			Height: page.Height,
+			Type:   page.MimeType,
+			Size:   infos[i].Size(),
+			Hash:   hash,
+		})
+		manifest.TotalSize += infos[i].Size()
+	}
+	return manifest, nil
+}
+
+// loadPageFiles loads a chapter's pages and stats their files, writing the
+// error response itself on failure
+func loadPageFiles(c *gin.Context, chapter *models.Chapter) ([]models.Page, []os.FileInfo, bool) {
+	pages, err := metadataManager.LoadPages(chapter)
+	if err != nil {
+		zapLogger.Error("Failed to retrieve pages", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve pages: " + err.Error()})
+		return nil, nil, false
+	}
+	infos, err := statPages(pages)
+	if err != nil {
+		zapLogger.Error("Failed to read page files", zap.String("chapterID", chapter.ID), zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to read page files: " + err.Error()})
+		return nil, nil, false
+	}
+	return pages, infos, true
+}
+
+// checkNotModified sets the ETag of a response that is revalidated on every
+// use, and answers 304 when the client's copy is current
+func checkNotModified(c *gin.Context, etag string) bool {
+	c.Header("ETag", etag)
+	c.Header("Cache-Control", "private, no-cache")
+	if c.GetHeader("If-None-Match") == etag {
+		c.Status(http.StatusNotModified)
+		return true
+	}
+	return false
+}
+
+// getChapterManifest returns every page of a chapter in one response, so
+// offline-capable readers can fetch and verify a whole chapter. The response
+// carries an ETag and is revalidated rather than downloaded again.
+func getChapterManifest(c *gin.Context) {
+	lookup, ok := lookupChapter(c)
+	if !ok {
+		return
+	}
+	chapter := lookup.Chapter()
+
+	pages, infos, ok := loadPageFiles(c, chapter)
+	if !ok {
+		return
+	}
+	if checkNotModified(c, `"`+contentVersion(chapter, infos)+`"`) {
+		return
+	}
+
+	manifest, err := buildManifest(chapter, pages, infos)
+	if err != nil {
+		zapLogger.Error("Failed to build chapter manifest", zap.String("chapterID", chapter.ID), zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to read page files: " + err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, manifest)
+}
@@ -0,0 +1,170 @@
+package routes
+
+import (
+	"mangahub/backend/models"
+	"net/http"
+	"net/url"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// mergeSeriesMetadata adds the titles, genres, tags and custom fields of
+// source to target. Target's own values win where both have one.
+func mergeSeriesMetadata(target, source *models.MangaSeries) {
+	for _, title := range append([]string{source.Title}, source.AltTitles...) {
+		title = strings.TrimSpace(title)
+		if title == "" || equalIgnoreCase(title, target.Title) || hasAltTitle(target.AltTitles, title) {
+			continue
+		}
+		target.AltTitles = append(target.AltTitles, title)
+	}
+	for _, genre := range source.Genres {
+		if !slices.ContainsFunc(target.Genres, func(g string) bool { return equalIgnoreCase(g, genre) }) {
+			target.Genres = append(target.Genres, genre)
+		}
+	}
+	target.Tags = tagStore.CanonicalList(append(target.Tags, source.Tags...))
+	for key, value := range source.CustomFields {
+		if _, ok := target.CustomFields[key]; ok {
+			continue
+		}
+		if target.CustomFields == nil {
+			target.CustomFields = make(map[string]string)
+		}
+		target.CustomFields[key] = value
+	}
+	target.NSFW = target.NSFW || source.NSFW
+}
+
+// mergeManga merges the series :id into the series named by "into": its
+// chapters move over, its titles become alternative titles and its genres,
+// tags and custom fields (such as external IDs) are combined. The merged
+// series is then removed and its ID redirects to the one it was merged into.
+// Nothing changes if both series have a chapter with the same number.
+func mergeManga(c *gin.Context) {
+	sourceID := c.Param("id")
+	var request struct {
+		Into string `json:"into" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request: " + err.Error()})
+		return
+	}
+	zapLogger.Info("mergeManga handler called", zap.String("mangaID", sourceID), zap.String("into", request.Into))
+
+	if request.Into == sourceID {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Cannot merge a series into itself"})
+		return
+	}
+	source, ok := lookupManga(c, sourceID)
+	if !ok {
+		return
+	}
+	target, ok := lookupManga(c, request.Into)
+	if !ok {
+		return
+	}
+	if source.Owner != target.Owner {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Series must belong to the same library"})
+		return
+	}
+
+	sourceChapters, err := metadataManager.ScanForChapters(source)
+	if err != nil {
+		zapLogger.Error("Failed to retrieve chapters", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve chapters: " + err.Error()})
+		return
+	}
+	targetChapters, err := metadataManager.ScanForChapters(target)
+	if err != nil {
+		zapLogger.Error("Failed to retrieve chapters", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve chapters: " + err.Error()})
+		return
+	}
+	existing := make(map[float64]bool, len(targetChapters))
+	for i := range targetChapters {
+		existing[targetChapters[i].Number] = true
+	}
+	conflicts := []float64{}
+	for i := range sourceChapters {
+		if existing[sourceChapters[i].Number] {
+			conflicts = append(conflicts, sourceChapters[i].Number)
+		}
+	}
+	if len(conflicts) > 0 {
+		c.JSON(http.StatusConflict, gin.H{"error": "Both series have chapters with the same number", "numbers": conflicts})
+		return
+	}
+
+	moved := []string{}
+	for i := range sourceChapters {
+		chapter := &sourceChapters[i]
+		if err := moveChapter(chapter, target); err != nil {
+			zapLogger.Error("Failed to move chapter",
+				zap.String("chapterID", chapter.ID),
+				zap.String("into", target.ID),
+				zap.Error(err))
+			libraryIndex.Refresh(source.Path)
+			libraryIndex.Refresh(target.Path)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to move chapter: " + err.Error(), "moved": moved})
+			return
+		}
+		moved = append(moved, chapter.ID)
+	}
+
+	mergeSeriesMetadata(target, source)
+	target.ChapterCount = len(targetChapters) + len(sourceChapters)
+	target.LastUpdated = timeNow()
+	if !saveManga(c, target) {
+		return
+	}
+
+	if err := redirectStore.Add(source.ID, target.ID); err != nil {
+		zapLogger.Error("Failed to save series redirect", zap.String("mangaID", source.ID), zap.Error(err))
+	}
+	if err := os.RemoveAll(source.Path); err != nil {
+		zapLogger.Error("Failed to delete manga directory", zap.String("mangaPath", source.Path), zap.Error(err))
+	}
+	libraryIndex.Refresh(source.Path)
+
+	c.JSON(http.StatusOK, gin.H{
+		"id":        target.ID,
+		"mergedId":  source.ID,
+		"chapters":  moved,
+		"altTitles": target.AltTitles,
+	})
+}
+
+// redirectMergedSeries answers a request for a series that was merged into
+// another with a permanent redirect to the same URL under the new ID. It
+// only handles URLs whose :id is mangaID, and reports whether it answered.
+func redirectMergedSeries(c *gin.Context, mangaID string) bool {
+	if c.Param("id") != mangaID {
+		return false
+	}
+	target, ok := redirectStore.Resolve(mangaID)
+	if !ok {
+		return false
+	}
+
+	routeSegments := strings.Split(c.FullPath(), "/")
+	pathSegments := strings.Split(c.Request.URL.EscapedPath(), "/")
+	if len(routeSegments) != len(pathSegments) {
+		return false
+	}
+	for i, segment := range routeSegments {
+		if segment == ":id" {
+			pathSegments[i] = url.PathEscape(target)
+		}
+	}
+	location := strings.Join(pathSegments, "/")
+	if c.Request.URL.RawQuery != "" {
+		location += "?" + c.Request.URL.RawQuery
+	}
+	c.Redirect(http.StatusPermanentRedirect, location)
+	return true
+}
@@ -0,0 +1,233 @@
+package routes
+
+import (
+	"context"
+	"fmt"
+	"mangahub/backend/importers"
+	"mangahub/backend/jobs"
+	"mangahub/backend/models"
+	"net/http"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// metadataRefreshJobType identifies metadata refreshes in the jobs API
+const metadataRefreshJobType = "metadata-refresh"
+
+// metadataLookupDelay spaces out provider requests to stay within their rate
+// limits
+const metadataLookupDelay = time.Second
+
+// metadataRefreshInterval is how often linked series are refreshed; 0 only
+// refreshes when an admin asks
+var metadataRefreshInterval time.Duration
+
+// SetMetadataRefreshInterval sets how often series linked to a metadata
+// provider are refreshed from it; 0 disables the periodic refresh
+func SetMetadataRefreshInterval(interval time.Duration) {
+	metadataRefreshInterval = interval
+}
+
+// metadataProviders look series up by the ID kept in the custom field of the
+// same name. A series linked to several is refreshed from the first.
+var metadataProviders = []struct {
+	field    string
+	provider importers.MetadataProvider
+}{
+	{importers.ProviderMangaDex, &importers.MangaDex{}},
+	{importers.TrackerAniList, &importers.AniList{}},
+}
+
+// fieldChange is one field a refresh changes
+type fieldChange struct {
+	Field string      `json:"field"`
+	From  interface{} `json:"from"`
+	To    interface{} `json:"to"`
+}
+
+// seriesRefresh lists what a refresh changes in one series
+type seriesRefresh struct {
+	MangaID  string        `json:"mangaId"`
+	Provider string        `json:"provider"`
+	Changes  []fieldChange `json:"changes"`
+}
+
+// metadataRefreshReport is the result of a metadata refresh. In a preview
+// nothing is saved and Changed is what a refresh would do.
+type metadataRefreshReport struct {
+	Preview   bool            `json:"preview"`
+	Checked   int             `json:"checked"`
+	Changed   []seriesRefresh `json:"changed"`
+	Unchanged []string        `json:"unchanged"`
+	Locked    []string        `json:"locked"` // Series skipped because they are locked
+	Failed    []gin.H         `json:"failed"`
+}
+
+// linkedProvider returns the provider a series is linked to by its custom
+// fields, with the series' ID there
+func linkedProvider(manga *models.MangaSeries) (string, importers.MetadataProvider, string, bool) {
+	for _, p := range metadataProviders {
+		if id := manga.CustomFields[p.field]; id != "" {
+			return p.field, p.provider, id, true
+		}
+	}
+	return "", nil, "", false
+}
+
+// metadataFieldValues are the refreshable fields of a series keyed by their
+// lockable names
+func metadataFieldValues(manga *models.MangaSeries) map[string]interface{} {
+	return map[string]interface{}{
+		models.FieldTitle:         manga.Title,
+		models.FieldAltTitles:     manga.AltTitles,
+		models.FieldDescription:   manga.Description,
+		models.FieldAuthor:        manga.Author,
+		models.FieldArtist:        manga.Artist,
+		models.FieldGenres:        manga.Genres,
+		models.FieldTags:          manga.Tags,
+		models.FieldStatus:        manga.Status,
+		models.FieldPublishedYear: manga.PublishedYear,
+		models.FieldPublisher:     manga.Publisher,
+		models.FieldDemographic:   manga.Demographic,
+	}
+}
+
+// refreshedMetadata looks a series up and returns it with the provider's
+// metadata applied to the fields that aren't locked, and what changed
+func refreshedMetadata(ctx context.Context, manga *models.MangaSeries, provider importers.MetadataProvider, id string) (models.MangaSeries, []fieldChange, error) {
+	series, err := provider.Lookup(ctx, id)
+	if err != nil {
+		return models.MangaSeries{}, nil, err
+	}
+
+	updated := *manga
+	updated.AltTitles = slices.Clone(manga.AltTitles)
+	updated.Tags = slices.Clone(manga.Tags)
+	applyImportedMetadata(&updated, *series)
+
+	before, after := metadataFieldValues(manga), metadataFieldValues(&updated)
+	changes := []fieldChange{}
+	for _, field := range models.LockableFields {
+		from, ok := before[field]
+		if ok && !reflect.DeepEqual(from, after[field]) {
+			changes = append(changes, fieldChange{Field: field, From: from, To: after[field]})
+		}
+	}
+	return updated, changes, nil
+}
+
+// startMetadataRefreshes refreshes linked series once the library index is
+// ready and then every metadataRefreshInterval
+func startMetadataRefreshes() {
+	if metadataRefreshInterval <= 0 {
+		zapLogger.Info("Periodic metadata refreshes disabled")
+		return
+	}
+	go func() {
+		for !libraryIndex.Ready() {
+			time.Sleep(10 * time.Second)
+		}
+		for {
+			if len(runningJobs(metadataRefreshJobType)) == 0 {
+				startMetadataRefresh(nil, false)
+			}
+			time.Sleep(metadataRefreshInterval)
+		}
+	}()
+}
+
+// startMetadataRefresh refreshes the given series, or every series linked to
+// a provider when ids is empty, as a job. A preview only reports the changes.
+func startMetadataRefresh(ids []string, preview bool) jobs.Job {
+	return jobManager.Start(metadataRefreshJobType, func(ctx context.Context, progress *jobs.Progress) (interface{}, error) {
+		report := metadataRefreshReport{
+			Preview:   preview,
+			Changed:   []seriesRefresh{},
+			Unchanged: []string{},
+			Locked:    []string{},
+			Failed:    []gin.H{},
+		}
+
+		var series []models.MangaSeries
+		for _, manga := range libraryIndex.List() {
+			if _, _, _, linked := linkedProvider(&manga); linked && (len(ids) == 0 || slices.Contains(ids, manga.ID)) {
+				series = append(series, manga)
+			}
+		}
+		for _, id := range ids {
+			if !slices.ContainsFunc(series, func(m models.MangaSeries) bool { return m.ID == id }) {
+				report.Failed = append(report.Failed, gin.H{"mangaId": id, "error": "series not found or not linked to a metadata provider"})
+			}
+		}
+
+		for i := range series {
+			manga := &series[i]
+			progress.Update(i, len(series), fmt.Sprintf("Checked %d of %d series", i, len(series)))
+			if manga.Locked {
+				report.Locked = append(report.Locked, manga.ID)
+				continue
+			}
+			if i > 0 {
+				select {
+				case <-ctx.Done():
+					return report, ctx.Err()
+				case <-time.After(metadataLookupDelay):
+				}
+			}
+			report.Checked++
+
+			name, provider, id, _ := linkedProvider(manga)
+			updated, changes, err := refreshedMetadata(ctx, manga, provider, id)
+			if err != nil {
+				zapLogger.Warn("Failed to look up series metadata",
+					zap.String("mangaID", manga.ID),
+					zap.String("provider", name),
+					zap.Error(err))
+				report.Failed = append(report.Failed, gin.H{"mangaId": manga.ID, "provider": name, "error": err.Error()})
+				continue
+			}
+			if len(changes) == 0 {
+				report.Unchanged = append(report.Unchanged, manga.ID)
+				continue
+			}
+			if !preview {
+				if err := updated.SaveToJSON(filepath.Join(manga.Path, models.MetadataFileName)); err != nil {
+					zapLogger.Warn("Failed to save refreshed metadata", zap.String("mangaID", manga.ID), zap.Error(err))
+					report.Failed = append(report.Failed, gin.H{"mangaId": manga.ID, "provider": name, "error": err.Error()})
+					continue
+				}
+				libraryIndex.Refresh(manga.Path)
+			}
+			report.Changed = append(report.Changed, seriesRefresh{MangaID: manga.ID, Provider: name, Changes: changes})
+		}
+		progress.Update(len(series), len(series), "")
+
+		zapLogger.Info("Metadata refresh complete",
+			zap.Bool("preview", preview),
+			zap.Int("checked", report.Checked),
+			zap.Int("changed", len(report.Changed)),
+			zap.Int("failed", len(report.Failed)))
+		return report, nil
+	})
+}
+
+// refreshMetadata starts a metadata refresh of the series linked to MangaDex
+// or AniList through a "mangadex" or "anilist" custom field, or only of the
+// series given as ?id=. With ?preview=true the job result lists the changes
+// without saving them. Locked fields and series are left alone.
+func refreshMetadata(c *gin.Context) {
+	ids := c.QueryArray("id")
+	preview := c.Query("preview") == "true"
+	zapLogger.Info("refreshMetadata handler called", zap.Strings("ids", ids), zap.Bool("preview", preview))
+
+	if running := runningJobs(metadataRefreshJobType); len(running) > 0 {
+		c.JSON(http.StatusConflict, gin.H{"error": "A metadata refresh is already running", "job": running[0]})
+		return
+	}
+	c.JSON(http.StatusAccepted, startMetadataRefresh(ids, preview))
+}
@@ -0,0 +1,197 @@
+package routes
+
+import (
+	"context"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// corsMaxAge is how long, in seconds, browsers may cache a preflight response
+const corsMaxAge = "600"
+
+// corsAllowedHeaders are the request headers cross-origin API clients may send
+var corsAllowedHeaders = []string{"Authorization", "Content-Type", "Accept-Language", csrfHeaderName}
+
+// corsExposedHeaders are the response headers cross-origin API clients may read
+var corsExposedHeaders = []string{"Content-Language", "Content-Disposition", "ETag", "Link", nextCursorHeader, libraryWarmingHeader}
+
+// methodOrder is the order methods are listed in Allow headers
+var methodOrder = []string{
+	http.MethodGet, http.MethodHead, http.MethodPost, http.MethodPut,
+	http.MethodPatch, http.MethodDelete, http.MethodOptions,
+}
+
+// corsOrigins are the origins allowed to call the API from a browser; "*"
+// allows any origin, without credentials
+var corsOrigins []string
+
+// SetCORSOrigins allows pages on other origins (e.g. "https://reader.example.com")
+// to call the API. Listed origins may send cookies; "*" allows any origin,
+// but only for requests without them.
+func SetCORSOrigins(origins []string) {
+	corsOrigins = nil
+	for _, origin := range origins {
+		if origin = strings.TrimRight(strings.TrimSpace(origin), "/"); origin != "" {
+			corsOrigins = append(corsOrigins, origin)
+		}
+	}
+}
+
+// headRequestKey marks requests that arrived as HEAD, see Methods
+type headRequestKey struct{}
+
+// Methods wraps the router so every route answers HEAD and OPTIONS. HEAD
+// requests are routed to the GET handler and answered by HeadRequests;
+// OPTIONS requests get the route's allowed methods and, for allowed origins,
+// a CORS preflight response.
+func Methods(router *gin.Engine) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		setCORSHeaders(w, r)
+		switch r.Method {
+		case http.MethodOptions:
+			answerOptions(router, w, r)
+			return
+		case http.MethodHead:
+			r = r.WithContext(context.WithValue(r.Context(), headRequestKey{}, true))
+			r.Method = http.MethodGet
+		}
+		router.ServeHTTP(w, r)
+	})
+}
+
+// HeadRequests restores the method of HEAD requests Methods routed to a GET
+// handler. The handler runs as usual, but its body is only counted for the
+// Content-Length header. It must come before other middleware.
+func HeadRequests(c *gin.Context) {
+	if c.Request.Context().Value(headRequestKey{}) == nil {
+		c.Next()
+		return
+	}
+	c.Request.Method = http.MethodHead
+	writer := &headWriter{ResponseWriter: c.Writer}
+	c.Writer = writer
+	c.Next()
+
+	if !writer.Written() && writer.size > 0 && writer.Header().Get("Content-Length") == "" {
+		writer.Header().Set("Content-Length", strconv.Itoa(writer.size))
+	}
+}
+
+// headWriter drops the body of a HEAD response, keeping its length
+type headWriter struct {
+	gin.ResponseWriter
+	size int
+}
+
+func (w *headWriter) Write(data []byte) (int, error) {
+	w.size += len(data)
+	return len(data), nil
+}
+
+func (w *headWriter) WriteString(s string) (int, error) {
+	w.size += len(s)
+	return len(s), nil
+}
+
+// answerOptions lists the methods a path allows, answering CORS preflight
+// requests for the API along the way
+func answerOptions(router *gin.Engine, w http.ResponseWriter, r *http.Request) {
+	allowed := allowedMethods(router, r.URL.Path)
+	if len(allowed) == 0 {
+		http.NotFound(w, r)
+		return
+	}
+	header := w.Header()
+	header.Set("Allow", strings.Join(allowed, ", "))
+
+	if header.Get("Access-Control-Allow-Origin") != "" && r.Header.Get("Access-Control-Request-Method") != "" {
+		header.Set("Access-Control-Allow-Methods", strings.Join(allowed, ", "))
+		header.Set("Access-Control-Allow-Headers", strings.Join(corsAllowedHeaders, ", "))
+		header.Set("Access-Control-Max-Age", corsMaxAge)
+		header.Add("Vary", "Access-Control-Request-Method")
+		header.Add("Vary", "Access-Control-Request-Headers")
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// allowedMethods lists the methods registered for a path, in methodOrder,
+// or none when no route matches it. GET routes also answer HEAD, and every
+// route answers OPTIONS.
+func allowedMethods(router *gin.Engine, path string) []string {
+	registered := make(map[string]bool)
+	for _, route := range router.Routes() {
+		if matchRoute(route.Path, path) {
+			registered[route.Method] = true
+		}
+	}
+	if len(registered) == 0 {
+		return nil
+	}
+	if registered[http.MethodGet] {
+		registered[http.MethodHead] = true
+	}
+	registered[http.MethodOptions] = true
+
+	var allowed []string
+	for _, method := range methodOrder {
+		if registered[method] {
+			allowed = append(allowed, method)
+		}
+	}
+	return allowed
+}
+
+// methodNotAllowed answers requests for a path whose routes don't take
+// their method, listing the methods they do take
+func methodNotAllowed(router *gin.Engine) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Header("Allow", strings.Join(allowedMethods(router, c.Request.URL.Path), ", "))
+		c.AbortWithStatusJSON(http.StatusMethodNotAllowed, gin.H{"error": "Method not allowed"})
+	}
+}
+
+// matchRoute reports whether path matches a route pattern with :param and
+// *catchAll segments
+func matchRoute(pattern, path string) bool {
+	patternParts := strings.Split(strings.Trim(pattern, "/"), "/")
+	pathParts := strings.Split(strings.Trim(path, "/"), "/")
+	for i, part := range patternParts {
+		if strings.HasPrefix(part, "*") {
+			return true
+		}
+		if i >= len(pathParts) {
+			return false
+		}
+		if !strings.HasPrefix(part, ":") && part != pathParts[i] {
+			return false
+		}
+		if strings.HasPrefix(part, ":") && pathParts[i] == "" {
+			return false
+		}
+	}
+	return len(patternParts) == len(pathParts)
+}
+
+// setCORSHeaders allows API requests from configured origins
+func setCORSHeaders(w http.ResponseWriter, r *http.Request) {
+	origin := r.Header.Get("Origin")
+	if origin == "" || len(corsOrigins) == 0 || !strings.HasPrefix(r.URL.Path, "/api/") {
+		return
+	}
+	header := w.Header()
+	header.Add("Vary", "Origin")
+	switch {
+	case slices.Contains(corsOrigins, origin):
+		header.Set("Access-Control-Allow-Origin", origin)
+		header.Set("Access-Control-Allow-Credentials", "true")
+	case slices.Contains(corsOrigins, "*"):
+		header.Set("Access-Control-Allow-Origin", "*")
+	default:
+		return
+	}
+	header.Set("Access-Control-Expose-Headers", strings.Join(corsExposedHeaders, ", "))
+}
@@ -0,0 +1,42 @@
+package routes
+
+import (
+	"mangahub/backend/models"
+	"mangahub/backend/naming"
+	"path/filepath"
+)
+
+// namingScheme sets the folder names of new series and chapters
+var namingScheme naming.Scheme
+
+// SetNaming sets the folder naming scheme used for new series and chapters
+func SetNaming(scheme naming.Scheme) {
+	namingScheme = scheme
+}
+
+// seriesFolderPath is where a new series with the given ID and title is
+// stored; by default a folder named after its ID
+func seriesFolderPath(id, title string) (string, error) {
+	folder, err := namingScheme.SeriesFolder(naming.Values{SeriesID: id, SeriesTitle: title}, id)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(metadataManager.RootDir, folder), nil
+}
+
+// chapterFolderPath is where a new chapter of manga is stored; by default a
+// folder named after the chapter ID
+func chapterFolderPath(manga *models.MangaSeries, chapter *models.Chapter) (string, error) {
+	values := naming.Values{
+		SeriesID:    manga.ID,
+		SeriesTitle: manga.Title,
+		Chapter:     chapter.Number,
+		Volume:      chapter.Volume,
+		Title:       chapter.Title,
+	}
+	folder, err := namingScheme.ChapterFolder(values, chapter.ID)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(manga.Path, folder), nil
+}
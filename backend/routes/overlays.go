@@ -0,0 +1,101 @@
+package routes
+
+import (
+	"mangahub/backend/models"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// getPageOverlays returns the translation text regions of a page
+func getPageOverlays(c *gin.Context) {
+	zapLogger.Info("getPageOverlays handler called",
+		zap.String("mangaID", c.Param("id")),
+		zap.String("chapterNumber", c.Param("chapterNumber")),
+		zap.String("pageNumber", c.Param("pageNumber")),
+	)
+
+	lookup, ok := lookupChapter(c)
+	if !ok {
+		return
+	}
+	_, page, ok := lookupPage(c, lookup.Chapter())
+	if !ok {
+		return
+	}
+
+	overlays, err := models.LoadOverlays(lookup.Chapter().Path)
+	if err != nil {
+		zapLogger.Error("Failed to load overlays", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load overlays: " + err.Error()})
+		return
+	}
+
+	regions := overlays.Pages[page.Number]
+	if regions == nil {
+		regions = []models.TextRegion{}
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"pageNumber": page.Number,
+		"regions":    regions,
+	})
+}
+
+// updatePageOverlays replaces the translation text regions of a page; an
+// empty list removes them
+func updatePageOverlays(c *gin.Context) {
+	zapLogger.Info("updatePageOverlays handler called",
+		zap.String("mangaID", c.Param("id")),
+		zap.String("chapterNumber", c.Param("chapterNumber")),
+		zap.String("pageNumber", c.Param("pageNumber")),
+	)
+
+	var request struct {
+		Regions []models.TextRegion `json:"regions"`
+	}
+	if err := c.ShouldBindJSON(&request); err != nil {
+		zapLogger.Warn("Invalid request data", zap.Error(err))
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request: " + err.Error()})
+		return
+	}
+
+	lookup, ok := lookupChapter(c)
+	if !ok {
+		return
+	}
+	_, page, ok := lookupPage(c, lookup.Chapter())
+	if !ok {
+		return
+	}
+
+	var invalid error
+	overlays, err := models.UpdateOverlays(lookup.Chapter().Path, func(overlays *models.ChapterOverlays) error {
+		invalid = overlays.SetPage(page.Number, request.Regions)
+		return invalid
+	})
+	if invalid != nil {
+		zapLogger.Warn("Invalid text region", zap.Error(invalid))
+		c.JSON(http.StatusBadRequest, gin.H{"error": invalid.Error()})
+		return
+	}
+	if err != nil {
+		zapLogger.Error("Failed to save overlays", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save overlays: " + err.Error()})
+		return
+	}
+
+	regions := overlays.Pages[page.Number]
+	if regions == nil {
+		regions = []models.TextRegion{}
+	}
+	zapLogger.Info("Overlays updated",
+		zap.String("chapterID", lookup.Chapter().ID),
+		zap.Int("pageNumber", page.Number),
+		zap.Int("regionCount", len(regions)),
+	)
+	c.JSON(http.StatusOK, gin.H{
+		"pageNumber": page.Number,
+		"regions":    regions,
+	})
+}
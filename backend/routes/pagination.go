@@ -0,0 +1,151 @@
+package routes
+
+import (
+	"encoding/base64"
+	"mangahub/backend/models"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// nextCursorHeader carries the cursor for the following window of a
+	// cursor-paginated list; it is absent on the last window
+	nextCursorHeader = "X-Next-Cursor"
+
+	defaultChapterPageSize = 100
+	maxChapterPageSize     = 500
+)
+
+// chapterWindow describes the part of a chapter list a request asks for
+type chapterWindow struct {
+	From, To float64 // inclusive chapter-number range; 0 means unbounded
+
+	// After is the position the previous window ended at
+	After      *chapterCursor
+	Limit      int // 0 returns everything
+	HasFilters bool
+}
+
+// chapterCursor is a position in the chapter list, encoded opaquely for clients.
+// Chapters are ordered by number, specialness and ID (see models.ChapterLess),
+// so the triple is a stable position even when chapters are added or removed
+// between requests.
+type chapterCursor struct {
+	Number  float64
+	Special bool
+	ID      string
+}
+
+// cursorAt is the cursor positioned at a chapter
+func cursorAt(chapter *models.Chapter) *chapterCursor {
+	return &chapterCursor{Number: chapter.Number, Special: chapter.Special, ID: chapter.ID}
+}
+
+func (cur chapterCursor) encode() string {
+	special := ""
+	if cur.Special {
+		special = "s"
+	}
+	raw := strconv.FormatFloat(cur.Number, 'f', -1, 64) + "|" + special + "|" + cur.ID
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+func decodeChapterCursor(s string) (*chapterCursor, bool) {
+	raw, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, false
+	}
+	numberStr, rest, ok := strings.Cut(string(raw), "|")
+	if !ok {
+		return nil, false
+	}
+	number, err := strconv.ParseFloat(numberStr, 64)
+	if err != nil {
+		return nil, false
+	}
+	cur := &chapterCursor{Number: number, ID: rest}
+	// Cursors issued before the special flag was added are "number|id"
+	if special, id, ok := strings.Cut(rest, "|"); ok && (special == "" || special == "s") {
+		cur.Special, cur.ID = special == "s", id
+	}
+	return cur, true
+}
+
+// isAfter reports whether a chapter sorts after the cursor position
+func (cur chapterCursor) isAfter(chapter *models.Chapter) bool {
+	return models.ChapterLess(&models.Chapter{Number: cur.Number, Special: cur.Special, ID: cur.ID}, chapter)
+}
+
+// parseChapterWindow reads ?from=, ?to=, ?cursor= and ?limit=. Without cursor
+// or limit the whole (range-filtered) list is returned.
+func parseChapterWindow(c *gin.Context) (chapterWindow, bool) {
+	var window chapterWindow
+
+	for _, param := range []struct {
+		name   string
+		target *float64
+	}{{"from", &window.From}, {"to", &window.To}} {
+		raw := c.Query(param.name)
+		if raw == "" {
+			continue
+		}
+		n, err := strconv.ParseFloat(raw, 64)
+		if err != nil || n < 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid " + param.name + " chapter number"})
+			return window, false
+		}
+		*param.target = n
+	}
+
+	if raw := c.Query("cursor"); raw != "" {
+		cur, ok := decodeChapterCursor(raw)
+		if !ok {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid cursor"})
+			return window, false
+		}
+		window.After = cur
+		window.Limit = defaultChapterPageSize
+	}
+
+	if raw := c.Query("limit"); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil || n < 1 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid limit"})
+			return window, false
+		}
+		window.Limit = min(n, maxChapterPageSize)
+	}
+
+	window.HasFilters = window.From > 0 || window.To > 0 || window.After != nil || window.Limit > 0
+	return window, true
+}
+
+// apply cuts the window out of a sorted chapter list. The returned cursor is
+// nil when there is nothing after the window.
+func (w chapterWindow) apply(chapters []models.Chapter) ([]models.Chapter, *chapterCursor) {
+	if !w.HasFilters {
+		return chapters, nil
+	}
+
+	selected := chapters[:0:0]
+	for i := range chapters {
+		chapter := &chapters[i]
+		if w.From > 0 && chapter.Number < w.From {
+			continue
+		}
+		if w.To > 0 && chapter.Number > w.To {
+			continue
+		}
+		if w.After != nil && !w.After.isAfter(chapter) {
+			continue
+		}
+		if w.Limit > 0 && len(selected) == w.Limit {
+			return selected, cursorAt(&selected[len(selected)-1])
+		}
+		selected = append(selected, *chapter)
+	}
+	return selected, nil
+}
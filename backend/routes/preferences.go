@@ -0,0 +1,36 @@
+package routes
+
+import (
+	"mangahub/backend/users"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// getPreferences returns the signed-in user's reader settings
+func getPreferences(c *gin.Context) {
+	user := currentUser(c)
+	c.JSON(http.StatusOK, userStore.Preferences(user.ID))
+}
+
+// updatePreferences replaces the signed-in user's reader settings. Omitted
+// settings fall back to their defaults.
+func updatePreferences(c *gin.Context) {
+	user := currentUser(c)
+
+	var prefs users.Preferences
+	if err := c.ShouldBindJSON(&prefs); err != nil {
+		zapLogger.Warn("Invalid request data", zap.Error(err))
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request: " + err.Error()})
+		return
+	}
+
+	saved, err := userStore.SetPreferences(user.ID, prefs)
+	if err != nil {
+		respondUserError(c, err)
+		return
+	}
+	zapLogger.Info("Preferences updated", zap.String("userID", user.ID))
+	c.JSON(http.StatusOK, saved)
+}
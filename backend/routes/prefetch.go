@@ -0,0 +1,43 @@
+package routes
+
+import (
+	"mangahub/backend/models"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// prefetchPages is how many upcoming page images the reader is hinted to
+// load ahead; 0 disables the hints
+var prefetchPages = 3
+
+// SetPrefetchPages sets how many upcoming page images getChapter and getPage
+// hint browsers to load ahead, so page turns don't wait on the network
+func SetPrefetchPages(n int) {
+	if n >= 0 {
+		prefetchPages = n
+	}
+}
+
+// prefetchURLs returns the image URLs of the pages to load ahead after the
+// page at index current, or the first pages when current is -1
+func prefetchURLs(pages []models.Page, current int) []string {
+	urls := []string{}
+	for i := current + 1; i < len(pages) && len(urls) < prefetchPages; i++ {
+		urls = append(urls, pages[i].GetImageURL())
+	}
+	return urls
+}
+
+// setPrefetchLinks adds the prefetch hints to the response as a Link header,
+// which browsers act on before the client reads the body
+func setPrefetchLinks(c *gin.Context, urls []string) {
+	if len(urls) == 0 {
+		return
+	}
+	links := make([]string, len(urls))
+	for i, url := range urls {
+		links[i] = "<" + url + ">; rel=prefetch; as=image"
+	}
+	c.Header("Link", strings.Join(links, ", "))
+}
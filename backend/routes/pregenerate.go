@@ -0,0 +1,189 @@
+package routes
+
+import (
+	"context"
+	"fmt"
+	"mangahub/backend/events"
+	"mangahub/backend/imaging"
+	"mangahub/backend/jobs"
+	"mangahub/backend/models"
+	"mangahub/backend/pregen"
+	"net/http"
+	"path/filepath"
+	"slices"
+	"sync/atomic"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// pregenerateBackfillJobType identifies queueing the whole library for image
+// pregeneration in the jobs API
+const pregenerateBackfillJobType = "pregenerate-backfill"
+
+// readAheadChapters is how many chapters, starting with the one being read,
+// are queued when a reader's progress is saved
+const readAheadChapters = 2
+
+var (
+	// pregenerateWorkers is how many chapters are pregenerated at once; 0
+	// disables pregeneration
+	pregenerateWorkers = 1
+
+	// pregenQueue is nil while pregeneration is disabled
+	pregenQueue *pregen.Queue
+
+	// imageRequests counts page images and thumbnails being served, during
+	// which pregeneration waits
+	imageRequests atomic.Int32
+)
+
+// SetPregenerateWorkers sets how many chapters have their image variants
+// generated ahead of time at once; 0 disables pregeneration
+func SetPregenerateWorkers(n int) {
+	if n >= 0 {
+		pregenerateWorkers = n
+	}
+}
+
+// startPregeneration starts the pregeneration workers and queues chapters as
+// they are imported
+func startPregeneration() {
+	if pregenerateWorkers == 0 {
+		zapLogger.Info("Image pregeneration disabled")
+		return
+	}
+	pregenQueue = pregen.New(pregenerateWorkers, pregenerateChapter, func() bool { return imageRequests.Load() > 0 })
+	pregenQueue.Start()
+
+	eventBus.Subscribe(events.ChapterImported, func(event events.Event) {
+		mangaID, _ := event.Data["mangaId"].(string)
+		path, _ := event.Data["path"].(string)
+		if path != "" {
+			pregenQueue.Add(mangaID, path, pregen.PriorityNew)
+		}
+	})
+}
+
+// pregenerateVariants are the image options a page is served with, other
+// than the original, for a series
+func pregenerateVariants(manga *models.MangaSeries) []imaging.Options {
+	var variants []imaging.Options
+	for _, scale := range variantScales {
+		opts := imaging.Options{Scale: scale, CropMargins: manga.AutoCrop}
+		if !opts.IsOriginal() {
+			variants = append(variants, opts)
+		}
+	}
+	return variants
+}
+
+// pregenerateChapter generates the resolution variants of every page of a
+// chapter and its thumbnail, yielding to requests between pages
+func pregenerateChapter(item pregen.Item) error {
+	manga, ok := libraryIndex.GetByPath(filepath.Dir(item.ChapterPath))
+	if !ok {
+		return fmt.Errorf("no series at %s", filepath.Dir(item.ChapterPath))
+	}
+	chapter, err := metadataManager.CreateChapterFromDirectory(manga.ID, item.ChapterPath)
+	if err != nil {
+		return err
+	}
+	pages, err := metadataManager.LoadPages(&chapter)
+	if err != nil {
+		return err
+	}
+
+	variants := pregenerateVariants(manga)
+	for i, page := range pages {
+		pregenQueue.WaitWhileBusy()
+		if i == 0 {
+			thumbnail := imaging.Options{MaxWidth: chapterThumbnailWidth, CropMargins: manga.AutoCrop}
+			if _, err := imageCache.Get(page.ImagePath, thumbnail); err != nil {
+				return err
+			}
+		}
+		for _, opts := range variants {
+			if _, err := imageCache.Get(page.ImagePath, opts); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// queueReadAhead queues the chapter a reader is on and the next ones, ahead
+// of anything else waiting
+func queueReadAhead(manga *models.MangaSeries, chapterNumber float64) {
+	if pregenQueue == nil {
+		return
+	}
+	chapters, err := metadataManager.ScanForChapters(manga)
+	if err != nil {
+		zapLogger.Warn("Failed to retrieve chapters", zap.String("mangaID", manga.ID), zap.Error(err))
+		return
+	}
+	queued := 0
+	for i := range chapters {
+		if chapters[i].Number >= chapterNumber && queued < readAheadChapters {
+			pregenQueue.Add(manga.ID, chapters[i].Path, pregen.PriorityReading)
+			queued++
+		}
+	}
+}
+
+// startPregenerateBackfill queues every chapter of the given series, or of
+// the whole library, at the lowest priority as a job
+func startPregenerateBackfill(ids []string) jobs.Job {
+	return jobManager.Start(pregenerateBackfillJobType, func(ctx context.Context, progress *jobs.Progress) (interface{}, error) {
+		series := libraryIndex.List()
+		queued := 0
+		for i := range series {
+			if err := ctx.Err(); err != nil {
+				return gin.H{"queued": queued}, err
+			}
+			manga := &series[i]
+			progress.Update(i, len(series), fmt.Sprintf("Queued %d chapters", queued))
+			if len(ids) > 0 && !slices.Contains(ids, manga.ID) {
+				continue
+			}
+			chapters, err := metadataManager.ScanForChapters(manga)
+			if err != nil {
+				zapLogger.Warn("Failed to retrieve chapters", zap.String("mangaID", manga.ID), zap.Error(err))
+				continue
+			}
+			for j := range chapters {
+				pregenQueue.Add(manga.ID, chapters[j].Path, pregen.PriorityBackfill)
+				queued++
+			}
+		}
+		progress.Update(len(series), len(series), "")
+		return gin.H{"queued": queued}, nil
+	})
+}
+
+// getPregeneration reports the state of the pregeneration queue
+func getPregeneration(c *gin.Context) {
+	if pregenQueue == nil {
+		c.JSON(http.StatusConflict, gin.H{"error": "Image pregeneration is not enabled"})
+		return
+	}
+	c.JSON(http.StatusOK, pregenQueue.Stats())
+}
+
+// pregenerateLibrary queues the chapters of the series given as ?id=, or of
+// the whole library, for pregeneration once nothing more urgent is waiting
+func pregenerateLibrary(c *gin.Context) {
+	ids := c.QueryArray("id")
+	zapLogger.Info("pregenerateLibrary handler called", zap.Strings("ids", ids))
+
+	if pregenQueue == nil {
+		c.JSON(http.StatusConflict, gin.H{"error": "Image pregeneration is not enabled"})
+		return
+	}
+	if running := runningJobs(pregenerateBackfillJobType); len(running) > 0 {
+		c.JSON(http.StatusConflict, gin.H{"error": "The library is already being queued for pregeneration", "job": running[0]})
+		return
+	}
+	c.JSON(http.StatusAccepted, startPregenerateBackfill(ids))
+}
@@ -0,0 +1,66 @@
+package routes
+
+import (
+	"mangahub/backend/presence"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+var (
+	// presenceEnabled shows how many people are reading each series
+	presenceEnabled bool
+
+	// readerPresence counts the readers connected to each series and chapter
+	readerPresence = presence.NewTracker()
+)
+
+// SetPresence enables the reader presence socket and counts
+func SetPresence(enabled bool) {
+	presenceEnabled = enabled
+}
+
+func requirePresence(c *gin.Context) {
+	if !presenceEnabled {
+		c.AbortWithStatusJSON(http.StatusNotFound, gin.H{"error": "Reader presence is not enabled"})
+		return
+	}
+	c.Next()
+}
+
+// watchPresence upgrades to a WebSocket that counts the requester as a reader
+// of the series while it's open. Clients pass ?chapter=<number> for the
+// chapter they're on and send {"type":"chapter","chapter":..} when it
+// changes (null when back on the series page); the server pushes
+// {"type":"count","readers":..,"chapterReaders":..} whenever the counts change.
+func watchPresence(c *gin.Context) {
+	manga, ok := lookupManga(c, c.Param("id"))
+	if !ok {
+		return
+	}
+
+	var chapter *float64
+	if raw := c.Query("chapter"); raw != "" {
+		number, err := strconv.ParseFloat(raw, 64)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid chapter number"})
+			return
+		}
+		chapter = &number
+	}
+
+	viewer := ""
+	if user := currentUser(c); user != nil {
+		viewer = user.ID
+	}
+
+	conn, err := syncUpgrader.Upgrade(c.Writer, c.Request, nil)
+	if err != nil {
+		// The upgrader has already written an error response
+		zapLogger.Warn("Presence upgrade failed", zap.Error(err))
+		return
+	}
+	readerPresence.Serve(conn, viewer, manga.ID, chapter)
+}
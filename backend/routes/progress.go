@@ -0,0 +1,157 @@
+package routes
+
+import (
+	"mangahub/backend/models"
+	"mangahub/backend/progress"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// maxStatsDays bounds the activity calendar to about two years
+const maxStatsDays = 731
+
+// listProgress returns the signed-in user's progress in every series
+func listProgress(c *gin.Context) {
+	list := progressStore.List(currentUser(c).ID)
+	if list == nil {
+		list = []progress.Entry{}
+	}
+	c.JSON(http.StatusOK, list)
+}
+
+// getProgress returns the signed-in user's progress in one series
+func getProgress(c *gin.Context) {
+	entry, ok := progressStore.Get(currentUser(c).ID, c.Param("id"))
+	if !ok {
+		c.JSON(http.StatusNotFound, gin.H{"error": "No progress recorded"})
+		return
+	}
+	c.JSON(http.StatusOK, entry)
+}
+
+// updateProgress records how far the signed-in user has read in a series
+func updateProgress(c *gin.Context) {
+	mangaID := c.Param("id")
+	user := currentUser(c)
+	zapLogger.Info("updateProgress handler called", zap.String("mangaID", mangaID), zap.String("userID", user.ID))
+
+	var request struct {
+		Chapter   float64 `json:"chapter"`
+		Page      int     `json:"page"`
+		Completed bool    `json:"completed"`
+		Status    string  `json:"status"` // Keeps the recorded status when empty
+	}
+	if err := c.ShouldBindJSON(&request); err != nil {
+		zapLogger.Warn("Invalid request data", zap.Error(err))
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request: " + err.Error()})
+		return
+	}
+	if request.Chapter < 0 || request.Page < 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Chapter and page must not be negative"})
+		return
+	}
+	if !progress.ValidStatus(request.Status) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "status must be one of " + strings.Join(progress.Statuses, ", ")})
+		return
+	}
+
+	manga, ok := lookupManga(c, mangaID)
+	if !ok {
+		return
+	}
+
+	entry, err := saveProgress(manga, progress.Entry{
+		UserID:    user.ID,
+		MangaID:   mangaID,
+		Chapter:   request.Chapter,
+		Page:      request.Page,
+		Completed: request.Completed,
+		Status:    request.Status,
+	})
+	if err != nil {
+		zapLogger.Error("Failed to save progress", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save progress: " + err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, entry)
+}
+
+// saveProgress stores a user's position in a series and counts the chapters
+// it moved past towards their reading activity. An entry without a status
+// keeps the recorded one.
+func saveProgress(manga *models.MangaSeries, entry progress.Entry) (progress.Entry, error) {
+	previous, hadPrevious := progressStore.Get(entry.UserID, entry.MangaID)
+	if entry.Status == "" {
+		entry.Status = previous.Status
+	}
+	entry, err := progressStore.Set(entry)
+	if err != nil {
+		return progress.Entry{}, err
+	}
+	go queueReadAhead(manga, entry.Chapter)
+	if hadPrevious {
+		finished := chaptersFinished(manga, previous, entry)
+		if err := activityStore.Record(entry.UserID, entry.UpdatedAt, finished); err != nil {
+			zapLogger.Warn("Failed to record reading activity", zap.String("userID", entry.UserID), zap.Error(err))
+		}
+	}
+	return entry, nil
+}
+
+// chaptersFinished counts the chapters a progress update moved past: those
+// from the previous position up to the new one, and the last chapter when
+// the series is marked completed
+func chaptersFinished(manga *models.MangaSeries, previous, current progress.Entry) int {
+	finished := 0
+	if current.Chapter > previous.Chapter {
+		chapters, err := metadataManager.ScanForChapters(manga)
+		if err != nil {
+			zapLogger.Warn("Failed to retrieve chapters", zap.String("mangaID", manga.ID), zap.Error(err))
+			return 0
+		}
+		for _, chapter := range chapters {
+			if chapter.Number >= previous.Chapter && chapter.Number < current.Chapter {
+				finished++
+			}
+		}
+	}
+	if current.Completed && !previous.Completed {
+		finished++
+	}
+	return finished
+}
+
+// getReadingStats returns the signed-in user's reading totals and streaks
+// with a calendar of chapters read per day (UTC) over the last ?days= days,
+// 365 by default
+func getReadingStats(c *gin.Context) {
+	days := 365
+	if raw := c.Query("days"); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil || n < 1 || n > maxStatsDays {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "days must be a number from 1 to " + strconv.Itoa(maxStatsDays)})
+			return
+		}
+		days = n
+	}
+	c.JSON(http.StatusOK, activityStore.Stats(currentUser(c).ID, timeNow(), days))
+}
+
+// deleteProgress forgets the signed-in user's progress in a series
+func deleteProgress(c *gin.Context) {
+	removed, err := progressStore.Delete(currentUser(c).ID, c.Param("id"))
+	if err != nil {
+		zapLogger.Error("Failed to save progress", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save progress: " + err.Error()})
+		return
+	}
+	if !removed {
+		c.JSON(http.StatusNotFound, gin.H{"error": "No progress recorded"})
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
@@ -0,0 +1,65 @@
+package routes
+
+import (
+	"net/netip"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// cloudflareProxies stands for Cloudflare's edge addresses in the trusted
+// proxy list
+const cloudflareProxies = "cloudflare"
+
+// cloudflareIPHeader carries the client address on requests through
+// Cloudflare
+const cloudflareIPHeader = "CF-Connecting-IP"
+
+// cloudflareRanges are the addresses Cloudflare's edge connects from, as
+// published at https://www.cloudflare.com/ips/
+var cloudflareRanges = []string{
+	"173.245.48.0/20", "103.21.244.0/22", "103.22.200.0/22", "103.31.4.0/22",
+	"141.101.64.0/18", "108.162.192.0/18", "190.93.240.0/20", "188.114.96.0/20",
+	"197.234.240.0/22", "198.41.128.0/17", "162.158.0.0/15", "104.16.0.0/13",
+	"104.24.0.0/14", "172.64.0.0/13", "131.0.72.0/22",
+	"2400:cb00::/32", "2606:4700::/32", "2803:f800::/32", "2405:b500::/32",
+	"2405:8100::/32", "2a06:98c0::/29", "2c0f:f248::/32",
+}
+
+// trustedProxies are the peers whose forwarding headers are believed
+var trustedProxies []netip.Prefix
+
+// SetTrustedProxies sets the reverse proxies, as addresses or CIDR ranges,
+// whose headers name the real client address. Headers are read in order;
+// "cloudflare" in proxies adds Cloudflare's ranges and its client address
+// header. Requests from any other peer are attributed to the peer itself.
+func SetTrustedProxies(router *gin.Engine, proxies, headers []string) error {
+	var expanded []string
+	for _, proxy := range proxies {
+		if strings.EqualFold(strings.TrimSpace(proxy), cloudflareProxies) {
+			expanded = append(expanded, cloudflareRanges...)
+			headers = append([]string{cloudflareIPHeader}, headers...)
+			continue
+		}
+		expanded = append(expanded, proxy)
+	}
+
+	prefixes, err := parsePrefixes(expanded)
+	if err != nil {
+		return err
+	}
+	if err := router.SetTrustedProxies(expanded); err != nil {
+		return err
+	}
+	if len(headers) > 0 {
+		router.RemoteIPHeaders = headers
+	}
+	trustedProxies = prefixes
+	return nil
+}
+
+// fromTrustedProxy reports whether the request came through a trusted proxy
+func fromTrustedProxy(c *gin.Context) bool {
+	addr, err := netip.ParseAddrPort(c.Request.RemoteAddr)
+	return err == nil && containsAddr(trustedProxies, addr.Addr().Unmap())
+}
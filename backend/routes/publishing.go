@@ -0,0 +1,119 @@
+package routes
+
+import (
+	"mangahub/backend/events"
+	"mangahub/backend/models"
+	"mangahub/backend/scheduler"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+var (
+	eventBus         *events.Bus
+	publishScheduler *scheduler.Scheduler
+)
+
+// canSeeUnpublished reports whether the request may see scheduled chapters:
+// admin endpoints and signed-in admins do
+func canSeeUnpublished(c *gin.Context) bool {
+	if strings.HasPrefix(c.FullPath(), "/api/admin/") {
+		return true
+	}
+	user := currentUser(c)
+	return user != nil && user.IsAdmin()
+}
+
+// publishedChapterDir reports whether the chapter stored in a folder of a
+// series may be served to the requester: it is published, or they may see
+// unpublished chapters. Only the folder's metadata file is read, as chapters
+// without one can't be scheduled.
+func publishedChapterDir(c *gin.Context, manga *models.MangaSeries, dir string) bool {
+	if canSeeUnpublished(c) {
+		return true
+	}
+	metadataPath := filepath.Join(manga.Path, dir, models.MetadataFileName)
+	if _, err := os.Stat(metadataPath); err != nil {
+		return os.IsNotExist(err)
+	}
+	var chapter models.Chapter
+	if err := chapter.LoadFromJSON(metadataPath); err != nil {
+		zapLogger.Warn("Failed to load chapter metadata", zap.String("metadataPath", metadataPath), zap.Error(err))
+		return false
+	}
+	return chapter.IsPublished(timeNow())
+}
+
+// visibleChapters drops chapters whose publish time hasn't come yet, unless
+// the caller may see them, and those a chapter share token doesn't grant
+func visibleChapters(c *gin.Context, chapters []models.Chapter) []models.Chapter {
+	if canSeeUnpublished(c) {
+		return chapters
+	}
+	now := timeNow()
+	visible := chapters[:0:0]
+	for _, chapter := range chapters {
+		if chapter.IsPublished(now) {
+			visible = append(visible, chapter)
+		}
+	}
+	return sharedChapters(c, visible)
+}
+
+// publishScheduledChapter flips a scheduled chapter live once its time has
+// come: the hold is cleared, the release date set and a notification fired
+func publishScheduledChapter(entry scheduler.Entry) error {
+	metadataPath := filepath.Join(entry.ChapterPath, models.MetadataFileName)
+
+	if _, err := os.Stat(metadataPath); os.IsNotExist(err) {
+		zapLogger.Warn("Dropping schedule for deleted chapter", zap.String("chapterPath", entry.ChapterPath))
+		return nil
+	}
+
+	var chapter models.Chapter
+	if err := chapter.LoadFromJSON(metadataPath); err != nil {
+		return err
+	}
+	if chapter.PublishAt == nil {
+		// Unscheduled by an edit in the meantime; nothing left to do
+		return nil
+	}
+
+	chapter.ReleaseDate = *chapter.PublishAt
+	chapter.PublishAt = nil
+	if err := chapter.SaveToJSON(metadataPath); err != nil {
+		return err
+	}
+
+	zapLogger.Info("Scheduled chapter published",
+		zap.String("mangaID", chapter.MangaID),
+		zap.String("chapterID", chapter.ID),
+	)
+	notifyChapterPublished(&chapter)
+	return nil
+}
+
+// notifyChapterImported announces that a chapter's files were added to the
+// library, whether or not it is published yet
+func notifyChapterImported(chapter *models.Chapter) {
+	eventBus.Publish(events.ChapterImported, map[string]interface{}{
+		"mangaId":   chapter.MangaID,
+		"chapterId": chapter.ID,
+		"number":    chapter.Number,
+		"path":      chapter.Path,
+	})
+}
+
+// notifyChapterPublished announces a newly readable chapter
+func notifyChapterPublished(chapter *models.Chapter) {
+	eventBus.Publish(events.ChapterPublished, map[string]interface{}{
+		"mangaId":     chapter.MangaID,
+		"chapterId":   chapter.ID,
+		"number":      chapter.Number,
+		"title":       chapter.Title,
+		"releaseDate": timestamp(chapter.ReleaseDate),
+	})
+}
@@ -0,0 +1,230 @@
+package routes
+
+import (
+	"errors"
+	"mangahub/backend/models"
+	"mangahub/backend/readingorders"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// readingOrderRequest is the body accepted when creating or updating a
+// reading order
+type readingOrderRequest struct {
+	Name    string                `json:"name" binding:"required"`
+	Summary string                `json:"summary"`
+	Entries []readingorders.Entry `json:"entries"`
+}
+
+// listReadingOrders returns every reading order without its chapters
+func listReadingOrders(c *gin.Context) {
+	response := []gin.H{}
+	for _, order := range readingOrderStore.List() {
+		response = append(response, gin.H{
+			"id":         order.ID,
+			"name":       order.Name,
+			"summary":    order.Summary,
+			"entryCount": len(order.Entries),
+			"updatedAt":  order.UpdatedAt,
+		})
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+// getReadingOrder returns a reading order with the series and chapter
+// titles of its entries. Chapters the requester may not see are skipped.
+func getReadingOrder(c *gin.Context) {
+	order, err := readingOrderStore.Get(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Reading order not found"})
+		return
+	}
+
+	chapters := readingOrderChapters{c: c}
+	entries := []gin.H{}
+	for i, entry := range order.Entries {
+		manga, chapter := chapters.find(entry)
+		if chapter == nil {
+			continue
+		}
+		entries = append(entries, gin.H{
+			"position":     i + 1,
+			"mangaId":      manga.ID,
+			"mangaTitle":   manga.Title,
+			"chapter":      chapter.Number,
+			"chapterTitle": chapter.Title,
+			"coverImage":   manga.GetCoverImageURL(),
+		})
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"id":        order.ID,
+		"name":      order.Name,
+		"summary":   order.Summary,
+		"entries":   entries,
+		"createdAt": order.CreatedAt,
+		"updatedAt": order.UpdatedAt,
+	})
+}
+
+// createReadingOrder adds a reading order
+func createReadingOrder(c *gin.Context) {
+	var request readingOrderRequest
+	if !bindReadingOrder(c, &request) {
+		return
+	}
+	zapLogger.Info("createReadingOrder handler called", zap.String("name", request.Name))
+
+	order, err := readingOrderStore.Create(request.Name, request.Summary, request.Entries)
+	if err != nil {
+		zapLogger.Error("Failed to save reading order", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save reading order: " + err.Error()})
+		return
+	}
+	c.JSON(http.StatusCreated, order)
+}
+
+// updateReadingOrder replaces a reading order's name, summary and chapters
+func updateReadingOrder(c *gin.Context) {
+	id := c.Param("id")
+	var request readingOrderRequest
+	if !bindReadingOrder(c, &request) {
+		return
+	}
+	zapLogger.Info("updateReadingOrder handler called", zap.String("orderID", id))
+
+	order, err := readingOrderStore.Update(id, request.Name, request.Summary, request.Entries)
+	if errors.Is(err, readingorders.ErrNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Reading order not found"})
+		return
+	}
+	if err != nil {
+		zapLogger.Error("Failed to save reading order", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save reading order: " + err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, order)
+}
+
+// deleteReadingOrder removes a reading order; its chapters are untouched
+func deleteReadingOrder(c *gin.Context) {
+	id := c.Param("id")
+	zapLogger.Info("deleteReadingOrder handler called", zap.String("orderID", id))
+
+	removed, err := readingOrderStore.Delete(id)
+	if err != nil {
+		zapLogger.Error("Failed to save reading orders", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save reading orders: " + err.Error()})
+		return
+	}
+	if !removed {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Reading order not found"})
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// bindReadingOrder parses a reading order request and checks that every
+// chapter it names exists. On failure it writes a 400 and returns false.
+func bindReadingOrder(c *gin.Context, request *readingOrderRequest) bool {
+	if err := c.ShouldBindJSON(request); err != nil {
+		zapLogger.Warn("Invalid request data", zap.Error(err))
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request: " + err.Error()})
+		return false
+	}
+	if strings.TrimSpace(request.Name) == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Reading order name is required"})
+		return false
+	}
+
+	chapters := readingOrderChapters{c: c}
+	for _, entry := range request.Entries {
+		if _, chapter := chapters.find(entry); chapter == nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Unknown chapter: " + entry.MangaID + " " +
+				strconv.FormatFloat(entry.Chapter, 'f', -1, 64)})
+			return false
+		}
+	}
+	return true
+}
+
+// readingOrderChapters looks up the chapters of reading order entries,
+// scanning each series once
+type readingOrderChapters struct {
+	c      *gin.Context
+	series map[string]*readingOrderSeries
+}
+
+type readingOrderSeries struct {
+	manga    *models.MangaSeries
+	chapters []models.Chapter
+}
+
+// find returns the series and chapter of an entry, or nils when either is
+// gone or hidden from the requester
+func (r *readingOrderChapters) find(entry readingorders.Entry) (*models.MangaSeries, *models.Chapter) {
+	if r.series == nil {
+		r.series = make(map[string]*readingOrderSeries)
+	}
+	series, ok := r.series[entry.MangaID]
+	if !ok {
+		series = &readingOrderSeries{}
+		if manga, found := libraryIndex.Get(entry.MangaID); found && canSeeSeries(r.c, manga) {
+			chapters, err := metadataManager.ScanForChapters(manga)
+			if err != nil {
+				zapLogger.Warn("Failed to retrieve chapters", zap.String("mangaID", manga.ID), zap.Error(err))
+			}
+			series.manga, series.chapters = manga, visibleChapters(r.c, chapters)
+		}
+		r.series[entry.MangaID] = series
+	}
+	for i := range series.chapters {
+		if series.chapters[i].Number == entry.Chapter {
+			return series.manga, &series.chapters[i]
+		}
+	}
+	return nil, nil
+}
+
+// readingOrderHints describes where a chapter sits in each reading order
+// that includes it, with the chapters to read before and after it
+func readingOrderHints(c *gin.Context, mangaID string, number float64) []gin.H {
+	hints := []gin.H{}
+	chapters := readingOrderChapters{c: c}
+	for _, order := range readingOrderStore.Containing(mangaID, number) {
+		position := order.Position(mangaID, number)
+		hint := gin.H{
+			"id":       order.ID,
+			"name":     order.Name,
+			"position": position + 1,
+			"total":    len(order.Entries),
+		}
+		for i := position + 1; i < len(order.Entries); i++ {
+			if manga, chapter := chapters.find(order.Entries[i]); chapter != nil {
+				hint["next"] = readingOrderStep(manga, chapter)
+				break
+			}
+		}
+		for i := position - 1; i >= 0; i-- {
+			if manga, chapter := chapters.find(order.Entries[i]); chapter != nil {
+				hint["prev"] = readingOrderStep(manga, chapter)
+				break
+			}
+		}
+		hints = append(hints, hint)
+	}
+	return hints
+}
+
+func readingOrderStep(manga *models.MangaSeries, chapter *models.Chapter) gin.H {
+	return gin.H{
+		"mangaId":      manga.ID,
+		"mangaTitle":   manga.Title,
+		"chapter":      chapter.Number,
+		"chapterTitle": chapter.Title,
+	}
+}
@@ -0,0 +1,23 @@
+package routes
+
+import "math"
+
+// readingSecondsPerPage is how long reading one page, or one screen of a
+// webtoon strip, is estimated to take
+var readingSecondsPerPage = 20
+
+// SetReadingSpeed sets the seconds per page reading time estimates use
+func SetReadingSpeed(secondsPerPage int) {
+	if secondsPerPage > 0 {
+		readingSecondsPerPage = secondsPerPage
+	}
+}
+
+// readingMinutes estimates the reading time of a chapter of the given length
+// in screens, rounded up to whole minutes, or returns nil when unmeasured
+func readingMinutes(screens float64) interface{} {
+	if screens <= 0 {
+		return nil
+	}
+	return int(math.Ceil(screens * float64(readingSecondsPerPage) / 60))
+}
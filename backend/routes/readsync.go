@@ -0,0 +1,50 @@
+package routes
+
+import (
+	"mangahub/backend/readsync"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+	"go.uber.org/zap"
+)
+
+// readingSync relays page turns between a user's open sessions
+var readingSync = readsync.NewHub()
+
+// syncUpgrader accepts same-origin WebSocket handshakes only
+var syncUpgrader = websocket.Upgrader{
+	ReadBufferSize:  1024,
+	WriteBufferSize: 1024,
+}
+
+// syncReadingPosition upgrades to a WebSocket that pushes the user's page
+// turns between devices. Clients pass ?session=<device id> and send
+// {"type":"position","position":{"mangaId":..,"chapter":..,"page":..}}.
+func syncReadingPosition(c *gin.Context) {
+	user := currentUser(c)
+	sessionID := c.Query("session")
+	if sessionID == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Missing session parameter"})
+		return
+	}
+
+	conn, err := syncUpgrader.Upgrade(c.Writer, c.Request, nil)
+	if err != nil {
+		// The upgrader has already written an error response
+		zapLogger.Warn("Reading sync upgrade failed", zap.Error(err))
+		return
+	}
+	readingSync.Serve(conn, user.ID, sessionID)
+}
+
+// getReadingPosition returns the latest position reported by any of the
+// user's sessions, for clients that aren't connected to the socket
+func getReadingPosition(c *gin.Context) {
+	pos, ok := readingSync.Latest(currentUser(c).ID)
+	if !ok {
+		c.JSON(http.StatusNotFound, gin.H{"error": "No reading position recorded"})
+		return
+	}
+	c.JSON(http.StatusOK, pos)
+}
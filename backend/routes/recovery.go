@@ -0,0 +1,134 @@
+package routes
+
+import (
+	"mangahub/backend/mail"
+	"mangahub/backend/users"
+	"net/http"
+	"net/url"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// verifyEmail mails new users a link to confirm their address
+var verifyEmail bool
+
+// SetVerifyEmail enables verification emails on registration
+func SetVerifyEmail(enabled bool) {
+	verifyEmail = enabled
+}
+
+// accountMailEnabled reports whether account emails can be sent. Their links
+// need the configured public URL: deriving it from the request's Host header
+// would let anyone send reset links pointing elsewhere.
+func accountMailEnabled() bool {
+	return mail.Enabled() && publicURL != ""
+}
+
+// requireAccountMail rejects requests for account emails when none can be sent
+func requireAccountMail(c *gin.Context) {
+	if !accountMailEnabled() {
+		c.AbortWithStatusJSON(http.StatusServiceUnavailable, gin.H{"error": "Email is not configured on this server"})
+		return
+	}
+	c.Next()
+}
+
+// requestPasswordReset mails a reset link to the accounts using an address.
+// The response is the same whether or not any account matched.
+func requestPasswordReset(c *gin.Context) {
+	zapLogger.Info("requestPasswordReset handler called")
+
+	var request struct {
+		Email string `json:"email" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&request); err != nil {
+		zapLogger.Warn("Invalid request data", zap.Error(err))
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request: " + err.Error()})
+		return
+	}
+
+	tokens, err := userStore.CreatePasswordReset(request.Email)
+	if err != nil {
+		respondUserError(c, err)
+		return
+	}
+	for token, user := range tokens {
+		body := "Hi " + user.Username + ",\n\n" +
+			"Someone asked to reset the password of your MangaHub account. To choose a new one, open:\n\n" +
+			publicURL + "/reset-password?token=" + url.QueryEscape(token) + "\n\n" +
+			"The link expires in an hour. If you didn't ask for this, you can ignore this email.\n"
+		if err := mail.Send(user.Email, "Reset your MangaHub password", body); err != nil {
+			zapLogger.Error("Failed to send password reset email", zap.String("userID", user.ID), zap.Error(err))
+		}
+	}
+	c.JSON(http.StatusAccepted, gin.H{"status": "If an account uses that address, a reset link is on its way"})
+}
+
+// resetPassword sets a new password from a reset link and logs the user in
+func resetPassword(c *gin.Context) {
+	zapLogger.Info("resetPassword handler called")
+
+	var request struct {
+		Token    string `json:"token" binding:"required"`
+		Password string `json:"password" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&request); err != nil {
+		zapLogger.Warn("Invalid request data", zap.Error(err))
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request: " + err.Error()})
+		return
+	}
+
+	user, err := userStore.ResetPassword(request.Token, request.Password)
+	if err != nil {
+		respondUserError(c, err)
+		return
+	}
+	issueSession(c, user, http.StatusOK)
+}
+
+// resendVerification mails the current user a new verification link
+func resendVerification(c *gin.Context) {
+	user := currentUser(c)
+	zapLogger.Info("resendVerification handler called", zap.String("userID", user.ID))
+
+	if err := sendVerification(user); err != nil {
+		respondUserError(c, err)
+		return
+	}
+	c.JSON(http.StatusAccepted, gin.H{"status": "Verification email sent"})
+}
+
+// confirmEmail marks an address as verified from a verification link
+func confirmEmail(c *gin.Context) {
+	zapLogger.Info("confirmEmail handler called")
+
+	var request struct {
+		Token string `json:"token" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&request); err != nil {
+		zapLogger.Warn("Invalid request data", zap.Error(err))
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request: " + err.Error()})
+		return
+	}
+
+	user, err := userStore.VerifyEmail(request.Token)
+	if err != nil {
+		respondUserError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, user.Public())
+}
+
+// sendVerification mails a user a link confirming their email address
+func sendVerification(user *users.User) error {
+	token, err := userStore.CreateEmailVerification(user.ID)
+	if err != nil {
+		return err
+	}
+	body := "Hi " + user.Username + ",\n\n" +
+		"Please confirm this is your email address by opening:\n\n" +
+		publicURL + "/verify-email?token=" + url.QueryEscape(token) + "\n\n" +
+		"The link expires in two days.\n"
+	return mail.Send(user.Email, "Confirm your MangaHub email address", body)
+}
@@ -0,0 +1,213 @@
+package routes
+
+import (
+	"context"
+	"fmt"
+	"mangahub/backend/importers"
+	"mangahub/backend/jobs"
+	"mangahub/backend/models"
+	"net/http"
+	"os"
+	"path/filepath"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+const (
+	// remoteFetchJobType identifies remote chapter downloads in the jobs API
+	remoteFetchJobType = "chapter-fetch"
+
+	// maxRemotePages caps the number of page URLs accepted per chapter
+	maxRemotePages = 1000
+)
+
+// fetchRemoteChapter creates a chapter from images hosted elsewhere: either a
+// list of page URLs, downloaded in order, or the URL of a CBZ archive. The
+// download runs as a job; the response is the job to poll.
+func fetchRemoteChapter(c *gin.Context) {
+	mangaID := c.Param("id")
+	zapLogger.Info("fetchRemoteChapter handler called", zap.String("mangaID", mangaID))
+
+	var request struct {
+		Number     float64  `json:"number" binding:"required"`
+		Title      string   `json:"title"`
+		Volume     int      `json:"volume"`
+		Special    bool     `json:"special"`
+		URLs       []string `json:"urls"`
+		ArchiveURL string   `json:"archiveUrl"`
+	}
+	if err := c.ShouldBindJSON(&request); err != nil {
+		zapLogger.Warn("Invalid request data", zap.Error(err))
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request: " + err.Error()})
+		return
+	}
+	if (len(request.URLs) == 0) == (request.ArchiveURL == "") {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Provide either urls or archiveUrl"})
+		return
+	}
+	if len(request.URLs) > maxRemotePages {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("At most %d page URLs are accepted", maxRemotePages)})
+		return
+	}
+	for _, u := range append(request.URLs, request.ArchiveURL) {
+		if u == "" {
+			continue
+		}
+		if err := importers.ValidateURL(u); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request: " + err.Error()})
+			return
+		}
+	}
+
+	manga, ok := lookupManga(c, mangaID)
+	if !ok {
+		return
+	}
+
+	chapter := models.Chapter{
+		MangaID: manga.ID,
+		Number:  request.Number,
+		Title:   request.Title,
+		Volume:  request.Volume,
+		Special: request.Special,
+	}
+	startChapterDownload(c, manga, chapter, func(ctx context.Context, stagingPath string, report func(done, total int)) (int, error) {
+		if request.ArchiveURL != "" {
+			return importers.DownloadArchive(ctx, nil, request.ArchiveURL, stagingPath, report)
+		}
+		return importers.DownloadPages(ctx, nil, importers.PagesFromURLs(request.URLs), stagingPath, report)
+	})
+}
+
+// chapterDownload fetches the pages of a new chapter into stagingPath,
+// calling report as pages arrive, and returns the page count
+type chapterDownload func(ctx context.Context, stagingPath string, report func(done, total int)) (int, error)
+
+// startChapterDownload creates a chapter of manga from pages fetched by
+// download, running it as a job, and responds with the job to poll. The
+// chapter's ID and path are derived from its number.
+func startChapterDownload(c *gin.Context, manga *models.MangaSeries, chapter models.Chapter, download chapterDownload) {
+	chapter.ID = chapterIDFor(chapter.Number)
+	chapterPath, err := chapterFolderPath(manga, &chapter)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request: " + err.Error()})
+		return
+	}
+	chapter.Path = chapterPath
+	if _, err := os.Stat(chapter.Path); err == nil {
+		c.JSON(http.StatusConflict, gin.H{"error": "Chapter already exists"})
+		return
+	}
+
+	stagingPath, err := newStagingDir(manga.Path, chapter.ID)
+	if err != nil {
+		zapLogger.Error("Failed to create chapter directory", zap.String("mangaPath", manga.Path), zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create chapter directory: " + err.Error()})
+		return
+	}
+	mangaPath := manga.Path
+
+	job := jobManager.Start(remoteFetchJobType, func(ctx context.Context, progress *jobs.Progress) (interface{}, error) {
+		progress.Update(0, 0, "Downloading pages")
+		pageCount, err := download(ctx, stagingPath, func(done, total int) {
+			progress.Update(done, total, fmt.Sprintf("Downloaded %d of %d pages", done, total))
+		})
+		if err == nil {
+			err = finishStagedChapter(&chapter, stagingPath, pageCount)
+		}
+		if err != nil {
+			os.RemoveAll(stagingPath)
+			return nil, err
+		}
+
+		libraryIndex.Refresh(mangaPath)
+		notifyChapterPublished(&chapter)
+		notifyChapterImported(&chapter)
+		return gin.H{
+			"mangaId":   chapter.MangaID,
+			"chapterId": chapter.ID,
+			"number":    chapter.Number,
+			"pageCount": pageCount,
+		}, nil
+	})
+
+	c.JSON(http.StatusAccepted, job)
+}
+
+// newStagingDir creates the directory a new chapter's pages are gathered in.
+// It is hidden, so chapter scans skip it, and moved into place with
+// finishStagedChapter once complete.
+func newStagingDir(mangaPath, chapterID string) (string, error) {
+	stagingPath, err := os.MkdirTemp(mangaPath, ".fetch-"+chapterID+"-")
+	if err != nil {
+		return "", err
+	}
+	if err := os.Chmod(stagingPath, 0755); err != nil {
+		os.RemoveAll(stagingPath)
+		return "", err
+	}
+	return stagingPath, nil
+}
+
+// finishStagedChapter fills in and classifies the chapter from a staged
+// ComicInfo.xml, applies the library's import policy to the staged pages,
+// writes the chapter metadata into the staging directory and moves it into
+// place
+func finishStagedChapter(chapter *models.Chapter, stagingPath string, pageCount int) error {
+	chapter.ReleaseDate = timeNow()
+	chapter.PageCount = pageCount
+	names := []string{chapter.Title}
+	if info, err := importers.TakeComicInfo(stagingPath); err != nil {
+		zapLogger.Warn("Ignoring unreadable ComicInfo.xml", zap.String("chapterID", chapter.ID), zap.Error(err))
+	} else if info != nil {
+		applyComicInfo(chapter, info)
+		names = append(names, info.Title, info.Format)
+	}
+	chapter.Classify(names...)
+	downscaled, err := applyImportPolicy(stagingPath, importPolicyFor(filepath.Dir(stagingPath)))
+	if err != nil {
+		return fmt.Errorf("downscaling pages: %w", err)
+	}
+	hashes, err := models.HashPages(stagingPath)
+	if err != nil {
+		return fmt.Errorf("hashing pages: %w", err)
+	}
+	chapter.PageHashes = hashes
+	staged := *chapter
+	staged.Path = stagingPath
+	if pages, err := staged.GetPages(); err == nil {
+		chapter.ReadingScreens = models.MeasureReadingScreens(pages)
+	}
+	if err := chapter.SaveToJSON(filepath.Join(stagingPath, models.MetadataFileName)); err != nil {
+		return fmt.Errorf("saving chapter metadata: %w", err)
+	}
+	if _, err := os.Stat(chapter.Path); err == nil {
+		return fmt.Errorf("chapter directory %s was created while downloading", filepath.Base(chapter.Path))
+	}
+	if err := os.Rename(stagingPath, chapter.Path); err != nil {
+		return fmt.Errorf("moving chapter into place: %w", err)
+	}
+	dedupChapter(chapter.Path)
+	zapLogger.Info("Chapter imported",
+		zap.String("mangaID", chapter.MangaID),
+		zap.String("chapterID", chapter.ID),
+		zap.Int("pageCount", pageCount),
+		zap.Int("downscaled", downscaled),
+	)
+	return nil
+}
+
+// applyComicInfo fills the chapter fields the request left empty from the
+// archive's ComicInfo.xml
+func applyComicInfo(chapter *models.Chapter, info *importers.ComicInfo) {
+	if chapter.Title == "" {
+		chapter.Title = info.Title
+	}
+	if chapter.Summary == "" {
+		chapter.Summary = info.Summary
+	}
+	if source := info.SourceURL(); chapter.SourceURL == "" && importers.ValidateURL(source) == nil {
+		chapter.SourceURL = source
+	}
+}
@@ -0,0 +1,118 @@
+package routes
+
+import (
+	"mangahub/backend/reviews"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+const (
+	defaultReviewPageSize = 20
+	maxReviewPageSize     = 100
+)
+
+// listReviews returns a paginated list of reviews for a series
+func listReviews(c *gin.Context) {
+	mangaID := c.Param("id")
+	zapLogger.Info("listReviews handler called", zap.String("mangaID", mangaID))
+
+	if _, ok := lookupManga(c, mangaID); !ok {
+		return
+	}
+
+	page, limit, ok := parsePagination(c, defaultReviewPageSize, maxReviewPageSize)
+	if !ok {
+		return
+	}
+
+	list, total := reviewStore.ListByManga(mangaID, (page-1)*limit, limit)
+	summary := reviewStore.SummaryFor(mangaID)
+
+	c.JSON(http.StatusOK, gin.H{
+		"reviews":     list,
+		"page":        page,
+		"limit":       limit,
+		"total":       total,
+		"rating":      summary.Average,
+		"ratingCount": summary.Count,
+	})
+}
+
+// submitReview creates or replaces the current user's rating and review
+func submitReview(c *gin.Context) {
+	mangaID := c.Param("id")
+	zapLogger.Info("submitReview handler called", zap.String("mangaID", mangaID))
+
+	var request struct {
+		Rating int    `json:"rating" binding:"required"`
+		Body   string `json:"body"`
+	}
+	if err := c.ShouldBindJSON(&request); err != nil {
+		zapLogger.Warn("Invalid request data", zap.Error(err))
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request: " + err.Error()})
+		return
+	}
+	if request.Rating < reviews.MinRating || request.Rating > reviews.MaxRating {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Rating must be between 1 and 10"})
+		return
+	}
+
+	if _, ok := lookupManga(c, mangaID); !ok {
+		return
+	}
+
+	user := currentUser(c)
+	review, err := reviewStore.Upsert(mangaID, user.ID, user.Username, request.Rating, request.Body)
+	if err != nil {
+		zapLogger.Error("Failed to save review", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save review: " + err.Error()})
+		return
+	}
+
+	zapLogger.Info("Review saved", zap.String("mangaID", mangaID), zap.String("userID", user.ID))
+	c.JSON(http.StatusOK, review)
+}
+
+// deleteReview removes the current user's review of a series
+func deleteReview(c *gin.Context) {
+	mangaID := c.Param("id")
+	user := currentUser(c)
+	zapLogger.Info("deleteReview handler called", zap.String("mangaID", mangaID), zap.String("userID", user.ID))
+
+	deleted, err := reviewStore.Delete(mangaID, user.ID)
+	if err != nil {
+		zapLogger.Error("Failed to delete review", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete review: " + err.Error()})
+		return
+	}
+	if !deleted {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Review not found"})
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// parsePagination reads ?page= (1-based) and ?limit= query parameters
+func parsePagination(c *gin.Context, defaultLimit, maxLimit int) (int, int, bool) {
+	page, limit := 1, defaultLimit
+	if raw := c.Query("page"); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil || n < 1 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid page"})
+			return 0, 0, false
+		}
+		page = n
+	}
+	if raw := c.Query("limit"); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil || n < 1 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid limit"})
+			return 0, 0, false
+		}
+		limit = min(n, maxLimit)
+	}
+	return page, limit, true
+}
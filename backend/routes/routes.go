@@ -1,11 +1,32 @@
 package routes
 
 import (
+	"fmt"
+	"mangahub/backend/collections"
+	"mangahub/backend/dedup"
+	"mangahub/backend/downloads"
+	"mangahub/backend/events"
+	"mangahub/backend/firstseen"
+	"mangahub/backend/hooks"
+	"mangahub/backend/imaging"
+	"mangahub/backend/importers"
 	"mangahub/backend/models"
+	"mangahub/backend/progress"
+	"mangahub/backend/readingorders"
+	"mangahub/backend/redirects"
+	"mangahub/backend/reviews"
+	"mangahub/backend/scheduler"
+	"mangahub/backend/search"
+	"mangahub/backend/sharetokens"
+	"mangahub/backend/shelves"
+	"mangahub/backend/shortlinks"
+	"mangahub/backend/slug"
+	"mangahub/backend/tags"
+	"mangahub/backend/tombstones"
+	"mangahub/backend/users"
 	"net/http"
 	"os"
 	"path/filepath"
-	"regexp"
 	"strconv"
 	"strings"
 	"time"
@@ -15,41 +36,338 @@ import (
 )
 
 var (
-	metadataManager *models.MetadataManager
-	zapLogger       *zap.Logger
+	metadataManager   *models.MetadataManager
+	libraryIndex      *models.LibraryIndex
+	userStore         *users.Store
+	reviewStore       *reviews.Store
+	tagStore          *tags.Store
+	progressStore     *progress.Store
+	activityStore     *progress.Activity
+	collectionStore   *collections.Store
+	shelfStore        *shelves.Store
+	readingOrderStore *readingorders.Store
+	shortLinkStore    *shortlinks.Store
+	shareTokenStore   *sharetokens.Store
+	redirectStore     *redirects.Store
+	tombstoneStore    *tombstones.Store
+	firstSeenStore    *firstseen.Store
+	downloadStore     *downloads.Store
+	zapLogger         = zap.NewNop()
 )
 
-// init sets up zap logger for this package
-func init() {
-	l, _ := zap.NewDevelopment()
+// libraryWarmingHeader is set on listing responses while the startup index
+// warm-up is still running and the results may be incomplete
+const libraryWarmingHeader = "X-Library-Warming"
+
+// indexSyncInterval is how often the library index is reconciled with the
+// disk in the background, so listing requests never walk the tree themselves
+const indexSyncInterval = 30 * time.Second
+
+// SetLogger sets the logger used by the route handlers
+func SetLogger(l *zap.Logger) {
 	zapLogger = l
 }
 
-// InitRoutes initializes the routes with the given manga root directory
-func InitRoutes(mangaRootDir string) {
-	zapLogger.Info("InitRoutes called", zap.String("mangaRootDir", mangaRootDir))
+// InitRoutes initializes the routes with the given manga root directory, the
+// file the library index is persisted to between restarts, the directory
+// generated images are cached in and the directory user data is stored in
+func InitRoutes(mangaRootDir, indexFile, cacheDir, dataDir string) {
+	zapLogger.Info("InitRoutes called",
+		zap.String("mangaRootDir", mangaRootDir),
+		zap.String("indexFile", indexFile),
+		zap.String("cacheDir", cacheDir),
+		zap.String("dataDir", dataDir),
+	)
 	metadataManager = models.NewMetadataManager(mangaRootDir)
+	applyScanPolicies()
+	imageCache = imaging.NewCache(cacheDir)
+	imageCache.SetMaxBytes(imageCacheMaxBytes)
+
+	var err error
+	if userStore, err = users.NewStore(dataDir); err != nil {
+		zapLogger.Fatal("Failed to load user store", zap.String("dataDir", dataDir), zap.Error(err))
+	}
+	userStore.SetInviteOnly(inviteOnly)
+	if reviewStore, err = reviews.NewStore(dataDir); err != nil {
+		zapLogger.Fatal("Failed to load review store", zap.String("dataDir", dataDir), zap.Error(err))
+	}
+	if tagStore, err = tags.NewStore(dataDir); err != nil {
+		zapLogger.Fatal("Failed to load tag aliases", zap.String("dataDir", dataDir), zap.Error(err))
+	}
+	if progressStore, err = progress.NewStore(dataDir); err != nil {
+		zapLogger.Fatal("Failed to load reading progress", zap.String("dataDir", dataDir), zap.Error(err))
+	}
+	if activityStore, err = progress.NewActivity(dataDir); err != nil {
+		zapLogger.Fatal("Failed to load reading activity", zap.String("dataDir", dataDir), zap.Error(err))
+	}
+	if collectionStore, err = collections.NewStore(dataDir); err != nil {
+		zapLogger.Fatal("Failed to load collections", zap.String("dataDir", dataDir), zap.Error(err))
+	}
+	if shelfStore, err = shelves.NewStore(dataDir); err != nil {
+		zapLogger.Fatal("Failed to load shelves", zap.String("dataDir", dataDir), zap.Error(err))
+	}
+	if readingOrderStore, err = readingorders.NewStore(dataDir); err != nil {
+		zapLogger.Fatal("Failed to load reading orders", zap.String("dataDir", dataDir), zap.Error(err))
+	}
+	if shortLinkStore, err = shortlinks.NewStore(dataDir); err != nil {
+		zapLogger.Fatal("Failed to load short links", zap.String("dataDir", dataDir), zap.Error(err))
+	}
+	if shareTokenStore, err = sharetokens.NewStore(dataDir); err != nil {
+		zapLogger.Fatal("Failed to load share tokens", zap.String("dataDir", dataDir), zap.Error(err))
+	}
+	if redirectStore, err = redirects.NewStore(dataDir); err != nil {
+		zapLogger.Fatal("Failed to load series redirects", zap.String("dataDir", dataDir), zap.Error(err))
+	}
+	if tombstoneStore, err = tombstones.NewStore(dataDir); err != nil {
+		zapLogger.Fatal("Failed to load tombstones", zap.String("dataDir", dataDir), zap.Error(err))
+	}
+	if firstSeenStore, err = firstseen.NewStore(dataDir); err != nil {
+		zapLogger.Fatal("Failed to load first-seen times", zap.String("dataDir", dataDir), zap.Error(err))
+	}
+	if downloadStore, err = downloads.NewStore(dataDir); err != nil {
+		zapLogger.Fatal("Failed to load download counts", zap.String("dataDir", dataDir), zap.Error(err))
+	}
+	if publishScheduler, err = scheduler.New(dataDir); err != nil {
+		zapLogger.Fatal("Failed to load publish schedule", zap.String("dataDir", dataDir), zap.Error(err))
+	}
+	if dedupEnabled {
+		if pageStore, err = dedup.NewStore(mangaRootDir); err != nil {
+			zapLogger.Fatal("Failed to open page store", zap.String("mangaRootDir", mangaRootDir), zap.Error(err))
+		}
+	}
+	eventBus = events.NewBus()
+	eventBus.Subscribe("*", hooks.Handle)
+	libraryIndex = models.NewLibraryIndex(metadataManager, indexFile)
+	libraryIndex.SetWorkers(scanWorkers)
+	libraryIndex.OnRemove(recordRemovedSeries)
+
+	go publishScheduler.Run(scheduler.DefaultInterval, nil, publishScheduledChapter)
+
+	// Build the index in the background so startup isn't blocked on a full scan
+	go libraryIndex.Warm()
+
+	startIndexSync()
+	startInbox()
+	startCountChecks()
+	startLibraryScans()
+	startMetadataRefreshes()
+	startPregeneration()
+}
+
+// startIndexSync picks up series added, changed or removed on disk outside
+// the API by syncing the library index every indexSyncInterval once it is
+// ready
+func startIndexSync() {
+	go func() {
+		for !libraryIndex.Ready() {
+			time.Sleep(10 * time.Second)
+		}
+		for {
+			time.Sleep(indexSyncInterval)
+			if err := libraryIndex.Sync(); err != nil {
+				zapLogger.Error("Library index sync failed", zap.Error(err))
+			}
+		}
+	}()
+}
+
+// indexedManga returns the series known to the library index, which is kept
+// in sync with the disk by startIndexSync. While the index is still warming
+// up the partial result is returned and flagged via a header.
+func indexedManga(c *gin.Context) ([]models.MangaSeries, error) {
+	if !libraryIndex.Ready() {
+		c.Header(libraryWarmingHeader, "true")
+	}
+	return visibleSeries(c, libraryIndex.List()), nil
 }
 
 // SetupRoutes configures all the API routes for the manga reader
 func SetupRoutes(router *gin.Engine) {
+	router.NoMethod(methodNotAllowed(router))
+	router.GET("/sitemap.xml", getSitemap)
+	router.GET("/s/:token", followShortLink)
+
+	kobo := router.Group("/kobo/:token", AccessGate(AccessGroupKobo), requireKobo, koboAuth)
+	{
+		kobo.GET("/v1/initialization", koboInitialization)
+		kobo.POST("/v1/auth/device", koboAuthDevice)
+		kobo.POST("/v1/auth/refresh", koboAuthDevice)
+		kobo.GET("/v1/library/sync", koboSync)
+		kobo.GET("/v1/library/tags", koboEmptyList)
+		kobo.GET("/v1/library/:bookID/metadata", koboBookMetadata)
+		kobo.GET("/v1/library/:bookID/state", koboGetState)
+		kobo.PUT("/v1/library/:bookID/state", koboPutState)
+		kobo.GET("/v1/library/:bookID/download", koboDownload)
+		kobo.DELETE("/v1/library/:bookID", koboArchive)
+		kobo.GET("/v1/user/profile", koboEmpty)
+		kobo.POST("/v1/analytics/gettests", koboAnalytics)
+		kobo.POST("/v1/analytics/event", koboAnalytics)
+		kobo.GET("/:imageID/:width/:height/:quality/:greyscale/image.jpg", koboCover)
+	}
+
 	api := router.Group("/api")
+	api.Use(localize, AccessGate(AccessGroupAPI), authenticate, enforceTokenScopes, guestGate)
 	{
 		api.GET("/manga", listManga)
 		api.GET("/manga/:id", getManga)
+		api.POST("/manga/batch", batchGetManga)
 		api.GET("/manga/:id/chapters", listChapters)
+		api.GET("/manga/:id/fields", getCustomFields)
+		api.GET("/manga/:id/covers", listCovers)
+		api.GET("/manga/:id/card.png", getShareCard)
+		api.GET("/manga/:id/presence", requirePresence, watchPresence)
 
 		api.GET("/manga/:id/chapter/:chapterNumber", getChapter)
 		api.GET("/manga/:id/chapter/:chapterNumber/page/:pageNumber", getPage)
+		api.GET("/manga/:id/chapter/:chapterNumber/page/:pageNumber/image", getPageImage)
+		api.GET("/manga/:id/chapter/:chapterNumber/thumbnail", getChapterThumbnail)
+		api.GET("/manga/:id/chapter/:chapterNumber/manifest", getChapterManifest)
+		api.GET("/manga/:id/chapter/:chapterNumber/bundle", getChapterBundle)
+		api.GET("/manga/:id/chapter/:chapterNumber/page/:pageNumber/overlays", getPageOverlays)
 
 		api.GET("/search", searchManga)
+		api.GET("/changes", getChanges)
+		api.GET("/sync", syncLibrary)
+		api.GET("/downloads", getDownloadUsage)
+		api.GET("/tags", listTags)
+		api.GET("/stats", getStats)
+		api.GET("/collections", listCollections)
+		api.GET("/collections/:id", getCollection)
+		api.GET("/shelves", listShelves)
+		api.GET("/shelves/:id", getShelf)
+		api.GET("/reading-orders", listReadingOrders)
+		api.GET("/reading-orders/:id", getReadingOrder)
+		api.POST("/share", createShortLink)
+		api.GET("/share/:token", getShortLink)
+
+		api.GET("/manga/:id/reviews", listReviews)
+		api.POST("/manga/:id/reviews", requireUser, submitReview)
+		api.DELETE("/manga/:id/reviews", requireUser, deleteReview)
+
+		auth := api.Group("/auth")
+		{
+			auth.POST("/register", register)
+			auth.POST("/login", login)
+			auth.POST("/logout", logout)
+			auth.GET("/me", requireUser, getCurrentUser)
+			auth.GET("/csrf", getCSRFToken)
+			auth.GET("/invites/:code", checkInvite)
+			auth.POST("/password-reset", requireAccountMail, requestPasswordReset)
+			auth.POST("/password-reset/confirm", resetPassword)
+			auth.POST("/verify-email", requireUser, requireAccountMail, resendVerification)
+			auth.POST("/verify-email/confirm", confirmEmail)
+		}
 
-		admin := api.Group("/admin")
+		user := api.Group("/user", requireUser)
+		{
+			user.GET("/export", exportUserData)
+			user.DELETE("/account", deleteAccount)
+			user.GET("/preferences", getPreferences)
+			user.PUT("/preferences", updatePreferences)
+			user.GET("/sync", syncReadingPosition)
+			user.GET("/position", getReadingPosition)
+			user.GET("/progress", listProgress)
+			user.POST("/progress/import", importTrackerProgress)
+			user.GET("/progress/:id", getProgress)
+			user.PUT("/progress/:id", updateProgress)
+			user.DELETE("/progress/:id", deleteProgress)
+			user.GET("/stats", getReadingStats)
+			user.GET("/sessions", listSessions)
+			user.DELETE("/sessions", revokeSessions)
+			user.DELETE("/sessions/:id", revokeSession)
+			user.GET("/tokens", listAPITokens)
+			user.POST("/tokens", createAPIToken)
+			user.DELETE("/tokens/:id", revokeAPIToken)
+
+			library := user.Group("/library", requirePersonalLibraries)
+			library.GET("", getLibrary)
+			library.POST("", createLibrarySeries)
+			library.PUT("/:id", updateLibrarySeries)
+			library.DELETE("/:id", deleteLibrarySeries)
+			library.POST("/:id/chapters", uploadLibraryChapter)
+			library.DELETE("/:id/chapters/:chapterNumber", deleteLibraryChapter)
+		}
+
+		admin := api.Group("/admin", AccessGate(AccessGroupAdmin), requireAdmin, requireCSRFToken)
 		{
 			admin.POST("/manga", addManga)
 			admin.PUT("/manga/:id", updateManga)
 			admin.POST("/manga/:id/chapter", addChapter)
+			admin.POST("/manga/:id/chapter/fetch", fetchRemoteChapter)
+			admin.POST("/manga/:id/verify", verifyManga)
+			admin.POST("/manga/:id/merge", mergeManga)
 			admin.PUT("/manga/:id/chapter/:chapterNumber", updateChapter)
+			admin.POST("/manga/:id/chapter/:chapterNumber/move", moveChapterToSeries)
+			admin.PUT("/manga/:id/chapter/:chapterNumber/page/:pageNumber/overlays", updatePageOverlays)
+
+			admin.POST("/manga/:id/alt-titles", addAltTitles)
+			admin.DELETE("/manga/:id/alt-titles/:title", removeAltTitle)
+
+			admin.POST("/manga/:id/covers", uploadCover)
+			admin.GET("/manga/:id/downloads", getMangaDownloads)
+			admin.PUT("/manga/:id/cover", selectCover)
+			admin.POST("/manga/:id/cover/fetch", fetchCover)
+			admin.DELETE("/manga/:id/covers/:file", deleteCover)
+
+			admin.PATCH("/manga/:id/fields", updateCustomFields)
+			admin.PUT("/manga/:id/fields/:key", setCustomField)
+			admin.DELETE("/manga/:id/fields/:key", deleteCustomField)
+
+			admin.POST("/manga/:id/tags", addMangaTags)
+			admin.DELETE("/manga/:id/tags/:tag", removeMangaTag)
+			admin.PUT("/tags/:tag", renameTag)
+			admin.DELETE("/tags/:tag", deleteTag)
+			admin.GET("/tags/aliases", listTagAliases)
+			admin.PUT("/tags/aliases/:alias", setTagAlias)
+			admin.DELETE("/tags/aliases/:alias", deleteTagAlias)
+			admin.PUT("/genres/:genre", renameGenre)
+
+			admin.POST("/collections", createCollection)
+			admin.PUT("/collections/:id", updateCollection)
+			admin.DELETE("/collections/:id", deleteCollection)
+			admin.POST("/shelves", createShelf)
+			admin.PUT("/shelves/:id", updateShelf)
+			admin.DELETE("/shelves/:id", deleteShelf)
+			admin.POST("/reading-orders", createReadingOrder)
+			admin.PUT("/reading-orders/:id", updateReadingOrder)
+			admin.DELETE("/reading-orders/:id", deleteReadingOrder)
+
+			admin.POST("/import/:source", importLibrary)
+
+			admin.GET("/jobs", listJobs)
+			admin.GET("/jobs/:id", getJob)
+			admin.DELETE("/jobs/:id", cancelJob)
+
+			admin.POST("/dedup", dedupLibrary)
+			admin.POST("/counts/check", checkCounts)
+			admin.POST("/metadata/persist", persistMetadata)
+			admin.POST("/metadata/refresh", refreshMetadata)
+			admin.GET("/debug/capture", getDebugCapture)
+			admin.PUT("/debug/capture", updateDebugCapture)
+			admin.DELETE("/debug/capture", clearDebugCapture)
+			admin.GET("/pregenerate", getPregeneration)
+			admin.POST("/pregenerate", pregenerateLibrary)
+			admin.GET("/image-cache", getImageCache)
+			admin.DELETE("/image-cache", flushImageCache)
+
+			admin.GET("/libraries", listLibraries)
+			admin.PUT("/libraries", updateLibraryPolicy)
+			admin.POST("/libraries/scan", scanLibrary)
+
+			admin.PUT("/users/:id/quota", setUserQuota)
+			admin.PUT("/users/:id/download-limit", setUserDownloadLimit)
+			admin.GET("/share-tokens", listShareTokens)
+			admin.POST("/share-tokens", createShareToken)
+			admin.DELETE("/share-tokens/:token", revokeShareToken)
+			admin.POST("/watermark/read", readWatermark)
+			admin.GET("/invites", listInvites)
+			admin.POST("/invites", createInvite)
+			admin.DELETE("/invites/:code", revokeInvite)
+
+			admin.GET("/sources", listSources)
+			admin.GET("/sources/:source/search", searchSource)
+			admin.GET("/sources/:source/chapters", listSourceChapters)
+			admin.POST("/sources/:source/download", downloadSourceChapter)
 		}
 	}
 }
@@ -58,29 +376,23 @@ func SetupRoutes(router *gin.Engine) {
 func listManga(c *gin.Context) {
 	zapLogger.Info("listManga handler called")
 
-	mangas, err := metadataManager.ScanForManga()
+	mangas, err := indexedManga(c)
 	if err != nil {
 		zapLogger.Error("Failed to retrieve manga list", zap.Error(err))
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve manga list: " + err.Error()})
 		return
 	}
 
-	var response []gin.H
-	for _, manga := range mangas {
-		response = append(response, gin.H{
-			"id":           manga.ID,
-			"title":        manga.Title,
-			"description":  manga.Description,
-			"coverImage":   manga.GetCoverImageURL(),
-			"genres":       manga.Genres,
-			"author":       manga.Author,
-			"status":       manga.Status,
-			"chapterCount": manga.ChapterCount,
-		})
-	}
+	ratings := reviewStore.Summaries()
+	fields := sparseFields(c)
 
-	zapLogger.Info("listManga returning data", zap.Int("mangaCount", len(response)))
-	c.JSON(http.StatusOK, response)
+	// Large libraries are streamed rather than built up as one response
+	zapLogger.Info("listManga returning data", zap.Int("mangaCount", len(mangas)))
+	streamJSONArray(c, len(mangas), func(i int) gin.H {
+		summary := mangaSummary(&mangas[i], ratings[mangas[i].ID])
+		trimFields(summary, fields)
+		return summary
+	})
 }
 
 // getManga returns details about a specific manga
@@ -88,51 +400,83 @@ func getManga(c *gin.Context) {
 	id := c.Param("id")
 	zapLogger.Info("getManga handler called", zap.String("mangaID", id))
 
-	manga, err := metadataManager.GetMangaByID(id)
-	if err != nil {
-		if models.IsMangaNotFoundError(err) {
-			zapLogger.Warn("Manga not found", zap.String("mangaID", id))
-			c.JSON(http.StatusNotFound, gin.H{"error": "Manga not found"})
-		} else {
-			zapLogger.Error("Failed to retrieve manga", zap.Error(err))
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve manga: " + err.Error()})
-		}
+	includes, ok := parseIncludes(c, "chapters")
+	if !ok {
 		return
 	}
 
+	manga, ok := lookupManga(c, id)
+	if !ok {
+		return
+	}
+
+	rating := reviewStore.SummaryFor(manga.ID)
 	response := gin.H{
 		"id":            manga.ID,
 		"title":         manga.Title,
 		"description":   manga.Description,
 		"coverImage":    manga.GetCoverImageURL(),
 		"genres":        manga.Genres,
+		"tags":          tagStore.CanonicalList(manga.Tags),
 		"author":        manga.Author,
 		"artist":        manga.Artist,
 		"status":        manga.Status,
 		"publishedYear": manga.PublishedYear,
-		"lastUpdated":   manga.LastUpdated,
+		"lastUpdated":   timestamp(manga.LastUpdated),
 		"chapterCount":  manga.ChapterCount,
 		"altTitles":     manga.AltTitles,
+		"publisher":     manga.Publisher,
+		"demographic":   manga.Demographic,
+		"serialization": manga.Serialization,
+		"autoCrop":      manga.AutoCrop,
+		"nsfw":          manga.NSFW,
+		"customFields":  customFieldsOf(manga),
+		"rating":        rating.Average,
+		"ratingCount":   rating.Count,
+	}
+	if presenceEnabled {
+		response["readers"] = readerPresence.Readers(manga.ID)
+	}
+
+	if includes["chapters"] {
+		chapters, err := metadataManager.ScanForChapters(manga)
+		if err != nil {
+			zapLogger.Error("Failed to retrieve chapters", zap.Error(err))
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve chapters: " + err.Error()})
+			return
+		}
+		chapters = visibleChapters(c, chapters)
+
+		chapterList := make([]gin.H, 0, len(chapters))
+		for i := range chapters {
+			chapterList = append(chapterList, chapterSummary(manga, &chapters[i]))
+		}
+		response["chapters"] = chapterList
 	}
 
 	zapLogger.Info("getManga returning data", zap.String("mangaID", manga.ID))
 	c.JSON(http.StatusOK, response)
 }
 
-// listChapters returns a list of chapters for a specific manga
+// listChapters returns the chapters of a manga. ?from= and ?to= restrict the
+// chapter-number range; ?limit= and ?cursor= page through it in windows, with
+// the next cursor returned in the X-Next-Cursor header. ?include=version adds
+// each chapter's content version, which opens every chapter folder.
 func listChapters(c *gin.Context) {
 	mangaID := c.Param("id")
 	zapLogger.Info("listChapters handler called", zap.String("mangaID", mangaID))
 
-	manga, err := metadataManager.GetMangaByID(mangaID)
-	if err != nil {
-		if models.IsMangaNotFoundError(err) {
-			zapLogger.Warn("Manga not found", zap.String("mangaID", mangaID))
-			c.JSON(http.StatusNotFound, gin.H{"error": "Manga not found"})
-		} else {
-			zapLogger.Error("Failed to retrieve manga", zap.Error(err))
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve manga: " + err.Error()})
-		}
+	window, ok := parseChapterWindow(c)
+	if !ok {
+		return
+	}
+	includes, ok := parseIncludes(c, "firstPage", "version")
+	if !ok {
+		return
+	}
+
+	manga, ok := lookupManga(c, mangaID)
+	if !ok {
 		return
 	}
 
@@ -142,26 +486,37 @@ func listChapters(c *gin.Context) {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve chapters: " + err.Error()})
 		return
 	}
+	chapters = visibleChapters(c, chapters)
 
-	var response []gin.H
-	for _, chapter := range chapters {
-		response = append(response, gin.H{
-			"id":          chapter.ID,
-			"mangaId":     chapter.MangaID,
-			"number":      chapter.Number,
-			"title":       chapter.Title,
-			"releaseDate": chapter.ReleaseDate,
-			"pageCount":   chapter.PageCount,
-			"volume":      chapter.Volume,
-			"special":     chapter.Special,
-		})
+	chapters, next := window.apply(chapters)
+	if next != nil {
+		c.Header(nextCursorHeader, next.encode())
+	}
+
+	response := make([]gin.H, 0, len(chapters))
+	for i := range chapters {
+		summary := chapterSummary(manga, &chapters[i])
+		if includes["firstPage"] {
+			summary["firstPage"] = firstPageOf(&chapters[i])
+			summary["pageCount"] = chapters[i].PageCount
+		}
+		if includes["version"] {
+			summary["version"] = nil
+			if version, err := chapterVersion(&chapters[i]); err == nil {
+				summary["version"] = version
+			} else {
+				zapLogger.Warn("Failed to read page files", zap.String("chapterID", chapters[i].ID), zap.Error(err))
+			}
+		}
+		response = append(response, summary)
 	}
 
 	zapLogger.Info("listChapters returning data", zap.Int("chapterCount", len(response)))
-	c.JSON(http.StatusOK, response)
+	c.JSON(http.StatusOK, applySparseFields(c, response))
 }
 
-// getChapter returns details about a specific chapter
+// getChapter returns details about a specific chapter, with a content version
+// that changes whenever its page files do
 func getChapter(c *gin.Context) {
 	mangaID := c.Param("id")
 	chapterNumberStr := c.Param("chapterNumber")
@@ -177,15 +532,8 @@ func getChapter(c *gin.Context) {
 		return
 	}
 
-	manga, err := metadataManager.GetMangaByID(mangaID)
-	if err != nil {
-		if models.IsMangaNotFoundError(err) {
-			zapLogger.Warn("Manga not found", zap.String("mangaID", mangaID))
-			c.JSON(http.StatusNotFound, gin.H{"error": "Manga not found"})
-		} else {
-			zapLogger.Error("Failed to retrieve manga", zap.Error(err))
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve manga: " + err.Error()})
-		}
+	manga, ok := lookupManga(c, mangaID)
+	if !ok {
 		return
 	}
 
@@ -195,11 +543,14 @@ func getChapter(c *gin.Context) {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve chapters: " + err.Error()})
 		return
 	}
+	chapters = visibleChapters(c, chapters)
 
 	var targetChapter *models.Chapter
+	var chapterIndex int
 	for i := range chapters {
 		if chapters[i].Number == chapterNumber {
 			targetChapter = &chapters[i]
+			chapterIndex = i
 			break
 		}
 	}
@@ -213,7 +564,7 @@ func getChapter(c *gin.Context) {
 		return
 	}
 
-	pages, err := targetChapter.GetPages()
+	pages, err := metadataManager.LoadPages(targetChapter)
 	if err != nil {
 		zapLogger.Error("Failed to retrieve pages", zap.Error(err))
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve pages: " + err.Error()})
@@ -225,21 +576,61 @@ func getChapter(c *gin.Context) {
 		"mangaId":     targetChapter.MangaID,
 		"number":      targetChapter.Number,
 		"title":       targetChapter.Title,
-		"releaseDate": targetChapter.ReleaseDate,
+		"releaseDate": timestamp(targetChapter.ReleaseDate),
 		"pageCount":   targetChapter.PageCount,
 		"volume":      targetChapter.Volume,
 		"special":     targetChapter.Special,
+		"kind":        targetChapter.Kind,
+		"summary":     targetChapter.Summary,
+		"sourceUrl":   targetChapter.SourceURL,
+		"scanlators":  targetChapter.Scanlators,
 		"pages":       []gin.H{},
 	}
+	if presenceEnabled {
+		response["readers"] = readerPresence.ChapterReaders(manga.ID, targetChapter.Number)
+	}
+	if screens, err := metadataManager.ReadingScreens(targetChapter); err == nil {
+		response["readingMinutes"] = readingMinutes(screens)
+	}
+	if infos, err := statPages(pages); err == nil {
+		response["version"] = contentVersion(targetChapter, infos)
+	} else {
+		zapLogger.Warn("Failed to read page files", zap.String("chapterID", targetChapter.ID), zap.Error(err))
+	}
 
 	var pagesList []gin.H
 	for _, page := range pages {
+		if err := page.LoadImageMetadata(); err != nil {
+			zapLogger.Warn("Failed to read page dimensions",
+				zap.String("imagePath", page.ImagePath),
+				zap.Error(err),
+			)
+		}
+		variants, srcset := pageVariants(&page, targetChapter.Number)
 		pagesList = append(pagesList, gin.H{
 			"number":   page.Number,
 			"imageUrl": page.GetImageURL(),
+			"width":    page.Width,
+			"height":   page.Height,
+			"variants": variants,
+			"srcset":   srcset,
 		})
 	}
 	response["pages"] = pagesList
+	prefetch := prefetchURLs(pages, -1)
+	response["prefetch"] = prefetch
+	setPrefetchLinks(c, prefetch)
+
+	prevChapter, nextChapter := models.AdjacentChapters(chapters, chapterIndex)
+	if nextChapter != nil {
+		response["nextChapterNumber"] = nextChapter.Number
+		response["nextChapterId"] = nextChapter.ID
+	}
+	if prevChapter != nil {
+		response["prevChapterNumber"] = prevChapter.Number
+		response["prevChapterId"] = prevChapter.ID
+	}
+	response["readingOrders"] = readingOrderHints(c, manga.ID, targetChapter.Number)
 
 	zapLogger.Info("getChapter returning data", zap.String("chapterID", targetChapter.ID))
 	c.JSON(http.StatusOK, response)
@@ -270,15 +661,8 @@ func getPage(c *gin.Context) {
 		return
 	}
 
-	manga, err := metadataManager.GetMangaByID(mangaID)
-	if err != nil {
-		if models.IsMangaNotFoundError(err) {
-			zapLogger.Warn("Manga not found", zap.String("mangaID", mangaID))
-			c.JSON(http.StatusNotFound, gin.H{"error": "Manga not found"})
-		} else {
-			zapLogger.Error("Failed to retrieve manga", zap.Error(err))
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve manga: " + err.Error()})
-		}
+	manga, ok := lookupManga(c, mangaID)
+	if !ok {
 		return
 	}
 
@@ -288,6 +672,7 @@ func getPage(c *gin.Context) {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve chapters: " + err.Error()})
 		return
 	}
+	chapters = visibleChapters(c, chapters)
 
 	var targetChapter *models.Chapter
 	var chapterIndex int
@@ -308,7 +693,7 @@ func getPage(c *gin.Context) {
 		return
 	}
 
-	pages, err := targetChapter.GetPages()
+	pages, err := metadataManager.LoadPages(targetChapter)
 	if err != nil {
 		zapLogger.Error("Failed to retrieve pages", zap.Error(err))
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve pages: " + err.Error()})
@@ -316,9 +701,11 @@ func getPage(c *gin.Context) {
 	}
 
 	var targetPage *models.Page
+	var pageIndex int
 	for i := range pages {
 		if pages[i].Number == pageNumber {
 			targetPage = &pages[i]
+			pageIndex = i
 			break
 		}
 	}
@@ -333,29 +720,52 @@ func getPage(c *gin.Context) {
 		return
 	}
 
-	var nextChapter, prevChapter string
-	if pageNumber >= len(pages) && chapterIndex < len(chapters)-1 {
-		nextChapter = strconv.FormatFloat(chapters[chapterIndex+1].Number, 'f', -1, 64)
+	// The previous chapter's pages are only needed to land on its last page
+	var prevChapterPages []models.Page
+	if prevChapter, _ := models.AdjacentChapters(chapters, chapterIndex); prevChapter != nil {
+		if prevPages, err := metadataManager.LoadPages(prevChapter); err == nil {
+			prevChapterPages = prevPages
+		} else {
+			zapLogger.Warn("Failed to load previous chapter pages",
+				zap.String("chapterID", prevChapter.ID),
+				zap.Error(err),
+			)
+		}
 	}
-	if pageNumber == 1 && chapterIndex > 0 {
-		prevChapter = strconv.FormatFloat(chapters[chapterIndex-1].Number, 'f', -1, 64)
+	navigation := models.NewNavigation(chapters, chapterIndex, pages, targetPage, prevChapterPages)
+
+	overlayCount := 0
+	if overlays, err := models.LoadOverlays(targetChapter.Path); err == nil {
+		overlayCount = len(overlays.Pages[targetPage.Number])
 	}
 
+	prefetch := prefetchURLs(pages, pageIndex)
+	setPrefetchLinks(c, prefetch)
+
 	response := gin.H{
-		"imageUrl":   targetPage.GetImageURL(),
-		"pageNumber": targetPage.Number,
-		"totalPages": len(pages),
-		"chapterID":  targetChapter.ID,
-		"mangaID":    mangaID,
-		"nextPage":   targetPage.GetNextPageNumber(),
-		"prevPage":   targetPage.GetPrevPageNumber(),
+		"imageUrl":     targetPage.GetImageURL(),
+		"pageNumber":   targetPage.Number,
+		"totalPages":   len(pages),
+		"chapterID":    targetChapter.ID,
+		"mangaID":      mangaID,
+		"nextPage":     navigation.NextPage,
+		"prevPage":     navigation.PrevPage,
+		"navigation":   navigation,
+		"overlayCount": overlayCount,
+		"prefetch":     prefetch,
 	}
 
-	if nextChapter != "" {
-		response["nextChapter"] = nextChapter
+	if sidecar, err := targetPage.LoadSidecar(); err != nil {
+		zapLogger.Warn("Failed to read page sidecar", zap.String("sidecarPath", targetPage.SidecarPath), zap.Error(err))
+	} else if sidecar != nil {
+		response["sidecar"] = sidecar
 	}
-	if prevChapter != "" {
-		response["prevChapter"] = prevChapter
+
+	if navigation.HasNextChapter {
+		response["nextChapter"] = navigation.NextChapter
+	}
+	if navigation.HasPrevChapter {
+		response["prevChapter"] = navigation.PrevChapter
 	}
 
 	zapLogger.Info("getPage returning data",
@@ -365,17 +775,39 @@ func getPage(c *gin.Context) {
 	c.JSON(http.StatusOK, response)
 }
 
-// searchManga handles searching for manga by title or filtering by genres
+// searchManga handles searching for manga by title, alternative title or
+// creator, and filtering by genre, tags, publisher and other metadata. The q=
+// parameter accepts the structured syntax described in package search.
 func searchManga(c *gin.Context) {
 	query := c.Query("q")
 	genre := c.Query("genre")
+	tagFilters := tagStore.CanonicalList(c.QueryArray("tag"))
+	publisher := c.Query("publisher")
+	demographic := c.Query("demographic")
+	serialization := c.Query("serialization")
+	author := c.Query("author")
+	artist := c.Query("artist")
 
 	zapLogger.Info("searchManga called",
 		zap.String("query", query),
 		zap.String("genre", genre),
+		zap.Strings("tags", tagFilters),
+		zap.String("publisher", publisher),
+		zap.String("demographic", demographic),
+		zap.String("serialization", serialization),
+		zap.String("author", author),
+		zap.String("artist", artist),
 	)
 
-	mangas, err := metadataManager.ScanForManga()
+	parsed, err := search.Parse(query)
+	if err != nil {
+		zapLogger.Warn("Invalid search query", zap.String("query", query), zap.Error(err))
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	parsed.MapValues("tag", tagStore.Canonical)
+
+	mangas, err := indexedManga(c)
 	if err != nil {
 		zapLogger.Error("Failed to retrieve manga list", zap.Error(err))
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve manga list: " + err.Error()})
@@ -384,19 +816,8 @@ func searchManga(c *gin.Context) {
 
 	var results []models.MangaSeries
 	for _, manga := range mangas {
-		if query != "" {
-			if !containsIgnoreCase(manga.Title, query) && !containsIgnoreCase(manga.Description, query) {
-				foundAlt := false
-				for _, altTitle := range manga.AltTitles {
-					if containsIgnoreCase(altTitle, query) {
-						foundAlt = true
-						break
-					}
-				}
-				if !foundAlt {
-					continue
-				}
-			}
+		if !parsed.Matches(&manga, tagStore.CanonicalList(manga.Tags)) {
+			continue
 		}
 		if genre != "" {
 			foundGenre := false
@@ -410,6 +831,24 @@ func searchManga(c *gin.Context) {
 				continue
 			}
 		}
+		if len(tagFilters) > 0 && !hasAllTags(tagStore.CanonicalList(manga.Tags), tagFilters) {
+			continue
+		}
+		if publisher != "" && !equalIgnoreCase(manga.Publisher, publisher) {
+			continue
+		}
+		if demographic != "" && !equalIgnoreCase(manga.Demographic, demographic) {
+			continue
+		}
+		if serialization != "" && !containsIgnoreCase(manga.Serialization, serialization) {
+			continue
+		}
+		if author != "" && !containsIgnoreCase(manga.Author, author) {
+			continue
+		}
+		if artist != "" && !containsIgnoreCase(manga.Artist, artist) {
+			continue
+		}
 		results = append(results, manga)
 	}
 
@@ -421,24 +860,36 @@ func searchManga(c *gin.Context) {
 			"description": manga.Description,
 			"coverImage":  manga.GetCoverImageURL(),
 			"genres":      manga.Genres,
+			"tags":        tagStore.CanonicalList(manga.Tags),
 			"author":      manga.Author,
+			"artist":      manga.Artist,
+			"publisher":   manga.Publisher,
+			"demographic": manga.Demographic,
 		})
 	}
 
 	zapLogger.Info("searchManga returning results", zap.Int("resultsCount", len(response)))
-	c.JSON(http.StatusOK, response)
+	c.JSON(http.StatusOK, applySparseFields(c, response))
 }
 
 func addManga(c *gin.Context) {
 	zapLogger.Info("addManga handler called")
 
 	var requestManga struct {
-		Title       string   `json:"title" binding:"required"`
-		Description string   `json:"description"`
-		Author      string   `json:"author"`
-		Artist      string   `json:"artist"`
-		Genres      []string `json:"genres"`
-		Status      string   `json:"status"`
+		ID            string            `json:"id"` // Optional explicit slug; derived from the title otherwise
+		Title         string            `json:"title" binding:"required"`
+		Description   string            `json:"description"`
+		Author        string            `json:"author"`
+		Artist        string            `json:"artist"`
+		Genres        []string          `json:"genres"`
+		Tags          []string          `json:"tags"`
+		Status        string            `json:"status"`
+		Publisher     string            `json:"publisher"`
+		Demographic   string            `json:"demographic"`
+		Serialization string            `json:"serialization"`
+		AutoCrop      bool              `json:"autoCrop"`
+		NSFW          bool              `json:"nsfw"`
+		CustomFields  map[string]string `json:"customFields"`
 	}
 
 	if err := c.ShouldBindJSON(&requestManga); err != nil {
@@ -447,14 +898,43 @@ func addManga(c *gin.Context) {
 		return
 	}
 
-	id := createSlug(requestManga.Title)
-	if _, err := metadataManager.GetMangaByID(id); err == nil {
-		zapLogger.Warn("Manga with this ID already exists", zap.String("id", id))
-		c.JSON(http.StatusConflict, gin.H{"error": "Manga with this ID already exists"})
+	demographic, err := models.NormalizeDemographic(requestManga.Demographic)
+	if err != nil {
+		zapLogger.Warn("Invalid demographic", zap.String("demographic", requestManga.Demographic))
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request: " + err.Error()})
 		return
 	}
 
-	mangaPath := filepath.Join(metadataManager.RootDir, id)
+	if !validateCustomFields(c, requestManga.CustomFields) {
+		return
+	}
+
+	var id string
+	if requestManga.ID != "" {
+		if !slug.Valid(requestManga.ID) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request: id may only contain lowercase letters, digits and hyphens"})
+			return
+		}
+		id = requestManga.ID
+		if mangaIDTaken(id) {
+			zapLogger.Warn("Manga with this ID already exists", zap.String("id", id))
+			c.JSON(http.StatusConflict, gin.H{"error": "Manga with this ID already exists"})
+			return
+		}
+	} else {
+		id = uniqueMangaID(requestManga.Title)
+	}
+
+	mangaPath, err := seriesFolderPath(id, requestManga.Title)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request: " + err.Error()})
+		return
+	}
+	if _, err := os.Stat(mangaPath); err == nil {
+		zapLogger.Warn("Manga folder already exists", zap.String("mangaPath", mangaPath))
+		c.JSON(http.StatusConflict, gin.H{"error": "A series folder named " + filepath.Base(mangaPath) + " already exists"})
+		return
+	}
 	if err := os.MkdirAll(mangaPath, 0755); err != nil {
 		zapLogger.Error("Failed to create manga directory", zap.String("mangaPath", mangaPath), zap.Error(err))
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create manga directory: " + err.Error()})
@@ -462,14 +942,23 @@ func addManga(c *gin.Context) {
 	}
 
 	manga := models.MangaSeries{
-		ID:          id,
-		Title:       requestManga.Title,
-		Description: requestManga.Description,
-		Author:      requestManga.Author,
-		Artist:      requestManga.Artist,
-		Genres:      requestManga.Genres,
-		Status:      requestManga.Status,
-		Path:        mangaPath,
+		ID:            id,
+		Title:         requestManga.Title,
+		Description:   requestManga.Description,
+		Author:        requestManga.Author,
+		Artist:        requestManga.Artist,
+		Genres:        requestManga.Genres,
+		Tags:          tagStore.CanonicalList(requestManga.Tags),
+		Status:        requestManga.Status,
+		Publisher:     requestManga.Publisher,
+		Demographic:   demographic,
+		Serialization: requestManga.Serialization,
+		AutoCrop:      requestManga.AutoCrop,
+		NSFW:          requestManga.NSFW,
+		Path:          mangaPath,
+	}
+	for key, value := range requestManga.CustomFields {
+		manga.SetCustomField(key, value)
 	}
 
 	metadataPath := filepath.Join(mangaPath, models.MetadataFileName)
@@ -481,15 +970,23 @@ func addManga(c *gin.Context) {
 		return
 	}
 
+	libraryIndex.Refresh(mangaPath)
+
 	zapLogger.Info("Manga created", zap.String("mangaID", manga.ID))
 	c.JSON(http.StatusCreated, gin.H{
-		"id":          manga.ID,
-		"title":       manga.Title,
-		"description": manga.Description,
-		"author":      manga.Author,
-		"artist":      manga.Artist,
-		"genres":      manga.Genres,
-		"status":      manga.Status,
+		"id":            manga.ID,
+		"title":         manga.Title,
+		"description":   manga.Description,
+		"author":        manga.Author,
+		"artist":        manga.Artist,
+		"genres":        manga.Genres,
+		"tags":          manga.Tags,
+		"status":        manga.Status,
+		"publisher":     manga.Publisher,
+		"demographic":   manga.Demographic,
+		"serialization": manga.Serialization,
+		"autoCrop":      manga.AutoCrop,
+		"nsfw":          manga.NSFW,
 	})
 }
 
@@ -498,12 +995,21 @@ func updateManga(c *gin.Context) {
 	zapLogger.Info("updateManga handler called", zap.String("mangaID", id))
 
 	var requestManga struct {
-		Title       string   `json:"title"`
-		Description string   `json:"description"`
-		Author      string   `json:"author"`
-		Artist      string   `json:"artist"`
-		Genres      []string `json:"genres"`
-		Status      string   `json:"status"`
+		Title         string            `json:"title"`
+		Description   string            `json:"description"`
+		Author        string            `json:"author"`
+		Artist        string            `json:"artist"`
+		Genres        []string          `json:"genres"`
+		Tags          []string          `json:"tags"`
+		Status        string            `json:"status"`
+		Publisher     string            `json:"publisher"`
+		Demographic   string            `json:"demographic"`
+		Serialization string            `json:"serialization"`
+		AutoCrop      *bool             `json:"autoCrop"`
+		NSFW          *bool             `json:"nsfw"`
+		CustomFields  map[string]string `json:"customFields"`
+		Locked        *bool             `json:"locked"`
+		LockedFields  []string          `json:"lockedFields"` // Replaces the list; [] unlocks every field
 	}
 
 	if err := c.ShouldBindJSON(&requestManga); err != nil {
@@ -512,15 +1018,8 @@ func updateManga(c *gin.Context) {
 		return
 	}
 
-	manga, err := metadataManager.GetMangaByID(id)
-	if err != nil {
-		if models.IsMangaNotFoundError(err) {
-			zapLogger.Warn("Manga not found", zap.String("mangaID", id))
-			c.JSON(http.StatusNotFound, gin.H{"error": "Manga not found"})
-		} else {
-			zapLogger.Error("Failed to retrieve manga", zap.Error(err))
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve manga: " + err.Error()})
-		}
+	manga, ok := lookupManga(c, id)
+	if !ok {
 		return
 	}
 
@@ -539,9 +1038,47 @@ func updateManga(c *gin.Context) {
 	if len(requestManga.Genres) > 0 {
 		manga.Genres = requestManga.Genres
 	}
+	if requestManga.Tags != nil {
+		manga.Tags = tagStore.CanonicalList(requestManga.Tags)
+	}
 	if requestManga.Status != "" {
 		manga.Status = requestManga.Status
 	}
+	if requestManga.Publisher != "" {
+		manga.Publisher = requestManga.Publisher
+	}
+	if requestManga.Demographic != "" {
+		demographic, err := models.NormalizeDemographic(requestManga.Demographic)
+		if err != nil {
+			zapLogger.Warn("Invalid demographic", zap.String("demographic", requestManga.Demographic))
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request: " + err.Error()})
+			return
+		}
+		manga.Demographic = demographic
+	}
+	if requestManga.Serialization != "" {
+		manga.Serialization = requestManga.Serialization
+	}
+	if requestManga.AutoCrop != nil {
+		manga.AutoCrop = *requestManga.AutoCrop
+	}
+	if requestManga.NSFW != nil {
+		manga.NSFW = *requestManga.NSFW
+	}
+	if requestManga.Locked != nil {
+		manga.Locked = *requestManga.Locked
+	}
+	if requestManga.LockedFields != nil {
+		fields, err := models.NormalizeLockedFields(requestManga.LockedFields)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request: " + err.Error()})
+			return
+		}
+		manga.LockedFields = fields
+	}
+	if !applyCustomFields(c, manga, requestManga.CustomFields) {
+		return
+	}
 
 	metadataPath := filepath.Join(manga.Path, models.MetadataFileName)
 	if err := manga.SaveToJSON(metadataPath); err != nil {
@@ -552,15 +1089,25 @@ func updateManga(c *gin.Context) {
 		return
 	}
 
+	libraryIndex.Refresh(manga.Path)
+
 	zapLogger.Info("Manga updated", zap.String("mangaID", manga.ID))
 	c.JSON(http.StatusOK, gin.H{
-		"id":          manga.ID,
-		"title":       manga.Title,
-		"description": manga.Description,
-		"author":      manga.Author,
-		"artist":      manga.Artist,
-		"genres":      manga.Genres,
-		"status":      manga.Status,
+		"id":            manga.ID,
+		"title":         manga.Title,
+		"description":   manga.Description,
+		"author":        manga.Author,
+		"artist":        manga.Artist,
+		"genres":        manga.Genres,
+		"tags":          manga.Tags,
+		"status":        manga.Status,
+		"publisher":     manga.Publisher,
+		"demographic":   manga.Demographic,
+		"serialization": manga.Serialization,
+		"autoCrop":      manga.AutoCrop,
+		"nsfw":          manga.NSFW,
+		"locked":        manga.Locked,
+		"lockedFields":  manga.LockedFields,
 	})
 }
 
@@ -569,10 +1116,21 @@ func addChapter(c *gin.Context) {
 	zapLogger.Info("addChapter handler called", zap.String("mangaID", mangaID))
 
 	var requestChapter struct {
-		Number  float64 `json:"number" binding:"required"`
-		Title   string  `json:"title"`
-		Volume  int     `json:"volume"`
-		Special bool    `json:"special"`
+		Number    float64    `json:"number" binding:"required"`
+		Title     string     `json:"title"`
+		Volume    int        `json:"volume"`
+		Special   bool       `json:"special"`
+		PublishAt *time.Time `json:"publishAt"` // Optional; a future time schedules the chapter
+
+		// Kind is classified from the title and number when omitted
+		Kind string `json:"kind"`
+
+		// ReleaseDate is a date or RFC 3339 timestamp; defaults to now
+		ReleaseDate string `json:"releaseDate"`
+
+		Summary    string   `json:"summary"`
+		SourceURL  string   `json:"sourceUrl"`
+		Scanlators []string `json:"scanlators"`
 	}
 
 	if err := c.ShouldBindJSON(&requestChapter); err != nil {
@@ -580,40 +1138,66 @@ func addChapter(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request: " + err.Error()})
 		return
 	}
-
-	manga, err := metadataManager.GetMangaByID(mangaID)
-	if err != nil {
-		if models.IsMangaNotFoundError(err) {
-			zapLogger.Warn("Manga not found", zap.String("mangaID", mangaID))
-			c.JSON(http.StatusNotFound, gin.H{"error": "Manga not found"})
-		} else {
-			zapLogger.Error("Failed to retrieve manga", zap.Error(err))
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve manga: " + err.Error()})
+	if requestChapter.SourceURL != "" {
+		if err := importers.ValidateURL(requestChapter.SourceURL); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request: sourceUrl: " + err.Error()})
+			return
+		}
+	}
+	if requestChapter.Kind != "" {
+		if err := models.ValidateChapterKind(requestChapter.Kind); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request: " + err.Error()})
+			return
+		}
+	}
+	releaseDate := timeNow()
+	if requestChapter.ReleaseDate != "" {
+		var err error
+		if releaseDate, err = models.ParseReleaseDate(requestChapter.ReleaseDate); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request: " + err.Error()})
+			return
 		}
-		return
 	}
 
-	chapterID := "chapter-" + strconv.FormatFloat(requestChapter.Number, 'f', 1, 64)
-	chapterID = createSlug(chapterID)
-
-	chapterPath := filepath.Join(manga.Path, chapterID)
-	if err := os.MkdirAll(chapterPath, 0755); err != nil {
-		zapLogger.Error("Failed to create chapter directory",
-			zap.String("chapterPath", chapterPath),
-			zap.Error(err))
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create chapter directory: " + err.Error()})
+	manga, ok := lookupManga(c, mangaID)
+	if !ok {
 		return
 	}
 
 	chapter := models.Chapter{
-		ID:          chapterID,
+		ID:          chapterIDFor(requestChapter.Number),
 		MangaID:     mangaID,
 		Number:      requestChapter.Number,
 		Title:       requestChapter.Title,
-		ReleaseDate: timeNow(),
-		Path:        chapterPath,
+		ReleaseDate: releaseDate,
 		Volume:      requestChapter.Volume,
 		Special:     requestChapter.Special,
+		Summary:     requestChapter.Summary,
+		SourceURL:   requestChapter.SourceURL,
+		Scanlators:  requestChapter.Scanlators,
+	}
+	if requestChapter.Kind != "" {
+		chapter.SetKind(requestChapter.Kind)
+	} else {
+		chapter.Classify(chapter.Title)
+	}
+	chapterPath, err := chapterFolderPath(manga, &chapter)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request: " + err.Error()})
+		return
+	}
+	chapter.Path = chapterPath
+	if err := os.MkdirAll(chapterPath, 0755); err != nil {
+		zapLogger.Error("Failed to create chapter directory",
+			zap.String("chapterPath", chapterPath),
+			zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create chapter directory: " + err.Error()})
+		return
+	}
+
+	if publishAt := requestChapter.PublishAt; publishAt != nil && publishAt.After(timeNow()) {
+		utc := publishAt.UTC()
+		chapter.PublishAt = &utc
 	}
 
 	metadataPath := filepath.Join(chapterPath, models.MetadataFileName)
@@ -625,6 +1209,23 @@ func addChapter(c *gin.Context) {
 		return
 	}
 
+	if chapter.PublishAt != nil {
+		entry := scheduler.Entry{
+			MangaID:     mangaID,
+			ChapterID:   chapter.ID,
+			ChapterPath: chapterPath,
+			PublishAt:   *chapter.PublishAt,
+		}
+		if err := publishScheduler.Schedule(entry); err != nil {
+			zapLogger.Error("Failed to schedule chapter", zap.String("chapterID", chapter.ID), zap.Error(err))
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to schedule chapter: " + err.Error()})
+			return
+		}
+	} else {
+		notifyChapterPublished(&chapter)
+	}
+	notifyChapterImported(&chapter)
+
 	zapLogger.Info("Chapter created",
 		zap.String("mangaID", mangaID),
 		zap.String("chapterID", chapter.ID),
@@ -634,9 +1235,14 @@ func addChapter(c *gin.Context) {
 		"mangaId":     chapter.MangaID,
 		"number":      chapter.Number,
 		"title":       chapter.Title,
-		"releaseDate": chapter.ReleaseDate,
+		"releaseDate": timestamp(chapter.ReleaseDate),
 		"volume":      chapter.Volume,
 		"special":     chapter.Special,
+		"kind":        chapter.Kind,
+		"summary":     chapter.Summary,
+		"sourceUrl":   chapter.SourceURL,
+		"scanlators":  chapter.Scanlators,
+		"publishAt":   optionalTimestamp(chapter.PublishAt),
 	})
 }
 
@@ -656,9 +1262,19 @@ func updateChapter(c *gin.Context) {
 	}
 
 	var requestChapter struct {
-		Title   string `json:"title"`
-		Volume  int    `json:"volume"`
-		Special bool   `json:"special"`
+		Title       string `json:"title"`
+		Volume      int    `json:"volume"`
+		Special     bool   `json:"special"`
+		ReleaseDate string `json:"releaseDate"` // Date or RFC 3339 timestamp; empty keeps it
+
+		// Summary, SourceURL and Scanlators are kept when omitted; empty
+		// values clear them
+		Summary    *string   `json:"summary"`
+		SourceURL  *string   `json:"sourceUrl"`
+		Scanlators *[]string `json:"scanlators"`
+
+		// Kind replaces special when given
+		Kind string `json:"kind"`
 	}
 
 	if err := c.ShouldBindJSON(&requestChapter); err != nil {
@@ -666,16 +1282,28 @@ func updateChapter(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request: " + err.Error()})
 		return
 	}
-
-	manga, err := metadataManager.GetMangaByID(mangaID)
-	if err != nil {
-		if models.IsMangaNotFoundError(err) {
-			zapLogger.Warn("Manga not found", zap.String("mangaID", mangaID))
-			c.JSON(http.StatusNotFound, gin.H{"error": "Manga not found"})
-		} else {
-			zapLogger.Error("Failed to retrieve manga", zap.Error(err))
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve manga: " + err.Error()})
+	if source := requestChapter.SourceURL; source != nil && *source != "" {
+		if err := importers.ValidateURL(*source); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request: sourceUrl: " + err.Error()})
+			return
 		}
+	}
+	if requestChapter.Kind != "" {
+		if err := models.ValidateChapterKind(requestChapter.Kind); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request: " + err.Error()})
+			return
+		}
+	}
+	var releaseDate time.Time
+	if requestChapter.ReleaseDate != "" {
+		if releaseDate, err = models.ParseReleaseDate(requestChapter.ReleaseDate); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request: " + err.Error()})
+			return
+		}
+	}
+
+	manga, ok := lookupManga(c, mangaID)
+	if !ok {
 		return
 	}
 
@@ -685,6 +1313,7 @@ func updateChapter(c *gin.Context) {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve chapters: " + err.Error()})
 		return
 	}
+	chapters = visibleChapters(c, chapters)
 
 	var targetChapter *models.Chapter
 	for i := range chapters {
@@ -707,7 +1336,23 @@ func updateChapter(c *gin.Context) {
 		targetChapter.Title = requestChapter.Title
 	}
 	targetChapter.Volume = requestChapter.Volume
-	targetChapter.Special = requestChapter.Special
+	if requestChapter.Kind != "" {
+		targetChapter.SetKind(requestChapter.Kind)
+	} else {
+		targetChapter.SetSpecial(requestChapter.Special)
+	}
+	if !releaseDate.IsZero() {
+		targetChapter.ReleaseDate = releaseDate
+	}
+	if requestChapter.Summary != nil {
+		targetChapter.Summary = *requestChapter.Summary
+	}
+	if requestChapter.SourceURL != nil {
+		targetChapter.SourceURL = *requestChapter.SourceURL
+	}
+	if requestChapter.Scanlators != nil {
+		targetChapter.Scanlators = *requestChapter.Scanlators
+	}
 
 	metadataPath := filepath.Join(targetChapter.Path, models.MetadataFileName)
 	if err := targetChapter.SaveToJSON(metadataPath); err != nil {
@@ -727,9 +1372,13 @@ func updateChapter(c *gin.Context) {
 		"mangaId":     targetChapter.MangaID,
 		"number":      targetChapter.Number,
 		"title":       targetChapter.Title,
-		"releaseDate": targetChapter.ReleaseDate,
+		"releaseDate": timestamp(targetChapter.ReleaseDate),
 		"volume":      targetChapter.Volume,
 		"special":     targetChapter.Special,
+		"kind":        targetChapter.Kind,
+		"summary":     targetChapter.Summary,
+		"sourceUrl":   targetChapter.SourceURL,
+		"scanlators":  targetChapter.Scanlators,
 	})
 }
 
@@ -739,22 +1388,65 @@ func containsIgnoreCase(s, substr string) bool {
 	return strings.Contains(s, substr)
 }
 
+// hasAllTags reports whether every wanted tag is present in list
+func hasAllTags(list, wanted []string) bool {
+	for _, w := range wanted {
+		found := false
+		for _, t := range list {
+			if t == w {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
 func equalIgnoreCase(s1, s2 string) bool {
 	return strings.ToLower(s1) == strings.ToLower(s2)
 }
 
 func createSlug(s string) string {
-	slug := strings.ToLower(s)
-	slug = strings.ReplaceAll(slug, " ", "-")
-	reg := regexp.MustCompile(`[^a-z0-9\-]`)
-	slug = reg.ReplaceAllString(slug, "")
-	for strings.Contains(slug, "--") {
-		slug = strings.ReplaceAll(slug, "--", "-")
-	}
-	slug = strings.Trim(slug, "-")
-	return slug
+	return slug.Make(s)
+}
+
+// uniqueMangaID derives an unused series ID from a title. Titles that can't be
+// fully transliterated get a hash suffix so distinct titles don't collide, and
+// remaining collisions are resolved with a numeric suffix (-2, -3, ...).
+func uniqueMangaID(title string) string {
+	base := slug.Make(title)
+	switch {
+	case base == "":
+		base = slug.Fallback("series", title)
+	case !slug.Complete(title):
+		base = slug.Fallback(base, title)
+	}
+
+	id := base
+	for n := 2; mangaIDTaken(id); n++ {
+		id = fmt.Sprintf("%s-%d", base, n)
+	}
+	return id
+}
+
+// mangaIDTaken reports whether a series ID or its directory is already in use
+func mangaIDTaken(id string) bool {
+	if _, err := os.Stat(filepath.Join(metadataManager.RootDir, id)); err == nil {
+		return true
+	}
+	_, err := metadataManager.GetMangaByID(id)
+	return err == nil
+}
+
+// chapterIDFor is the ID, and directory name, of a chapter created through the API
+func chapterIDFor(number float64) string {
+	return createSlug("chapter-" + strconv.FormatFloat(number, 'f', 1, 64))
 }
 
+// timeNow is the current time in UTC, the zone every stored timestamp uses
 func timeNow() time.Time {
-	return time.Now()
+	return time.Now().UTC()
 }
@@ -0,0 +1,396 @@
+package routes
+
+import (
+	"context"
+	"fmt"
+	"mangahub/backend/importers"
+	"mangahub/backend/jobs"
+	"mangahub/backend/models"
+	"mangahub/backend/naming"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// libraryScanJobType identifies library root scans in the jobs API
+const libraryScanJobType = "library-scan"
+
+// Content ratings given to series found without a metadata.json
+const (
+	contentRatingSafe = "safe"
+	contentRatingNSFW = "nsfw"
+)
+
+// ScanPolicy sets how a library root is scanned
+type ScanPolicy struct {
+	// IntervalMinutes rescans the root in the background; 0 leaves it to
+	// listings to notice changed folders
+	IntervalMinutes int `json:"intervalMinutes"`
+
+	// Archives turns CBZ files in series folders into chapters
+	Archives bool `json:"archives"`
+
+	// GenerateMetadata saves derived metadata; nil follows
+	// SetPersistDerivedMetadata
+	GenerateMetadata *bool `json:"generateMetadata,omitempty"`
+
+	// ContentRating is given to series found without a metadata.json
+	ContentRating string `json:"contentRating"`
+}
+
+// validate normalizes the policy and checks its values
+func (p *ScanPolicy) validate() error {
+	if p.IntervalMinutes < 0 {
+		return fmt.Errorf("intervalMinutes must not be negative")
+	}
+	p.ContentRating = strings.ToLower(strings.TrimSpace(p.ContentRating))
+	switch p.ContentRating {
+	case "":
+		p.ContentRating = contentRatingSafe
+	case contentRatingSafe, contentRatingNSFW:
+	default:
+		return fmt.Errorf("contentRating must be safe or nsfw")
+	}
+	return nil
+}
+
+var (
+	scanPoliciesMu sync.RWMutex
+	scanPolicies   = map[string]ScanPolicy{} // keyed by cleaned root path
+
+	// saveScanPolicies stores edited policies; nil keeps them in memory only
+	saveScanPolicies func(map[string]ScanPolicy) error
+
+	// scanWorkers is how many series folders a library scan loads at once
+	scanWorkers = 1
+)
+
+// SetScanWorkers sets how many series folders are loaded at once when the
+// library is scanned
+func SetScanWorkers(n int) {
+	if n > 0 {
+		scanWorkers = n
+	}
+}
+
+// SetScanPolicies sets the scan policy of each library root, keyed by the
+// root's path, and how policies edited through the API are saved
+func SetScanPolicies(policies map[string]ScanPolicy, save func(map[string]ScanPolicy) error) error {
+	cleaned := make(map[string]ScanPolicy, len(policies))
+	for root, policy := range policies {
+		if err := policy.validate(); err != nil {
+			return fmt.Errorf("library %s: %w", root, err)
+		}
+		cleaned[filepath.Clean(root)] = policy
+	}
+	scanPoliciesMu.Lock()
+	scanPolicies = cleaned
+	saveScanPolicies = save
+	scanPoliciesMu.Unlock()
+	return nil
+}
+
+// libraryRoots lists the library roots; personal libraries live inside the
+// shared one, so there is currently just one
+func libraryRoots() []string {
+	return []string{filepath.Clean(metadataManager.RootDir)}
+}
+
+// scanPolicyFor returns the policy of a library root, the defaults if it
+// has none
+func scanPolicyFor(root string) ScanPolicy {
+	scanPoliciesMu.RLock()
+	policy, ok := scanPolicies[filepath.Clean(root)]
+	scanPoliciesMu.RUnlock()
+	if !ok {
+		policy = ScanPolicy{ContentRating: contentRatingSafe}
+	}
+	return policy
+}
+
+// applyScanPolicies configures the metadata manager from the policy of its
+// root, warning about policies of roots that don't exist
+func applyScanPolicies() {
+	root := filepath.Clean(metadataManager.RootDir)
+	scanPoliciesMu.RLock()
+	for configured := range scanPolicies {
+		if configured != root {
+			zapLogger.Warn("Ignoring scan policy of unknown library root", zap.String("root", configured))
+		}
+	}
+	scanPoliciesMu.RUnlock()
+
+	policy := scanPolicyFor(root)
+	generate := persistDerivedMetadata
+	if policy.GenerateMetadata != nil {
+		generate = *policy.GenerateMetadata
+	}
+	metadataManager.SetPersistDerived(generate)
+	metadataManager.SetDefaultNSFW(policy.ContentRating == contentRatingNSFW)
+}
+
+// startLibraryScans rescans each library root whose policy has an interval,
+// once the library index is ready. Intervals are rechecked every minute, so
+// edited policies take effect without a restart.
+func startLibraryScans() {
+	go func() {
+		for !libraryIndex.Ready() {
+			time.Sleep(10 * time.Second)
+		}
+		lastScan := make(map[string]time.Time)
+		for {
+			for _, root := range libraryRoots() {
+				interval := time.Duration(scanPolicyFor(root).IntervalMinutes) * time.Minute
+				if _, ok := lastScan[root]; !ok {
+					// The warm-up just scanned the root
+					lastScan[root] = time.Now()
+				}
+				if interval <= 0 || time.Since(lastScan[root]) < interval {
+					continue
+				}
+				lastScan[root] = time.Now()
+				if len(runningJobs(libraryScanJobType)) == 0 {
+					startLibraryScan(root)
+				}
+			}
+			time.Sleep(time.Minute)
+		}
+	}()
+}
+
+// scanReport is the result of a library root scan
+type scanReport struct {
+	Root     string  `json:"root"`
+	Series   int     `json:"series"`
+	Imported []gin.H `json:"imported"` // Chapters made from archives
+	Failed   []gin.H `json:"failed"`
+}
+
+// startLibraryScan rescans a library root as a job, turning the archives in
+// its series folders into chapters if its policy allows
+func startLibraryScan(root string) jobs.Job {
+	return jobManager.Start(libraryScanJobType, func(ctx context.Context, progress *jobs.Progress) (interface{}, error) {
+		report := scanReport{Root: root, Imported: []gin.H{}, Failed: []gin.H{}}
+		if err := libraryIndex.Sync(); err != nil {
+			return report, err
+		}
+		series := libraryIndex.List()
+		report.Series = len(series)
+		if !scanPolicyFor(root).Archives {
+			return report, nil
+		}
+
+		for i := range series {
+			if err := ctx.Err(); err != nil {
+				return report, err
+			}
+			manga := &series[i]
+			progress.Update(i, len(series), fmt.Sprintf("Scanned %d of %d series", i, len(series)))
+
+			imported, failed := importSeriesArchives(ctx, manga)
+			report.Imported = append(report.Imported, imported...)
+			report.Failed = append(report.Failed, failed...)
+			if len(imported) > 0 {
+				libraryIndex.Refresh(manga.Path)
+			}
+		}
+		if len(report.Failed) > 0 {
+			return report, fmt.Errorf("%d archives failed to import", len(report.Failed))
+		}
+		return report, nil
+	})
+}
+
+// importSeriesArchives extracts the CBZ files in a series folder into
+// chapters named after them, removing each archive once its chapter is in
+// place. Archives whose chapter already exists are left alone.
+func importSeriesArchives(ctx context.Context, manga *models.MangaSeries) (imported, failed []gin.H) {
+	entries, err := os.ReadDir(manga.Path)
+	if err != nil {
+		return nil, []gin.H{{"mangaId": manga.ID, "error": err.Error()}}
+	}
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || strings.HasPrefix(name, ".") || !strings.EqualFold(filepath.Ext(name), ".cbz") {
+			continue
+		}
+		archivePath := filepath.Join(manga.Path, name)
+		chapter, err := importSeriesArchive(ctx, manga, archivePath)
+		if err != nil {
+			zapLogger.Warn("Failed to import archive", zap.String("path", archivePath), zap.Error(err))
+			failed = append(failed, gin.H{"mangaId": manga.ID, "file": name, "error": err.Error()})
+			continue
+		}
+		if chapter == nil {
+			continue
+		}
+		imported = append(imported, gin.H{
+			"file":      name,
+			"mangaId":   chapter.MangaID,
+			"chapterId": chapter.ID,
+			"number":    chapter.Number,
+			"pageCount": chapter.PageCount,
+		})
+	}
+	return imported, failed
+}
+
+// importSeriesArchive extracts one archive into a new chapter; it returns a
+// nil chapter when the chapter already exists
+func importSeriesArchive(ctx context.Context, manga *models.MangaSeries, archivePath string) (*models.Chapter, error) {
+	base := strings.TrimSuffix(filepath.Base(archivePath), filepath.Ext(archivePath))
+	parsed, ok := naming.ParseChapter(base)
+	if !ok || parsed.Number <= 0 {
+		return nil, fmt.Errorf("no chapter number in %q", base)
+	}
+
+	chapter := models.Chapter{
+		ID:      chapterIDFor(parsed.Number),
+		MangaID: manga.ID,
+		Number:  parsed.Number,
+		Volume:  parsed.Volume,
+		Title:   parsed.Title,
+	}
+	chapter.Classify(base)
+	chapterPath, err := chapterFolderPath(manga, &chapter)
+	if err != nil {
+		return nil, err
+	}
+	chapter.Path = chapterPath
+	if _, err := os.Stat(chapter.Path); err == nil {
+		return nil, nil
+	}
+
+	stagingPath, err := newStagingDir(manga.Path, chapter.ID)
+	if err != nil {
+		return nil, err
+	}
+	pageCount, err := importers.ExtractArchive(ctx, archivePath, stagingPath, nil)
+	if err == nil {
+		err = finishStagedChapter(&chapter, stagingPath, pageCount)
+	}
+	if err != nil {
+		os.RemoveAll(stagingPath)
+		return nil, err
+	}
+	if err := os.Remove(archivePath); err != nil {
+		zapLogger.Warn("Failed to remove imported archive", zap.String("path", archivePath), zap.Error(err))
+	}
+
+	notifyChapterPublished(&chapter)
+	notifyChapterImported(&chapter)
+	return &chapter, nil
+}
+
+// libraryResponse is the JSON shape of a library root
+type libraryResponse struct {
+	Root   string     `json:"root"`
+	Policy ScanPolicy `json:"policy"`
+}
+
+// listLibraries returns every library root with its scan policy
+func listLibraries(c *gin.Context) {
+	libraries := []libraryResponse{}
+	for _, root := range libraryRoots() {
+		libraries = append(libraries, libraryResponse{Root: root, Policy: scanPolicyFor(root)})
+	}
+	c.JSON(http.StatusOK, libraries)
+}
+
+// lookupLibraryRoot resolves the "root" of a request to a library root;
+// empty means the shared library
+func lookupLibraryRoot(c *gin.Context, root string) (string, bool) {
+	roots := libraryRoots()
+	if root == "" {
+		return roots[0], true
+	}
+	for _, r := range roots {
+		if r == filepath.Clean(root) {
+			return r, true
+		}
+	}
+	c.JSON(http.StatusNotFound, gin.H{"error": "Library not found"})
+	return "", false
+}
+
+// updateLibraryPolicy replaces the scan policy of a library root and saves
+// it to the config file. Changes to metadata generation and the content
+// rating reload the series derived without a metadata.json.
+func updateLibraryPolicy(c *gin.Context) {
+	var request libraryResponse
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request: " + err.Error()})
+		return
+	}
+	root, ok := lookupLibraryRoot(c, request.Root)
+	if !ok {
+		return
+	}
+	policy := request.Policy
+	if err := policy.validate(); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	zapLogger.Info("updateLibraryPolicy handler called", zap.String("root", root), zap.Any("policy", policy))
+
+	scanPoliciesMu.Lock()
+	previous, had := scanPolicies[root]
+	updated := make(map[string]ScanPolicy, len(scanPolicies)+1)
+	for r, p := range scanPolicies {
+		updated[r] = p
+	}
+	updated[root] = policy
+	if saveScanPolicies != nil {
+		if err := saveScanPolicies(updated); err != nil {
+			scanPoliciesMu.Unlock()
+			zapLogger.Error("Failed to save scan policy", zap.String("root", root), zap.Error(err))
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save scan policy: " + err.Error()})
+			return
+		}
+	}
+	scanPolicies = updated
+	scanPoliciesMu.Unlock()
+
+	if !had {
+		previous = ScanPolicy{ContentRating: contentRatingSafe}
+	}
+	applyScanPolicies()
+	if previous.ContentRating != policy.ContentRating || !sameBoolPointer(previous.GenerateMetadata, policy.GenerateMetadata) {
+		go func() {
+			if err := libraryIndex.ReloadDerived(); err != nil {
+				zapLogger.Error("Failed to reload derived series", zap.Error(err))
+			}
+		}()
+	}
+	c.JSON(http.StatusOK, libraryResponse{Root: root, Policy: policy})
+}
+
+// sameBoolPointer reports whether two optional settings are the same
+func sameBoolPointer(a, b *bool) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
+
+// scanLibrary rescans a library root right away; the report is the job
+// result
+func scanLibrary(c *gin.Context) {
+	root, ok := lookupLibraryRoot(c, c.Query("root"))
+	if !ok {
+		return
+	}
+	zapLogger.Info("scanLibrary handler called", zap.String("root", root))
+
+	if running := runningJobs(libraryScanJobType); len(running) > 0 {
+		c.JSON(http.StatusConflict, gin.H{"error": "A library scan is already running", "job": running[0]})
+		return
+	}
+	c.JSON(http.StatusAccepted, startLibraryScan(root))
+}
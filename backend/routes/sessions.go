@@ -0,0 +1,60 @@
+package routes
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// listSessions returns the current user's active sessions, flagging the one
+// making the request
+func listSessions(c *gin.Context) {
+	user := currentUser(c)
+	current := currentSession(c)
+
+	response := []gin.H{}
+	for _, session := range userStore.Sessions(user.ID) {
+		response = append(response, gin.H{
+			"id":        session.ID,
+			"device":    session.Device,
+			"ip":        session.IP,
+			"createdAt": session.CreatedAt,
+			"lastSeen":  session.LastSeen,
+			"expiresAt": session.ExpiresAt,
+			"current":   current != nil && session.ID == current.ID,
+		})
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+// revokeSession logs one of the current user's devices out
+func revokeSession(c *gin.Context) {
+	user := currentUser(c)
+	sessionID := c.Param("id")
+	zapLogger.Info("revokeSession handler called", zap.String("userID", user.ID), zap.String("sessionID", sessionID))
+
+	if err := userStore.RevokeSession(user.ID, sessionID); err != nil {
+		respondUserError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"status": "revoked", "id": sessionID})
+}
+
+// revokeSessions logs all of the current user's devices out; with
+// ?others=true the one making the request stays logged in
+func revokeSessions(c *gin.Context) {
+	user := currentUser(c)
+	zapLogger.Info("revokeSessions handler called", zap.String("userID", user.ID))
+
+	var keep string
+	if current := currentSession(c); current != nil && c.Query("others") == "true" {
+		keep = current.ID
+	}
+	revoked, err := userStore.RevokeSessions(user.ID, keep)
+	if err != nil {
+		respondUserError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"status": "revoked", "count": revoked})
+}
@@ -0,0 +1,207 @@
+package routes
+
+import (
+	"bytes"
+	"encoding/xml"
+	"html"
+	"io/fs"
+	"mangahub/backend/models"
+	"mangahub/backend/web"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// publicURL is the configured external base URL, without a trailing slash
+var publicURL string
+
+// maxPreviewDescription caps the og:description length, in runes
+const maxPreviewDescription = 200
+
+// SetPublicURL sets the external base URL used in the sitemap and share
+// previews; empty derives it from each request
+func SetPublicURL(u string) {
+	publicURL = strings.TrimRight(u, "/")
+}
+
+// baseURL returns the external base URL for links in responses
+func baseURL(c *gin.Context) string {
+	if publicURL != "" {
+		return publicURL
+	}
+	scheme, host := "http", c.Request.Host
+	if c.Request.TLS != nil {
+		scheme = "https"
+	}
+	// A proxy terminating TLS says so, and may forward another host
+	if fromTrustedProxy(c) {
+		if proto := c.GetHeader("X-Forwarded-Proto"); proto == "https" || proto == "http" {
+			scheme = proto
+		}
+		if forwarded, _, _ := strings.Cut(c.GetHeader("X-Forwarded-Host"), ","); strings.TrimSpace(forwarded) != "" {
+			host = strings.TrimSpace(forwarded)
+		}
+	}
+	return scheme + "://" + host
+}
+
+type sitemapURLSet struct {
+	XMLName xml.Name     `xml:"http://www.sitemaps.org/schemas/sitemap/0.9 urlset"`
+	URLs    []sitemapURL `xml:"url"`
+}
+
+type sitemapURL struct {
+	Loc     string `xml:"loc"`
+	LastMod string `xml:"lastmod,omitempty"`
+}
+
+// getSitemap lists the home page and every series page for search engines
+func getSitemap(c *gin.Context) {
+	zapLogger.Info("getSitemap handler called")
+
+	mangas, err := indexedManga(c)
+	if err != nil {
+		zapLogger.Error("Failed to retrieve manga list", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve manga list: " + err.Error()})
+		return
+	}
+
+	base := baseURL(c)
+	set := sitemapURLSet{URLs: []sitemapURL{{Loc: base + "/"}}}
+	for _, manga := range publicSeries(mangas) {
+		entry := sitemapURL{Loc: base + seriesPagePath(manga.ID)}
+		if !manga.LastUpdated.IsZero() {
+			entry.LastMod = manga.LastUpdated.UTC().Format("2006-01-02")
+		}
+		set.URLs = append(set.URLs, entry)
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString(xml.Header)
+	if err := xml.NewEncoder(&buf).Encode(set); err != nil {
+		zapLogger.Error("Failed to encode sitemap", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to encode sitemap: " + err.Error()})
+		return
+	}
+	c.Data(http.StatusOK, "application/xml; charset=utf-8", buf.Bytes())
+}
+
+// getShareCard serves the Open Graph image of a series: its cover and title
+// composited onto a 1200x630 card
+func getShareCard(c *gin.Context) {
+	mangaID := c.Param("id")
+	zapLogger.Info("getShareCard handler called", zap.String("mangaID", mangaID))
+
+	manga, ok := lookupManga(c, mangaID)
+	if !ok {
+		return
+	}
+
+	coverPath := manga.GetCoverImagePath()
+	if _, err := os.Stat(coverPath); coverPath != "" && err != nil {
+		coverPath = ""
+	}
+	card, err := openCached(func() (string, error) {
+		return imageCache.Card(coverPath, manga.Title, creditLine(manga), manga.Publisher)
+	})
+	if err != nil {
+		zapLogger.Error("Failed to render share card", zap.String("mangaID", mangaID), zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to render share card: " + err.Error()})
+		return
+	}
+	defer card.Close()
+	info, err := card.Stat()
+	if err != nil {
+		zapLogger.Error("Failed to stat share card", zap.String("path", card.Name()), zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to read share card: " + err.Error()})
+		return
+	}
+
+	c.Header("Cache-Control", "public, max-age=86400")
+	http.ServeContent(c.Writer, c.Request, card.Name(), info.ModTime(), card)
+}
+
+// ServeIndex serves the single-page app's index.html from the frontend files.
+// Series and reader pages get Open Graph and Twitter tags injected so shared
+// links render a preview.
+func ServeIndex(c *gin.Context, frontend fs.FS) {
+	page, err := fs.ReadFile(frontend, web.IndexFile)
+	if err != nil {
+		zapLogger.Error("Failed to read index page", zap.Error(err))
+		c.Status(http.StatusNotFound)
+		return
+	}
+
+	rememberShareToken(c)
+	if mangaID, ok := seriesPageID(c.Request.URL.Path); ok {
+		manga, err := metadataManager.GetMangaByID(mangaID)
+		if err == nil && guestCanSee(manga) {
+			tags := previewTags(c, manga)
+			if i := bytes.Index(page, []byte("</head>")); i >= 0 {
+				page = append(page[:i:i], append([]byte(tags), page[i:]...)...)
+			}
+		}
+	}
+	c.Data(http.StatusOK, "text/html; charset=utf-8", page)
+}
+
+// previewTags renders the share-preview meta tags of a series page
+func previewTags(c *gin.Context, manga *models.MangaSeries) string {
+	base := baseURL(c)
+	description := []rune(strings.TrimSpace(manga.Description))
+	if len(description) > maxPreviewDescription {
+		description = append(description[:maxPreviewDescription-1], '…')
+	}
+
+	properties := [][2]string{
+		{"og:type", "book"},
+		{"og:site_name", "MangaHub"},
+		{"og:title", manga.Title},
+		{"og:description", string(description)},
+		{"og:url", base + seriesPagePath(manga.ID)},
+		{"og:image", base + "/api/manga/" + url.PathEscape(manga.ID) + "/card.png"},
+		{"og:image:width", "1200"},
+		{"og:image:height", "630"},
+	}
+	var b strings.Builder
+	for _, p := range properties {
+		b.WriteString(`<meta property="` + p[0] + `" content="` + html.EscapeString(p[1]) + `">` + "\n")
+	}
+	b.WriteString(`<meta name="twitter:card" content="summary_large_image">` + "\n")
+	return b.String()
+}
+
+// creditLine names the author and, when different, the artist
+func creditLine(manga *models.MangaSeries) string {
+	if manga.Artist == "" || equalIgnoreCase(manga.Artist, manga.Author) {
+		return manga.Author
+	}
+	if manga.Author == "" {
+		return manga.Artist
+	}
+	return manga.Author + " / " + manga.Artist
+}
+
+// seriesPagePath is the frontend path of a series page
+func seriesPagePath(mangaID string) string {
+	return "/manga/" + url.PathEscape(mangaID)
+}
+
+// seriesPageID extracts the series ID from a frontend series page path or
+// reader path (/reader/:id/:chapter/:page), which short links resolve to
+func seriesPageID(path string) (string, bool) {
+	if rest, ok := strings.CutPrefix(path, "/reader/"); ok {
+		id, _, _ := strings.Cut(rest, "/")
+		return id, id != ""
+	}
+	rest, ok := strings.CutPrefix(path, "/manga/")
+	id := strings.TrimSuffix(rest, "/")
+	if !ok || id == "" || strings.Contains(id, "/") {
+		return "", false
+	}
+	return id, true
+}
@@ -0,0 +1,231 @@
+package routes
+
+import (
+	"errors"
+	"mangahub/backend/models"
+	"mangahub/backend/sharetokens"
+	"mangahub/backend/shortlinks"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+const (
+	// shareCookieName carries a share token for browsers opening a shared link
+	shareCookieName = "mangahub_share"
+
+	// shareTokenHeader carries a share token for API clients
+	shareTokenHeader = "X-Share-Token"
+
+	// shareTokenContextKey is where resolveShareToken stores the share token
+	shareTokenContextKey = "shareToken"
+)
+
+// Share tokens last defaultShareHours unless an admin asks for another
+// lifetime, up to maxShareHours
+const (
+	defaultShareHours = 7 * 24
+	maxShareHours     = 90 * 24
+)
+
+// resolveShareToken stores the valid share token the request carries, in
+// the Authorization or X-Share-Token header, the share query parameter or
+// the share cookie, in the context
+func resolveShareToken(c *gin.Context) {
+	candidates := []string{bearerToken(c), c.GetHeader(shareTokenHeader), c.Query("share")}
+	if cookie, err := c.Cookie(shareCookieName); err == nil {
+		candidates = append(candidates, cookie)
+	}
+	for _, candidate := range candidates {
+		if !strings.HasPrefix(candidate, sharetokens.Prefix) {
+			continue
+		}
+		if token, ok := shareTokenStore.Get(candidate, timeNow()); ok {
+			c.Set(shareTokenContextKey, &token)
+			return
+		}
+		zapLogger.Debug("Ignoring invalid share token")
+	}
+}
+
+// currentShareToken returns the request's share token, or nil
+func currentShareToken(c *gin.Context) *sharetokens.Token {
+	if value, ok := c.Get(shareTokenContextKey); ok {
+		return value.(*sharetokens.Token)
+	}
+	return nil
+}
+
+// rememberShareToken keeps a share token from a shared link in a cookie, so
+// the frontend's API and image requests carry it too
+func rememberShareToken(c *gin.Context) {
+	secret := c.Query("share")
+	if !strings.HasPrefix(secret, sharetokens.Prefix) {
+		return
+	}
+	token, ok := shareTokenStore.Get(secret, timeNow())
+	if !ok {
+		return
+	}
+	c.SetSameSite(http.SameSiteLaxMode)
+	c.SetCookie(shareCookieName, secret, int(token.ExpiresAt.Sub(timeNow()).Seconds()), "/", "", false, true)
+}
+
+// sharedWithRequester reports whether the request's share token is for a
+// series
+func sharedWithRequester(c *gin.Context, mangaID string) bool {
+	token := currentShareToken(c)
+	return token != nil && token.MangaID == mangaID
+}
+
+// chapterShare returns the share token limiting the requester to one chapter
+// of a series, when the token is all that lets them see the series
+func chapterShare(c *gin.Context, mangaID string) *sharetokens.Token {
+	token := currentShareToken(c)
+	if token == nil || token.Chapter == nil || token.MangaID != mangaID {
+		return nil
+	}
+	if manga, ok := libraryIndex.Get(mangaID); ok && accountCanSeeSeries(c, manga) {
+		return nil
+	}
+	return token
+}
+
+// sharedChapters drops the chapters a chapter share token doesn't grant
+func sharedChapters(c *gin.Context, chapters []models.Chapter) []models.Chapter {
+	if len(chapters) == 0 {
+		return chapters
+	}
+	token := chapterShare(c, chapters[0].MangaID)
+	if token == nil {
+		return chapters
+	}
+	shared := chapters[:0:0]
+	for _, chapter := range chapters {
+		if token.Grants(chapter.Number) {
+			shared = append(shared, chapter)
+		}
+	}
+	return shared
+}
+
+// sharedChapterDir reports whether a chapter share token, if the requester
+// depends on one, grants the chapter stored in a folder of a series
+func sharedChapterDir(c *gin.Context, manga *models.MangaSeries, dir string) bool {
+	token := chapterShare(c, manga.ID)
+	if token == nil {
+		return true
+	}
+	chapters, err := metadataManager.ScanForChapters(manga)
+	if err != nil {
+		zapLogger.Warn("Failed to retrieve chapters", zap.String("mangaID", manga.ID), zap.Error(err))
+		return false
+	}
+	for _, chapter := range chapters {
+		if token.Grants(chapter.Number) && filepath.Base(chapter.Path) == dir {
+			return true
+		}
+	}
+	return false
+}
+
+// listShareTokens returns every share token, newest first
+func listShareTokens(c *gin.Context) {
+	tokens := shareTokenStore.List(timeNow())
+	response := make([]gin.H, 0, len(tokens))
+	for _, token := range tokens {
+		response = append(response, shareTokenResponse(c, token))
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+// createShareToken mints a token granting read access to a series, or one
+// chapter of it, to whoever holds the link
+func createShareToken(c *gin.Context) {
+	var request struct {
+		MangaID        string   `json:"mangaId" binding:"required"`
+		Chapter        *float64 `json:"chapter"` // Omitted to share the whole series
+		Note           string   `json:"note"`
+		ExpiresInHours int      `json:"expiresInHours"` // 0 for a week
+	}
+	if err := c.ShouldBindJSON(&request); err != nil {
+		zapLogger.Warn("Invalid request data", zap.Error(err))
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request: " + err.Error()})
+		return
+	}
+	zapLogger.Info("createShareToken handler called", zap.String("mangaID", request.MangaID))
+	if request.ExpiresInHours < 0 || request.ExpiresInHours > maxShareHours {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request: expiresInHours must be from 1 to 2160"})
+		return
+	}
+	if request.ExpiresInHours == 0 {
+		request.ExpiresInHours = defaultShareHours
+	}
+
+	manga, ok := lookupManga(c, request.MangaID)
+	if !ok {
+		return
+	}
+	if request.Chapter != nil {
+		chapters, err := metadataManager.ScanForChapters(manga)
+		if err != nil {
+			zapLogger.Error("Failed to retrieve chapters", zap.Error(err))
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve chapters: " + err.Error()})
+			return
+		}
+		found := false
+		for i := range chapters {
+			found = found || chapters[i].Number == *request.Chapter
+		}
+		if !found {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Chapter not found"})
+			return
+		}
+	}
+
+	ttl := time.Duration(request.ExpiresInHours) * time.Hour
+	token, err := shareTokenStore.Create(manga.ID, request.Chapter, request.Note, currentUser(c).ID, ttl)
+	if err != nil {
+		zapLogger.Error("Failed to save share token", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save share token: " + err.Error()})
+		return
+	}
+	c.JSON(http.StatusCreated, shareTokenResponse(c, token))
+}
+
+// revokeShareToken deletes a share token so its link stops working
+func revokeShareToken(c *gin.Context) {
+	zapLogger.Info("revokeShareToken handler called")
+
+	if err := shareTokenStore.Revoke(c.Param("token")); err != nil {
+		if errors.Is(err, sharetokens.ErrNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Share token not found"})
+			return
+		}
+		zapLogger.Error("Failed to revoke share token", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to revoke share token: " + err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"status": "revoked"})
+}
+
+func shareTokenResponse(c *gin.Context, token sharetokens.Token) gin.H {
+	target := shortlinks.Link{MangaID: token.MangaID}
+	if token.Chapter != nil {
+		target.Chapter = *token.Chapter
+	}
+	return gin.H{
+		"token":     token.Token,
+		"url":       baseURL(c) + shortLinkTarget(target) + "?share=" + token.Token,
+		"mangaId":   token.MangaID,
+		"chapter":   token.Chapter,
+		"note":      token.Note,
+		"createdAt": token.CreatedAt,
+		"expiresAt": token.ExpiresAt,
+		"expired":   !token.Valid(timeNow()),
+	}
+}
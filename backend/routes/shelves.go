@@ -0,0 +1,238 @@
+package routes
+
+import (
+	"errors"
+	"mangahub/backend/models"
+	"mangahub/backend/search"
+	"mangahub/backend/shelves"
+	"net/http"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// shelfRequest is the body accepted when creating or updating a shelf
+type shelfRequest struct {
+	Name     string   `json:"name" binding:"required"`
+	Query    string   `json:"query"` // Search syntax, e.g. `status:completed genre:romance`; makes the shelf smart
+	MangaIDs []string `json:"mangaIds"`
+	Sort     string   `json:"sort"`
+	Limit    int      `json:"limit"`
+	Position int      `json:"position"`
+}
+
+// listShelves returns every shelf with the series on it, for the browse page.
+// Each shelf shows at most its own limit of series; the count is of all.
+func listShelves(c *gin.Context) {
+	mangas, err := indexedManga(c)
+	if err != nil {
+		zapLogger.Error("Failed to retrieve manga list", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve manga list: " + err.Error()})
+		return
+	}
+	mangas = visibleSeries(c, mangas)
+
+	response := []gin.H{}
+	for _, shelf := range shelfStore.List() {
+		response = append(response, shelfResponse(shelf, mangas, shelf.Limit))
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+// getShelf returns one shelf with every series on it
+func getShelf(c *gin.Context) {
+	shelf, err := shelfStore.Get(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Shelf not found"})
+		return
+	}
+	mangas, err := indexedManga(c)
+	if err != nil {
+		zapLogger.Error("Failed to retrieve manga list", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve manga list: " + err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, shelfResponse(shelf, visibleSeries(c, mangas), 0))
+}
+
+// createShelf adds a shelf
+func createShelf(c *gin.Context) {
+	var request shelfRequest
+	if !bindShelf(c, &request) {
+		return
+	}
+	zapLogger.Info("createShelf handler called", zap.String("name", request.Name))
+
+	shelf, err := shelfStore.Create(request.shelf())
+	if err != nil {
+		zapLogger.Error("Failed to save shelf", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save shelf: " + err.Error()})
+		return
+	}
+	c.JSON(http.StatusCreated, shelf)
+}
+
+// updateShelf replaces a shelf's name, filter or series, order and position
+func updateShelf(c *gin.Context) {
+	id := c.Param("id")
+	var request shelfRequest
+	if !bindShelf(c, &request) {
+		return
+	}
+	zapLogger.Info("updateShelf handler called", zap.String("shelfID", id))
+
+	shelf, err := shelfStore.Update(id, request.shelf())
+	if errors.Is(err, shelves.ErrNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Shelf not found"})
+		return
+	}
+	if err != nil {
+		zapLogger.Error("Failed to save shelf", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save shelf: " + err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, shelf)
+}
+
+// deleteShelf removes a shelf; its series are untouched
+func deleteShelf(c *gin.Context) {
+	id := c.Param("id")
+	zapLogger.Info("deleteShelf handler called", zap.String("shelfID", id))
+
+	removed, err := shelfStore.Delete(id)
+	if err != nil {
+		zapLogger.Error("Failed to save shelves", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save shelves: " + err.Error()})
+		return
+	}
+	if !removed {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Shelf not found"})
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// bindShelf parses a shelf request and checks its query, order and series.
+// On failure it writes a 400 and returns false.
+func bindShelf(c *gin.Context, request *shelfRequest) bool {
+	if err := c.ShouldBindJSON(request); err != nil {
+		zapLogger.Warn("Invalid request data", zap.Error(err))
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request: " + err.Error()})
+		return false
+	}
+	if strings.TrimSpace(request.Name) == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Shelf name is required"})
+		return false
+	}
+	if request.Limit < 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request: limit must not be negative"})
+		return false
+	}
+	if sortBy := strings.ToLower(strings.TrimSpace(request.Sort)); sortBy != "" && !slices.Contains(shelves.Sorts, sortBy) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request: sort must be one of " + strings.Join(shelves.Sorts, ", ")})
+		return false
+	}
+	if strings.TrimSpace(request.Query) != "" {
+		if _, err := search.Parse(request.Query); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return false
+		}
+		return true
+	}
+	for _, id := range request.MangaIDs {
+		if !mangaIDTaken(id) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Unknown manga: " + id})
+			return false
+		}
+	}
+	return true
+}
+
+func (r shelfRequest) shelf() shelves.Shelf {
+	return shelves.Shelf{
+		Name:     r.Name,
+		Query:    r.Query,
+		MangaIDs: r.MangaIDs,
+		Sort:     r.Sort,
+		Limit:    r.Limit,
+		Position: r.Position,
+	}
+}
+
+// shelfSeries works out the series on a shelf from the given, visible ones
+func shelfSeries(shelf shelves.Shelf, mangas []models.MangaSeries) []*models.MangaSeries {
+	var series []*models.MangaSeries
+	if shelf.Smart() {
+		query, err := search.Parse(shelf.Query)
+		if err != nil {
+			// Queries are checked when saved, so this only follows a
+			// change to the search syntax
+			zapLogger.Warn("Skipping shelf with an invalid query", zap.String("shelfID", shelf.ID), zap.Error(err))
+			return nil
+		}
+		query.MapValues("tag", tagStore.Canonical)
+		for i := range mangas {
+			if query.Matches(&mangas[i], tagStore.CanonicalList(mangas[i].Tags)) {
+				series = append(series, &mangas[i])
+			}
+		}
+		if shelf.Sort == "" {
+			shelf.Sort = shelves.SortTitle
+		}
+	} else {
+		byID := make(map[string]*models.MangaSeries, len(mangas))
+		for i := range mangas {
+			byID[mangas[i].ID] = &mangas[i]
+		}
+		for _, id := range shelf.MangaIDs {
+			if manga, ok := byID[id]; ok {
+				series = append(series, manga)
+			}
+		}
+	}
+
+	switch shelf.Sort {
+	case shelves.SortTitle:
+		sort.SliceStable(series, func(i, j int) bool {
+			return strings.ToLower(series[i].Title) < strings.ToLower(series[j].Title)
+		})
+	case shelves.SortUpdated:
+		sort.SliceStable(series, func(i, j int) bool {
+			return series[i].LastUpdated.After(series[j].LastUpdated)
+		})
+	case shelves.SortRating:
+		ratings := reviewStore.Summaries()
+		sort.SliceStable(series, func(i, j int) bool {
+			return ratings[series[i].ID].Average > ratings[series[j].ID].Average
+		})
+	}
+	return series
+}
+
+// shelfResponse describes a shelf with up to limit of its series (0 for all)
+func shelfResponse(shelf shelves.Shelf, mangas []models.MangaSeries, limit int) gin.H {
+	series := shelfSeries(shelf, mangas)
+	count := len(series)
+	if limit > 0 && len(series) > limit {
+		series = series[:limit]
+	}
+
+	ratings := reviewStore.Summaries()
+	summaries := make([]gin.H, 0, len(series))
+	for _, manga := range series {
+		summaries = append(summaries, mangaSummary(manga, ratings[manga.ID]))
+	}
+	return gin.H{
+		"id":       shelf.ID,
+		"name":     shelf.Name,
+		"query":    shelf.Query,
+		"smart":    shelf.Smart(),
+		"sort":     shelf.Sort,
+		"position": shelf.Position,
+		"count":    count,
+		"manga":    summaries,
+	}
+}
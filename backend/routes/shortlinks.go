@@ -0,0 +1,129 @@
+package routes
+
+import (
+	"mangahub/backend/shortlinks"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// createShortLink mints a /s/:token link to a series, a chapter or a page
+func createShortLink(c *gin.Context) {
+	var request struct {
+		MangaID string  `json:"mangaId" binding:"required"`
+		Chapter float64 `json:"chapter"`
+		Page    int     `json:"page"`
+	}
+	if err := c.ShouldBindJSON(&request); err != nil {
+		zapLogger.Warn("Invalid request data", zap.Error(err))
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request: " + err.Error()})
+		return
+	}
+	zapLogger.Info("createShortLink handler called",
+		zap.String("mangaID", request.MangaID),
+		zap.Float64("chapter", request.Chapter),
+		zap.Int("page", request.Page),
+	)
+	if request.Page != 0 && request.Chapter == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "A page link needs a chapter"})
+		return
+	}
+	if request.Page < 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid page number"})
+		return
+	}
+
+	manga, ok := lookupManga(c, request.MangaID)
+	if !ok {
+		return
+	}
+	if request.Chapter != 0 {
+		chapters, err := metadataManager.ScanForChapters(manga)
+		if err != nil {
+			zapLogger.Error("Failed to retrieve chapters", zap.Error(err))
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve chapters: " + err.Error()})
+			return
+		}
+		chapters = visibleChapters(c, chapters)
+
+		found := false
+		for i := range chapters {
+			if chapters[i].Number != request.Chapter {
+				continue
+			}
+			found = true
+			if request.Page > 0 {
+				pages, err := metadataManager.LoadPages(&chapters[i])
+				if err != nil || request.Page > len(pages) {
+					c.JSON(http.StatusNotFound, gin.H{"error": "Page not found"})
+					return
+				}
+			}
+			break
+		}
+		if !found {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Chapter not found"})
+			return
+		}
+	}
+
+	createdBy := ""
+	if user := currentUser(c); user != nil {
+		createdBy = user.ID
+	}
+	link, err := shortLinkStore.Create(manga.ID, request.Chapter, request.Page, createdBy)
+	if err != nil {
+		zapLogger.Error("Failed to save short link", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save short link: " + err.Error()})
+		return
+	}
+	c.JSON(http.StatusCreated, shortLinkResponse(c, link))
+}
+
+// getShortLink describes a short link without following it
+func getShortLink(c *gin.Context) {
+	link, ok := shortLinkStore.Get(c.Param("token"))
+	if !ok {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Short link not found"})
+		return
+	}
+	c.JSON(http.StatusOK, shortLinkResponse(c, link))
+}
+
+// followShortLink redirects /s/:token to the page it points at
+func followShortLink(c *gin.Context) {
+	token := c.Param("token")
+	link, ok := shortLinkStore.Resolve(token)
+	if !ok {
+		zapLogger.Warn("Short link not found", zap.String("token", token))
+		c.JSON(http.StatusNotFound, gin.H{"error": "Short link not found"})
+		return
+	}
+	c.Redirect(http.StatusFound, shortLinkTarget(link))
+}
+
+func shortLinkResponse(c *gin.Context, link shortlinks.Link) gin.H {
+	return gin.H{
+		"token":     link.Token,
+		"url":       baseURL(c) + "/s/" + link.Token,
+		"target":    shortLinkTarget(link),
+		"mangaId":   link.MangaID,
+		"chapter":   link.Chapter,
+		"page":      link.Page,
+		"createdAt": link.CreatedAt,
+		"hits":      link.Hits,
+	}
+}
+
+// shortLinkTarget is the frontend path a link resolves to
+func shortLinkTarget(link shortlinks.Link) string {
+	if link.Chapter == 0 {
+		return seriesPagePath(link.MangaID)
+	}
+	page := max(link.Page, 1)
+	return "/reader/" + url.PathEscape(link.MangaID) + "/" +
+		strconv.FormatFloat(link.Chapter, 'f', -1, 64) + "/" + strconv.Itoa(page)
+}
@@ -0,0 +1,161 @@
+package routes
+
+import (
+	"context"
+	"fmt"
+	"mangahub/backend/importers"
+	"mangahub/backend/models"
+	"mangahub/backend/sources"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// listSources returns the registered download sources
+func listSources(c *gin.Context) {
+	c.JSON(http.StatusOK, sources.List())
+}
+
+// searchSource searches a source's catalog for ?q=, one page (?page=) at a time
+func searchSource(c *gin.Context) {
+	source, ok := lookupSource(c)
+	if !ok {
+		return
+	}
+	query := strings.TrimSpace(c.Query("q"))
+	if query == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Search query is required"})
+		return
+	}
+	page, err := strconv.Atoi(c.DefaultQuery("page", "1"))
+	if err != nil || page < 1 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid page"})
+		return
+	}
+
+	results, err := source.Search(c.Request.Context(), query, page)
+	if err != nil {
+		sourceError(c, err)
+		return
+	}
+	if results == nil {
+		results = []sources.Series{}
+	}
+	c.JSON(http.StatusOK, results)
+}
+
+// listSourceChapters lists the chapters of the source series in ?series=
+func listSourceChapters(c *gin.Context) {
+	source, ok := lookupSource(c)
+	if !ok {
+		return
+	}
+	seriesID := c.Query("series")
+	if seriesID == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Series ID is required"})
+		return
+	}
+
+	chapters, err := source.Chapters(c.Request.Context(), seriesID)
+	if err != nil {
+		sourceError(c, err)
+		return
+	}
+	if chapters == nil {
+		chapters = []sources.Chapter{}
+	}
+	c.JSON(http.StatusOK, chapters)
+}
+
+// downloadSourceChapter downloads a source chapter into a manga as a new
+// chapter. Like fetchRemoteChapter it runs as a job; the response is the job
+// to poll.
+func downloadSourceChapter(c *gin.Context) {
+	source, ok := lookupSource(c)
+	if !ok {
+		return
+	}
+
+	var request struct {
+		MangaID   string  `json:"mangaId" binding:"required"`
+		ChapterID string  `json:"chapterId" binding:"required"`
+		Number    float64 `json:"number" binding:"required"`
+		Title     string  `json:"title"`
+		Volume    int     `json:"volume"`
+		Special   bool    `json:"special"`
+	}
+	if err := c.ShouldBindJSON(&request); err != nil {
+		zapLogger.Warn("Invalid request data", zap.Error(err))
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request: " + err.Error()})
+		return
+	}
+	zapLogger.Info("downloadSourceChapter handler called",
+		zap.String("source", source.Info().ID),
+		zap.String("mangaID", request.MangaID),
+		zap.String("chapterID", request.ChapterID),
+	)
+
+	manga, ok := lookupManga(c, request.MangaID)
+	if !ok {
+		return
+	}
+
+	chapter := models.Chapter{
+		MangaID: manga.ID,
+		Number:  request.Number,
+		Title:   request.Title,
+		Volume:  request.Volume,
+		Special: request.Special,
+	}
+	startChapterDownload(c, manga, chapter, func(ctx context.Context, stagingPath string, report func(done, total int)) (int, error) {
+		pages, err := source.Pages(ctx, request.ChapterID)
+		if err != nil {
+			return 0, fmt.Errorf("resolving pages: %w", err)
+		}
+		if len(pages) == 0 {
+			return 0, fmt.Errorf("source returned no pages")
+		}
+		if len(pages) > maxRemotePages {
+			return 0, fmt.Errorf("source returned more than %d pages", maxRemotePages)
+		}
+
+		remote := make([]importers.RemotePage, len(pages))
+		for i, page := range pages {
+			if err := importers.ValidateURL(page.URL); err != nil {
+				return 0, fmt.Errorf("page %d: %w", i+1, err)
+			}
+			remote[i].URL = page.URL
+			if len(page.Headers) > 0 {
+				remote[i].Header = make(http.Header)
+				for key, value := range page.Headers {
+					remote[i].Header.Set(key, value)
+				}
+			}
+		}
+		return importers.DownloadPages(ctx, nil, remote, stagingPath, report)
+	})
+}
+
+// lookupSource fetches the source named in the URL, responding with 404 if
+// it isn't registered
+func lookupSource(c *gin.Context) (sources.Source, bool) {
+	source, err := sources.Get(c.Param("source"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Source not found"})
+		return nil, false
+	}
+	return source, true
+}
+
+// sourceError responds to a failed source call
+func sourceError(c *gin.Context, err error) {
+	zapLogger.Error("Source call failed", zap.String("source", c.Param("source")), zap.Error(err))
+	if sources.IsPluginError(err) {
+		c.JSON(http.StatusBadGateway, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusBadGateway, gin.H{"error": "Source unavailable: " + err.Error()})
+}
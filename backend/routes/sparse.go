@@ -0,0 +1,52 @@
+package routes
+
+import (
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// sparseFields reads the comma-separated ?fields= parameter listing which
+// properties a client wants in list responses. Nil means all of them.
+func sparseFields(c *gin.Context) map[string]bool {
+	raw := c.Query("fields")
+	if raw == "" {
+		return nil
+	}
+	fields := make(map[string]bool)
+	for _, f := range strings.Split(raw, ",") {
+		if f = strings.TrimSpace(f); f != "" {
+			fields[f] = true
+		}
+	}
+	if len(fields) == 0 {
+		return nil
+	}
+	return fields
+}
+
+// applySparseFields drops every property not requested via ?fields= from
+// the items of a list response
+func applySparseFields(c *gin.Context, items []gin.H) []gin.H {
+	fields := sparseFields(c)
+	if fields == nil {
+		return items
+	}
+	for _, item := range items {
+		trimFields(item, fields)
+	}
+	return items
+}
+
+// trimFields drops the properties of an item not in fields, keeping all of
+// them when fields is nil
+func trimFields(item gin.H, fields map[string]bool) {
+	if fields == nil {
+		return
+	}
+	for key := range item {
+		if !fields[key] {
+			delete(item, key)
+		}
+	}
+}
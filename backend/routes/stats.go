@@ -0,0 +1,49 @@
+package routes
+
+import (
+	"mangahub/backend/models"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// statsMaxAge is how long computed library statistics are served before
+// being recomputed
+const statsMaxAge = 10 * time.Minute
+
+var (
+	statsMu    sync.Mutex
+	statsCache *models.LibraryStats
+)
+
+// getStats returns library totals and breakdowns. The numbers are cached for
+// statsMaxAge since computing them walks the whole library.
+func getStats(c *gin.Context) {
+	zapLogger.Info("getStats handler called")
+
+	statsMu.Lock()
+	defer statsMu.Unlock()
+
+	if statsCache == nil || time.Since(statsCache.ComputedAt) > statsMaxAge || !libraryIndex.Ready() {
+		mangas, err := indexedManga(c)
+		if err != nil {
+			zapLogger.Error("Failed to retrieve manga list", zap.Error(err))
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve manga list: " + err.Error()})
+			return
+		}
+		// The cache is shared by every requester, so only public series count
+		stats := metadataManager.ComputeStats(publicSeries(mangas))
+		if libraryIndex.Ready() {
+			// Partial results from a warming index aren't worth keeping
+			statsCache = &stats
+		} else {
+			c.JSON(http.StatusOK, stats)
+			return
+		}
+	}
+
+	c.JSON(http.StatusOK, statsCache)
+}
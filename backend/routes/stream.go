@@ -0,0 +1,38 @@
+package routes
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// streamJSONArray writes a JSON array of n items one at a time, building each
+// with item only as it is written, so a long listing never sits in memory as
+// a whole. The response is sent chunked; once it has started an error can
+// only cut it short.
+func streamJSONArray(c *gin.Context, n int, item func(i int) gin.H) {
+	c.Header("Content-Type", "application/json; charset=utf-8")
+	c.Status(http.StatusOK)
+
+	w := c.Writer
+	if _, err := w.WriteString("["); err != nil {
+		return
+	}
+	for i := 0; i < n; i++ {
+		data, err := json.Marshal(item(i))
+		if err != nil {
+			zapLogger.Error("Failed to encode list item", zap.Int("index", i), zap.Error(err))
+			return
+		}
+		if i > 0 {
+			data = append([]byte{','}, data...)
+		}
+		if _, err := w.Write(data); err != nil {
+			zapLogger.Debug("Client went away during a streamed response", zap.Error(err))
+			return
+		}
+	}
+	w.WriteString("]")
+}
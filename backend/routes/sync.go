@@ -0,0 +1,156 @@
+package routes
+
+import (
+	"encoding/base64"
+	"mangahub/backend/models"
+	"mangahub/backend/tombstones"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// syncChanges lists what changed in one kind of content since a sync
+type syncChanges struct {
+	Created []gin.H                `json:"created"`
+	Updated []gin.H                `json:"updated"`
+	Deleted []tombstones.Tombstone `json:"deleted"`
+}
+
+func newSyncChanges() *syncChanges {
+	return &syncChanges{Created: []gin.H{}, Updated: []gin.H{}, Deleted: []tombstones.Tombstone{}}
+}
+
+// add files an item as created when it was first seen after since, or as
+// updated when it was modified after since
+func (s *syncChanges) add(item gin.H, firstSeen, modified, since time.Time) {
+	switch {
+	case firstSeen.After(since):
+		s.Created = append(s.Created, item)
+	case modified.After(since):
+		s.Updated = append(s.Updated, item)
+	}
+}
+
+// encodeSyncCursor turns the time a sync covers up to into an opaque cursor
+func encodeSyncCursor(t time.Time) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(t.Format(time.RFC3339Nano)))
+}
+
+func decodeSyncCursor(s string) (time.Time, bool) {
+	raw, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return time.Time{}, false
+	}
+	t, err := time.Parse(time.RFC3339Nano, string(raw))
+	return t, err == nil
+}
+
+// modifiedAt is when a series or chapter folder, or the metadata.json in it,
+// last changed. Metadata is rewritten in place, which the folder's own
+// modification time doesn't show.
+func modifiedAt(dir string) time.Time {
+	var modified time.Time
+	for _, path := range []string{dir, filepath.Join(dir, models.MetadataFileName)} {
+		if info, err := os.Stat(path); err == nil && info.ModTime().After(modified) {
+			modified = info.ModTime()
+		}
+	}
+	return modified.UTC()
+}
+
+func seriesSyncKey(mangaID string) string {
+	return "series/" + mangaID
+}
+
+func chapterSyncKey(mangaID, chapterID string) string {
+	return "chapter/" + mangaID + "/" + chapterID
+}
+
+// syncLibrary returns the series and chapters created, updated and deleted
+// since the cursor given as ?since=, with the cursor to pass next time.
+// Without a cursor, or when deletions since it were already forgotten,
+// "fullSync" is true: everything is listed as created and the client should
+// drop whatever else it holds.
+func syncLibrary(c *gin.Context) {
+	var since time.Time
+	if raw := c.Query("since"); raw != "" {
+		var ok bool
+		if since, ok = decodeSyncCursor(raw); !ok {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid cursor"})
+			return
+		}
+	}
+	zapLogger.Info("syncLibrary handler called", zap.Time("since", since))
+
+	// First-seen times are only right when the whole library is observed
+	if !libraryIndex.Ready() {
+		c.Header("Retry-After", "10")
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "The library is still being indexed"})
+		return
+	}
+	if err := libraryIndex.Sync(); err != nil {
+		zapLogger.Error("Failed to retrieve manga list", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve manga list: " + err.Error()})
+		return
+	}
+
+	until := timeNow()
+	series := libraryIndex.List()
+	chapters := make([][]models.Chapter, len(series))
+	keys := make([]string, 0, len(series))
+	for i := range series {
+		keys = append(keys, seriesSyncKey(series[i].ID))
+		var err error
+		if chapters[i], err = metadataManager.ScanForChapters(&series[i]); err != nil {
+			zapLogger.Warn("Failed to retrieve chapters", zap.String("mangaID", series[i].ID), zap.Error(err))
+			continue
+		}
+		for _, chapter := range chapters[i] {
+			keys = append(keys, chapterSyncKey(series[i].ID, chapter.ID))
+		}
+	}
+	firstSeen := firstSeenStore.Observe(keys, until)
+
+	deleted, complete := visibleTombstones(c, since, until)
+	fullSync := since.IsZero() || !complete
+	if fullSync {
+		since, deleted = time.Time{}, nil
+	}
+
+	mangaChanges, chapterChanges := newSyncChanges(), newSyncChanges()
+	ratings := reviewStore.Summaries()
+	for i := range series {
+		manga := &series[i]
+		if !canSeeSeries(c, manga) {
+			continue
+		}
+		mangaChanges.add(mangaSummary(manga, ratings[manga.ID]), firstSeen[seriesSyncKey(manga.ID)], modifiedAt(manga.Path), since)
+		for _, chapter := range visibleChapters(c, chapters[i]) {
+			chapterChanges.add(chapterSummary(manga, &chapter), firstSeen[chapterSyncKey(manga.ID, chapter.ID)], modifiedAt(chapter.Path), since)
+		}
+	}
+
+	for _, t := range deleted {
+		if t.Kind == tombstones.KindSeries {
+			mangaChanges.Deleted = append(mangaChanges.Deleted, t)
+		} else if _, exists := firstSeen[chapterSyncKey(t.MangaID, t.ChapterID)]; !exists {
+			chapterChanges.Deleted = append(chapterChanges.Deleted, t)
+		}
+	}
+
+	zapLogger.Info("syncLibrary returning data",
+		zap.Int("mangaCreated", len(mangaChanges.Created)),
+		zap.Int("mangaUpdated", len(mangaChanges.Updated)),
+		zap.Int("chaptersCreated", len(chapterChanges.Created)),
+		zap.Int("chaptersUpdated", len(chapterChanges.Updated)))
+	c.JSON(http.StatusOK, gin.H{
+		"cursor":   encodeSyncCursor(until),
+		"fullSync": fullSync,
+		"manga":    mangaChanges,
+		"chapters": chapterChanges,
+	})
+}
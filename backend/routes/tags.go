@@ -0,0 +1,217 @@
+package routes
+
+import (
+	"mangahub/backend/models"
+	"mangahub/backend/tags"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// listTags returns the tag catalog with the number of series per tag
+func listTags(c *gin.Context) {
+	zapLogger.Info("listTags handler called")
+
+	mangas, err := indexedManga(c)
+	if err != nil {
+		zapLogger.Error("Failed to retrieve manga list", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve manga list: " + err.Error()})
+		return
+	}
+
+	seriesTags := make([][]string, 0, len(mangas))
+	for _, manga := range mangas {
+		seriesTags = append(seriesTags, manga.Tags)
+	}
+	c.JSON(http.StatusOK, tagStore.Catalog(seriesTags))
+}
+
+// addMangaTags attaches tags to a series
+func addMangaTags(c *gin.Context) {
+	mangaID := c.Param("id")
+	zapLogger.Info("addMangaTags handler called", zap.String("mangaID", mangaID))
+
+	var request struct {
+		Tags []string `json:"tags" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&request); err != nil {
+		zapLogger.Warn("Invalid request data", zap.Error(err))
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request: " + err.Error()})
+		return
+	}
+
+	manga, ok := lookupManga(c, mangaID)
+	if !ok {
+		return
+	}
+
+	manga.Tags = tagStore.CanonicalList(append(manga.Tags, request.Tags...))
+	if !saveManga(c, manga) {
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"id": manga.ID, "tags": manga.Tags})
+}
+
+// removeMangaTag detaches a tag (or any of its aliases) from a series
+func removeMangaTag(c *gin.Context) {
+	mangaID := c.Param("id")
+	tag := tagStore.Canonical(c.Param("tag"))
+	zapLogger.Info("removeMangaTag handler called", zap.String("mangaID", mangaID), zap.String("tag", tag))
+
+	manga, ok := lookupManga(c, mangaID)
+	if !ok {
+		return
+	}
+
+	var remaining []string
+	for _, t := range tagStore.CanonicalList(manga.Tags) {
+		if t != tag {
+			remaining = append(remaining, t)
+		}
+	}
+	manga.Tags = remaining
+	if !saveManga(c, manga) {
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"id": manga.ID, "tags": manga.Tags})
+}
+
+// renameTag renames a tag on every series that carries it
+func renameTag(c *gin.Context) {
+	from := tagStore.Canonical(c.Param("tag"))
+
+	var request struct {
+		Name string `json:"name" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&request); err != nil {
+		zapLogger.Warn("Invalid request data", zap.Error(err))
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request: " + err.Error()})
+		return
+	}
+	to := tags.Normalize(request.Name)
+	zapLogger.Info("renameTag handler called", zap.String("from", from), zap.String("to", to))
+
+	updated, ok := rewriteTags(c, func(list []string) []string {
+		for i, t := range list {
+			if t == from {
+				list[i] = to
+			}
+		}
+		return list
+	})
+	if !ok {
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"from": from, "to": to, "updatedSeries": updated})
+}
+
+// deleteTag removes a tag from every series that carries it
+func deleteTag(c *gin.Context) {
+	tag := tagStore.Canonical(c.Param("tag"))
+	zapLogger.Info("deleteTag handler called", zap.String("tag", tag))
+
+	updated, ok := rewriteTags(c, func(list []string) []string {
+		var remaining []string
+		for _, t := range list {
+			if t != tag {
+				remaining = append(remaining, t)
+			}
+		}
+		return remaining
+	})
+	if !ok {
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"tag": tag, "updatedSeries": updated})
+}
+
+// rewriteTags applies rewrite to the canonical tags of every series and saves
+// the ones that changed, returning their IDs
+func rewriteTags(c *gin.Context, rewrite func([]string) []string) ([]string, bool) {
+	mangas, err := indexedManga(c)
+	if err != nil {
+		zapLogger.Error("Failed to retrieve manga list", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve manga list: " + err.Error()})
+		return nil, false
+	}
+
+	updated := []string{}
+	for i := range mangas {
+		manga := &mangas[i]
+		before := tagStore.CanonicalList(manga.Tags)
+		after := tagStore.CanonicalList(rewrite(append([]string(nil), before...)))
+		if equalStringSlices(before, after) {
+			continue
+		}
+		manga.Tags = after
+		if !saveManga(c, manga) {
+			return nil, false
+		}
+		updated = append(updated, manga.ID)
+	}
+	return updated, true
+}
+
+// listTagAliases returns the alias table
+func listTagAliases(c *gin.Context) {
+	c.JSON(http.StatusOK, tagStore.Aliases())
+}
+
+// setTagAlias merges an alias into a canonical tag
+func setTagAlias(c *gin.Context) {
+	alias := c.Param("alias")
+
+	var request struct {
+		Canonical string `json:"canonical" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&request); err != nil {
+		zapLogger.Warn("Invalid request data", zap.Error(err))
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request: " + err.Error()})
+		return
+	}
+	zapLogger.Info("setTagAlias handler called", zap.String("alias", alias), zap.String("canonical", request.Canonical))
+
+	if err := tagStore.SetAlias(alias, request.Canonical); err != nil {
+		if models.IsValidationError(err) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		zapLogger.Error("Failed to save tag alias", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save tag alias: " + err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, tagStore.Aliases())
+}
+
+// deleteTagAlias removes an alias so the tag stands on its own again
+func deleteTagAlias(c *gin.Context) {
+	alias := c.Param("alias")
+	zapLogger.Info("deleteTagAlias handler called", zap.String("alias", alias))
+
+	removed, err := tagStore.RemoveAlias(alias)
+	if err != nil {
+		zapLogger.Error("Failed to save tag aliases", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save tag aliases: " + err.Error()})
+		return
+	}
+	if !removed {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Alias not found"})
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+func equalStringSlices(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,148 @@
+package routes
+
+import (
+	"io"
+	"mangahub/backend/importers"
+	"mangahub/backend/models"
+	"mangahub/backend/progress"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// newTrackerMatcher returns a function finding the library series a tracker
+// list entry refers to: by a "mal" or "anilist" custom field holding its
+// tracker ID first, then like imported series, by slug or title
+func newTrackerMatcher(mangas []models.MangaSeries) func(importers.TrackerEntry) *models.MangaSeries {
+	byTrackerID := make(map[string]*models.MangaSeries)
+	for i := range mangas {
+		for _, tracker := range []string{importers.TrackerMAL, importers.TrackerAniList} {
+			if id := mangas[i].CustomFields[tracker]; id != "" {
+				byTrackerID[tracker+":"+id] = &mangas[i]
+			}
+		}
+	}
+	matchSeries := newSeriesMatcher(mangas)
+
+	return func(entry importers.TrackerEntry) *models.MangaSeries {
+		if manga, ok := byTrackerID[entry.Tracker+":"+entry.ID]; ok {
+			return manga
+		}
+		if manga, ok := byTrackerID[importers.TrackerMAL+":"+entry.MALID]; ok && entry.MALID != "" {
+			return manga
+		}
+		return matchSeries(importers.Series{Title: entry.Title, AltTitles: entry.AltTitles})
+	}
+}
+
+// trackerPosition is where a user who read chaptersRead chapters of a series
+// resumes: the first page of the next chapter the library has, or the last
+// chapter read when there is none
+func trackerPosition(manga *models.MangaSeries, chaptersRead float64) (float64, int) {
+	if chaptersRead <= 0 {
+		return 0, 0
+	}
+	chapters, err := metadataManager.ScanForChapters(manga)
+	if err != nil {
+		zapLogger.Warn("Failed to retrieve chapters", zap.String("mangaID", manga.ID), zap.Error(err))
+		return chaptersRead, 1
+	}
+	for i := range chapters {
+		if chapters[i].Number > chaptersRead {
+			return chapters[i].Number, 1
+		}
+	}
+	return chaptersRead, 1
+}
+
+// importTrackerProgress reads a MyAnimeList or AniList list export uploaded
+// in the "file" field and records the status and reading position of every
+// series in it the library has. Local progress that is further along keeps
+// its position and only takes the status. Imported progress isn't counted
+// as reading activity, which would credit years of reading to one day.
+func importTrackerProgress(c *gin.Context) {
+	user := currentUser(c)
+	zapLogger.Info("importTrackerProgress handler called", zap.String("userID", user.ID))
+
+	header, err := c.FormFile("file")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request: missing export file"})
+		return
+	}
+	if header.Size > importers.MaxTrackerExportSize {
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": "Export file is too large"})
+		return
+	}
+	file, err := header.Open()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to read upload: " + err.Error()})
+		return
+	}
+	data, err := io.ReadAll(file)
+	file.Close()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to read upload: " + err.Error()})
+		return
+	}
+
+	entries, err := importers.ParseTrackerExport(data)
+	if err != nil {
+		zapLogger.Warn("Rejected tracker export", zap.Error(err))
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid export file: " + err.Error()})
+		return
+	}
+
+	mangas, err := indexedManga(c)
+	if err != nil {
+		zapLogger.Error("Failed to retrieve manga list", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve manga list: " + err.Error()})
+		return
+	}
+	match := newTrackerMatcher(mangas)
+
+	imported := []gin.H{}
+	unmatched := []string{}
+	for _, e := range entries {
+		manga := match(e)
+		if manga == nil {
+			unmatched = append(unmatched, e.Title)
+			continue
+		}
+
+		chapter, page := trackerPosition(manga, e.ChaptersRead)
+		entry := progress.Entry{
+			UserID:    user.ID,
+			MangaID:   manga.ID,
+			Chapter:   chapter,
+			Page:      page,
+			Completed: e.Status == progress.StatusCompleted,
+			Status:    e.Status,
+		}
+		if existing, ok := progressStore.Get(user.ID, manga.ID); ok && existing.Chapter >= chapter {
+			entry.Chapter, entry.Page = existing.Chapter, existing.Page
+			entry.Completed = entry.Completed || existing.Completed
+			if entry.Status == "" {
+				entry.Status = existing.Status
+			}
+		}
+		if entry, err = progressStore.Set(entry); err != nil {
+			zapLogger.Error("Failed to save progress", zap.Error(err))
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save progress: " + err.Error(), "imported": imported})
+			return
+		}
+		imported = append(imported, gin.H{
+			"title":   e.Title,
+			"mangaId": manga.ID,
+			"chapter": entry.Chapter,
+			"page":    entry.Page,
+			"status":  entry.Status,
+		})
+	}
+
+	zapLogger.Info("Tracker progress imported",
+		zap.String("userID", user.ID),
+		zap.Int("imported", len(imported)),
+		zap.Int("unmatched", len(unmatched)))
+	c.JSON(http.StatusOK, gin.H{"imported": imported, "unmatched": unmatched})
+}
@@ -0,0 +1,89 @@
+package routes
+
+import (
+	"fmt"
+	"mangahub/backend/antivirus"
+	"mangahub/backend/imaging"
+	"mangahub/backend/importers"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// reencodeUploads re-encodes uploaded images rather than only stripping
+// their metadata
+var reencodeUploads bool
+
+// SetReencodeUploads makes uploaded images be decoded and encoded afresh
+func SetReencodeUploads(enabled bool) {
+	reencodeUploads = enabled
+}
+
+// sanitizeUpload checks that an uploaded image is what its extension claims
+// and strips its metadata, or re-encodes it. It returns the cleaned image
+// and the extension to store it under, which changes if re-encoding changed
+// the format.
+func sanitizeUpload(data []byte, ext string) ([]byte, string, error) {
+	format, err := imaging.CheckExtension(data, ext)
+	if err != nil {
+		return nil, "", err
+	}
+	cleaned, cleanedFormat, err := imaging.Sanitize(data, reencodeUploads)
+	if err != nil {
+		return nil, "", err
+	}
+	if cleanedFormat != format {
+		ext = imaging.Extension(cleanedFormat)
+	}
+	return cleaned, ext, nil
+}
+
+// sanitizePages cleans every page image of an uploaded chapter in place.
+// Extraction already named each page after its actual format.
+func sanitizePages(dir string) error {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		if entry.IsDir() || strings.HasPrefix(entry.Name(), ".") || entry.Name() == importers.ComicInfoFileName {
+			continue
+		}
+		path := filepath.Join(dir, entry.Name())
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		cleaned, format, err := imaging.Sanitize(data, reencodeUploads)
+		if err != nil {
+			return fmt.Errorf("%s: %w", entry.Name(), err)
+		}
+		target := strings.TrimSuffix(path, filepath.Ext(path)) + imaging.Extension(format)
+		if err := os.WriteFile(target, cleaned, 0644); err != nil {
+			return err
+		}
+		if target != path {
+			os.Remove(path)
+		}
+	}
+	return nil
+}
+
+// scanned checks the result of scanning an upload with ClamAV, writing an
+// error response if the file is infected or couldn't be scanned
+func scanned(c *gin.Context, err error) bool {
+	switch {
+	case err == nil:
+		return true
+	case antivirus.IsInfected(err):
+		zapLogger.Warn("Rejected infected upload", zap.String("path", c.Request.URL.Path), zap.Error(err))
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": "Upload rejected: the file is " + err.Error()})
+	default:
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Upload could not be scanned for viruses; try again later"})
+	}
+	return false
+}
@@ -0,0 +1,94 @@
+package routes
+
+import (
+	"mangahub/backend/models"
+	"net/http"
+	"os"
+	"path/filepath"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// pageProblem is a page integrity problem found by verifyManga
+type pageProblem struct {
+	ChapterID string  `json:"chapterId"`
+	Number    float64 `json:"number"`
+	models.PageCheck
+}
+
+// verifyManga re-hashes the pages of every chapter of a series and reports
+// files that changed, went missing or appeared since their hashes were
+// recorded. Chapters without recorded hashes get them recorded now. With
+// ?accept=true the current files are recorded as the new baseline after
+// checking, e.g. after pages were replaced on purpose.
+func verifyManga(c *gin.Context) {
+	mangaID := c.Param("id")
+	zapLogger.Info("verifyManga handler called", zap.String("mangaID", mangaID))
+	accept := c.Query("accept") == "true"
+
+	manga, ok := lookupManga(c, mangaID)
+	if !ok {
+		return
+	}
+	chapters, err := metadataManager.ScanForChapters(manga)
+	if err != nil {
+		zapLogger.Error("Failed to retrieve chapters", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve chapters: " + err.Error()})
+		return
+	}
+
+	problems := []pageProblem{}
+	recorded := []float64{}
+	skipped := []float64{}
+	pagesChecked := 0
+	for i := range chapters {
+		chapter := &chapters[i]
+		metadataPath := filepath.Join(chapter.Path, models.MetadataFileName)
+		if _, err := os.Stat(metadataPath); err != nil {
+			// Derived from the folder name; there is nowhere to record hashes
+			skipped = append(skipped, chapter.Number)
+			continue
+		}
+
+		if chapter.PageHashes != nil {
+			checks, checked, err := models.VerifyPages(chapter.Path, chapter.PageHashes)
+			if err != nil {
+				problems = append(problems, pageProblem{chapter.ID, chapter.Number, models.PageCheck{Status: models.PageUnreadable, Error: err.Error()}})
+				continue
+			}
+			pagesChecked += checked
+			for _, check := range checks {
+				problems = append(problems, pageProblem{chapter.ID, chapter.Number, check})
+			}
+			if !accept || len(checks) == 0 {
+				continue
+			}
+		}
+
+		hashes, err := models.HashPages(chapter.Path)
+		if err == nil {
+			chapter.PageHashes = hashes
+			err = chapter.SaveToJSON(metadataPath)
+		}
+		if err != nil {
+			zapLogger.Error("Failed to record page hashes", zap.String("chapterPath", chapter.Path), zap.Error(err))
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to record page hashes: " + err.Error()})
+			return
+		}
+		recorded = append(recorded, chapter.Number)
+	}
+
+	if len(problems) > 0 {
+		zapLogger.Warn("Page integrity problems found", zap.String("mangaID", manga.ID), zap.Int("problems", len(problems)))
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"mangaId":      manga.ID,
+		"ok":           len(problems) == 0,
+		"chapters":     len(chapters),
+		"pagesChecked": pagesChecked,
+		"problems":     problems,
+		"recorded":     recorded,
+		"skipped":      skipped,
+	})
+}
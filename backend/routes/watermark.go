@@ -0,0 +1,164 @@
+package routes
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"image"
+	"mangahub/backend/imaging"
+	"mangahub/backend/models"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// Watermark modes: how downloaded pages are marked with who downloaded them
+const (
+	watermarkVisible = "visible" // Translucent text in a corner of each page
+	watermarkHidden  = "hidden"  // Hidden in the pixels; pages become PNG
+	watermarkBoth    = "both"
+)
+
+var watermarkModes = []string{watermarkVisible, watermarkHidden, watermarkBoth}
+
+// watermarkMode is the configured watermark mode; empty leaves pages as they are
+var watermarkMode string
+
+// maxMarkedImageBytes caps the images the admin can have read for a mark
+const maxMarkedImageBytes = 64 << 20
+
+// SetWatermark marks the pages of downloaded chapters with the downloader
+// and the time: "visible", "hidden", "both", or empty for no watermark
+func SetWatermark(mode string) error {
+	mode = strings.ToLower(strings.TrimSpace(mode))
+	if mode == "" {
+		watermarkMode = ""
+		return nil
+	}
+	for _, m := range watermarkModes {
+		if m == mode {
+			watermarkMode = mode
+			return nil
+		}
+	}
+	return fmt.Errorf("watermark must be one of %s, got %q", strings.Join(watermarkModes, ", "), mode)
+}
+
+// markedPage is a page image with the downloader's watermark
+type markedPage struct {
+	Data     []byte
+	MimeType string
+	Ext      string // File extension of the format it was saved in
+}
+
+// watermarkText says who downloaded a page and when
+func watermarkText(c *gin.Context, now time.Time) string {
+	downloader := "guest " + c.ClientIP()
+	if user := currentUser(c); user != nil {
+		downloader = user.Username
+	}
+	return downloader + " " + now.UTC().Format(time.RFC3339)
+}
+
+// markPage returns a page image with the watermark, or nil when downloads
+// aren't watermarked
+func markPage(path, text string) (*markedPage, error) {
+	if watermarkMode == "" {
+		return nil, nil
+	}
+	release := imaging.AcquireDecode()
+	defer release()
+	img, format, err := imaging.Decode(path)
+	if err != nil {
+		return nil, err
+	}
+
+	page := imaging.RGBA(img)
+	if watermarkMode != watermarkHidden {
+		if err := imaging.Watermark(page, text); err != nil {
+			return nil, err
+		}
+	}
+	if watermarkMode != watermarkVisible {
+		if !imaging.EmbedMark(page, text) {
+			zapLogger.Warn("Page too small for a hidden watermark", zap.String("imagePath", path))
+		}
+		// Lossy formats would lose the mark
+		format = "png"
+	}
+
+	var b bytes.Buffer
+	if err := imaging.Encode(&b, page, format); err != nil {
+		return nil, err
+	}
+	format = imaging.OutputFormat(format)
+	return &markedPage{Data: b.Bytes(), MimeType: "image/" + format, Ext: imaging.Extension(format)}, nil
+}
+
+// markBundlePages watermarks the pages of a chapter bundle, pointing the
+// manifest at the marked images. It returns nil when downloads aren't
+// watermarked.
+func markBundlePages(c *gin.Context, pages []models.Page, manifest *chapterManifest) ([]*markedPage, error) {
+	if watermarkMode == "" {
+		return nil, nil
+	}
+	text := watermarkText(c, timeNow())
+	marked := make([]*markedPage, len(pages))
+	manifest.TotalSize = 0
+	for i := range pages {
+		page, err := markPage(pages[i].ImagePath, text)
+		if err != nil {
+			return nil, err
+		}
+		sum := sha256.Sum256(page.Data)
+		entry := &manifest.Pages[i]
+		entry.Size = int64(len(page.Data))
+		entry.Hash = hex.EncodeToString(sum[:])
+		entry.Type = page.MimeType
+		entry.File = strings.TrimSuffix(entry.File, filepath.Ext(entry.File)) + page.Ext
+		manifest.TotalSize += entry.Size
+		marked[i] = page
+	}
+	return marked, nil
+}
+
+// readWatermark reads the hidden watermark from an uploaded page image, to
+// find out who downloaded a leaked page
+func readWatermark(c *gin.Context) {
+	zapLogger.Info("readWatermark handler called")
+
+	file, err := c.FormFile("image")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request: an image file is required"})
+		return
+	}
+	if file.Size > maxMarkedImageBytes {
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": "Image too large"})
+		return
+	}
+	src, err := file.Open()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to read image: " + err.Error()})
+		return
+	}
+	defer src.Close()
+
+	release := imaging.AcquireDecode()
+	defer release()
+	img, _, err := image.Decode(src)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid image: " + err.Error()})
+		return
+	}
+	mark, found := imaging.ReadMark(img)
+	response := gin.H{"found": found, "file": filepath.Base(file.Filename)}
+	if found {
+		response["mark"] = mark
+	}
+	c.JSON(http.StatusOK, response)
+}
@@ -0,0 +1,140 @@
+package scheduler
+
+import (
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"mangahub/backend/storage"
+
+	"go.uber.org/zap"
+)
+
+const (
+	scheduleFileName = "schedule.json"
+
+	// DefaultInterval is how often the scheduler checks for due entries
+	DefaultInterval = time.Minute
+)
+
+// logger discards output until SetLogger installs the configured logger
+var logger = zap.NewNop()
+
+// SetLogger sets the logger used by this package
+func SetLogger(l *zap.Logger) {
+	logger = l
+}
+
+// Entry is a chapter waiting to be published
+type Entry struct {
+	MangaID     string    `json:"mangaId"`
+	ChapterID   string    `json:"chapterId"`
+	ChapterPath string    `json:"chapterPath"`
+	PublishAt   time.Time `json:"publishAt"`
+}
+
+// Scheduler keeps the pending publish entries in the data directory and
+// hands each one to a callback once its time has come
+type Scheduler struct {
+	path string
+
+	mu      sync.Mutex
+	entries []Entry
+}
+
+// New loads the pending schedule from dataDir
+func New(dataDir string) (*Scheduler, error) {
+	s := &Scheduler{path: filepath.Join(dataDir, scheduleFileName)}
+	if err := storage.LoadJSON(s.path, &s.entries); err != nil {
+		return nil, err
+	}
+	logger.Info("Publish schedule loaded", zap.Int("pendingCount", len(s.entries)))
+	return s, nil
+}
+
+// Schedule adds or replaces the entry for a chapter directory
+func (s *Scheduler) Schedule(entry Entry) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.removeLocked(entry.ChapterPath)
+	s.entries = append(s.entries, entry)
+	sort.Slice(s.entries, func(i, j int) bool {
+		return s.entries[i].PublishAt.Before(s.entries[j].PublishAt)
+	})
+	return storage.SaveJSON(s.path, s.entries)
+}
+
+// Cancel drops the pending entry for a chapter directory, if any
+func (s *Scheduler) Cancel(chapterPath string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !s.removeLocked(chapterPath) {
+		return nil
+	}
+	return storage.SaveJSON(s.path, s.entries)
+}
+
+// Pending returns the entries that have not been published yet, soonest first
+func (s *Scheduler) Pending() []Entry {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]Entry(nil), s.entries...)
+}
+
+// Run checks for due entries every interval until stop is closed. Due entries
+// are removed from the schedule and passed to publish; entries whose publish
+// fails are retried on the next tick.
+func (s *Scheduler) Run(interval time.Duration, stop <-chan struct{}, publish func(Entry) error) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	s.runDue(publish)
+	for {
+		select {
+		case <-ticker.C:
+			s.runDue(publish)
+		case <-stop:
+			return
+		}
+	}
+}
+
+func (s *Scheduler) runDue(publish func(Entry) error) {
+	now := time.Now()
+
+	s.mu.Lock()
+	var due []Entry
+	for _, entry := range s.entries {
+		if !entry.PublishAt.After(now) {
+			due = append(due, entry)
+		}
+	}
+	s.mu.Unlock()
+
+	for _, entry := range due {
+		if err := publish(entry); err != nil {
+			logger.Error("Failed to publish scheduled chapter",
+				zap.String("mangaID", entry.MangaID),
+				zap.String("chapterID", entry.ChapterID),
+				zap.Error(err),
+			)
+			continue
+		}
+		if err := s.Cancel(entry.ChapterPath); err != nil {
+			logger.Error("Failed to update publish schedule", zap.Error(err))
+		}
+	}
+}
+
+func (s *Scheduler) removeLocked(chapterPath string) bool {
+	for i, entry := range s.entries {
+		if entry.ChapterPath == chapterPath {
+			s.entries = append(s.entries[:i], s.entries[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
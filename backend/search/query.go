@@ -0,0 +1,256 @@
+// Package search parses the structured search syntax accepted by /api/search,
+// e.g. `genre:action -genre:romance status:completed year:>2015 "exact phrase"`.
+package search
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"mangahub/backend/models"
+)
+
+// Fields that may prefix a term. Anything else before a colon is treated as
+// plain text, so titles like "Re:Zero" still search as expected.
+var Fields = []string{"title", "author", "artist", "genre", "tag", "status", "year", "publisher", "demographic", "serialization"}
+
+// Term is a single condition of a query
+type Term struct {
+	Field  string // empty for free text
+	Value  string
+	Negate bool
+
+	// Op and Year hold the comparison for year: terms (=, >, >=, <, <=)
+	Op   string
+	Year int
+}
+
+// Query is a parsed search string; a series matches when every term does
+type Query struct {
+	Terms []Term
+}
+
+// ParseError reports a malformed query
+type ParseError struct {
+	Message string
+}
+
+func (e ParseError) Error() string {
+	return fmt.Sprintf("invalid search query: %s", e.Message)
+}
+
+// Parse splits a query string into terms. Bare words and "quoted phrases"
+// are free text; field:value (or field:"quoted value") restricts a field;
+// a leading - negates any term.
+func Parse(q string) (Query, error) {
+	var query Query
+	runes := []rune(q)
+
+	for i := 0; i < len(runes); {
+		if unicode.IsSpace(runes[i]) {
+			i++
+			continue
+		}
+
+		var term Term
+		if runes[i] == '-' && i+1 < len(runes) && !unicode.IsSpace(runes[i+1]) {
+			term.Negate = true
+			i++
+		}
+
+		// field: prefix
+		if j := fieldEnd(runes, i); j > i {
+			if field := strings.ToLower(string(runes[i:j])); isField(field) {
+				term.Field = field
+				i = j + 1
+			}
+		}
+
+		value, next, err := readValue(runes, i)
+		if err != nil {
+			return Query{}, err
+		}
+		i = next
+		term.Value = value
+		if term.Value == "" {
+			if term.Field != "" {
+				return Query{}, ParseError{Message: "missing value for " + term.Field + ":"}
+			}
+			continue
+		}
+
+		if term.Field == "year" {
+			if err := parseYear(&term); err != nil {
+				return Query{}, err
+			}
+		}
+		query.Terms = append(query.Terms, term)
+	}
+	return query, nil
+}
+
+// fieldEnd returns the index of the colon ending a field name at i, or i if
+// there is none
+func fieldEnd(runes []rune, i int) int {
+	for j := i; j < len(runes); j++ {
+		r := runes[j]
+		if r == ':' {
+			return j
+		}
+		if !unicode.IsLetter(r) {
+			break
+		}
+	}
+	return i
+}
+
+// readValue reads a bare word or quoted phrase starting at i
+func readValue(runes []rune, i int) (string, int, error) {
+	if i < len(runes) && runes[i] == '"' {
+		end := i + 1
+		for end < len(runes) && runes[end] != '"' {
+			end++
+		}
+		if end == len(runes) {
+			return "", 0, ParseError{Message: "unterminated quote"}
+		}
+		return string(runes[i+1 : end]), end + 1, nil
+	}
+
+	end := i
+	for end < len(runes) && !unicode.IsSpace(runes[end]) {
+		end++
+	}
+	return string(runes[i:end]), end, nil
+}
+
+func parseYear(term *Term) error {
+	value := term.Value
+	term.Op = "="
+	for _, op := range []string{">=", "<=", ">", "<", "="} {
+		if rest, ok := strings.CutPrefix(value, op); ok {
+			term.Op = op
+			value = rest
+			break
+		}
+	}
+	year, err := strconv.Atoi(value)
+	if err != nil {
+		return ParseError{Message: "year must be a number, optionally prefixed by >, >=, < or <="}
+	}
+	term.Year = year
+	return nil
+}
+
+func isField(name string) bool {
+	for _, f := range Fields {
+		if f == name {
+			return true
+		}
+	}
+	return false
+}
+
+// HasText reports whether the query contains free-text terms
+func (q Query) HasText() bool {
+	for _, t := range q.Terms {
+		if t.Field == "" {
+			return true
+		}
+	}
+	return false
+}
+
+// Matches reports whether a series satisfies every term. tags are the
+// series' canonical tags; tag: values are compared as given, so callers
+// should canonicalize them first (see MapValues).
+func (q Query) Matches(m *models.MangaSeries, tags []string) bool {
+	for _, t := range q.Terms {
+		if t.matches(m, tags) == t.Negate {
+			return false
+		}
+	}
+	return true
+}
+
+// MapValues rewrites the values of all terms on field, e.g. to canonicalize tags
+func (q Query) MapValues(field string, fn func(string) string) {
+	for i := range q.Terms {
+		if q.Terms[i].Field == field {
+			q.Terms[i].Value = fn(q.Terms[i].Value)
+		}
+	}
+}
+
+func (t Term) matches(m *models.MangaSeries, tags []string) bool {
+	switch t.Field {
+	case "":
+		if contains(m.Title, t.Value) || contains(m.Description, t.Value) ||
+			contains(m.Author, t.Value) || contains(m.Artist, t.Value) {
+			return true
+		}
+		for _, alt := range m.AltTitles {
+			if contains(alt, t.Value) {
+				return true
+			}
+		}
+		return false
+	case "title":
+		if contains(m.Title, t.Value) {
+			return true
+		}
+		for _, alt := range m.AltTitles {
+			if contains(alt, t.Value) {
+				return true
+			}
+		}
+		return false
+	case "author":
+		return contains(m.Author, t.Value)
+	case "artist":
+		return contains(m.Artist, t.Value)
+	case "genre":
+		return anyEqual(m.Genres, t.Value)
+	case "tag":
+		return anyEqual(tags, t.Value)
+	case "status":
+		return strings.EqualFold(m.Status, t.Value)
+	case "publisher":
+		return strings.EqualFold(m.Publisher, t.Value)
+	case "demographic":
+		return strings.EqualFold(m.Demographic, t.Value)
+	case "serialization":
+		return contains(m.Serialization, t.Value)
+	case "year":
+		if m.PublishedYear == 0 {
+			return false
+		}
+		switch t.Op {
+		case ">":
+			return m.PublishedYear > t.Year
+		case ">=":
+			return m.PublishedYear >= t.Year
+		case "<":
+			return m.PublishedYear < t.Year
+		case "<=":
+			return m.PublishedYear <= t.Year
+		default:
+			return m.PublishedYear == t.Year
+		}
+	}
+	return false
+}
+
+func contains(s, substr string) bool {
+	return strings.Contains(strings.ToLower(s), strings.ToLower(substr))
+}
+
+func anyEqual(list []string, value string) bool {
+	for _, v := range list {
+		if strings.EqualFold(v, value) {
+			return true
+		}
+	}
+	return false
+}
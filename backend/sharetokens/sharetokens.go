@@ -0,0 +1,169 @@
+// Package sharetokens stores time-limited tokens that let someone without an
+// account read a single series, or a single chapter of it, so an admin can
+// share one series from an otherwise private server.
+package sharetokens
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"mangahub/backend/storage"
+
+	"go.uber.org/zap"
+)
+
+const tokensFileName = "share-tokens.json"
+
+// Prefix starts every share token, telling them apart from login tokens
+const Prefix = "mhs_"
+
+// ErrNotFound is returned for tokens that don't exist
+var ErrNotFound = errors.New("share token not found")
+
+// logger discards output until SetLogger installs the configured logger
+var logger = zap.NewNop()
+
+// SetLogger sets the logger used by this package
+func SetLogger(l *zap.Logger) {
+	logger = l
+}
+
+// Token grants read access to a series until it expires. Chapter is nil when
+// the whole series is shared.
+type Token struct {
+	Token     string    `json:"token"`
+	MangaID   string    `json:"mangaId"`
+	Chapter   *float64  `json:"chapter,omitempty"`
+	Note      string    `json:"note,omitempty"`
+	CreatedBy string    `json:"createdBy,omitempty"`
+	CreatedAt time.Time `json:"createdAt"`
+	ExpiresAt time.Time `json:"expiresAt"`
+}
+
+// Valid reports whether the token still grants access at the given time
+func (t *Token) Valid(now time.Time) bool {
+	return now.Before(t.ExpiresAt)
+}
+
+// Grants reports whether the token grants access to a chapter of its series
+func (t *Token) Grants(chapter float64) bool {
+	return t.Chapter == nil || *t.Chapter == chapter
+}
+
+// Store keeps share tokens in a JSON file in the data directory
+type Store struct {
+	path string
+
+	mu     sync.Mutex
+	tokens map[string]*Token // keyed by token
+}
+
+// NewStore loads the share token store from dataDir
+func NewStore(dataDir string) (*Store, error) {
+	s := &Store{path: filepath.Join(dataDir, tokensFileName), tokens: make(map[string]*Token)}
+	if err := storage.LoadJSON(s.path, &s.tokens); err != nil {
+		return nil, err
+	}
+	logger.Info("Share token store loaded", zap.Int("tokenCount", len(s.tokens)))
+	return s, nil
+}
+
+// Create mints a token for a series, or one chapter of it, valid for ttl
+func (s *Store) Create(mangaID string, chapter *float64, note, createdBy string, ttl time.Duration) (Token, error) {
+	now := time.Now().UTC()
+	token := &Token{
+		Token:     newToken(),
+		MangaID:   mangaID,
+		Chapter:   chapter,
+		Note:      note,
+		CreatedBy: createdBy,
+		CreatedAt: now,
+		ExpiresAt: now.Add(ttl),
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.tokens[token.Token] = token
+	if err := s.saveLocked(); err != nil {
+		delete(s.tokens, token.Token)
+		return Token{}, err
+	}
+	logger.Info("Share token created", zap.String("mangaID", mangaID), zap.String("createdBy", createdBy), zap.Time("expiresAt", token.ExpiresAt))
+	return *token, nil
+}
+
+// Get returns a token that is still valid at the given time
+func (s *Store) Get(token string, now time.Time) (Token, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if t, ok := s.tokens[token]; ok && t.Valid(now) {
+		return *t, true
+	}
+	return Token{}, false
+}
+
+// List returns every token, newest first. Expired tokens older than a day
+// past their expiry are dropped.
+func (s *Store) List(now time.Time) []Token {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	pruned := false
+	list := make([]Token, 0, len(s.tokens))
+	for key, t := range s.tokens {
+		if now.Sub(t.ExpiresAt) > 24*time.Hour {
+			delete(s.tokens, key)
+			pruned = true
+			continue
+		}
+		list = append(list, *t)
+	}
+	if pruned {
+		if err := s.saveLocked(); err != nil {
+			logger.Warn("Failed to prune expired share tokens", zap.Error(err))
+		}
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].CreatedAt.After(list[j].CreatedAt) })
+	return list
+}
+
+// Revoke deletes a token so it stops granting access
+func (s *Store) Revoke(token string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	t, ok := s.tokens[token]
+	if !ok {
+		return ErrNotFound
+	}
+	delete(s.tokens, token)
+	if err := s.saveLocked(); err != nil {
+		s.tokens[token] = t
+		return err
+	}
+	logger.Info("Share token revoked", zap.String("mangaID", t.MangaID))
+	return nil
+}
+
+func (s *Store) saveLocked() error {
+	if err := storage.SaveJSON(s.path, s.tokens); err != nil {
+		logger.Error("Failed to save share tokens", zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+func newToken() string {
+	b := make([]byte, 24)
+	if _, err := rand.Read(b); err != nil {
+		panic("crypto/rand failed: " + err.Error())
+	}
+	return Prefix + hex.EncodeToString(b)
+}
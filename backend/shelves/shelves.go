@@ -0,0 +1,214 @@
+// Package shelves stores the virtual shelves of the browse page. A shelf is
+// either smart, holding every series that matches a search query, or a manual
+// list of series; its contents are worked out from the library index each
+// time it is shown.
+package shelves
+
+import (
+	"errors"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"mangahub/backend/slug"
+	"mangahub/backend/storage"
+
+	"go.uber.org/zap"
+)
+
+const shelvesFileName = "shelves.json"
+
+// Orders a shelf can list its series in
+const (
+	SortTitle   = "title"   // By title
+	SortUpdated = "updated" // Most recently updated first
+	SortRating  = "rating"  // Best rated first
+)
+
+// Sorts are the accepted shelf orders; empty keeps a manual list's own order
+// and sorts smart shelves by title
+var Sorts = []string{SortTitle, SortUpdated, SortRating}
+
+// ErrNotFound is returned for operations on a shelf that doesn't exist
+var ErrNotFound = errors.New("shelf not found")
+
+// logger discards output until SetLogger installs the configured logger
+var logger = zap.NewNop()
+
+// SetLogger sets the logger used by this package
+func SetLogger(l *zap.Logger) {
+	logger = l
+}
+
+// Shelf is a named row of series on the browse page. It is smart when Query
+// is set, in the syntax of package search, and a manual list of MangaIDs
+// otherwise.
+type Shelf struct {
+	ID        string    `json:"id"`
+	Name      string    `json:"name"`
+	Query     string    `json:"query,omitempty"`
+	MangaIDs  []string  `json:"mangaIds,omitempty"`
+	Sort      string    `json:"sort,omitempty"`
+	Limit     int       `json:"limit,omitempty"` // Most series shown; 0 for all
+	Position  int       `json:"position"`        // Shelves are shown in ascending position
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+// Smart reports whether the shelf is filled by its query
+func (s *Shelf) Smart() bool {
+	return strings.TrimSpace(s.Query) != ""
+}
+
+// Store keeps shelves in a JSON file in the data directory
+type Store struct {
+	path string
+
+	mu      sync.RWMutex
+	shelves []*Shelf
+}
+
+// NewStore loads the shelf store from dataDir
+func NewStore(dataDir string) (*Store, error) {
+	s := &Store{path: filepath.Join(dataDir, shelvesFileName)}
+	if err := storage.LoadJSON(s.path, &s.shelves); err != nil {
+		return nil, err
+	}
+	logger.Info("Shelf store loaded", zap.Int("shelfCount", len(s.shelves)))
+	return s, nil
+}
+
+// List returns every shelf in position order, then by name
+func (s *Store) List() []Shelf {
+	s.mu.RLock()
+	list := make([]Shelf, 0, len(s.shelves))
+	for _, shelf := range s.shelves {
+		list = append(list, *shelf)
+	}
+	s.mu.RUnlock()
+
+	sort.Slice(list, func(i, j int) bool {
+		if list[i].Position != list[j].Position {
+			return list[i].Position < list[j].Position
+		}
+		return strings.ToLower(list[i].Name) < strings.ToLower(list[j].Name)
+	})
+	return list
+}
+
+// Get returns one shelf
+func (s *Store) Get(id string) (Shelf, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if shelf := s.findLocked(id); shelf != nil {
+		return *shelf, nil
+	}
+	return Shelf{}, ErrNotFound
+}
+
+// Create adds a shelf, deriving its ID from the name
+func (s *Store) Create(shelf Shelf) (Shelf, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	base := slug.Make(shelf.Name)
+	if base == "" {
+		base = slug.Fallback("shelf", shelf.Name)
+	}
+	id := base
+	for n := 2; s.findLocked(id) != nil; n++ {
+		id = base + "-" + strconv.Itoa(n)
+	}
+
+	now := time.Now().UTC()
+	created := normalize(shelf)
+	created.ID = id
+	created.CreatedAt = now
+	created.UpdatedAt = now
+	s.shelves = append(s.shelves, &created)
+	if err := s.saveLocked(); err != nil {
+		s.shelves = s.shelves[:len(s.shelves)-1]
+		return Shelf{}, err
+	}
+	logger.Info("Shelf created", zap.String("shelfID", id))
+	return created, nil
+}
+
+// Update replaces everything about a shelf but its ID
+func (s *Store) Update(id string, shelf Shelf) (Shelf, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	existing := s.findLocked(id)
+	if existing == nil {
+		return Shelf{}, ErrNotFound
+	}
+	previous := *existing
+	updated := normalize(shelf)
+	updated.ID = id
+	updated.CreatedAt = existing.CreatedAt
+	updated.UpdatedAt = time.Now().UTC()
+	*existing = updated
+	if err := s.saveLocked(); err != nil {
+		*existing = previous
+		return Shelf{}, err
+	}
+	return updated, nil
+}
+
+// Delete removes a shelf, reporting whether it existed
+func (s *Store) Delete(id string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i, shelf := range s.shelves {
+		if shelf.ID == id {
+			s.shelves = append(s.shelves[:i], s.shelves[i+1:]...)
+			return true, s.saveLocked()
+		}
+	}
+	return false, nil
+}
+
+func (s *Store) findLocked(id string) *Shelf {
+	for _, shelf := range s.shelves {
+		if shelf.ID == id {
+			return shelf
+		}
+	}
+	return nil
+}
+
+func (s *Store) saveLocked() error {
+	if err := storage.SaveJSON(s.path, s.shelves); err != nil {
+		logger.Error("Failed to save shelves", zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+// normalize trims a shelf's text and keeps only the fields its kind uses
+func normalize(shelf Shelf) Shelf {
+	shelf.Name = strings.TrimSpace(shelf.Name)
+	shelf.Query = strings.TrimSpace(shelf.Query)
+	shelf.Sort = strings.ToLower(strings.TrimSpace(shelf.Sort))
+	if shelf.Smart() {
+		shelf.MangaIDs = nil
+		return shelf
+	}
+	seen := make(map[string]bool, len(shelf.MangaIDs))
+	ids := []string{}
+	for _, id := range shelf.MangaIDs {
+		if id == "" || seen[id] {
+			continue
+		}
+		seen[id] = true
+		ids = append(ids, id)
+	}
+	shelf.MangaIDs = ids
+	return shelf
+}
@@ -0,0 +1,144 @@
+// Package shortlinks stores short tokens that point at a series, chapter or
+// page, so reading positions can be shared as compact URLs.
+package shortlinks
+
+import (
+	"crypto/rand"
+	"math/big"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"mangahub/backend/storage"
+
+	"go.uber.org/zap"
+)
+
+const (
+	linksFileName = "shortlinks.json"
+
+	// TokenLength is the number of characters in a generated token
+	TokenLength = 8
+
+	tokenAlphabet = "abcdefghijkmnopqrstuvwxyzABCDEFGHJKLMNPQRSTUVWXYZ23456789"
+)
+
+// logger discards output until SetLogger installs the configured logger
+var logger = zap.NewNop()
+
+// SetLogger sets the logger used by this package
+func SetLogger(l *zap.Logger) {
+	logger = l
+}
+
+// Link is a short token and the position it resolves to. Chapter and Page are
+// zero when the link points at the series or the start of a chapter.
+type Link struct {
+	Token     string    `json:"token"`
+	MangaID   string    `json:"mangaId"`
+	Chapter   float64   `json:"chapter,omitempty"`
+	Page      int       `json:"page,omitempty"`
+	CreatedBy string    `json:"createdBy,omitempty"`
+	CreatedAt time.Time `json:"createdAt"`
+	Hits      int       `json:"hits"`
+}
+
+// Store keeps short links in a JSON file in the data directory
+type Store struct {
+	path string
+
+	mu    sync.Mutex
+	links map[string]*Link // keyed by token
+}
+
+// NewStore loads the short link store from dataDir
+func NewStore(dataDir string) (*Store, error) {
+	s := &Store{path: filepath.Join(dataDir, linksFileName), links: make(map[string]*Link)}
+	if err := storage.LoadJSON(s.path, &s.links); err != nil {
+		return nil, err
+	}
+	logger.Info("Short link store loaded", zap.Int("linkCount", len(s.links)))
+	return s, nil
+}
+
+// Create returns a link to the given position. Sharing the same position
+// again returns the existing link rather than minting a new token.
+func (s *Store) Create(mangaID string, chapter float64, page int, createdBy string) (Link, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, link := range s.links {
+		if link.MangaID == mangaID && link.Chapter == chapter && link.Page == page {
+			return *link, nil
+		}
+	}
+
+	token := newToken()
+	for s.links[token] != nil {
+		token = newToken()
+	}
+	link := &Link{
+		Token:     token,
+		MangaID:   mangaID,
+		Chapter:   chapter,
+		Page:      page,
+		CreatedBy: createdBy,
+		CreatedAt: time.Now().UTC(),
+	}
+	s.links[token] = link
+	if err := s.saveLocked(); err != nil {
+		delete(s.links, token)
+		return Link{}, err
+	}
+	return *link, nil
+}
+
+// Get returns the link for a token without counting a visit
+func (s *Store) Get(token string) (Link, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if link, ok := s.links[token]; ok {
+		return *link, true
+	}
+	return Link{}, false
+}
+
+// Resolve returns the link for a token and counts the visit
+func (s *Store) Resolve(token string) (Link, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	link, ok := s.links[token]
+	if !ok {
+		return Link{}, false
+	}
+	link.Hits++
+	if err := s.saveLocked(); err != nil {
+		// The hit count is informational; the link still resolves
+		logger.Warn("Failed to record short link visit", zap.String("token", token), zap.Error(err))
+	}
+	return *link, true
+}
+
+func (s *Store) saveLocked() error {
+	if err := storage.SaveJSON(s.path, s.links); err != nil {
+		logger.Error("Failed to save short links", zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+// newToken returns a random token from an alphabet without look-alike characters
+func newToken() string {
+	b := make([]byte, TokenLength)
+	max := big.NewInt(int64(len(tokenAlphabet)))
+	for i := range b {
+		n, err := rand.Int(rand.Reader, max)
+		if err != nil {
+			panic("crypto/rand failed: " + err.Error())
+		}
+		b[i] = tokenAlphabet[n.Int64()]
+	}
+	return string(b)
+}
@@ -0,0 +1,115 @@
+package slug
+
+import "strings"
+
+// romanizeKana converts hiragana and katakana to Hepburn romaji. Other text
+// passes through unchanged; each run of kana becomes its own word.
+func romanizeKana(s string) string {
+	runes := []rune(s)
+	var b strings.Builder
+	inKana := false
+
+	for i := 0; i < len(runes); i++ {
+		r := toHiragana(runes[i])
+		syllable, isKana := kana[r]
+		if !isKana && r != 'っ' && r != 'ー' {
+			if inKana {
+				b.WriteRune(' ')
+				inKana = false
+			}
+			b.WriteRune(runes[i])
+			continue
+		}
+		if !inKana && b.Len() > 0 {
+			b.WriteRune(' ')
+		}
+		inKana = true
+
+		switch r {
+		case 'っ':
+			// Sokuon doubles the next consonant
+			if i+1 < len(runes) {
+				if next, ok := kana[toHiragana(runes[i+1])]; ok && next != "" {
+					b.WriteByte(next[0])
+				}
+			}
+			continue
+		case 'ー':
+			// The long vowel mark is dropped, as in most romanized titles
+			continue
+		}
+
+		// Combine with a following small ya/yu/yo: き+ゃ -> kya, し+ゃ -> sha
+		if i+1 < len(runes) {
+			if small, ok := smallY[toHiragana(runes[i+1])]; ok && strings.HasSuffix(syllable, "i") {
+				stem := strings.TrimSuffix(syllable, "i")
+				if stem == "sh" || stem == "ch" || stem == "j" {
+					syllable = stem + small[1:]
+				} else {
+					syllable = stem + small
+				}
+				i++
+			}
+		}
+		// Combine with a following small vowel: チ+ェ -> che, フ+ァ -> fa, テ+ィ -> ti
+		if i+1 < len(runes) {
+			if vowel, ok := smallVowel[toHiragana(runes[i+1])]; ok {
+				if combined, ok := combineSmallVowel(syllable, vowel); ok {
+					syllable = combined
+					i++
+				}
+			}
+		}
+		b.WriteString(syllable)
+	}
+	return b.String()
+}
+
+// combineSmallVowel merges a syllable with a following small vowel kana
+func combineSmallVowel(syllable, vowel string) (string, bool) {
+	switch syllable {
+	case "shi", "chi", "ji":
+		return strings.TrimSuffix(syllable, "i") + vowel, true
+	case "fu", "vu":
+		return syllable[:1] + vowel, true
+	case "te", "de":
+		return syllable[:1] + vowel, true
+	case "u":
+		return "w" + vowel, true
+	case "tsu":
+		return "ts" + vowel, true
+	}
+	return "", false
+}
+
+// toHiragana maps katakana to the matching hiragana
+func toHiragana(r rune) rune {
+	if r >= 'ァ' && r <= 'ヶ' {
+		return r - ('ァ' - 'ぁ')
+	}
+	return r
+}
+
+var smallY = map[rune]string{'ゃ': "ya", 'ゅ': "yu", 'ょ': "yo"}
+
+var smallVowel = map[rune]string{'ぁ': "a", 'ぃ': "i", 'ぅ': "u", 'ぇ': "e", 'ぉ': "o"}
+
+var kana = map[rune]string{
+	'あ': "a", 'い': "i", 'う': "u", 'え': "e", 'お': "o",
+	'か': "ka", 'き': "ki", 'く': "ku", 'け': "ke", 'こ': "ko",
+	'が': "ga", 'ぎ': "gi", 'ぐ': "gu", 'げ': "ge", 'ご': "go",
+	'さ': "sa", 'し': "shi", 'す': "su", 'せ': "se", 'そ': "so",
+	'ざ': "za", 'じ': "ji", 'ず': "zu", 'ぜ': "ze", 'ぞ': "zo",
+	'た': "ta", 'ち': "chi", 'つ': "tsu", 'て': "te", 'と': "to",
+	'だ': "da", 'ぢ': "ji", 'づ': "zu", 'で': "de", 'ど': "do",
+	'な': "na", 'に': "ni", 'ぬ': "nu", 'ね': "ne", 'の': "no",
+	'は': "ha", 'ひ': "hi", 'ふ': "fu", 'へ': "he", 'ほ': "ho",
+	'ば': "ba", 'び': "bi", 'ぶ': "bu", 'べ': "be", 'ぼ': "bo",
+	'ぱ': "pa", 'ぴ': "pi", 'ぷ': "pu", 'ぺ': "pe", 'ぽ': "po",
+	'ま': "ma", 'み': "mi", 'む': "mu", 'め': "me", 'も': "mo",
+	'や': "ya", 'ゆ': "yu", 'よ': "yo",
+	'ら': "ra", 'り': "ri", 'る': "ru", 'れ': "re", 'ろ': "ro",
+	'わ': "wa", 'ゐ': "i", 'ゑ': "e", 'を': "o", 'ん': "n", 'ゔ': "vu",
+	'ぁ': "a", 'ぃ': "i", 'ぅ': "u", 'ぇ': "e", 'ぉ': "o",
+	'ゃ': "ya", 'ゅ': "yu", 'ょ': "yo", 'ゎ': "wa",
+}
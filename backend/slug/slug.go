@@ -0,0 +1,104 @@
+// Package slug turns titles into URL- and filesystem-safe identifiers,
+// transliterating common non-ASCII scripts instead of dropping them.
+package slug
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// Make returns the slug for s: lowercase ASCII letters, digits and single
+// hyphens. Accented Latin letters lose their accents, and kana and Cyrillic
+// are romanized; characters with no transliteration (e.g. kanji) are dropped.
+func Make(s string) string {
+	s = transliterate(s)
+
+	var b strings.Builder
+	for _, r := range strings.ToLower(s) {
+		switch {
+		case r >= 'a' && r <= 'z', r >= '0' && r <= '9', r == '-':
+			b.WriteRune(r)
+		case r == ' ':
+			b.WriteRune('-')
+		}
+	}
+
+	slug := b.String()
+	for strings.Contains(slug, "--") {
+		slug = strings.ReplaceAll(slug, "--", "-")
+	}
+	return strings.Trim(slug, "-")
+}
+
+// Fallback returns a stable identifier for titles that produce an empty
+// slug, derived from a hash of the title
+func Fallback(prefix, s string) string {
+	sum := sha256.Sum256([]byte(s))
+	return prefix + "-" + hex.EncodeToString(sum[:4])
+}
+
+// Complete reports whether every letter and digit of s survives in its slug,
+// i.e. nothing was dropped for lack of a transliteration
+func Complete(s string) bool {
+	for _, r := range transliterate(s) {
+		if r >= unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r)) {
+			return false
+		}
+	}
+	return true
+}
+
+// Valid reports whether s is already a well-formed slug
+func Valid(s string) bool {
+	return s != "" && Make(s) == s
+}
+
+// transliterate rewrites non-ASCII text into its closest ASCII spelling
+func transliterate(s string) string {
+	s = romanizeKana(s)
+
+	var b strings.Builder
+	for _, r := range norm.NFKD.String(s) {
+		if r < unicode.MaxASCII {
+			b.WriteRune(r)
+			continue
+		}
+		if unicode.Is(unicode.Mn, r) {
+			// Combining accents split off by NFKD
+			continue
+		}
+		if latin, ok := specialLatin[r]; ok {
+			b.WriteString(latin)
+			continue
+		}
+		if cyr, ok := cyrillic[unicode.ToLower(r)]; ok {
+			b.WriteString(cyr)
+			continue
+		}
+		if unicode.IsSpace(r) || unicode.IsPunct(r) {
+			b.WriteRune(' ')
+			continue
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// specialLatin covers letters NFKD does not decompose into ASCII
+var specialLatin = map[rune]string{
+	'ß': "ss", 'æ': "ae", 'Æ': "ae", 'œ': "oe", 'Œ': "oe", 'ø': "o", 'Ø': "o",
+	'ł': "l", 'Ł': "l", 'đ': "d", 'Đ': "d", 'ð': "d", 'Ð': "d", 'þ': "th", 'Þ': "th",
+	'ı': "i",
+}
+
+var cyrillic = map[rune]string{
+	'а': "a", 'б': "b", 'в': "v", 'г': "g", 'д': "d", 'е': "e", 'ё': "yo", 'ж': "zh",
+	'з': "z", 'и': "i", 'й': "y", 'к': "k", 'л': "l", 'м': "m", 'н': "n", 'о': "o",
+	'п': "p", 'р': "r", 'с': "s", 'т': "t", 'у': "u", 'ф': "f", 'х': "kh", 'ц': "ts",
+	'ч': "ch", 'ш': "sh", 'щ': "shch", 'ъ': "", 'ы': "y", 'ь': "", 'э': "e", 'ю': "yu",
+	'я': "ya", 'і': "i", 'ї': "yi", 'є': "ye", 'ґ': "g",
+}
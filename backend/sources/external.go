@@ -0,0 +1,314 @@
+package sources
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// External plugins are executables in the sources directory. Each is started
+// once and kept running; MangaHub writes one JSON request per line to its
+// stdin and reads one JSON response per line from its stdout:
+//
+//	{"id": 1, "method": "search", "params": {"query": "berserk", "page": 1}}
+//	{"id": 1, "result": [{"id": "123", "title": "Berserk"}]}
+//
+// Methods are "info" (no params, result Info), "search" (query, page; result
+// []Series), "chapters" (seriesId; result []Chapter) and "pages" (chapterId;
+// result []Page). A failed call answers {"id": 1, "error": "message"}.
+// Anything the plugin writes to stderr is logged. The plugin should exit
+// when its stdin is closed.
+
+// callTimeout bounds a single call to an external plugin
+const callTimeout = 2 * time.Minute
+
+// maxResponseSize caps a single response line from an external plugin
+const maxResponseSize = 16 << 20
+
+// PluginError is an error reported by a source, as opposed to a failure to
+// talk to it
+type PluginError struct {
+	Source  string
+	Message string
+}
+
+func (e *PluginError) Error() string {
+	return fmt.Sprintf("source %s: %s", e.Source, e.Message)
+}
+
+// IsPluginError checks if an error is a PluginError
+func IsPluginError(err error) bool {
+	var pluginErr *PluginError
+	return errors.As(err, &pluginErr)
+}
+
+type request struct {
+	ID     int         `json:"id"`
+	Method string      `json:"method"`
+	Params interface{} `json:"params,omitempty"`
+}
+
+type response struct {
+	ID     int             `json:"id"`
+	Result json.RawMessage `json:"result"`
+	Error  string          `json:"error"`
+}
+
+// External is a source implemented by an external process
+type External struct {
+	path string
+	info Info
+
+	mu     sync.Mutex // Serialises calls; the protocol has one request in flight
+	cmd    *exec.Cmd
+	stdin  io.WriteCloser
+	stdout *bufio.Reader
+	nextID int
+}
+
+// NewExternal starts the plugin at path and asks it to describe itself. A
+// plugin that reports no ID is named after its file.
+func NewExternal(path string) (*External, error) {
+	e := &External{path: path}
+
+	ctx, cancel := context.WithTimeout(context.Background(), callTimeout)
+	defer cancel()
+	if err := e.call(ctx, "info", nil, &e.info); err != nil {
+		e.Close()
+		return nil, err
+	}
+	if e.info.ID == "" {
+		e.info.ID = strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	}
+	if e.info.Name == "" {
+		e.info.Name = e.info.ID
+	}
+	e.info.External = true
+	return e, nil
+}
+
+// Info describes the plugin as it reported itself at startup
+func (e *External) Info() Info {
+	return e.info
+}
+
+// Search implements Source
+func (e *External) Search(ctx context.Context, query string, page int) ([]Series, error) {
+	var results []Series
+	params := map[string]interface{}{"query": query, "page": page}
+	if err := e.call(ctx, "search", params, &results); err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+// Chapters implements Source
+func (e *External) Chapters(ctx context.Context, seriesID string) ([]Chapter, error) {
+	var chapters []Chapter
+	if err := e.call(ctx, "chapters", map[string]string{"seriesId": seriesID}, &chapters); err != nil {
+		return nil, err
+	}
+	return chapters, nil
+}
+
+// Pages implements Source
+func (e *External) Pages(ctx context.Context, chapterID string) ([]Page, error) {
+	var pages []Page
+	if err := e.call(ctx, "pages", map[string]string{"chapterId": chapterID}, &pages); err != nil {
+		return nil, err
+	}
+	return pages, nil
+}
+
+// Close stops the plugin process
+func (e *External) Close() {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.stopLocked()
+}
+
+// call sends one request and decodes the result into v. The process is
+// started on demand, and stopped whenever a call fails other than with an
+// error reported by the plugin, so that the next call starts afresh.
+func (e *External) call(ctx context.Context, method string, params, v interface{}) error {
+	ctx, cancel := context.WithTimeout(ctx, callTimeout)
+	defer cancel()
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if e.cmd == nil {
+		if err := e.startLocked(); err != nil {
+			return err
+		}
+	}
+
+	e.nextID++
+	line, err := json.Marshal(request{ID: e.nextID, Method: method, Params: params})
+	if err != nil {
+		return err
+	}
+	if _, err := e.stdin.Write(append(line, '\n')); err != nil {
+		e.stopLocked()
+		return fmt.Errorf("writing to %s: %w", e.path, err)
+	}
+
+	type readResult struct {
+		line []byte
+		err  error
+	}
+	read := make(chan readResult, 1)
+	stdout := e.stdout
+	go func() {
+		line, err := readLine(stdout)
+		read <- readResult{line, err}
+	}()
+
+	var got readResult
+	select {
+	case got = <-read:
+	case <-ctx.Done():
+		// Killing the process unblocks the reader
+		e.stopLocked()
+		return ctx.Err()
+	}
+	if got.err != nil {
+		e.stopLocked()
+		return fmt.Errorf("reading from %s: %w", e.path, got.err)
+	}
+
+	var resp response
+	if err := json.Unmarshal(got.line, &resp); err != nil || resp.ID != e.nextID {
+		e.stopLocked()
+		return fmt.Errorf("%s sent an invalid response to %s", e.path, method)
+	}
+	if resp.Error != "" {
+		return &PluginError{Source: e.info.ID, Message: resp.Error}
+	}
+	if v == nil || len(resp.Result) == 0 {
+		return nil
+	}
+	if err := json.Unmarshal(resp.Result, v); err != nil {
+		return fmt.Errorf("decoding %s result from %s: %w", method, e.path, err)
+	}
+	return nil
+}
+
+func (e *External) startLocked() error {
+	cmd := exec.Command(e.path)
+	cmd.Dir = filepath.Dir(e.path)
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return err
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return err
+	}
+	stderr, err := cmd.StderrPipe()
+	if err != nil {
+		return err
+	}
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("starting %s: %w", e.path, err)
+	}
+
+	go func() {
+		scanner := bufio.NewScanner(stderr)
+		for scanner.Scan() {
+			logger.Info("Source plugin output", zap.String("plugin", e.path), zap.String("line", scanner.Text()))
+		}
+	}()
+
+	e.cmd = cmd
+	e.stdin = stdin
+	e.stdout = bufio.NewReader(stdout)
+	logger.Info("Source plugin started", zap.String("plugin", e.path), zap.Int("pid", cmd.Process.Pid))
+	return nil
+}
+
+func (e *External) stopLocked() {
+	if e.cmd == nil {
+		return
+	}
+	e.stdin.Close()
+	e.cmd.Process.Kill()
+	e.cmd.Wait()
+	logger.Info("Source plugin stopped", zap.String("plugin", e.path))
+	e.cmd = nil
+	e.stdin = nil
+	e.stdout = nil
+}
+
+// readLine reads one newline-terminated line of at most maxResponseSize bytes
+func readLine(r *bufio.Reader) ([]byte, error) {
+	var line []byte
+	for {
+		chunk, isPrefix, err := r.ReadLine()
+		if err != nil {
+			return nil, err
+		}
+		line = append(line, chunk...)
+		if len(line) > maxResponseSize {
+			return nil, fmt.Errorf("response larger than %d bytes", maxResponseSize)
+		}
+		if !isPrefix {
+			return line, nil
+		}
+	}
+}
+
+// LoadExternal starts and registers every executable in dir as an external
+// source. A missing directory is not an error. Plugins that fail to start
+// are skipped and their errors returned together.
+func LoadExternal(dir string) ([]Info, error) {
+	entries, err := os.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var loaded []Info
+	var errs []error
+	for _, entry := range entries {
+		if entry.IsDir() || strings.HasPrefix(entry.Name(), ".") {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil || info.Mode()&0111 == 0 {
+			continue
+		}
+
+		path, err := filepath.Abs(filepath.Join(dir, entry.Name()))
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		source, err := NewExternal(path)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if _, err := Get(source.Info().ID); err == nil {
+			source.Close()
+			errs = append(errs, fmt.Errorf("%s: source %q is already registered", path, source.Info().ID))
+			continue
+		}
+		Register(source)
+		loaded = append(loaded, source.Info())
+	}
+	return loaded, errors.Join(errs...)
+}
@@ -0,0 +1,135 @@
+// Package sources lets MangaHub download chapters from external sites through
+// pluggable sources. A source searches a remote catalog, lists the chapters of
+// a series and resolves the page images of a chapter.
+//
+// Sources are either compiled in, by a package that calls Register from its
+// init function and is blank-imported by main, or external executables that
+// speak JSON over stdio (see LoadExternal).
+package sources
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// logger discards output until SetLogger installs the configured logger
+var logger = zap.NewNop()
+
+// SetLogger sets the logger used by this package
+func SetLogger(l *zap.Logger) {
+	logger = l
+}
+
+// ErrNotFound is returned by Get for an unregistered source
+var ErrNotFound = errors.New("source not found")
+
+// Info describes a source
+type Info struct {
+	ID        string   `json:"id"`
+	Name      string   `json:"name"`
+	Version   string   `json:"version,omitempty"`
+	BaseURL   string   `json:"baseUrl,omitempty"`
+	Languages []string `json:"languages,omitempty"`
+	External  bool     `json:"external"` // Runs as an external process
+}
+
+// Series is one search result from a source's catalog
+type Series struct {
+	ID          string   `json:"id"` // Opaque to MangaHub; passed back to Chapters
+	Title       string   `json:"title"`
+	AltTitles   []string `json:"altTitles,omitempty"`
+	Description string   `json:"description,omitempty"`
+	CoverURL    string   `json:"coverUrl,omitempty"`
+	Author      string   `json:"author,omitempty"`
+	Artist      string   `json:"artist,omitempty"`
+	Status      string   `json:"status,omitempty"`
+	Genres      []string `json:"genres,omitempty"`
+	URL         string   `json:"url,omitempty"`
+}
+
+// Chapter is one chapter of a series on a source
+type Chapter struct {
+	ID          string     `json:"id"` // Opaque to MangaHub; passed back to Pages
+	Number      float64    `json:"number"`
+	Title       string     `json:"title,omitempty"`
+	Volume      int        `json:"volume,omitempty"`
+	URL         string     `json:"url,omitempty"`
+	PublishedAt *time.Time `json:"publishedAt,omitempty"`
+}
+
+// Page is one page image of a chapter, with any headers (e.g. Referer) the
+// image host requires
+type Page struct {
+	Number  int               `json:"number"`
+	URL     string            `json:"url"`
+	Headers map[string]string `json:"headers,omitempty"`
+}
+
+// Source is a site chapters can be downloaded from
+type Source interface {
+	Info() Info
+
+	// Search returns one page (1-based) of catalog results for query
+	Search(ctx context.Context, query string, page int) ([]Series, error)
+
+	// Chapters lists the chapters of a series returned by Search
+	Chapters(ctx context.Context, seriesID string) ([]Chapter, error)
+
+	// Pages resolves the page images of a chapter returned by Chapters, in
+	// reading order
+	Pages(ctx context.Context, chapterID string) ([]Page, error)
+}
+
+var (
+	registryMu sync.RWMutex
+	registry   = make(map[string]Source)
+)
+
+// Register makes a source available by its ID. It panics if the ID is empty
+// or already registered, as for a duplicate database/sql driver.
+func Register(source Source) {
+	id := source.Info().ID
+	if id == "" {
+		panic("sources: Register called with an empty source ID")
+	}
+
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	if _, dup := registry[id]; dup {
+		panic(fmt.Sprintf("sources: Register called twice for source %q", id))
+	}
+	registry[id] = source
+	logger.Info("Source registered", zap.String("source", id))
+}
+
+// Get returns the source registered under id
+func Get(id string) (Source, error) {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+
+	if source, ok := registry[id]; ok {
+		return source, nil
+	}
+	return nil, ErrNotFound
+}
+
+// List returns the info of every registered source, ordered by ID
+func List() []Info {
+	registryMu.RLock()
+	list := make([]Info, 0, len(registry))
+	for _, source := range registry {
+		list = append(list, source.Info())
+	}
+	registryMu.RUnlock()
+
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].ID < list[j].ID
+	})
+	return list
+}
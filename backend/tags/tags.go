@@ -0,0 +1,162 @@
+package tags
+
+import (
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"mangahub/backend/models"
+	"mangahub/backend/storage"
+
+	"go.uber.org/zap"
+)
+
+const aliasesFileName = "tag-aliases.json"
+
+// logger discards output until SetLogger installs the configured logger
+var logger = zap.NewNop()
+
+// SetLogger sets the logger used by this package
+func SetLogger(l *zap.Logger) {
+	logger = l
+}
+
+// Normalize trims and lower-cases a tag and collapses inner whitespace
+func Normalize(tag string) string {
+	return strings.Join(strings.Fields(strings.ToLower(tag)), " ")
+}
+
+// Store holds the admin-managed alias table that merges equivalent tags,
+// e.g. "science fiction" -> "sci-fi"
+type Store struct {
+	path string
+
+	mu      sync.RWMutex
+	aliases map[string]string // normalized alias -> normalized canonical tag
+}
+
+// NewStore loads the alias table from dataDir
+func NewStore(dataDir string) (*Store, error) {
+	s := &Store{
+		path:    filepath.Join(dataDir, aliasesFileName),
+		aliases: make(map[string]string),
+	}
+	if err := storage.LoadJSON(s.path, &s.aliases); err != nil {
+		return nil, err
+	}
+	logger.Info("Tag aliases loaded", zap.Int("aliasCount", len(s.aliases)))
+	return s, nil
+}
+
+// Canonical returns the canonical form of a tag after applying aliases
+func (s *Store) Canonical(tag string) string {
+	tag = Normalize(tag)
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if canonical, ok := s.aliases[tag]; ok {
+		return canonical
+	}
+	return tag
+}
+
+// CanonicalList canonicalizes a list of tags, dropping empties and duplicates
+func (s *Store) CanonicalList(list []string) []string {
+	seen := make(map[string]bool, len(list))
+	var result []string
+	for _, tag := range list {
+		canonical := s.Canonical(tag)
+		if canonical == "" || seen[canonical] {
+			continue
+		}
+		seen[canonical] = true
+		result = append(result, canonical)
+	}
+	return result
+}
+
+// Aliases returns a copy of the alias table
+func (s *Store) Aliases() map[string]string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	copied := make(map[string]string, len(s.aliases))
+	for alias, canonical := range s.aliases {
+		copied[alias] = canonical
+	}
+	return copied
+}
+
+// SetAlias maps alias onto canonical. Aliases pointing at the old alias are
+// redirected so lookups never need more than one hop.
+func (s *Store) SetAlias(alias, canonical string) error {
+	alias, canonical = Normalize(alias), Normalize(canonical)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if target, ok := s.aliases[canonical]; ok {
+		canonical = target
+	}
+	if alias == "" || canonical == "" || alias == canonical {
+		return models.NewValidationError("alias and canonical tag must be different, non-empty tags")
+	}
+
+	s.aliases[alias] = canonical
+	for a, c := range s.aliases {
+		if c == alias {
+			s.aliases[a] = canonical
+		}
+	}
+	return s.saveLocked()
+}
+
+// RemoveAlias deletes an alias, reporting whether it existed
+func (s *Store) RemoveAlias(alias string) (bool, error) {
+	alias = Normalize(alias)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.aliases[alias]; !ok {
+		return false, nil
+	}
+	delete(s.aliases, alias)
+	return true, s.saveLocked()
+}
+
+func (s *Store) saveLocked() error {
+	if err := storage.SaveJSON(s.path, s.aliases); err != nil {
+		logger.Error("Failed to save tag aliases", zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+// Count is a tag with the number of series carrying it
+type Count struct {
+	Tag   string `json:"tag"`
+	Count int    `json:"count"`
+}
+
+// Catalog counts canonical tags across the given per-series tag lists,
+// ordered by descending count and then name
+func (s *Store) Catalog(seriesTags [][]string) []Count {
+	counts := make(map[string]int)
+	for _, list := range seriesTags {
+		for _, tag := range s.CanonicalList(list) {
+			counts[tag]++
+		}
+	}
+
+	catalog := make([]Count, 0, len(counts))
+	for tag, count := range counts {
+		catalog = append(catalog, Count{Tag: tag, Count: count})
+	}
+	sort.Slice(catalog, func(i, j int) bool {
+		if catalog[i].Count != catalog[j].Count {
+			return catalog[i].Count > catalog[j].Count
+		}
+		return catalog[i].Tag < catalog[j].Tag
+	})
+	return catalog
+}
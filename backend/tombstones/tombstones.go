@@ -0,0 +1,116 @@
+// Package tombstones records the series and chapters removed from the
+// library, so sync clients can reconcile deletions instead of only
+// discovering additions.
+package tombstones
+
+import (
+	"path/filepath"
+	"sync"
+	"time"
+
+	"mangahub/backend/storage"
+
+	"go.uber.org/zap"
+)
+
+const fileName = "tombstones.json"
+
+// MaxAge is how long tombstones are kept. Clients that last synced before
+// the oldest one still kept need a full sync.
+const MaxAge = 90 * 24 * time.Hour
+
+// Kinds of removed content
+const (
+	KindSeries  = "series"
+	KindChapter = "chapter"
+)
+
+// logger discards output until SetLogger installs the configured logger
+var logger = zap.NewNop()
+
+// SetLogger sets the logger used by this package
+func SetLogger(l *zap.Logger) {
+	logger = l
+}
+
+// Tombstone records one removed series or chapter
+type Tombstone struct {
+	Kind      string    `json:"kind"`
+	MangaID   string    `json:"mangaId"`
+	ChapterID string    `json:"chapterId,omitempty"`
+	Number    float64   `json:"number,omitempty"`  // Chapter number
+	MovedTo   string    `json:"movedTo,omitempty"` // Series that took the content over, for merges and moves
+	Owner     string    `json:"owner,omitempty"`   // Owner of a personal series
+	DeletedAt time.Time `json:"deletedAt"`
+}
+
+// file is the on-disk form of the store
+type file struct {
+	PrunedBefore time.Time   `json:"prunedBefore"`
+	Tombstones   []Tombstone `json:"tombstones"`
+}
+
+// Store keeps tombstones in the order they were added
+type Store struct {
+	path string
+
+	mu   sync.RWMutex
+	data file
+}
+
+// NewStore loads the tombstones from dataDir
+func NewStore(dataDir string) (*Store, error) {
+	s := &Store{path: filepath.Join(dataDir, fileName)}
+	if err := storage.LoadJSON(s.path, &s.data); err != nil {
+		return nil, err
+	}
+	logger.Info("Tombstones loaded", zap.Int("tombstoneCount", len(s.data.Tombstones)))
+	return s, nil
+}
+
+// Add records a removal, stamping it with the current time, and drops
+// tombstones older than MaxAge
+func (s *Store) Add(t Tombstone) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now().UTC()
+	t.DeletedAt = now
+	s.data.Tombstones = append(s.data.Tombstones, t)
+
+	horizon := now.Add(-MaxAge)
+	kept := s.data.Tombstones[:0]
+	for _, old := range s.data.Tombstones {
+		if old.DeletedAt.After(horizon) {
+			kept = append(kept, old)
+		} else if old.DeletedAt.After(s.data.PrunedBefore) {
+			s.data.PrunedBefore = old.DeletedAt
+		}
+	}
+	s.data.Tombstones = kept
+
+	if err := storage.SaveJSON(s.path, s.data); err != nil {
+		logger.Error("Failed to save tombstones", zap.Error(err))
+		return err
+	}
+	logger.Info("Tombstone recorded",
+		zap.String("kind", t.Kind),
+		zap.String("mangaID", t.MangaID),
+		zap.String("chapterID", t.ChapterID))
+	return nil
+}
+
+// Since returns the tombstones recorded after since, oldest first. complete
+// is false when tombstones that may matter to since were already pruned.
+func (s *Store) Since(since time.Time) (tombstones []Tombstone, complete bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	tombstones = []Tombstone{}
+	for _, t := range s.data.Tombstones {
+		if t.DeletedAt.After(since) {
+			tombstones = append(tombstones, t)
+		}
+	}
+	return tombstones, !since.Before(s.data.PrunedBefore)
+}
@@ -0,0 +1,186 @@
+package users
+
+import (
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"mangahub/backend/storage"
+
+	"go.uber.org/zap"
+)
+
+const (
+	apiTokensFileName = "api-tokens.json"
+
+	// APITokenPrefix starts every personal API token, telling them apart
+	// from login tokens
+	APITokenPrefix = "mhp_"
+)
+
+// Scopes a personal API token can be limited to
+const (
+	ScopeRead     = "read"     // Browse and read the library
+	ScopeProgress = "progress" // Read and update reading progress
+)
+
+// Scopes are the accepted values of APIToken.Scopes
+var Scopes = []string{ScopeRead, ScopeProgress}
+
+// APIToken is a long-lived personal token for readers and scripts. It is
+// independent of login sessions and only grants its scopes. Only a hash of
+// the secret is stored.
+type APIToken struct {
+	ID        string     `json:"id"`
+	UserID    string     `json:"userId"`
+	Name      string     `json:"name"`
+	Scopes    []string   `json:"scopes"`
+	Hash      string     `json:"hash"`
+	CreatedAt time.Time  `json:"createdAt"`
+	LastUsed  *time.Time `json:"lastUsed,omitempty"`
+	ExpiresAt *time.Time `json:"expiresAt,omitempty"`
+}
+
+// HasScope reports whether the token grants a scope
+func (t *APIToken) HasScope(scope string) bool {
+	for _, s := range t.Scopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
+
+// CreateAPIToken mints a personal token and returns it along with its
+// secret, which is not stored and can't be shown again. A zero ttl never
+// expires.
+func (s *Store) CreateAPIToken(userID, name string, scopes []string, ttl time.Duration) (APIToken, string, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return APIToken{}, "", NewValidationError("name is required")
+	}
+	if len(scopes) == 0 {
+		return APIToken{}, "", NewValidationError("at least one scope is required")
+	}
+	for _, scope := range scopes {
+		if !validScope(scope) {
+			return APIToken{}, "", NewValidationError("scopes must be among " + strings.Join(Scopes, ", "))
+		}
+	}
+	if ttl < 0 {
+		return APIToken{}, "", NewValidationError("expiry must not be negative")
+	}
+	if _, err := s.Get(userID); err != nil {
+		return APIToken{}, "", err
+	}
+
+	secret := APITokenPrefix + randomID(24)
+	token := &APIToken{
+		ID:        randomID(8),
+		UserID:    userID,
+		Name:      name,
+		Scopes:    scopes,
+		Hash:      hashToken(secret),
+		CreatedAt: time.Now().UTC(),
+	}
+	if ttl > 0 {
+		expiresAt := token.CreatedAt.Add(ttl)
+		token.ExpiresAt = &expiresAt
+	}
+
+	s.tokensMu.Lock()
+	defer s.tokensMu.Unlock()
+
+	s.apiTokens[token.ID] = token
+	if err := s.saveAPITokensLocked(); err != nil {
+		delete(s.apiTokens, token.ID)
+		return APIToken{}, "", err
+	}
+	logger.Info("API token created", zap.String("userID", userID), zap.String("tokenID", token.ID), zap.Strings("scopes", scopes))
+	return *token, secret, nil
+}
+
+// APITokens returns a user's personal tokens, newest first
+func (s *Store) APITokens(userID string) []APIToken {
+	s.tokensMu.Lock()
+	defer s.tokensMu.Unlock()
+
+	list := []APIToken{}
+	for _, token := range s.apiTokens {
+		if token.UserID == userID {
+			list = append(list, *token)
+		}
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].CreatedAt.After(list[j].CreatedAt) })
+	return list
+}
+
+// RevokeAPIToken deletes one of a user's personal tokens
+func (s *Store) RevokeAPIToken(userID, tokenID string) error {
+	s.tokensMu.Lock()
+	defer s.tokensMu.Unlock()
+
+	token, ok := s.apiTokens[tokenID]
+	if !ok || token.UserID != userID {
+		return NewUserNotFoundError("no API token with ID: " + tokenID)
+	}
+	delete(s.apiTokens, tokenID)
+	if err := s.saveAPITokensLocked(); err != nil {
+		s.apiTokens[tokenID] = token
+		return err
+	}
+	logger.Info("API token revoked", zap.String("userID", userID), zap.String("tokenID", tokenID))
+	return nil
+}
+
+// UserForAPIToken checks a personal token and returns its user and the
+// token, which is marked as used
+func (s *Store) UserForAPIToken(secret string) (*User, APIToken, error) {
+	hash := hashToken(secret)
+	now := time.Now().UTC()
+
+	s.tokensMu.Lock()
+	var found *APIToken
+	for _, token := range s.apiTokens {
+		if token.Hash == hash {
+			found = token
+			break
+		}
+	}
+	if found == nil || (found.ExpiresAt != nil && now.After(*found.ExpiresAt)) {
+		s.tokensMu.Unlock()
+		return nil, APIToken{}, NewAuthError("invalid or expired API token")
+	}
+	if found.LastUsed == nil || now.Sub(*found.LastUsed) > sessionTouchInterval {
+		found.LastUsed = &now
+		if err := s.saveAPITokensLocked(); err != nil {
+			logger.Warn("Failed to record API token use", zap.String("tokenID", found.ID), zap.Error(err))
+		}
+	}
+	token := *found
+	s.tokensMu.Unlock()
+
+	user, err := s.Get(token.UserID)
+	if err != nil {
+		return nil, APIToken{}, NewAuthError("token user no longer exists")
+	}
+	return user, token, nil
+}
+
+func (s *Store) saveAPITokensLocked() error {
+	if err := storage.SaveJSON(filepath.Join(s.dataDir, apiTokensFileName), s.apiTokens); err != nil {
+		logger.Error("Failed to save API tokens", zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+func validScope(scope string) bool {
+	for _, s := range Scopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,83 @@
+package users
+
+import "fmt"
+
+// UserNotFoundError indicates that a user does not exist
+type UserNotFoundError struct {
+	Message string
+}
+
+func (e UserNotFoundError) Error() string {
+	return fmt.Sprintf("user not found: %s", e.Message)
+}
+
+// NewUserNotFoundError creates a new UserNotFoundError
+func NewUserNotFoundError(message string) error {
+	return UserNotFoundError{Message: message}
+}
+
+// IsUserNotFoundError checks if an error is a UserNotFoundError
+func IsUserNotFoundError(err error) bool {
+	_, ok := err.(UserNotFoundError)
+	return ok
+}
+
+// AuthError indicates missing, invalid or expired credentials
+type AuthError struct {
+	Message string
+}
+
+func (e AuthError) Error() string {
+	return fmt.Sprintf("authentication failed: %s", e.Message)
+}
+
+// NewAuthError creates a new AuthError
+func NewAuthError(message string) error {
+	return AuthError{Message: message}
+}
+
+// IsAuthError checks if an error is an AuthError
+func IsAuthError(err error) bool {
+	_, ok := err.(AuthError)
+	return ok
+}
+
+// ConflictError indicates that a user record clashes with an existing one
+type ConflictError struct {
+	Message string
+}
+
+func (e ConflictError) Error() string {
+	return fmt.Sprintf("conflict: %s", e.Message)
+}
+
+// NewConflictError creates a new ConflictError
+func NewConflictError(message string) error {
+	return ConflictError{Message: message}
+}
+
+// IsConflictError checks if an error is a ConflictError
+func IsConflictError(err error) bool {
+	_, ok := err.(ConflictError)
+	return ok
+}
+
+// ValidationError indicates invalid user input such as a short password
+type ValidationError struct {
+	Message string
+}
+
+func (e ValidationError) Error() string {
+	return fmt.Sprintf("validation error: %s", e.Message)
+}
+
+// NewValidationError creates a new ValidationError
+func NewValidationError(message string) error {
+	return ValidationError{Message: message}
+}
+
+// IsValidationError checks if an error is a ValidationError
+func IsValidationError(err error) bool {
+	_, ok := err.(ValidationError)
+	return ok
+}
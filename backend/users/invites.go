@@ -0,0 +1,145 @@
+package users
+
+import (
+	"path/filepath"
+	"sort"
+	"time"
+
+	"mangahub/backend/storage"
+
+	"go.uber.org/zap"
+)
+
+const invitesFileName = "invites.json"
+
+// Invite lets people register while registration is invite-only. It can be
+// used MaxUses times (unlimited when 0) until it expires.
+type Invite struct {
+	Code      string     `json:"code"`
+	Note      string     `json:"note,omitempty"`
+	CreatedBy string     `json:"createdBy,omitempty"`
+	CreatedAt time.Time  `json:"createdAt"`
+	ExpiresAt *time.Time `json:"expiresAt,omitempty"`
+	MaxUses   int        `json:"maxUses"`
+	UsedBy    []string   `json:"usedBy,omitempty"` // IDs of the users who registered with it
+}
+
+// Valid reports whether the invite can still be used at the given time
+func (i *Invite) Valid(now time.Time) bool {
+	if i.ExpiresAt != nil && !now.Before(*i.ExpiresAt) {
+		return false
+	}
+	return i.MaxUses == 0 || len(i.UsedBy) < i.MaxUses
+}
+
+// SetInviteOnly makes registration require an invite, except for the first
+// account
+func (s *Store) SetInviteOnly(inviteOnly bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.inviteOnly = inviteOnly
+}
+
+// InviteOnly reports whether registration requires an invite
+func (s *Store) InviteOnly() bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.inviteOnly
+}
+
+// CreateInvite mints an invite; a zero ttl never expires
+func (s *Store) CreateInvite(createdBy, note string, maxUses int, ttl time.Duration) (Invite, error) {
+	if maxUses < 0 || ttl < 0 {
+		return Invite{}, NewValidationError("maxUses and expiry must not be negative")
+	}
+
+	invite := &Invite{
+		Code:      randomID(12),
+		Note:      note,
+		CreatedBy: createdBy,
+		CreatedAt: time.Now().UTC(),
+		MaxUses:   maxUses,
+	}
+	if ttl > 0 {
+		expiresAt := invite.CreatedAt.Add(ttl)
+		invite.ExpiresAt = &expiresAt
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.invites[invite.Code] = invite
+	if err := s.saveInvitesLocked(); err != nil {
+		delete(s.invites, invite.Code)
+		return Invite{}, err
+	}
+	logger.Info("Invite created", zap.String("createdBy", createdBy), zap.Int("maxUses", maxUses))
+	return *invite, nil
+}
+
+// Invites returns every invite, newest first
+func (s *Store) Invites() []Invite {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	list := make([]Invite, 0, len(s.invites))
+	for _, invite := range s.invites {
+		list = append(list, *invite)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].CreatedAt.After(list[j].CreatedAt) })
+	return list
+}
+
+// CheckInvite returns an invite if it can still be used
+func (s *Store) CheckInvite(code string) (Invite, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	invite, ok := s.invites[code]
+	if !ok || !invite.Valid(time.Now()) {
+		return Invite{}, NewAuthError("invalid or expired invite")
+	}
+	return *invite, nil
+}
+
+// RevokeInvite deletes an invite; accounts registered with it are unaffected
+func (s *Store) RevokeInvite(code string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	invite, ok := s.invites[code]
+	if !ok {
+		return NewUserNotFoundError("no invite with code: " + code)
+	}
+	delete(s.invites, code)
+	if err := s.saveInvitesLocked(); err != nil {
+		s.invites[code] = invite
+		return err
+	}
+	logger.Info("Invite revoked", zap.String("code", code))
+	return nil
+}
+
+// useInviteLocked checks that an account may be registered with the given
+// invite code and returns the invite to record the registration on, if any
+func (s *Store) useInviteLocked(code string) (*Invite, error) {
+	if code == "" {
+		if s.inviteOnly && len(s.users) > 0 {
+			return nil, NewAuthError("an invite is required to register")
+		}
+		return nil, nil
+	}
+	invite, ok := s.invites[code]
+	if !ok || !invite.Valid(time.Now()) {
+		return nil, NewAuthError("invalid or expired invite")
+	}
+	return invite, nil
+}
+
+func (s *Store) saveInvitesLocked() error {
+	if err := storage.SaveJSON(filepath.Join(s.dataDir, invitesFileName), s.invites); err != nil {
+		logger.Error("Failed to save invites", zap.Error(err))
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,131 @@
+package users
+
+import (
+	"path/filepath"
+	"strings"
+	"time"
+
+	"mangahub/backend/storage"
+
+	"go.uber.org/zap"
+)
+
+const preferencesFileName = "preferences.json"
+
+// Reader settings accepted in Preferences
+var (
+	FitModes          = []string{"width", "height", "screen", "original"}
+	ReadingDirections = []string{"ltr", "rtl", "vertical"}
+
+	// ImageQualities map to the page image variants: full size, half and quarter scale
+	ImageQualities = []string{"original", "medium", "low"}
+)
+
+// Preferences are a user's reader settings, stored server-side so they follow
+// the user across devices
+type Preferences struct {
+	FitMode          string `json:"fitMode"`
+	ReadingDirection string `json:"readingDirection"`
+
+	// DirectionOverrides sets the reading direction for individual series, keyed by manga ID
+	DirectionOverrides map[string]string `json:"directionOverrides,omitempty"`
+
+	ImageQuality   string         `json:"imageQuality"`
+	ContentFilters ContentFilters `json:"contentFilters"`
+	UpdatedAt      *time.Time     `json:"updatedAt,omitempty"`
+}
+
+// ContentFilters lists what the user prefers not to see in listings
+type ContentFilters struct {
+	HiddenGenres       []string `json:"hiddenGenres,omitempty"`
+	HiddenTags         []string `json:"hiddenTags,omitempty"`
+	HiddenDemographics []string `json:"hiddenDemographics,omitempty"`
+}
+
+// DefaultPreferences returns the settings used until a user saves their own
+func DefaultPreferences() Preferences {
+	return Preferences{
+		FitMode:          "width",
+		ReadingDirection: "ltr",
+		ImageQuality:     "original",
+	}
+}
+
+// Validate fills in defaults for empty settings and rejects unknown values
+func (p *Preferences) Validate() error {
+	defaults := DefaultPreferences()
+	if p.FitMode == "" {
+		p.FitMode = defaults.FitMode
+	}
+	if p.ReadingDirection == "" {
+		p.ReadingDirection = defaults.ReadingDirection
+	}
+	if p.ImageQuality == "" {
+		p.ImageQuality = defaults.ImageQuality
+	}
+
+	if !oneOf(p.FitMode, FitModes) {
+		return NewValidationError("fitMode must be one of " + strings.Join(FitModes, ", "))
+	}
+	if !oneOf(p.ReadingDirection, ReadingDirections) {
+		return NewValidationError("readingDirection must be one of " + strings.Join(ReadingDirections, ", "))
+	}
+	for mangaID, direction := range p.DirectionOverrides {
+		if !oneOf(direction, ReadingDirections) {
+			return NewValidationError("direction override for " + mangaID + " must be one of " + strings.Join(ReadingDirections, ", "))
+		}
+	}
+	if !oneOf(p.ImageQuality, ImageQualities) {
+		return NewValidationError("imageQuality must be one of " + strings.Join(ImageQualities, ", "))
+	}
+	return nil
+}
+
+func oneOf(value string, allowed []string) bool {
+	for _, a := range allowed {
+		if value == a {
+			return true
+		}
+	}
+	return false
+}
+
+// Preferences returns the user's saved settings, or the defaults
+func (s *Store) Preferences(userID string) Preferences {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if prefs, ok := s.preferences[userID]; ok {
+		return prefs
+	}
+	return DefaultPreferences()
+}
+
+// SetPreferences validates and saves the user's settings
+func (s *Store) SetPreferences(userID string, prefs Preferences) (Preferences, error) {
+	if err := prefs.Validate(); err != nil {
+		return Preferences{}, err
+	}
+	now := time.Now().UTC()
+	prefs.UpdatedAt = &now
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.users[userID]; !ok {
+		return Preferences{}, NewUserNotFoundError("no user with ID: " + userID)
+	}
+
+	previous, existed := s.preferences[userID]
+	s.preferences[userID] = prefs
+	if err := storage.SaveJSON(filepath.Join(s.dataDir, preferencesFileName), s.preferences); err != nil {
+		if existed {
+			s.preferences[userID] = previous
+		} else {
+			delete(s.preferences, userID)
+		}
+		logger.Error("Failed to save preferences", zap.Error(err))
+		return Preferences{}, err
+	}
+	return prefs, nil
+}
@@ -0,0 +1,204 @@
+package users
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"mangahub/backend/storage"
+
+	"go.uber.org/zap"
+	"golang.org/x/crypto/bcrypt"
+)
+
+const (
+	actionTokensFileName = "action-tokens.json"
+
+	// PasswordResetLifetime is how long a password reset link stays valid
+	PasswordResetLifetime = time.Hour
+
+	// VerificationLifetime is how long an email verification link stays valid
+	VerificationLifetime = 48 * time.Hour
+)
+
+// Purposes of an action token
+const (
+	purposePasswordReset = "password-reset"
+	purposeVerifyEmail   = "verify-email"
+)
+
+// actionToken is a single-use token mailed to a user. Only its hash is
+// stored, so a leaked data directory doesn't leak working links.
+type actionToken struct {
+	Purpose   string    `json:"purpose"`
+	UserID    string    `json:"userId"`
+	Email     string    `json:"email,omitempty"` // Address being verified
+	ExpiresAt time.Time `json:"expiresAt"`
+}
+
+// CreatePasswordReset issues password reset tokens for the accounts using
+// an email address, returned keyed by token. No accounts is not an error, so
+// callers don't reveal which addresses are registered.
+func (s *Store) CreatePasswordReset(email string) (map[string]User, error) {
+	email = strings.TrimSpace(email)
+	if email == "" {
+		return nil, NewValidationError("email is required")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	tokens := make(map[string]User)
+	for _, user := range s.users {
+		if strings.EqualFold(user.Email, email) {
+			token := s.addActionTokenLocked(actionToken{
+				Purpose:   purposePasswordReset,
+				UserID:    user.ID,
+				ExpiresAt: time.Now().Add(PasswordResetLifetime).UTC(),
+			})
+			tokens[token] = *user
+		}
+	}
+	if len(tokens) == 0 {
+		return tokens, nil
+	}
+	if err := s.saveActionTokensLocked(); err != nil {
+		return nil, err
+	}
+	logger.Info("Password reset requested", zap.Int("accounts", len(tokens)))
+	return tokens, nil
+}
+
+// ResetPassword sets a new password using a reset token and ends every
+// session of the account.
+func (s *Store) ResetPassword(token, password string) (*User, error) {
+	if len(password) < MinPasswordLength {
+		return nil, NewValidationError("password must be at least 8 characters")
+	}
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	user, _, err := s.useActionTokenLocked(token, purposePasswordReset)
+	if err != nil {
+		return nil, err
+	}
+	previousHash := user.PasswordHash
+	user.PasswordHash = string(hash)
+	if err := s.saveLocked(); err != nil {
+		user.PasswordHash = previousHash
+		return nil, err
+	}
+	if _, err := s.RevokeSessions(user.ID, ""); err != nil {
+		logger.Error("Failed to end sessions after password reset", zap.String("userID", user.ID), zap.Error(err))
+	}
+
+	logger.Info("Password reset", zap.String("userID", user.ID))
+	copied := *user
+	return &copied, nil
+}
+
+// CreateEmailVerification issues a token confirming the user's current email
+func (s *Store) CreateEmailVerification(userID string) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	user, ok := s.users[userID]
+	if !ok {
+		return "", NewUserNotFoundError("no user with ID: " + userID)
+	}
+	switch {
+	case user.Email == "":
+		return "", NewValidationError("the account has no email address")
+	case user.EmailVerified:
+		return "", NewConflictError("email address already verified")
+	}
+
+	token := s.addActionTokenLocked(actionToken{
+		Purpose:   purposeVerifyEmail,
+		UserID:    user.ID,
+		Email:     user.Email,
+		ExpiresAt: time.Now().Add(VerificationLifetime).UTC(),
+	})
+	if err := s.saveActionTokensLocked(); err != nil {
+		return "", err
+	}
+	return token, nil
+}
+
+// VerifyEmail marks the user's email as verified using a verification token
+func (s *Store) VerifyEmail(token string) (*User, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	user, action, err := s.useActionTokenLocked(token, purposeVerifyEmail)
+	if err != nil {
+		return nil, err
+	}
+	if !strings.EqualFold(user.Email, action.Email) {
+		return nil, NewAuthError("the email address has changed since the link was sent")
+	}
+	user.EmailVerified = true
+	if err := s.saveLocked(); err != nil {
+		user.EmailVerified = false
+		return nil, err
+	}
+
+	logger.Info("Email verified", zap.String("userID", user.ID))
+	copied := *user
+	return &copied, nil
+}
+
+// addActionTokenLocked stores a new token and returns it; expired tokens are
+// dropped on the way
+func (s *Store) addActionTokenLocked(action actionToken) string {
+	now := time.Now()
+	for hash, existing := range s.actionTokens {
+		if now.After(existing.ExpiresAt) {
+			delete(s.actionTokens, hash)
+		}
+	}
+	token := randomID(24)
+	s.actionTokens[hashToken(token)] = action
+	return token
+}
+
+// useActionTokenLocked consumes a token issued for the given purpose
+func (s *Store) useActionTokenLocked(token, purpose string) (*User, actionToken, error) {
+	hash := hashToken(token)
+	action, ok := s.actionTokens[hash]
+	if !ok || action.Purpose != purpose || time.Now().After(action.ExpiresAt) {
+		return nil, actionToken{}, NewAuthError("invalid or expired link")
+	}
+	user, ok := s.users[action.UserID]
+	if !ok {
+		return nil, actionToken{}, NewAuthError("invalid or expired link")
+	}
+
+	delete(s.actionTokens, hash)
+	if err := s.saveActionTokensLocked(); err != nil {
+		s.actionTokens[hash] = action
+		return nil, actionToken{}, err
+	}
+	return user, action, nil
+}
+
+func (s *Store) saveActionTokensLocked() error {
+	if err := storage.SaveJSON(filepath.Join(s.dataDir, actionTokensFileName), s.actionTokens); err != nil {
+		logger.Error("Failed to save action tokens", zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+// hashToken is the form action tokens are stored in
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
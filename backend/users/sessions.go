@@ -0,0 +1,152 @@
+package users
+
+import (
+	"path/filepath"
+	"sort"
+	"time"
+
+	"mangahub/backend/storage"
+
+	"go.uber.org/zap"
+)
+
+const (
+	sessionsFileName = "sessions.json"
+
+	// sessionTouchInterval is how stale a session's last-seen time may get
+	// before it is saved again, so busy clients don't rewrite the file on
+	// every request
+	sessionTouchInterval = 5 * time.Minute
+)
+
+// Session is a login on one device. Every login token belongs to a session,
+// and revoking the session logs that device out.
+type Session struct {
+	ID        string    `json:"id"`
+	UserID    string    `json:"userId"`
+	Device    string    `json:"device"` // The client's User-Agent
+	IP        string    `json:"ip"`
+	CreatedAt time.Time `json:"createdAt"`
+	LastSeen  time.Time `json:"lastSeen"`
+	ExpiresAt time.Time `json:"expiresAt"`
+}
+
+// Sessions returns a user's active sessions, most recently used first
+func (s *Store) Sessions(userID string) []Session {
+	s.sessionsMu.Lock()
+	defer s.sessionsMu.Unlock()
+
+	now := time.Now()
+	list := []Session{}
+	for _, session := range s.sessions {
+		if session.UserID == userID && now.Before(session.ExpiresAt) {
+			list = append(list, *session)
+		}
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].LastSeen.After(list[j].LastSeen) })
+	return list
+}
+
+// RevokeSession ends one of a user's sessions
+func (s *Store) RevokeSession(userID, sessionID string) error {
+	s.sessionsMu.Lock()
+	defer s.sessionsMu.Unlock()
+
+	session, ok := s.sessions[sessionID]
+	if !ok || session.UserID != userID {
+		return NewUserNotFoundError("no session with ID: " + sessionID)
+	}
+	delete(s.sessions, sessionID)
+	if err := s.saveSessionsLocked(); err != nil {
+		s.sessions[sessionID] = session
+		return err
+	}
+	logger.Info("Session revoked", zap.String("userID", userID), zap.String("sessionID", sessionID))
+	return nil
+}
+
+// RevokeSessions ends all of a user's sessions except keep, which may be
+// empty, and returns how many were ended
+func (s *Store) RevokeSessions(userID, keep string) (int, error) {
+	s.sessionsMu.Lock()
+	defer s.sessionsMu.Unlock()
+	return s.revokeSessionsLocked(userID, keep)
+}
+
+func (s *Store) revokeSessionsLocked(userID, keep string) (int, error) {
+	revoked := make(map[string]*Session)
+	for id, session := range s.sessions {
+		if session.UserID == userID && id != keep {
+			revoked[id] = session
+			delete(s.sessions, id)
+		}
+	}
+	if len(revoked) == 0 {
+		return 0, nil
+	}
+	if err := s.saveSessionsLocked(); err != nil {
+		for id, session := range revoked {
+			s.sessions[id] = session
+		}
+		return 0, err
+	}
+	logger.Info("Sessions revoked", zap.String("userID", userID), zap.Int("count", len(revoked)))
+	return len(revoked), nil
+}
+
+func (s *Store) createSession(userID, device, ip string) (Session, error) {
+	now := time.Now().UTC()
+	session := &Session{
+		ID:        randomID(12),
+		UserID:    userID,
+		Device:    device,
+		IP:        ip,
+		CreatedAt: now,
+		LastSeen:  now,
+		ExpiresAt: now.Add(TokenLifetime),
+	}
+
+	s.sessionsMu.Lock()
+	defer s.sessionsMu.Unlock()
+
+	for id, existing := range s.sessions {
+		if now.After(existing.ExpiresAt) {
+			delete(s.sessions, id)
+		}
+	}
+	s.sessions[session.ID] = session
+	if err := s.saveSessionsLocked(); err != nil {
+		delete(s.sessions, session.ID)
+		return Session{}, err
+	}
+	return *session, nil
+}
+
+// touchSession looks up a live session and records that it was just used
+func (s *Store) touchSession(sessionID, userID, ip string) (Session, bool) {
+	s.sessionsMu.Lock()
+	defer s.sessionsMu.Unlock()
+
+	session, ok := s.sessions[sessionID]
+	now := time.Now().UTC()
+	if !ok || session.UserID != userID || now.After(session.ExpiresAt) {
+		return Session{}, false
+	}
+	if now.Sub(session.LastSeen) > sessionTouchInterval || session.IP != ip {
+		session.LastSeen = now
+		session.IP = ip
+		if err := s.saveSessionsLocked(); err != nil {
+			// Only the session list goes stale
+			logger.Warn("Failed to record session activity", zap.String("sessionID", sessionID), zap.Error(err))
+		}
+	}
+	return *session, true
+}
+
+func (s *Store) saveSessionsLocked() error {
+	if err := storage.SaveJSON(filepath.Join(s.dataDir, sessionsFileName), s.sessions); err != nil {
+		logger.Error("Failed to save sessions", zap.Error(err))
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,345 @@
+package users
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"mangahub/backend/storage"
+
+	"go.uber.org/zap"
+	"golang.org/x/crypto/bcrypt"
+)
+
+const (
+	usersFileName  = "users.json"
+	secretFileName = "token-secret"
+)
+
+// logger discards output until SetLogger installs the configured logger
+var logger = zap.NewNop()
+
+// SetLogger sets the logger used by this package
+func SetLogger(l *zap.Logger) {
+	logger = l
+}
+
+// Store keeps user accounts in a JSON file in the data directory
+type Store struct {
+	dataDir string
+	secret  []byte
+
+	mu          sync.RWMutex
+	users       map[string]*User       // keyed by ID
+	preferences map[string]Preferences // keyed by user ID
+	invites     map[string]*Invite     // keyed by code
+	inviteOnly  bool
+
+	// actionTokens are pending password resets and email verifications,
+	// keyed by token hash
+	actionTokens map[string]actionToken
+
+	sessionsMu sync.Mutex
+	sessions   map[string]*Session // keyed by ID
+
+	tokensMu  sync.Mutex
+	apiTokens map[string]*APIToken // keyed by ID
+}
+
+// NewStore loads (or initializes) the user store in dataDir
+func NewStore(dataDir string) (*Store, error) {
+	logger.Info("NewStore called", zap.String("dataDir", dataDir))
+
+	s := &Store{
+		dataDir:     dataDir,
+		users:       make(map[string]*User),
+		preferences: make(map[string]Preferences),
+		invites:     make(map[string]*Invite),
+
+		actionTokens: make(map[string]actionToken),
+		sessions:     make(map[string]*Session),
+		apiTokens:    make(map[string]*APIToken),
+	}
+
+	var list []*User
+	if err := storage.LoadJSON(filepath.Join(dataDir, usersFileName), &list); err != nil {
+		return nil, err
+	}
+	for _, u := range list {
+		s.users[u.ID] = u
+	}
+
+	if err := storage.LoadJSON(filepath.Join(dataDir, preferencesFileName), &s.preferences); err != nil {
+		return nil, err
+	}
+	if err := storage.LoadJSON(filepath.Join(dataDir, invitesFileName), &s.invites); err != nil {
+		return nil, err
+	}
+	if err := storage.LoadJSON(filepath.Join(dataDir, actionTokensFileName), &s.actionTokens); err != nil {
+		return nil, err
+	}
+	if err := storage.LoadJSON(filepath.Join(dataDir, sessionsFileName), &s.sessions); err != nil {
+		return nil, err
+	}
+	if err := storage.LoadJSON(filepath.Join(dataDir, apiTokensFileName), &s.apiTokens); err != nil {
+		return nil, err
+	}
+
+	secret, err := loadOrCreateSecret(filepath.Join(dataDir, secretFileName))
+	if err != nil {
+		return nil, err
+	}
+	s.secret = secret
+
+	logger.Info("User store loaded", zap.Int("userCount", len(s.users)))
+	return s, nil
+}
+
+// Register creates a new account. The first account created becomes an admin.
+// While registration is invite-only every later account needs a valid invite
+// code; otherwise the code is optional.
+func (s *Store) Register(username, password, email, inviteCode string) (*User, error) {
+	username = normalizeUsername(username)
+	if err := validateCredentials(username, password); err != nil {
+		return nil, err
+	}
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	invite, err := s.useInviteLocked(inviteCode)
+	if err != nil {
+		return nil, err
+	}
+	if s.findByUsernameLocked(username) != nil {
+		return nil, NewConflictError("username already taken")
+	}
+
+	user := &User{
+		ID:           randomID(8),
+		Username:     username,
+		Email:        email,
+		PasswordHash: string(hash),
+		Role:         RoleUser,
+		CreatedAt:    time.Now().UTC(),
+	}
+	if len(s.users) == 0 {
+		user.Role = RoleAdmin
+	}
+
+	s.users[user.ID] = user
+	if err := s.saveLocked(); err != nil {
+		delete(s.users, user.ID)
+		return nil, err
+	}
+	if invite != nil {
+		invite.UsedBy = append(invite.UsedBy, user.ID)
+		if err := s.saveInvitesLocked(); err != nil {
+			// The account exists; at worst the invite can be used once more
+			logger.Warn("Failed to record invite use", zap.String("code", invite.Code), zap.Error(err))
+		}
+	}
+
+	logger.Info("User registered", zap.String("userID", user.ID), zap.String("role", user.Role), zap.Bool("invited", invite != nil))
+	copied := *user
+	return &copied, nil
+}
+
+// Authenticate checks a username/password pair
+func (s *Store) Authenticate(username, password string) (*User, error) {
+	s.mu.RLock()
+	user := s.findByUsernameLocked(normalizeUsername(username))
+	s.mu.RUnlock()
+
+	if user == nil {
+		return nil, NewAuthError("invalid username or password")
+	}
+	if err := bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(password)); err != nil {
+		return nil, NewAuthError("invalid username or password")
+	}
+
+	copied := *user
+	return &copied, nil
+}
+
+// Get returns the user with the given ID
+func (s *Store) Get(id string) (*User, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	user, ok := s.users[id]
+	if !ok {
+		return nil, NewUserNotFoundError("no user with ID: " + id)
+	}
+	copied := *user
+	return &copied, nil
+}
+
+// List returns all users ordered by creation time
+func (s *Store) List() []User {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	list := make([]User, 0, len(s.users))
+	for _, u := range s.users {
+		list = append(list, *u)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].CreatedAt.Before(list[j].CreatedAt) })
+	return list
+}
+
+// SetQuota sets a user's personal library quota in megabytes; 0 restores the default
+func (s *Store) SetQuota(id string, quotaMB int) (*User, error) {
+	if quotaMB < 0 {
+		return nil, NewValidationError("quota must not be negative")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	user, ok := s.users[id]
+	if !ok {
+		return nil, NewUserNotFoundError("no user with ID: " + id)
+	}
+	previous := user.QuotaMB
+	user.QuotaMB = quotaMB
+	if err := s.saveLocked(); err != nil {
+		user.QuotaMB = previous
+		return nil, err
+	}
+
+	logger.Info("User quota changed", zap.String("userID", id), zap.Int("quotaMB", quotaMB))
+	copied := *user
+	return &copied, nil
+}
+
+// SetDownloadLimit sets how many chapters a user may download per day; 0
+// restores the default
+func (s *Store) SetDownloadLimit(id string, perDay int) (*User, error) {
+	if perDay < 0 {
+		return nil, NewValidationError("download limit must not be negative")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	user, ok := s.users[id]
+	if !ok {
+		return nil, NewUserNotFoundError("no user with ID: " + id)
+	}
+	previous := user.DownloadsPerDay
+	user.DownloadsPerDay = perDay
+	if err := s.saveLocked(); err != nil {
+		user.DownloadsPerDay = previous
+		return nil, err
+	}
+
+	logger.Info("User download limit changed", zap.String("userID", id), zap.Int("downloadsPerDay", perDay))
+	copied := *user
+	return &copied, nil
+}
+
+// Delete removes an account along with its settings, sessions, API tokens
+// and pending email links. The last admin can't be deleted. Once the account
+// is gone, failures to clean up after it are only logged.
+func (s *Store) Delete(id string) error {
+	s.mu.Lock()
+	user, ok := s.users[id]
+	if !ok {
+		s.mu.Unlock()
+		return NewUserNotFoundError("no user with ID: " + id)
+	}
+	if user.IsAdmin() {
+		admins := 0
+		for _, u := range s.users {
+			if u.IsAdmin() {
+				admins++
+			}
+		}
+		if admins == 1 {
+			s.mu.Unlock()
+			return NewConflictError("the last admin account cannot be deleted")
+		}
+	}
+	delete(s.users, id)
+	if err := s.saveLocked(); err != nil {
+		s.users[id] = user
+		s.mu.Unlock()
+		return err
+	}
+
+	if _, ok := s.preferences[id]; ok {
+		delete(s.preferences, id)
+		if err := storage.SaveJSON(filepath.Join(s.dataDir, preferencesFileName), s.preferences); err != nil {
+			logger.Error("Failed to save preferences", zap.Error(err))
+		}
+	}
+	for hash, action := range s.actionTokens {
+		if action.UserID == id {
+			delete(s.actionTokens, hash)
+		}
+	}
+	s.saveActionTokensLocked()
+	s.mu.Unlock()
+
+	s.sessionsMu.Lock()
+	s.revokeSessionsLocked(id, "")
+	s.sessionsMu.Unlock()
+
+	s.tokensMu.Lock()
+	for tokenID, token := range s.apiTokens {
+		if token.UserID == id {
+			delete(s.apiTokens, tokenID)
+		}
+	}
+	s.saveAPITokensLocked()
+	s.tokensMu.Unlock()
+
+	logger.Info("User deleted", zap.String("userID", id))
+	return nil
+}
+
+func (s *Store) findByUsernameLocked(username string) *User {
+	for _, u := range s.users {
+		if u.Username == username {
+			return u
+		}
+	}
+	return nil
+}
+
+// saveLocked persists all users; callers must hold s.mu
+func (s *Store) saveLocked() error {
+	list := make([]*User, 0, len(s.users))
+	for _, u := range s.users {
+		list = append(list, u)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].CreatedAt.Before(list[j].CreatedAt) })
+
+	if err := storage.SaveJSON(filepath.Join(s.dataDir, usersFileName), list); err != nil {
+		logger.Error("Failed to save users", zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+// loadOrCreateSecret returns the token signing key, generating it on first run
+func loadOrCreateSecret(path string) ([]byte, error) {
+	if secret, err := os.ReadFile(path); err == nil && len(secret) >= 32 {
+		return secret, nil
+	}
+
+	secret := []byte(randomID(32))
+	if err := storage.WriteFileAtomic(path, secret, 0600); err != nil {
+		return nil, err
+	}
+	logger.Info("Generated new token signing secret", zap.String("path", path))
+	return secret, nil
+}
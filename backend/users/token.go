@@ -0,0 +1,79 @@
+package users
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"strings"
+	"time"
+)
+
+// TokenLifetime is how long a login token stays valid
+const TokenLifetime = 30 * 24 * time.Hour
+
+// tokenClaims is the signed payload of a login token
+type tokenClaims struct {
+	UserID    string `json:"uid"`
+	SessionID string `json:"sid"`
+	ExpiresAt int64  `json:"exp"`
+}
+
+// IssueToken starts a session for the user and returns its signed login
+// token. device and ip describe the client for the session list.
+func (s *Store) IssueToken(user *User, device, ip string) (string, time.Time, error) {
+	session, err := s.createSession(user.ID, device, ip)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	payload, err := json.Marshal(tokenClaims{UserID: user.ID, SessionID: session.ID, ExpiresAt: session.ExpiresAt.Unix()})
+	if err != nil {
+		return "", time.Time{}, err
+	}
+
+	encoded := base64.RawURLEncoding.EncodeToString(payload)
+	return encoded + "." + s.sign(encoded), session.ExpiresAt, nil
+}
+
+// UserForToken verifies a login token and returns the user it was issued to
+// along with its session, which is marked as seen from ip
+func (s *Store) UserForToken(token, ip string) (*User, Session, error) {
+	encoded, signature, ok := strings.Cut(token, ".")
+	if !ok || !hmac.Equal([]byte(signature), []byte(s.sign(encoded))) {
+		return nil, Session{}, NewAuthError("invalid token")
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, Session{}, NewAuthError("invalid token")
+	}
+	var claims tokenClaims
+	if err := json.Unmarshal(payload, &claims); err != nil {
+		return nil, Session{}, NewAuthError("invalid token")
+	}
+	if time.Now().Unix() > claims.ExpiresAt {
+		return nil, Session{}, NewAuthError("token expired")
+	}
+
+	session, ok := s.touchSession(claims.SessionID, claims.UserID, ip)
+	if !ok {
+		return nil, Session{}, NewAuthError("session revoked")
+	}
+	user, err := s.Get(claims.UserID)
+	if err != nil {
+		return nil, Session{}, NewAuthError("token user no longer exists")
+	}
+	return user, session, nil
+}
+
+// CSRFToken derives the CSRF token of a login token, which cookie-authenticated
+// requests must echo
+func (s *Store) CSRFToken(loginToken string) string {
+	return s.sign("csrf." + loginToken)
+}
+
+func (s *Store) sign(encoded string) string {
+	mac := hmac.New(sha256.New, s.secret)
+	mac.Write([]byte(encoded))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
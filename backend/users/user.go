@@ -0,0 +1,85 @@
+package users
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// Roles a user can have
+const (
+	RoleAdmin = "admin"
+	RoleUser  = "user"
+)
+
+// MinPasswordLength is the shortest password accepted at registration
+const MinPasswordLength = 8
+
+var usernamePattern = regexp.MustCompile(`^[a-zA-Z0-9_.-]{3,32}$`)
+
+// User is a registered account
+type User struct {
+	ID           string    `json:"id"`
+	Username     string    `json:"username"`
+	Email        string    `json:"email,omitempty"`
+	PasswordHash string    `json:"passwordHash"`
+	Role         string    `json:"role"`
+	CreatedAt    time.Time `json:"createdAt"`
+
+	// QuotaMB overrides the default personal library quota; 0 uses the default
+	QuotaMB int `json:"quotaMB,omitempty"`
+
+	// DownloadsPerDay overrides the default daily chapter download limit; 0
+	// uses the default
+	DownloadsPerDay int `json:"downloadsPerDay,omitempty"`
+
+	EmailVerified bool `json:"emailVerified,omitempty"`
+}
+
+// IsAdmin reports whether the user has the admin role
+func (u *User) IsAdmin() bool {
+	return u.Role == RoleAdmin
+}
+
+// Public returns the fields of the user that are safe to send to clients
+func (u *User) Public() map[string]interface{} {
+	return map[string]interface{}{
+		"id":        u.ID,
+		"username":  u.Username,
+		"email":     u.Email,
+		"role":      u.Role,
+		"createdAt": u.CreatedAt,
+		"quotaMB":   u.QuotaMB,
+
+		"downloadsPerDay": u.DownloadsPerDay,
+
+		"emailVerified": u.EmailVerified,
+	}
+}
+
+// validateCredentials checks username and password rules for new accounts
+func validateCredentials(username, password string) error {
+	if !usernamePattern.MatchString(username) {
+		return NewValidationError("username must be 3-32 characters of letters, digits, '.', '_' or '-'")
+	}
+	if len(password) < MinPasswordLength {
+		return NewValidationError("password must be at least 8 characters")
+	}
+	return nil
+}
+
+// normalizeUsername is used for case-insensitive username lookups
+func normalizeUsername(username string) string {
+	return strings.ToLower(strings.TrimSpace(username))
+}
+
+// randomID returns a random hex identifier of n bytes
+func randomID(n int) string {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		panic("crypto/rand failed: " + err.Error())
+	}
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,40 @@
+// Package web holds the built frontend, embedded into the binary so a single
+// executable serves the whole app. Build it with "npm run build" in frontend/
+// before building the server.
+package web
+
+import (
+	"embed"
+	"fmt"
+	"io/fs"
+	"os"
+)
+
+// IndexFile is the single-page app's entry point
+const IndexFile = "index.html"
+
+//go:embed all:dist
+var dist embed.FS
+
+// FS returns the frontend files: those in dir when set, e.g. to try a
+// frontend build or a customized frontend without rebuilding the server,
+// otherwise the embedded build
+func FS(dir string) (fs.FS, error) {
+	if dir == "" {
+		return fs.Sub(dist, "dist")
+	}
+	info, err := os.Stat(dir)
+	if err != nil {
+		return nil, err
+	}
+	if !info.IsDir() {
+		return nil, fmt.Errorf("%s is not a directory", dir)
+	}
+	return os.DirFS(dir), nil
+}
+
+// Built reports whether files hold a frontend build
+func Built(files fs.FS) bool {
+	_, err := fs.Stat(files, IndexFile)
+	return err == nil
+}
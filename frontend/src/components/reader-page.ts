@@ -99,7 +99,7 @@ export async function renderReaderPage(mangaId: string, chapterNumber: number, p
     const nextButton = document.getElementById('next-page') as HTMLButtonElement;
     
     if (prevButton) {
-      if (pageNumber <= 1 && !pageData.prevChapter) {
+      if (!pageData.navigation?.hasPrev) {
         prevButton.disabled = true;
       } else {
         prevButton.addEventListener('click', () => {
@@ -109,7 +109,7 @@ export async function renderReaderPage(mangaId: string, chapterNumber: number, p
     }
     
     if (nextButton) {
-      if (pageNumber >= (pageData.totalPages || 0) && !pageData.nextChapter) {
+      if (!pageData.navigation?.hasNext) {
         nextButton.disabled = true;
       } else {
         nextButton.addEventListener('click', () => {
@@ -159,11 +159,15 @@ function navigateToPrevPage(
   pageNumber: number, 
   pageData: any
 ) {
-  if (pageNumber > 1) {
+  const nav = pageData.navigation;
+  if (nav?.hasPrevPage) {
     // Go to previous page in same chapter
-    navigateTo(`/reader/${mangaId}/${chapterNumber}/${pageNumber - 1}`);
+    navigateTo(`/reader/${mangaId}/${chapterNumber}/${nav.prevPage}`);
+  } else if (nav?.prevChapter && nav.prevChapterLastPage) {
+    // Go straight to the last page of the previous chapter
+    navigateTo(`/reader/${mangaId}/${nav.prevChapter}/${nav.prevChapterLastPage}`);
   } else if (pageData.prevChapter) {
-    // Go to last page of previous chapter
+    // Fall back to looking up the previous chapter's page count
     getChapters(mangaId)
       .then(chapters => {
         const prevChapter = chapters.find(ch => ch.number === parseFloat(pageData.prevChapter));
@@ -184,9 +188,10 @@ function navigateToNextPage(
   pageNumber: number, 
   pageData: any
 ) {
-  if (pageNumber < (pageData.totalPages || 0)) {
+  const nav = pageData.navigation;
+  if (nav?.hasNextPage) {
     // Go to next page in same chapter
-    navigateTo(`/reader/${mangaId}/${chapterNumber}/${pageNumber + 1}`);
+    navigateTo(`/reader/${mangaId}/${chapterNumber}/${nav.nextPage}`);
   } else if (pageData.nextChapter) {
     // Go to first page of next chapter
     navigateTo(`/reader/${mangaId}/${pageData.nextChapter}/1`);
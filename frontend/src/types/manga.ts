@@ -5,6 +5,7 @@ export interface Manga {
     description: string;
     coverImage: string;
     genres: string[];
+    tags?: string[];
     author: string;
     artist?: string;
     status: string;
@@ -12,6 +13,12 @@ export interface Manga {
     lastUpdated: string;
     chapterCount: number;
     altTitles?: string[];
+    publisher?: string;
+    demographic?: string;
+    serialization?: string;
+    rating?: number;
+    ratingCount?: number;
+    customFields?: Record<string, string>;
   }
   
   // Chapter interface
@@ -24,6 +31,7 @@ export interface Manga {
     pageCount: number;
     volume?: number;
     special?: boolean;
+    thumbnailUrl?: string;
   }
   
   // Page interface
@@ -37,4 +45,22 @@ export interface Manga {
     prevPage?: number;
     nextChapter?: string;
     prevChapter?: string;
+    navigation?: PageNavigation;
+  }
+
+  // Navigation info returned with every page
+  export interface PageNavigation {
+    hasNext: boolean;
+    hasPrev: boolean;
+    hasNextPage: boolean;
+    hasPrevPage: boolean;
+    nextPage: number;
+    prevPage: number;
+    hasNextChapter: boolean;
+    hasPrevChapter: boolean;
+    nextChapter?: string;
+    prevChapter?: string;
+    nextChapterId?: string;
+    prevChapterId?: string;
+    prevChapterLastPage?: number;
   }
\ No newline at end of file
@@ -1,12 +1,25 @@
 import { defineConfig } from 'vite';
 import { fileURLToPath } from 'url';
 import path from 'path';
+import fs from 'fs';
 
 const __dirname = path.dirname(fileURLToPath(import.meta.url));
 
+// The server embeds this directory, and Go can't embed an empty one, so it
+// keeps a placeholder file, put back after each build empties it
+const outDir = path.resolve(__dirname, '../backend/web/dist');
+
 export default defineConfig({
+  plugins: [
+    {
+      name: 'keep-embed-placeholder',
+      closeBundle() {
+        fs.writeFileSync(path.join(outDir, '.gitkeep'), '');
+      }
+    }
+  ],
   build: {
-    outDir: '../backend/static',
+    outDir,
     emptyOutDir: true,
     rollupOptions: {
       input: path.resolve(__dirname, 'index.html')